@@ -3,31 +3,94 @@ package ethereum
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"strconv"
 	"sync"
 	"time"
 )
 
-// BlockCounter represents a block counter.
-type BlockCounter struct {
+// headerWatcherBufferSize is the size of the channel buffer of header
+// watchers. Headers are fetched one by one when the counter catches up
+// after a jump of the block height so the buffer gives the consumer some
+// time to process them before they start being dropped. The same size is
+// used for the queue of block heights whose headers are yet to be fetched.
+const headerWatcherBufferSize = 100
+
+// headerFetchTimeout is the timeout of fetching a single block header.
+const headerFetchTimeout = 10 * time.Second
+
+// resubscribeDelay is the delay after which the block counter re-establishes
+// the interrupted subscription to new blocks.
+const resubscribeDelay = 5 * time.Second
+
+// BlockCounter provides the ability to wait for and watch new blocks
+// of the chain.
+type BlockCounter interface {
+	BlockHeightWaiter
+
+	// BlockHeightWaiter returns a channel receiving the given block height
+	// once it is reached.
+	BlockHeightWaiter(blockNumber uint64) (<-chan uint64, error)
+
+	// BlockHeightWaiterWithConfirmations returns a channel receiving
+	// the block height once the given block is buried under the given
+	// number of confirmations.
+	BlockHeightWaiterWithConfirmations(
+		blockNumber uint64,
+		confirmations uint64,
+	) (<-chan uint64, error)
+
+	// CurrentBlock returns the current block height.
+	CurrentBlock() (uint64, error)
+
+	// WatchBlocks returns a channel receiving each new block height until
+	// the given context is done.
+	WatchBlocks(ctx context.Context) <-chan uint64
+}
+
+// EthereumBlockCounter is a BlockCounter implementation tracking blocks
+// of the Ethereum chain.
+type EthereumBlockCounter struct {
 	structMutex         sync.Mutex
 	latestBlockHeight   uint64
 	subscriptionChannel chan block
 	waiters             map[uint64][]chan uint64
-	watchers            []*watcher
+	watchers            []*watcher[uint64]
+	headerWatchers      []*watcher[*Header]
+	chainReader         ChainReader
+
+	// maxBlockInterval is the maximum time the subscription to new blocks
+	// can stay silent before it is considered dead and re-established.
+	// The subscription is not watched if the value is not set.
+	maxBlockInterval time.Duration
+	resubscribeDelay time.Duration
+}
+
+// BlockCounterOption is an option of the block counter.
+type BlockCounterOption func(*EthereumBlockCounter)
+
+// WithMaxBlockInterval enables the watchdog of the subscription to new
+// blocks. Some providers stop delivering new blocks without reporting
+// an error on the subscription which would stall the block counter forever.
+// If no new block is received within the given interval, the subscription
+// is torn down and re-established.
+func WithMaxBlockInterval(maxBlockInterval time.Duration) BlockCounterOption {
+	return func(bc *EthereumBlockCounter) {
+		bc.maxBlockInterval = maxBlockInterval
+	}
 }
 
 type block struct {
 	Number string
 }
 
-type watcher struct {
+type watcher[T any] struct {
 	ctx     context.Context
-	channel chan uint64
+	channel chan T
 }
 
 // WaitForBlockHeight waits for a given block height.
-func (bc *BlockCounter) WaitForBlockHeight(blockNumber uint64) error {
+func (bc *EthereumBlockCounter) WaitForBlockHeight(blockNumber uint64) error {
 	waiter, err := bc.BlockHeightWaiter(blockNumber)
 	if err != nil {
 		return err
@@ -37,7 +100,7 @@ func (bc *BlockCounter) WaitForBlockHeight(blockNumber uint64) error {
 }
 
 // BlockHeightWaiter returns a waiter for the given block.
-func (bc *BlockCounter) BlockHeightWaiter(
+func (bc *EthereumBlockCounter) BlockHeightWaiter(
 	blockNumber uint64,
 ) (<-chan uint64, error) {
 	newWaiter := make(chan uint64)
@@ -59,14 +122,35 @@ func (bc *BlockCounter) BlockHeightWaiter(
 	return newWaiter, nil
 }
 
+// BlockHeightWaiterWithConfirmations returns a waiter for the given block
+// buried under the given number of confirmations. The waiter fires once the
+// block height of blockNumber + confirmations is reached so that the given
+// block is unlikely to be reverted by a reorg. The returned channel receives
+// the block height at which the confirmation requirement has been met.
+func (bc *EthereumBlockCounter) BlockHeightWaiterWithConfirmations(
+	blockNumber uint64,
+	confirmations uint64,
+) (<-chan uint64, error) {
+	confirmedBlockNumber := blockNumber + confirmations
+	if confirmedBlockNumber < blockNumber {
+		return nil, fmt.Errorf(
+			"block [%v] with [%v] confirmations exceeds the maximum height",
+			blockNumber,
+			confirmations,
+		)
+	}
+
+	return bc.BlockHeightWaiter(confirmedBlockNumber)
+}
+
 // CurrentBlock returns the current block.
-func (bc *BlockCounter) CurrentBlock() (uint64, error) {
+func (bc *EthereumBlockCounter) CurrentBlock() (uint64, error) {
 	return bc.latestBlockHeight, nil
 }
 
 // WatchBlocks watches the blocks.
-func (bc *BlockCounter) WatchBlocks(ctx context.Context) <-chan uint64 {
-	watcher := &watcher{
+func (bc *EthereumBlockCounter) WatchBlocks(ctx context.Context) <-chan uint64 {
+	watcher := &watcher[uint64]{
 		ctx:     ctx,
 		channel: make(chan uint64),
 	}
@@ -79,23 +163,96 @@ func (bc *BlockCounter) WatchBlocks(ctx context.Context) <-chan uint64 {
 		<-ctx.Done()
 
 		bc.structMutex.Lock()
-		for i, w := range bc.watchers {
-			if w == watcher {
-				bc.watchers[i] = bc.watchers[len(bc.watchers)-1]
-				bc.watchers = bc.watchers[:len(bc.watchers)-1]
-				break
-			}
-		}
+		bc.watchers = removeWatcher(bc.watchers, watcher)
+		bc.structMutex.Unlock()
+	}()
+
+	return watcher.channel
+}
+
+// WatchBlockHeaders watches the blocks and delivers their headers, including
+// block hashes and timestamps. Unlike WatchBlocks, the counter fetches each
+// block from the chain, including blocks skipped when the counter catches up
+// after a jump of the block height, so the returned channel can be used for
+// per-block processing. Headers are dropped if the consumer does not keep up
+// with processing them.
+func (bc *EthereumBlockCounter) WatchBlockHeaders(ctx context.Context) <-chan *Header {
+	watcher := &watcher[*Header]{
+		ctx:     ctx,
+		channel: make(chan *Header, headerWatcherBufferSize),
+	}
+
+	bc.structMutex.Lock()
+	bc.headerWatchers = append(bc.headerWatchers, watcher)
+	bc.structMutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		bc.structMutex.Lock()
+		bc.headerWatchers = removeWatcher(bc.headerWatchers, watcher)
 		bc.structMutex.Unlock()
 	}()
 
 	return watcher.channel
 }
 
+// ConsumeBlocks watches the blocks of the given block counter and calls
+// the handler with the height of each new block. It blocks until the given
+// context is done or the channel of the watcher is closed, and the watcher is
+// removed from the block counter once the context is done. The handler is
+// called synchronously so, just like with WatchBlocks, blocks mined while
+// the handler is still processing the previous block are dropped instead of
+// piling up behind a slow handler.
+func ConsumeBlocks(
+	ctx context.Context,
+	blockCounter BlockCounter,
+	handler func(height uint64),
+) {
+	blocksChan := blockCounter.WatchBlocks(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case height, ok := <-blocksChan:
+			if !ok {
+				return
+			}
+
+			// The context may have been cancelled while the block was
+			// being delivered.
+			if ctx.Err() != nil {
+				return
+			}
+
+			handler(height)
+		}
+	}
+}
+
+func removeWatcher[T any](watchers []*watcher[T], toRemove *watcher[T]) []*watcher[T] {
+	for i, w := range watchers {
+		if w == toRemove {
+			watchers[i] = watchers[len(watchers)-1]
+			return watchers[:len(watchers)-1]
+		}
+	}
+
+	return watchers
+}
+
 // receiveBlocks gets each new block back from Geth and extracts the
 // block height (topBlockNumber) form it. For each block height that is being
-// waited on a message will be sent.
-func (bc *BlockCounter) receiveBlocks() {
+// waited on a message will be sent. Headers of the new blocks are fetched
+// by a separate goroutine so that a slow chain reader does not hold up
+// the waiters and watchers of block heights.
+func (bc *EthereumBlockCounter) receiveBlocks() {
+	headerHeights := make(chan uint64, headerWatcherBufferSize)
+	defer close(headerHeights)
+
+	go bc.fetchHeaders(headerHeights)
+
 	for block := range bc.subscriptionChannel {
 		topBlockNumber, err := strconv.ParseInt(block.Number, 0, 32)
 		if err != nil {
@@ -133,27 +290,99 @@ func (bc *BlockCounter) receiveBlocks() {
 			}
 
 			bc.structMutex.Lock()
-			watchers := make([]*watcher, len(bc.watchers))
+			watchers := make([]*watcher[uint64], len(bc.watchers))
 			copy(watchers, bc.watchers)
+			hasHeaderWatchers := len(bc.headerWatchers) > 0
 			bc.structMutex.Unlock()
 
-			for _, watcher := range watchers {
-				if watcher.ctx.Err() != nil {
-					close(watcher.channel)
-					continue
-				}
+			notifyWatchers(watchers, height)
 
+			// Fetch the block header only if someone is interested in it
+			// so that height-only consumers do not cost additional requests.
+			if hasHeaderWatchers {
 				select {
-				case watcher.channel <- height: // perfect
-				default: // we don't care, let's drop it
+				case headerHeights <- height:
+				default:
+					logger.Warningf(
+						"dropping header of block [%v]; "+
+							"fetching headers does not keep up",
+						height,
+					)
 				}
 			}
 		}
 	}
 }
 
+// fetchHeaders fetches the headers of blocks with the heights received from
+// the given channel and delivers them to the header watchers. It returns
+// once the channel is closed.
+func (bc *EthereumBlockCounter) fetchHeaders(heights <-chan uint64) {
+	for height := range heights {
+		bc.structMutex.Lock()
+		headerWatchers := make([]*watcher[*Header], len(bc.headerWatchers))
+		copy(headerWatchers, bc.headerWatchers)
+		bc.structMutex.Unlock()
+
+		if len(headerWatchers) == 0 {
+			continue
+		}
+
+		header, err := bc.fetchHeader(height)
+		if err != nil {
+			logger.Warningf(
+				"could not fetch header of block [%v]: [%v]",
+				height,
+				err,
+			)
+			continue
+		}
+
+		notifyWatchers(headerWatchers, header)
+	}
+}
+
+func notifyWatchers[T any](watchers []*watcher[T], value T) {
+	for _, watcher := range watchers {
+		if watcher.ctx.Err() != nil {
+			close(watcher.channel)
+			continue
+		}
+
+		select {
+		case watcher.channel <- value: // perfect
+		default: // we don't care, let's drop it
+		}
+	}
+}
+
+// fetchHeader fetches the header of the block with the given number. Only
+// the header is requested if the chain reader implements HeaderReader;
+// otherwise, the whole block is fetched.
+func (bc *EthereumBlockCounter) fetchHeader(blockNumber uint64) (*Header, error) {
+	if bc.chainReader == nil {
+		return nil, fmt.Errorf("chain reader not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), headerFetchTimeout)
+	defer cancel()
+
+	number := new(big.Int).SetUint64(blockNumber)
+
+	if headerReader, ok := bc.chainReader.(HeaderReader); ok {
+		return headerReader.HeaderByNumber(ctx, number)
+	}
+
+	block, err := bc.chainReader.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return block.Header, nil
+}
+
 // subscribeBlocks creates a subscription to Geth to get each block.
-func (bc *BlockCounter) subscribeBlocks(
+func (bc *EthereumBlockCounter) subscribeBlocks(
 	ctx context.Context,
 	chainReader ChainReader,
 ) error {
@@ -188,6 +417,15 @@ func (bc *BlockCounter) subscribeBlocks(
 				subscription.Unsubscribe()
 				errorChan <- err
 				return
+			case <-bc.watchdog():
+				logger.Warningf(
+					"no new blocks received within [%v]; "+
+						"subscription to new blocks stalled",
+					bc.maxBlockInterval,
+				)
+				subscription.Unsubscribe()
+				errorChan <- fmt.Errorf("subscription to new blocks stalled")
+				return
 			}
 		}
 
@@ -197,7 +435,7 @@ func (bc *BlockCounter) subscribeBlocks(
 		for {
 			go subscribe()
 			<-errorChan
-			time.Sleep(5 * time.Second)
+			time.Sleep(bc.resubscribeDelay)
 		}
 	}()
 
@@ -211,8 +449,22 @@ func (bc *BlockCounter) subscribeBlocks(
 	return nil
 }
 
+// watchdog returns a channel receiving a value once the maximum interval
+// between new blocks passes. The channel never receives a value if the
+// maximum interval is not set.
+func (bc *EthereumBlockCounter) watchdog() <-chan time.Time {
+	if bc.maxBlockInterval <= 0 {
+		return nil
+	}
+
+	return time.After(bc.maxBlockInterval)
+}
+
 // CreateBlockCounter creates a block counter.
-func CreateBlockCounter(chainReader ChainReader) (*BlockCounter, error) {
+func CreateBlockCounter(
+	chainReader ChainReader,
+	options ...BlockCounterOption,
+) (*EthereumBlockCounter, error) {
 	ctx := context.Background()
 
 	startupBlock, err := chainReader.BlockByNumber(ctx, nil)
@@ -224,10 +476,16 @@ func CreateBlockCounter(chainReader ChainReader) (*BlockCounter, error) {
 			)
 	}
 
-	blockCounter := &BlockCounter{
+	blockCounter := &EthereumBlockCounter{
 		latestBlockHeight:   startupBlock.Number.Uint64(),
 		waiters:             make(map[uint64][]chan uint64),
 		subscriptionChannel: make(chan block),
+		chainReader:         chainReader,
+		resubscribeDelay:    resubscribeDelay,
+	}
+
+	for _, option := range options {
+		option(blockCounter)
 	}
 
 	go blockCounter.receiveBlocks()
@@ -2,6 +2,11 @@ package ethereum
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -11,7 +16,7 @@ func TestWaitForNewMinedBlock(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
 
-	blockCounter := &BlockCounter{
+	blockCounter := &EthereumBlockCounter{
 		latestBlockHeight:   uint64(1),
 		waiters:             make(map[uint64][]chan uint64),
 		subscriptionChannel: make(chan block),
@@ -43,11 +48,77 @@ func TestWaitForNewMinedBlock(t *testing.T) {
 	}
 }
 
+func TestBlockHeightWaiterWithConfirmations(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	blockCounter := &EthereumBlockCounter{
+		latestBlockHeight:   uint64(1),
+		waiters:             make(map[uint64][]chan uint64),
+		subscriptionChannel: make(chan block),
+	}
+
+	block2Waiter, err := blockCounter.BlockHeightWaiterWithConfirmations(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go blockCounter.receiveBlocks()
+
+	blockCounter.subscriptionChannel <- block{Number: "2"}
+	blockCounter.subscriptionChannel <- block{Number: "4"}
+
+	select {
+	case block := <-block2Waiter:
+		t.Fatalf("unexpected block [%v] before confirmations", block)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	blockCounter.subscriptionChannel <- block{Number: "5"}
+
+	select {
+	case block := <-block2Waiter:
+		if block != 5 {
+			t.Fatalf(
+				"unexpected block number\nexpected: [5]\nactual:   [%v]\n",
+				block,
+			)
+		}
+
+	case <-ctx.Done():
+		t.Fatal(ctx.Err())
+	}
+}
+
+func TestBlockHeightWaiterWithConfirmations_Overflow(t *testing.T) {
+	blockCounter := &EthereumBlockCounter{
+		latestBlockHeight: uint64(1),
+		waiters:           make(map[uint64][]chan uint64),
+	}
+
+	_, err := blockCounter.BlockHeightWaiterWithConfirmations(
+		math.MaxUint64,
+		1,
+	)
+
+	expectedError := fmt.Sprintf(
+		"block [%v] with [1] confirmations exceeds the maximum height",
+		uint64(math.MaxUint64),
+	)
+	if err == nil || err.Error() != expectedError {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
 func TestExecuteBlockHandlerOnlyOnce(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	blockCounter := &BlockCounter{
+	blockCounter := &EthereumBlockCounter{
 		latestBlockHeight:   uint64(1),
 		waiters:             make(map[uint64][]chan uint64),
 		subscriptionChannel: make(chan block),
@@ -104,7 +175,7 @@ func TestExecuteBlockHandlerOnlyOnce(t *testing.T) {
 }
 
 func TestWatchBlocks(t *testing.T) {
-	blockCounter := &BlockCounter{
+	blockCounter := &EthereumBlockCounter{
 		latestBlockHeight:   uint64(1),
 		waiters:             make(map[uint64][]chan uint64),
 		subscriptionChannel: make(chan block),
@@ -156,7 +227,7 @@ func TestWatchBlocksIsNonBlocking(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	blockCounter := &BlockCounter{
+	blockCounter := &EthereumBlockCounter{
 		latestBlockHeight:   uint64(1),
 		waiters:             make(map[uint64][]chan uint64),
 		subscriptionChannel: make(chan block),
@@ -184,3 +255,339 @@ func TestWatchBlocksIsNonBlocking(t *testing.T) {
 		t.Fatalf("watcher should receive [2] blocks, has [%v]", receivedCount)
 	}
 }
+
+func TestConsumeBlocks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blockCounter := &EthereumBlockCounter{
+		latestBlockHeight:   uint64(1),
+		waiters:             make(map[uint64][]chan uint64),
+		subscriptionChannel: make(chan block),
+	}
+	go blockCounter.receiveBlocks()
+
+	handled := make(chan uint64, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ConsumeBlocks(ctx, blockCounter, func(height uint64) {
+			handled <- height
+		})
+	}()
+	// give some time for the consumer to register the watcher
+	time.Sleep(50 * time.Millisecond)
+
+	blockCounter.subscriptionChannel <- block{Number: "2"}
+
+	select {
+	case height := <-handled:
+		if height != 2 {
+			t.Errorf(
+				"unexpected block height\nexpected: [%v]\nactual:   [%v]",
+				2,
+				height,
+			)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("block not handled")
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("consumer did not return after cancelling the context")
+	}
+
+	blockCounter.subscriptionChannel <- block{Number: "3"}
+	time.Sleep(50 * time.Millisecond)
+
+	select {
+	case height := <-handled:
+		t.Errorf("unexpected block handled after cancelling: [%v]", height)
+	default:
+	}
+
+	blockCounter.structMutex.Lock()
+	watchersCount := len(blockCounter.watchers)
+	blockCounter.structMutex.Unlock()
+
+	if watchersCount != 0 {
+		t.Errorf(
+			"unexpected number of watchers\nexpected: [%v]\nactual:   [%v]",
+			0,
+			watchersCount,
+		)
+	}
+}
+
+func TestWatchBlockHeaders(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blockCounter := &EthereumBlockCounter{
+		latestBlockHeight:   uint64(1),
+		waiters:             make(map[uint64][]chan uint64),
+		subscriptionChannel: make(chan block),
+		chainReader:         &mockChainReader{},
+	}
+	go blockCounter.receiveBlocks()
+
+	headerWatcher := blockCounter.WatchBlockHeaders(ctx)
+
+	// Jump from block 1 to block 4; headers of the skipped blocks 2 and 3
+	// should be delivered as well.
+	blockCounter.subscriptionChannel <- block{Number: "4"}
+
+	var receivedNumbers []uint64
+	var receivedTimes []uint64
+	for i := 0; i < 3; i++ {
+		select {
+		case header := <-headerWatcher:
+			receivedNumbers = append(receivedNumbers, header.Number.Uint64())
+			receivedTimes = append(receivedTimes, header.Time)
+		case <-time.After(time.Second):
+			t.Fatalf("header [%v] has not been received", i)
+		}
+	}
+
+	expectedNumbers := []uint64{2, 3, 4}
+	if !reflect.DeepEqual(expectedNumbers, receivedNumbers) {
+		t.Errorf(
+			"unexpected block numbers\nexpected: [%v]\nactual:   [%v]",
+			expectedNumbers,
+			receivedNumbers,
+		)
+	}
+
+	expectedTimes := []uint64{20, 30, 40}
+	if !reflect.DeepEqual(expectedTimes, receivedTimes) {
+		t.Errorf(
+			"unexpected block times\nexpected: [%v]\nactual:   [%v]",
+			expectedTimes,
+			receivedTimes,
+		)
+	}
+}
+
+func TestWatchBlockHeaders_SlowHeaderReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chainReader := &mockHeaderChainReader{
+		mockChainReader: &mockChainReader{},
+		release:         make(chan struct{}),
+	}
+
+	blockCounter := &EthereumBlockCounter{
+		latestBlockHeight:   uint64(1),
+		waiters:             make(map[uint64][]chan uint64),
+		subscriptionChannel: make(chan block),
+		chainReader:         chainReader,
+	}
+	go blockCounter.receiveBlocks()
+
+	// The watcher channel is not buffered so the heights need to be
+	// received in the background not to be dropped.
+	heights := make(chan uint64, 2)
+	go func() {
+		for height := range blockCounter.WatchBlocks(ctx) {
+			heights <- height
+		}
+	}()
+	headerWatcher := blockCounter.WatchBlockHeaders(ctx)
+
+	// Give the background receiver the time to start waiting.
+	time.Sleep(10 * time.Millisecond)
+
+	// Fetching headers blocks until released, so the block heights must be
+	// delivered without waiting for the headers.
+	for i := 2; i <= 3; i++ {
+		blockCounter.subscriptionChannel <- block{Number: strconv.Itoa(i)}
+
+		select {
+		case height := <-heights:
+			if height != uint64(i) {
+				t.Errorf(
+					"unexpected block height\nexpected: [%v]\nactual:   [%v]",
+					i,
+					height,
+				)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("block height [%v] has not been received", i)
+		}
+	}
+
+	close(chainReader.release)
+
+	for i := 2; i <= 3; i++ {
+		select {
+		case header := <-headerWatcher:
+			if header.Number.Uint64() != uint64(i) {
+				t.Errorf(
+					"unexpected header number\nexpected: [%v]\nactual:   [%v]",
+					i,
+					header.Number,
+				)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("header [%v] has not been received", i)
+		}
+	}
+
+	if blocksFetched := atomic.LoadUint64(&chainReader.blocksFetched); blocksFetched != 0 {
+		t.Errorf(
+			"unexpected number of fetched blocks\nexpected: [%v]\nactual:   [%v]",
+			0,
+			blocksFetched,
+		)
+	}
+}
+
+func TestSubscribeBlocks_Watchdog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chainReader := &mockQuietChainReader{}
+
+	blockCounter := &EthereumBlockCounter{
+		latestBlockHeight:   uint64(1),
+		waiters:             make(map[uint64][]chan uint64),
+		subscriptionChannel: make(chan block),
+		chainReader:         chainReader,
+		maxBlockInterval:    50 * time.Millisecond,
+	}
+	go blockCounter.receiveBlocks()
+
+	if err := blockCounter.subscribeBlocks(ctx, chainReader); err != nil {
+		t.Fatal(err)
+	}
+
+	// The subscription delivers a single block and goes quiet without
+	// reporting an error. The watchdog should re-establish it.
+	time.Sleep(500 * time.Millisecond)
+
+	if subscriptions := atomic.LoadUint64(&chainReader.subscriptions); subscriptions < 2 {
+		t.Errorf(
+			"unexpected number of subscriptions\n"+
+				"expected: [at least 2]\nactual:   [%v]",
+			subscriptions,
+		)
+	}
+
+	if unsubscribed := atomic.LoadUint64(&chainReader.unsubscribed); unsubscribed < 1 {
+		t.Errorf(
+			"unexpected number of unsubscribed subscriptions\n"+
+				"expected: [at least 1]\nactual:   [%v]",
+			unsubscribed,
+		)
+	}
+}
+
+type mockChainReader struct{}
+
+func (mcr *mockChainReader) BlockByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*Block, error) {
+	if number == nil {
+		return nil, fmt.Errorf("latest block not supported")
+	}
+
+	return &Block{
+		Header: &Header{
+			Number: number,
+			Hash:   [32]byte{byte(number.Uint64())},
+			Time:   number.Uint64() * 10,
+		},
+	}, nil
+}
+
+func (mcr *mockChainReader) SubscribeNewHead(
+	ctx context.Context,
+	ch chan<- *Header,
+) (Subscription, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+// mockHeaderChainReader implements HeaderReader, delivering headers once
+// the release channel is closed, and counts the blocks fetched with
+// BlockByNumber.
+type mockHeaderChainReader struct {
+	*mockChainReader
+
+	release       chan struct{}
+	blocksFetched uint64
+}
+
+func (mhcr *mockHeaderChainReader) BlockByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*Block, error) {
+	atomic.AddUint64(&mhcr.blocksFetched, 1)
+	return mhcr.mockChainReader.BlockByNumber(ctx, number)
+}
+
+func (mhcr *mockHeaderChainReader) HeaderByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*Header, error) {
+	select {
+	case <-mhcr.release:
+		return &Header{Number: number}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// mockQuietChainReader delivers a single new block on each subscription and
+// goes quiet afterwards without reporting any subscription error.
+type mockQuietChainReader struct {
+	subscriptions uint64
+	unsubscribed  uint64
+}
+
+func (mqcr *mockQuietChainReader) BlockByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*Block, error) {
+	if number == nil {
+		number = big.NewInt(1)
+	}
+
+	return &Block{Header: &Header{Number: number}}, nil
+}
+
+func (mqcr *mockQuietChainReader) SubscribeNewHead(
+	ctx context.Context,
+	ch chan<- *Header,
+) (Subscription, error) {
+	subscriptions := atomic.AddUint64(&mqcr.subscriptions, 1)
+
+	go func() {
+		ch <- &Header{Number: new(big.Int).SetUint64(subscriptions + 1)}
+	}()
+
+	return &mockQuietSubscription{
+		err: make(chan error),
+		unsubscribeFn: func() {
+			atomic.AddUint64(&mqcr.unsubscribed, 1)
+		},
+	}, nil
+}
+
+type mockQuietSubscription struct {
+	err           chan error
+	unsubscribeFn func()
+}
+
+func (mqs *mockQuietSubscription) Unsubscribe() {
+	mqs.unsubscribeFn()
+}
+
+func (mqs *mockQuietSubscription) Err() <-chan error {
+	return mqs.err
+}
@@ -17,6 +17,15 @@ func (a Address) TerminalString() string {
 // Header represents a block header in the Ethereum blockchain.
 type Header struct {
 	Number *big.Int
+
+	// Hash is the hash of the block. It may be not set if the header comes
+	// from a notification about a new head block.
+	Hash [32]byte
+
+	// Time is the timestamp of the block in seconds since the Unix epoch.
+	// It may be not set if the header comes from a notification about a new
+	// head block.
+	Time uint64
 }
 
 // Block represents an entire block in the Ethereum blockchain.
@@ -51,6 +60,15 @@ type ChainReader interface {
 	) (Subscription, error)
 }
 
+// HeaderReader is implemented by the chain readers able to get the header of
+// a block without fetching the whole block. Consumers fall back to
+// BlockByNumber if the chain reader does not implement it.
+type HeaderReader interface {
+	// HeaderByNumber gets the header of the block by its number. The block
+	// number argument can be nil to select the latest block.
+	HeaderByNumber(ctx context.Context, number *big.Int) (*Header, error)
+}
+
 // ContractTransactor defines the methods needed to allow operating with
 // contract on a write only basis.
 type ContractTransactor interface {
@@ -3,6 +3,8 @@ package ethereum
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -26,11 +28,55 @@ type Config struct {
 	// expected to be lowercase contract names.
 	ContractAddresses map[string]string
 
+	// A map from contract names to numbers of blocks the contracts were
+	// deployed at. The keys in the map are expected to be lowercase contract
+	// names. Deployment blocks are used as the starting point when all past
+	// events of a contract are fetched.
+	DeploymentBlocks map[string]uint64
+
 	// MiningCheckInterval is the interval in which transaction
 	// mining status is checked. If the transaction is not mined within this
 	// time, the gas price is increased and transaction is resubmitted.
 	MiningCheckInterval time.Duration
 
+	// InitialMiningCheckInterval is the time given for the transaction to be
+	// mined before it is resubmitted for the first time. It lets the first
+	// wait be shorter or longer than the subsequent ones. The mining check
+	// interval is used if the value is not set.
+	InitialMiningCheckInterval time.Duration
+
+	// ForceMiningTimeout bounds the total time the mining waiter tries to
+	// get the transaction mined, including all resubmissions. If the
+	// transaction is not mined within this time, no further resubmission
+	// attempts are performed. The timeout is disabled if the value is not set.
+	ForceMiningTimeout time.Duration
+
+	// DisableResubmission makes the mining waiter only wait for the
+	// transaction to be mined, without ever resubmitting it with a higher
+	// gas price. This is useful when transactions are submitted through
+	// an external relayer.
+	DisableResubmission bool
+
+	// FailOnRevert makes the mining waiter report transactions mined with
+	// a failed execution status, i.e. reverted, as failures. By default,
+	// the transaction being mined is considered a success regardless of its
+	// execution status.
+	FailOnRevert bool
+
+	// ForceMiningConcurrencyLimit sets the maximum number of transactions
+	// the mining waiter force mines at the same time when waiting for
+	// multiple transactions.
+	ForceMiningConcurrencyLimit int
+
+	// PrivateTransactionRelayURL is the URL of a private transaction RPC
+	// endpoint, for example Flashbots Protect, the transactions should be
+	// submitted through instead of the public mempool. The endpoint must
+	// accept the transactions with the eth_sendRawTransaction method; relays
+	// requiring signed requests are not supported. Transactions are submitted
+	// to the public mempool if the value is not set.
+	// Example: "https://rpc.flashbots.net".
+	PrivateTransactionRelayURL string
+
 	// RequestsPerSecondLimit sets the maximum average number of requests
 	// per second which can be executed against the Ethereum node.
 	// All types of chain requests are rate-limited,
@@ -52,11 +98,49 @@ type Config struct {
 	// and for EIP-1559 transactions, this value works as max gas fee cap.
 	MaxGasFeeCap Wei
 
+	// EmergencyMaxGasFeeCap specifies the maximum gas fee cap applied to
+	// a transaction whose original gas price or gas fee cap already meets or
+	// exceeds the max gas fee cap. Without it, such a transaction is never
+	// resubmitted and may stay stuck if the base fee rose past its price.
+	// The value must be higher than MaxGasFeeCap. The emergency cap is
+	// disabled if the value is not set.
+	EmergencyMaxGasFeeCap Wei
+
+	// A map from gas profile names to gas profiles. Gas profiles allow to
+	// apply different economic limits to different classes of transactions
+	// submitted through the same client. The keys in the map are expected
+	// to be lowercase gas profile names.
+	GasProfiles map[string]GasProfile
+
+	// MinGasTipCap specifies the minimum gas tip cap of a resubmitted EIP-1559
+	// transaction. If the gas tip cap bumped up by the mining waiter is lower
+	// than this value, this value is used instead. The value must be
+	// non-negative and lower than MaxGasFeeCap. The floor is disabled if the
+	// value is not set.
+	MinGasTipCap Wei
+
+	// GasFeeCapHeadroom specifies the minimum margin the gas fee cap of
+	// a resubmitted EIP-1559 transaction must keep over the latest base fee.
+	// If the latest base fee increased by this headroom already exceeds the
+	// gas fee cap the client can afford, no further resubmission attempts are
+	// performed. The check is disabled if the value is not set.
+	GasFeeCapHeadroom Wei
+
 	// BalanceAlertThreshold defines a minimum value of the operator's
 	// account balance below which an alert will be triggered.
 	BalanceAlertThreshold Wei
 }
 
+// GasProfile is a struct that contains the economic limits applied to
+// a class of transactions.
+type GasProfile struct {
+	// MaxGasFeeCap specifies the maximum gas fee cap the client is willing
+	// to pay for the transactions of the profile to be mined. It works just
+	// like the MaxGasFeeCap of the Config which is used if the value is not
+	// set.
+	MaxGasFeeCap Wei
+}
+
 // Account is a struct that contains the configuration for accessing an
 // Ethereum network and a contract on the network.
 type Account struct {
@@ -96,8 +180,203 @@ func (c *Config) ContractAddress(contractName string) (common.Address, error) {
 	return address, nil
 }
 
+// ErrDeploymentBlockNotConfigured is an error that is returned when
+// a deployment block for the given contract name was not found in the Config.
+var ErrDeploymentBlockNotConfigured = errors.New("deployment block not configured")
+
+// DeploymentBlock finds a given contract's deployment block configuration
+// and returns it.
+func (c *Config) DeploymentBlock(contractName string) (uint64, error) {
+	deploymentBlock, exists := c.DeploymentBlocks[strings.ToLower(contractName)]
+	if !exists {
+		return 0, ErrDeploymentBlockNotConfigured
+	}
+
+	return deploymentBlock, nil
+}
+
+// ValidateContractAddresses checks if addresses of all the required contracts
+// are configured, are valid hex addresses, and are not zero addresses.
+// It reports all the problems found at once so that the configuration can be
+// fixed in one go before a long-running process starts.
+func (c *Config) ValidateContractAddresses(required []string) error {
+	_, problems := c.resolveContractAddresses(required)
+	if len(problems) > 0 {
+		return fmt.Errorf(
+			"invalid contract addresses configuration: [%v]",
+			strings.Join(problems, "; "),
+		)
+	}
+
+	return nil
+}
+
+// ResolveContractAddresses finds addresses of all the given contracts and
+// returns them as Ethereum addresses keyed by the contract names, as passed.
+// It reports all the contracts whose addresses are missing, malformed, or
+// zero addresses at once; no addresses are returned in such a case.
+// The method is not named ContractAddresses as the name is taken by
+// the Config field holding the configured addresses.
+func (c *Config) ResolveContractAddresses(
+	contractNames ...string,
+) (map[string]common.Address, error) {
+	addresses, problems := c.resolveContractAddresses(contractNames)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf(
+			"could not resolve contract addresses: [%v]",
+			strings.Join(problems, "; "),
+		)
+	}
+
+	return addresses, nil
+}
+
+// resolveContractAddresses finds addresses of all the given contracts and
+// returns them keyed by the contract names along with descriptions of all
+// the problems found, in the order of the given contract names.
+func (c *Config) resolveContractAddresses(
+	contractNames []string,
+) (map[string]common.Address, []string) {
+	addresses := make(map[string]common.Address, len(contractNames))
+	var problems []string
+
+	for _, contractName := range contractNames {
+		address, err := c.ContractAddress(contractName)
+		if errors.Is(err, ErrAddressNotConfigured) {
+			problems = append(
+				problems,
+				fmt.Sprintf(
+					"address for contract [%v] is not configured",
+					contractName,
+				),
+			)
+			continue
+		}
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+
+		if address == (common.Address{}) {
+			problems = append(
+				problems,
+				fmt.Sprintf(
+					"configured address for contract [%v] is a zero address",
+					contractName,
+				),
+			)
+			continue
+		}
+
+		addresses[contractName] = address
+	}
+
+	return addresses, problems
+}
+
 // SetContractAddress sets address for a contract in the contracts addresses
 // mapping.
 func (c *Config) SetContractAddress(contractName string, address string) {
 	c.ContractAddresses[strings.ToLower(contractName)] = address
 }
+
+// redacted is the placeholder rendered in the configuration summary instead
+// of secret values.
+const redacted = "[REDACTED]"
+
+// Summary renders the effective configuration in a human-readable form, one
+// setting per line, so that it can be safely shared, e.g. in support tickets.
+// Secrets are never rendered: the key file password is redacted and only
+// the scheme and host of URLs are rendered, as the rest of a URL may carry
+// credentials or API keys. Ether values are rendered in the most
+// convenient unit, e.g. "45 gwei".
+func (c *Config) Summary() string {
+	var builder strings.Builder
+
+	line := func(name string, value interface{}) {
+		fmt.Fprintf(&builder, "%v: %v\n", name, value)
+	}
+
+	line("network", c.Network)
+	line("url", redactURL(c.URL))
+	line("url rpc", redactURL(c.URLRPC))
+	line("key file", c.KeyFile)
+	if c.KeyFilePassword != "" {
+		line("key file password", redacted)
+	} else {
+		line("key file password", "not set")
+	}
+
+	for _, name := range sortedKeys(c.ContractAddresses) {
+		line(fmt.Sprintf("contract address [%v]", name), c.ContractAddresses[name])
+	}
+	for _, name := range sortedKeys(c.DeploymentBlocks) {
+		line(fmt.Sprintf("deployment block [%v]", name), c.DeploymentBlocks[name])
+	}
+
+	line("mining check interval", c.MiningCheckInterval)
+	line("initial mining check interval", c.InitialMiningCheckInterval)
+	line("force mining timeout", c.ForceMiningTimeout)
+	line("disable resubmission", c.DisableResubmission)
+	line("fail on revert", c.FailOnRevert)
+	line("force mining concurrency limit", c.ForceMiningConcurrencyLimit)
+	line("private transaction relay url", redactURL(c.PrivateTransactionRelayURL))
+	line("requests per second limit", c.RequestsPerSecondLimit)
+	line("concurrency limit", c.ConcurrencyLimit)
+	line("max gas fee cap", weiSummary(c.MaxGasFeeCap))
+	line("emergency max gas fee cap", weiSummary(c.EmergencyMaxGasFeeCap))
+	for _, name := range sortedKeys(c.GasProfiles) {
+		line(
+			fmt.Sprintf("gas profile [%v] max gas fee cap", name),
+			weiSummary(c.GasProfiles[name].MaxGasFeeCap),
+		)
+	}
+	line("min gas tip cap", weiSummary(c.MinGasTipCap))
+	line("gas fee cap headroom", weiSummary(c.GasFeeCapHeadroom))
+	line("balance alert threshold", weiSummary(c.BalanceAlertThreshold))
+
+	return builder.String()
+}
+
+// weiSummary renders the given value in the most convenient unit.
+func weiSummary(value Wei) string {
+	if value.Int == nil {
+		return "not set"
+	}
+
+	return value.String()
+}
+
+// redactURL renders only the scheme and the host of the given URL. Providers
+// such as Infura or Alchemy embed API keys in the URL path or query, and
+// the URL may carry credentials as well, so everything else is redacted.
+func redactURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		// The URL can not be reliably redacted so it is not rendered at all.
+		return redacted
+	}
+
+	rendered := parsed.Scheme + "://" + parsed.Host
+
+	hasPath := parsed.Path != "" && parsed.Path != "/"
+	if parsed.User != nil || hasPath || parsed.RawQuery != "" || parsed.Fragment != "" {
+		rendered += "/" + redacted
+	}
+
+	return rendered
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
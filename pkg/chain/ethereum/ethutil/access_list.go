@@ -0,0 +1,90 @@
+package ethutil
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// CreateAccessList computes the EIP-2930 access list of the given contract
+// method call at the given block. The access list is computed with the
+// eth_createAccessList method of the raw RPC client, since the typed client
+// does not expose it. If the block number is nil, the latest block is used.
+//
+// Errors returned by the node, including the ones caused by the call
+// reverting, are resolved with the given error resolver.
+//
+// The returned access list can be used to simulate the call with
+// CallAtBlockWithAccessList and to submit the transaction carrying it.
+func CreateAccessList(
+	ctx context.Context,
+	client RPCCaller,
+	fromAddress common.Address,
+	blockNumber *big.Int,
+	value *big.Int,
+	contractABI *abi.ABI,
+	errorResolver *ErrorResolver,
+	contractAddress common.Address,
+	method string,
+	parameters ...interface{},
+) (types.AccessList, error) {
+	input, err := contractABI.Pack(method, parameters...)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := map[string]interface{}{
+		"from":  fromAddress,
+		"to":    contractAddress,
+		"input": hexutil.Bytes(input),
+	}
+	if value != nil {
+		arg["value"] = (*hexutil.Big)(value)
+	}
+
+	var result struct {
+		AccessList *types.AccessList `json:"accessList"`
+		Error      string            `json:"error,omitempty"`
+	}
+
+	err = client.CallContext(
+		ctx,
+		&result,
+		"eth_createAccessList",
+		arg,
+		toBlockNumberArg(blockNumber),
+	)
+	if err == nil && result.Error != "" {
+		err = errors.New(result.Error)
+	}
+	if err != nil {
+		return nil, errorResolver.ResolveError(
+			err,
+			fromAddress,
+			value,
+			method,
+			parameters...,
+		)
+	}
+
+	if result.AccessList == nil {
+		return types.AccessList{}, nil
+	}
+
+	return *result.AccessList, nil
+}
+
+// toBlockNumberArg converts the block number to the block parameter of
+// the RPC call. The nil block number denotes the latest block.
+func toBlockNumberArg(blockNumber *big.Int) string {
+	if blockNumber == nil {
+		return "latest"
+	}
+
+	return hexutil.EncodeBig(blockNumber)
+}
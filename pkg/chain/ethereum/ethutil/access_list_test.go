@@ -0,0 +1,296 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const accessListTestABI = `[{
+	"name": "balanceOf",
+	"type": "function",
+	"stateMutability": "view",
+	"inputs": [{"name": "account", "type": "address"}],
+	"outputs": [{"name": "", "type": "uint256"}]
+}]`
+
+var (
+	accessListTestContract = common.HexToAddress(
+		"0x7e3f14ad6eb4b2bb3f3a4bba6a0d5d4f4bd4b7e1",
+	)
+	accessListTestAccount = common.HexToAddress(
+		"0x2b5ad5c4795c026514f8317c7a215e218dccd6cf",
+	)
+	accessListTestStorageKey = common.HexToHash(
+		"0x0000000000000000000000000000000000000000000000000000000000000001",
+	)
+)
+
+func TestCallAtBlockWithAccessList(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(accessListTestABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accessList := types.AccessList{
+		{
+			Address:     accessListTestContract,
+			StorageKeys: []common.Hash{accessListTestStorageKey},
+		},
+	}
+
+	var tests = map[string]struct {
+		callFn             func(caller *mockRecordingContractCaller) (*big.Int, error)
+		expectedAccessList types.AccessList
+	}{
+		"with access list": {
+			callFn: func(caller *mockRecordingContractCaller) (*big.Int, error) {
+				var result *big.Int
+				err := CallAtBlockWithAccessList(
+					accessListTestAccount,
+					big.NewInt(100),
+					nil,
+					accessList,
+					&contractABI,
+					caller,
+					NewErrorResolver(caller, &contractABI, &accessListTestContract),
+					accessListTestContract,
+					"balanceOf",
+					&result,
+					accessListTestAccount,
+				)
+				return result, err
+			},
+			expectedAccessList: accessList,
+		},
+		"without access list": {
+			callFn: func(caller *mockRecordingContractCaller) (*big.Int, error) {
+				var result *big.Int
+				err := CallAtBlock(
+					accessListTestAccount,
+					big.NewInt(100),
+					nil,
+					&contractABI,
+					caller,
+					NewErrorResolver(caller, &contractABI, &accessListTestContract),
+					accessListTestContract,
+					"balanceOf",
+					&result,
+					accessListTestAccount,
+				)
+				return result, err
+			},
+			expectedAccessList: nil,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			caller := &mockRecordingContractCaller{
+				output: common.LeftPadBytes(big.NewInt(42).Bytes(), 32),
+			}
+
+			result, err := test.callFn(caller)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Cmp(big.NewInt(42)) != 0 {
+				t.Errorf(
+					"unexpected result\nexpected: [%v]\nactual:   [%v]",
+					42,
+					result,
+				)
+			}
+
+			if len(caller.calls) != 1 {
+				t.Fatalf(
+					"unexpected number of calls\nexpected: [%v]\nactual:   [%v]",
+					1,
+					len(caller.calls),
+				)
+			}
+
+			if !reflect.DeepEqual(
+				test.expectedAccessList,
+				caller.calls[0].AccessList,
+			) {
+				t.Errorf(
+					"unexpected access list\nexpected: [%v]\nactual:   [%v]",
+					test.expectedAccessList,
+					caller.calls[0].AccessList,
+				)
+			}
+		})
+	}
+}
+
+func TestCreateAccessList(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(accessListTestABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &mockRPCCaller{
+		response: `{
+			"accessList": [{
+				"address": "0x7e3f14ad6eb4b2bb3f3a4bba6a0d5d4f4bd4b7e1",
+				"storageKeys": [
+					"0x0000000000000000000000000000000000000000000000000000000000000001"
+				]
+			}],
+			"gasUsed": "0x5208"
+		}`,
+	}
+
+	accessList, err := CreateAccessList(
+		context.Background(),
+		client,
+		accessListTestAccount,
+		big.NewInt(100),
+		big.NewInt(1),
+		&contractABI,
+		NewErrorResolver(
+			&mockRecordingContractCaller{},
+			&contractABI,
+			&accessListTestContract,
+		),
+		accessListTestContract,
+		"balanceOf",
+		accessListTestAccount,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedAccessList := types.AccessList{
+		{
+			Address:     accessListTestContract,
+			StorageKeys: []common.Hash{accessListTestStorageKey},
+		},
+	}
+	if !reflect.DeepEqual(expectedAccessList, accessList) {
+		t.Errorf(
+			"unexpected access list\nexpected: [%v]\nactual:   [%v]",
+			expectedAccessList,
+			accessList,
+		)
+	}
+
+	if client.method != "eth_createAccessList" {
+		t.Errorf(
+			"unexpected method\nexpected: [%v]\nactual:   [%v]",
+			"eth_createAccessList",
+			client.method,
+		)
+	}
+
+	input, err := contractABI.Pack("balanceOf", accessListTestAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedArgs := []interface{}{
+		map[string]interface{}{
+			"from":  accessListTestAccount,
+			"to":    accessListTestContract,
+			"input": hexutil.Bytes(input),
+			"value": (*hexutil.Big)(big.NewInt(1)),
+		},
+		"0x64",
+	}
+	if !reflect.DeepEqual(expectedArgs, client.args) {
+		t.Errorf(
+			"unexpected arguments\nexpected: [%v]\nactual:   [%v]",
+			expectedArgs,
+			client.args,
+		)
+	}
+}
+
+func TestCreateAccessList_Errors(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(accessListTestABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]struct {
+		response      string
+		callErr       error
+		expectedError string
+	}{
+		"rpc call failed": {
+			callErr:       fmt.Errorf("connection refused"),
+			expectedError: "connection refused",
+		},
+		"error in the result": {
+			response:      `{"accessList": [], "error": "execution reverted"}`,
+			expectedError: "execution reverted",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			client := &mockRPCCaller{
+				response: test.response,
+				err:      test.callErr,
+			}
+
+			_, err := CreateAccessList(
+				context.Background(),
+				client,
+				accessListTestAccount,
+				nil,
+				nil,
+				&contractABI,
+				NewErrorResolver(
+					&mockRecordingContractCaller{},
+					&contractABI,
+					&accessListTestContract,
+				),
+				accessListTestContract,
+				"balanceOf",
+				accessListTestAccount,
+			)
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
+
+// mockRecordingContractCaller records the calls made to the contract and
+// returns the fixed output for each of them.
+type mockRecordingContractCaller struct {
+	output []byte
+	calls  []ethereum.CallMsg
+}
+
+func (mrcc *mockRecordingContractCaller) CodeAt(
+	ctx context.Context,
+	contract common.Address,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (mrcc *mockRecordingContractCaller) CallContract(
+	ctx context.Context,
+	call ethereum.CallMsg,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	mrcc.calls = append(mrcc.calls, call)
+	return mrcc.output, nil
+}
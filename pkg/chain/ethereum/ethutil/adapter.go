@@ -10,6 +10,17 @@ import (
 	chainEthereum "github.com/keep-network/keep-common/pkg/chain/ethereum"
 )
 
+// ToChainHeader converts the given go-ethereum block header to the chain
+// abstraction header, so that code bridging go-ethereum and the chain
+// abstraction does not have to reimplement the mapping.
+func ToChainHeader(header *types.Header) *chainEthereum.Header {
+	return &chainEthereum.Header{
+		Number: header.Number,
+		Hash:   header.Hash(),
+		Time:   header.Time,
+	}
+}
+
 type ethereumAdapter struct {
 	delegate EthereumClient
 }
@@ -24,12 +35,22 @@ func (ea *ethereumAdapter) BlockByNumber(
 	}
 
 	return &chainEthereum.Block{
-		Header: &chainEthereum.Header{
-			Number: block.Number(),
-		},
+		Header: ToChainHeader(block.Header()),
 	}, nil
 }
 
+func (ea *ethereumAdapter) HeaderByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*chainEthereum.Header, error) {
+	header, err := ea.delegate.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return ToChainHeader(header), nil
+}
+
 func (ea *ethereumAdapter) SubscribeNewHead(
 	ctx context.Context,
 	headersChan chan<- *chainEthereum.Header,
@@ -47,9 +68,7 @@ func (ea *ethereumAdapter) SubscribeNewHead(
 		for {
 			select {
 			case header := <-internalHeadersChan:
-				headersChan <- &chainEthereum.Header{
-					Number: header.Number,
-				}
+				headersChan <- ToChainHeader(header)
 			case <-stop:
 				return
 			}
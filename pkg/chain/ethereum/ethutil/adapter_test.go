@@ -15,6 +15,28 @@ import (
 	chainEthereum "github.com/keep-network/keep-common/pkg/chain/ethereum"
 )
 
+func TestToChainHeader(t *testing.T) {
+	header := &types.Header{
+		Number: big.NewInt(100),
+		Time:   1650000000,
+	}
+
+	expectedHeader := &chainEthereum.Header{
+		Number: big.NewInt(100),
+		Hash:   header.Hash(),
+		Time:   1650000000,
+	}
+
+	chainHeader := ToChainHeader(header)
+	if !reflect.DeepEqual(expectedHeader, chainHeader) {
+		t.Errorf(
+			"unexpected header\nexpected: [%+v]\nactual:   [%+v]",
+			expectedHeader,
+			chainHeader,
+		)
+	}
+}
+
 func TestEthereumAdapter_BlockByNumber(t *testing.T) {
 	client := &mockAdaptedEthereumClient{
 		blocks: []*big.Int{
@@ -64,6 +86,42 @@ func TestEthereumAdapter_BlockByNumber(t *testing.T) {
 	}
 }
 
+func TestEthereumAdapter_HeaderByNumber(t *testing.T) {
+	client := &mockAdaptedEthereumClient{
+		blocks: []*big.Int{
+			big.NewInt(0),
+			big.NewInt(1),
+			big.NewInt(2),
+		},
+		blocksBaseFee: []*big.Int{
+			big.NewInt(10),
+			big.NewInt(11),
+			big.NewInt(12),
+		},
+	}
+
+	adapter := &ethereumAdapter{client}
+
+	header, err := adapter.HeaderByNumber(context.Background(), big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedHeader := ToChainHeader(&types.Header{
+		Number:  big.NewInt(1),
+		BaseFee: big.NewInt(11),
+	})
+	if !reflect.DeepEqual(expectedHeader, header) {
+		t.Errorf(
+			"unexpected header\n"+
+				"expected: [%+v]\n"+
+				"actual:   [%+v]",
+			expectedHeader,
+			header,
+		)
+	}
+}
+
 func TestEthereumAdapter_SubscribeNewHead(t *testing.T) {
 	ctx, cancelCtx := context.WithTimeout(
 		context.Background(),
@@ -184,6 +242,18 @@ func (maec *mockAdaptedEthereumClient) BlockByNumber(
 	), nil
 }
 
+func (maec *mockAdaptedEthereumClient) HeaderByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*types.Header, error) {
+	block, err := maec.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return block.Header(), nil
+}
+
 func (maec *mockAdaptedEthereumClient) SubscribeNewHead(
 	ctx context.Context,
 	ch chan<- *types.Header,
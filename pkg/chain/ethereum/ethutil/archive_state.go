@@ -0,0 +1,70 @@
+package ethutil
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrArchiveStateUnavailable is returned when the state at a historical block
+// has been requested but the node has already pruned it, that is, the node is
+// not an archive node. It lets the callers tell the missing state apart from
+// data that does not exist.
+var ErrArchiveStateUnavailable = errors.New(
+	"historical state unavailable; the node is not an archive node",
+)
+
+// archiveStateErrors lists messages of errors returned by the nodes and
+// the node providers when the state at a historical block has been pruned.
+// The errors are received over the RPC so they can be recognized only by
+// their messages.
+var archiveStateErrors = []string{
+	// go-ethereum with hash-based state scheme
+	"missing trie node",
+	// go-ethereum with path-based state scheme
+	"historical state",
+	// Nethermind, Besu
+	"state is not available",
+	"world state unavailable",
+	// Erigon
+	"state history not available",
+	// Infura, Alchemy, and other providers limiting the archive access
+	"archive state",
+	"archive node",
+}
+
+// isArchiveStateError returns true if the given error means the state at
+// a historical block is not available on the node.
+func isArchiveStateError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, archiveStateError := range archiveStateErrors {
+		if strings.Contains(message, archiveStateError) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wrapArchiveStateError wraps the given error of the call executed at
+// the given block in ErrArchiveStateUnavailable if the error means
+// the historical state is not available. Other errors, as well as errors
+// of calls executed at the latest block, for which the block number is nil,
+// are returned unchanged.
+func wrapArchiveStateError(err error, blockNumber *big.Int) error {
+	if blockNumber == nil || !isArchiveStateError(err) {
+		return err
+	}
+
+	return fmt.Errorf(
+		"%w: block [%v]: [%v]",
+		ErrArchiveStateUnavailable,
+		blockNumber,
+		err,
+	)
+}
@@ -0,0 +1,161 @@
+package ethutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var archiveStateTestErrors = map[string]struct {
+	err                  error
+	blockNumber          *big.Int
+	expectedArchiveError bool
+}{
+	"geth hash-based scheme": {
+		err: fmt.Errorf(
+			"missing trie node " +
+				"9a1d0a4e2b7ad6ad0e1a7c2b1b8a7d7e6f0e2d1c3b4a5968778695a4b3c2d1e0 " +
+				"(path )",
+		),
+		blockNumber:          big.NewInt(100),
+		expectedArchiveError: true,
+	},
+	"geth path-based scheme": {
+		err: fmt.Errorf(
+			"historical state 0x9a1d0a4e2b7ad6ad0e1a7c2b1b8a7d7e6f0e2d1c " +
+				"is not available",
+		),
+		blockNumber:          big.NewInt(100),
+		expectedArchiveError: true,
+	},
+	"nethermind": {
+		err: fmt.Errorf(
+			"No state available for block " +
+				"0x9a1d0a4e2b7ad6ad0e1a7c2b1b8a7d7e6f0e2d1c: " +
+				"state is not available",
+		),
+		blockNumber:          big.NewInt(100),
+		expectedArchiveError: true,
+	},
+	"besu": {
+		err:                  fmt.Errorf("World state unavailable"),
+		blockNumber:          big.NewInt(100),
+		expectedArchiveError: true,
+	},
+	"infura": {
+		err: fmt.Errorf(
+			"project ID does not have access to archive state",
+		),
+		blockNumber:          big.NewInt(100),
+		expectedArchiveError: true,
+	},
+	"latest block": {
+		err:                  fmt.Errorf("missing trie node"),
+		blockNumber:          nil,
+		expectedArchiveError: false,
+	},
+	"other error": {
+		err:                  fmt.Errorf("connection refused"),
+		blockNumber:          big.NewInt(100),
+		expectedArchiveError: false,
+	},
+}
+
+func TestCallAtBlock_ArchiveStateUnavailable(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(accessListTestABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for testName, test := range archiveStateTestErrors {
+		t.Run(testName, func(t *testing.T) {
+			caller := &mockFailingContractCaller{err: test.err}
+
+			var result *big.Int
+			err := CallAtBlock(
+				accessListTestAccount,
+				test.blockNumber,
+				nil,
+				&contractABI,
+				caller,
+				NewErrorResolver(caller, &contractABI, &accessListTestContract),
+				accessListTestContract,
+				"balanceOf",
+				&result,
+				accessListTestAccount,
+			)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			archiveErr := errors.Is(err, ErrArchiveStateUnavailable)
+			if archiveErr != test.expectedArchiveError {
+				t.Errorf(
+					"unexpected archive state error\n"+
+						"expected: [%v]\nactual:   [%v]\nerror: [%v]",
+					test.expectedArchiveError,
+					archiveErr,
+					err,
+				)
+			}
+		})
+	}
+}
+
+func TestBalancesAt_ArchiveStateUnavailable(t *testing.T) {
+	for testName, test := range archiveStateTestErrors {
+		t.Run(testName, func(t *testing.T) {
+			client := &mockBatchCaller{balanceErr: test.err}
+
+			_, err := BalancesAt(
+				context.Background(),
+				client,
+				[]common.Address{balancesTestAccount1},
+				test.blockNumber,
+			)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			archiveErr := errors.Is(err, ErrArchiveStateUnavailable)
+			if archiveErr != test.expectedArchiveError {
+				t.Errorf(
+					"unexpected archive state error\n"+
+						"expected: [%v]\nactual:   [%v]\nerror: [%v]",
+					test.expectedArchiveError,
+					archiveErr,
+					err,
+				)
+			}
+		})
+	}
+}
+
+// mockFailingContractCaller fails all the contract calls with the configured
+// error.
+type mockFailingContractCaller struct {
+	err error
+}
+
+func (mfcc *mockFailingContractCaller) CodeAt(
+	ctx context.Context,
+	contract common.Address,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (mfcc *mockFailingContractCaller) CallContract(
+	ctx context.Context,
+	call ethereum.CallMsg,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	return nil, mfcc.err
+}
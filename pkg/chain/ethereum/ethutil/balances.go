@@ -0,0 +1,134 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// balanceReader is the subset of the client able to read account balances.
+type balanceReader interface {
+	BalanceAt(
+		ctx context.Context,
+		account common.Address,
+		blockNumber *big.Int,
+	) (*big.Int, error)
+}
+
+// BalancesAt returns the balances of the given accounts at the given block.
+// If the block number is nil, the latest block is used.
+// If the node does not have the state at the given block because it is not
+// an archive node, the returned error wraps ErrArchiveStateUnavailable.
+//
+// All the balances are fetched with a single batch request of the raw RPC
+// client, so that reporting balances of many accounts does not take a round
+// trip per account. If the batch request fails, for example because
+// the endpoint does not support batching, the balances are fetched with
+// sequential calls.
+func BalancesAt(
+	ctx context.Context,
+	client RPCBatchCaller,
+	accounts []common.Address,
+	blockNumber *big.Int,
+) (map[common.Address]*big.Int, error) {
+	if len(accounts) == 0 {
+		return make(map[common.Address]*big.Int), nil
+	}
+
+	block := toBlockNumberArg(blockNumber)
+
+	results := make([]hexutil.Big, len(accounts))
+	batch := make([]rpc.BatchElem, len(accounts))
+	for i, account := range accounts {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getBalance",
+			Args:   []interface{}{account, block},
+			Result: &results[i],
+		}
+	}
+
+	if err := client.BatchCallContext(ctx, batch); err != nil {
+		logger.Warningf(
+			"could not get balances in a batch; "+
+				"falling back to sequential calls: [%v]",
+			err,
+		)
+
+		for i := range batch {
+			batch[i].Error = client.CallContext(
+				ctx,
+				batch[i].Result,
+				batch[i].Method,
+				batch[i].Args...,
+			)
+		}
+	}
+
+	balances := make(map[common.Address]*big.Int, len(accounts))
+	for i, account := range accounts {
+		if batch[i].Error != nil {
+			return nil, fmt.Errorf(
+				"could not get balance of account [%v]: [%w]",
+				account.Hex(),
+				wrapArchiveStateError(batch[i].Error, blockNumber),
+			)
+		}
+
+		balances[account] = results[i].ToInt()
+	}
+
+	return balances, nil
+}
+
+// WaitForBalance blocks until the latest balance of the given account is at
+// least the given minimum, e.g. until a funding transaction lands, and returns
+// that balance. The balance is checked immediately and then every poll
+// interval. Failed balance checks are logged and retried with the next poll.
+// The client should be rate-limited so that polling many accounts does not
+// overload the node. If the context is done before the minimum balance is
+// reached, the function returns the last balance read, or nil if none has
+// been read, along with an error wrapping the context error.
+func WaitForBalance(
+	ctx context.Context,
+	client balanceReader,
+	account common.Address,
+	min *big.Int,
+	pollInterval time.Duration,
+) (*big.Int, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var balance *big.Int
+	for {
+		latestBalance, err := client.BalanceAt(ctx, account, nil)
+		if err != nil {
+			logger.Warningf(
+				"could not get balance of account [%v]: [%v]",
+				account.Hex(),
+				err,
+			)
+		} else {
+			balance = latestBalance
+			if balance.Cmp(min) >= 0 {
+				return balance, nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return balance, fmt.Errorf(
+				"balance of account [%v] did not reach [%v]; last balance [%v]: [%w]",
+				account.Hex(),
+				min,
+				balance,
+				ctx.Err(),
+			)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package ethutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	balancesTestAccount1 = common.HexToAddress(
+		"0x2b5ad5c4795c026514f8317c7a215e218dccd6cf",
+	)
+	balancesTestAccount2 = common.HexToAddress(
+		"0x6813eb9362372eef6200f3b1dbc3f819671cba69",
+	)
+)
+
+func TestBalancesAt(t *testing.T) {
+	var tests = map[string]struct {
+		batchErr                error
+		expectedBatches         int
+		expectedSequentialCalls int
+	}{
+		"batching supported": {
+			expectedBatches:         1,
+			expectedSequentialCalls: 0,
+		},
+		"batching unsupported": {
+			batchErr:                fmt.Errorf("batch requests not supported"),
+			expectedBatches:         1,
+			expectedSequentialCalls: 2,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			client := &mockBatchCaller{
+				balances: map[common.Address]string{
+					balancesTestAccount1: "0x64",
+					balancesTestAccount2: "0xc8",
+				},
+				batchErr: test.batchErr,
+			}
+
+			balances, err := BalancesAt(
+				context.Background(),
+				client,
+				[]common.Address{balancesTestAccount1, balancesTestAccount2},
+				big.NewInt(1000),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedBalances := map[common.Address]*big.Int{
+				balancesTestAccount1: big.NewInt(100),
+				balancesTestAccount2: big.NewInt(200),
+			}
+			if !reflect.DeepEqual(expectedBalances, balances) {
+				t.Errorf(
+					"unexpected balances\nexpected: [%v]\nactual:   [%v]",
+					expectedBalances,
+					balances,
+				)
+			}
+
+			if len(client.batches) != test.expectedBatches {
+				t.Fatalf(
+					"unexpected number of batches\nexpected: [%v]\nactual:   [%v]",
+					test.expectedBatches,
+					len(client.batches),
+				)
+			}
+
+			batch := client.batches[0]
+			if len(batch) != 2 {
+				t.Fatalf(
+					"unexpected batch size\nexpected: [%v]\nactual:   [%v]",
+					2,
+					len(batch),
+				)
+			}
+			for i, account := range []common.Address{
+				balancesTestAccount1,
+				balancesTestAccount2,
+			} {
+				expectedArgs := []interface{}{account, "0x3e8"}
+				if batch[i].Method != "eth_getBalance" ||
+					!reflect.DeepEqual(expectedArgs, batch[i].Args) {
+					t.Errorf(
+						"unexpected batch element [%v]\n"+
+							"expected: [eth_getBalance %v]\n"+
+							"actual:   [%v %v]",
+						i,
+						expectedArgs,
+						batch[i].Method,
+						batch[i].Args,
+					)
+				}
+			}
+
+			if client.sequentialCalls != test.expectedSequentialCalls {
+				t.Errorf(
+					"unexpected number of sequential calls\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedSequentialCalls,
+					client.sequentialCalls,
+				)
+			}
+		})
+	}
+}
+
+func TestBalancesAt_Error(t *testing.T) {
+	client := &mockBatchCaller{
+		balances: map[common.Address]string{
+			balancesTestAccount1: "0x64",
+		},
+	}
+
+	_, err := BalancesAt(
+		context.Background(),
+		client,
+		[]common.Address{balancesTestAccount1, balancesTestAccount2},
+		nil,
+	)
+
+	expectedError := fmt.Sprintf(
+		"could not get balance of account [%v]: [unknown account]",
+		balancesTestAccount2.Hex(),
+	)
+	if err == nil || err.Error() != expectedError {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
+func TestWaitForBalance(t *testing.T) {
+	var tests = map[string]struct {
+		balances         []int64
+		balanceErrs      []error
+		min              int64
+		expectedBalance  *big.Int
+		expectedCalls    int
+		expectedTimedOut bool
+	}{
+		"balance already above the minimum": {
+			balances:        []int64{150},
+			min:             100,
+			expectedBalance: big.NewInt(150),
+			expectedCalls:   1,
+		},
+		"balance reaches the minimum": {
+			balances:        []int64{0, 50, 100},
+			min:             100,
+			expectedBalance: big.NewInt(100),
+			expectedCalls:   3,
+		},
+		"balance check fails": {
+			balances:        []int64{0, 0, 120},
+			balanceErrs:     []error{nil, fmt.Errorf("connection refused")},
+			min:             100,
+			expectedBalance: big.NewInt(120),
+			expectedCalls:   3,
+		},
+		"balance does not reach the minimum": {
+			balances:         []int64{10, 20},
+			min:              100,
+			expectedBalance:  big.NewInt(20),
+			expectedTimedOut: true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			client := &mockBalanceReader{
+				balances:    test.balances,
+				balanceErrs: test.balanceErrs,
+			}
+
+			ctx, cancel := context.WithTimeout(
+				context.Background(),
+				100*time.Millisecond,
+			)
+			defer cancel()
+
+			balance, err := WaitForBalance(
+				ctx,
+				client,
+				balancesTestAccount1,
+				big.NewInt(test.min),
+				time.Millisecond,
+			)
+
+			if test.expectedTimedOut {
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf(
+						"unexpected error\nexpected: [%v]\nactual:   [%v]",
+						context.DeadlineExceeded,
+						err,
+					)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if client.calls != test.expectedCalls {
+					t.Errorf(
+						"unexpected number of balance checks\n"+
+							"expected: [%v]\nactual:   [%v]",
+						test.expectedCalls,
+						client.calls,
+					)
+				}
+			}
+
+			if balance.Cmp(test.expectedBalance) != 0 {
+				t.Errorf(
+					"unexpected balance\nexpected: [%v]\nactual:   [%v]",
+					test.expectedBalance,
+					balance,
+				)
+			}
+		})
+	}
+}
+
+// mockBalanceReader returns the consecutive configured balances, or errors
+// if they are set, on each balance check. Once all the balances have been
+// returned, the last one is returned on further checks.
+type mockBalanceReader struct {
+	balances    []int64
+	balanceErrs []error
+	calls       int
+}
+
+func (mbr *mockBalanceReader) BalanceAt(
+	ctx context.Context,
+	account common.Address,
+	blockNumber *big.Int,
+) (*big.Int, error) {
+	call := mbr.calls
+	mbr.calls++
+
+	if call < len(mbr.balanceErrs) && mbr.balanceErrs[call] != nil {
+		return nil, mbr.balanceErrs[call]
+	}
+
+	if call >= len(mbr.balances) {
+		call = len(mbr.balances) - 1
+	}
+
+	return big.NewInt(mbr.balances[call]), nil
+}
+
+// mockBatchCaller records the batches and returns the configured balances
+// of the accounts.
+type mockBatchCaller struct {
+	balances        map[common.Address]string
+	balanceErr      error
+	batchErr        error
+	batches         [][]rpc.BatchElem
+	sequentialCalls int
+}
+
+func (mbc *mockBatchCaller) BatchCallContext(
+	ctx context.Context,
+	batch []rpc.BatchElem,
+) error {
+	mbc.batches = append(mbc.batches, batch)
+
+	if mbc.batchErr != nil {
+		return mbc.batchErr
+	}
+
+	for i := range batch {
+		batch[i].Error = mbc.getBalance(batch[i].Result, batch[i].Args...)
+	}
+
+	return nil
+}
+
+func (mbc *mockBatchCaller) CallContext(
+	ctx context.Context,
+	result interface{},
+	method string,
+	args ...interface{},
+) error {
+	mbc.sequentialCalls++
+	return mbc.getBalance(result, args...)
+}
+
+func (mbc *mockBatchCaller) getBalance(
+	result interface{},
+	args ...interface{},
+) error {
+	if mbc.balanceErr != nil {
+		return mbc.balanceErr
+	}
+
+	balance, ok := mbc.balances[args[0].(common.Address)]
+	if !ok {
+		return fmt.Errorf("unknown account")
+	}
+
+	return json.Unmarshal([]byte(`"`+balance+`"`), result)
+}
@@ -0,0 +1,49 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	finalizedBlockTag = "finalized"
+	safeBlockTag      = "safe"
+)
+
+// FinalizedBlock returns the number of the latest finalized block. It uses
+// the raw RPC client, e.g. *rpc.Client, since the typed client can not
+// request blocks by the finalized tag. The returned block number can be used to anchor calls to
+// the finalized state of the chain, e.g. with the AtBlock contract methods.
+func FinalizedBlock(ctx context.Context, client RPCCaller) (uint64, error) {
+	return blockNumberByTag(ctx, client, finalizedBlockTag)
+}
+
+// SafeBlock returns the number of the latest safe block, i.e. the latest
+// block which is unlikely to be reorged. It uses the raw RPC client, e.g.
+// *rpc.Client, since the typed client can not request blocks by the safe tag.
+func SafeBlock(ctx context.Context, client RPCCaller) (uint64, error) {
+	return blockNumberByTag(ctx, client, safeBlockTag)
+}
+
+func blockNumberByTag(
+	ctx context.Context,
+	client RPCCaller,
+	tag string,
+) (uint64, error) {
+	var header *struct {
+		Number hexutil.Uint64 `json:"number"`
+	}
+
+	err := client.CallContext(ctx, &header, "eth_getBlockByNumber", tag, false)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get [%v] block: [%v]", tag, err)
+	}
+
+	if header == nil {
+		return 0, fmt.Errorf("[%v] block not found", tag)
+	}
+
+	return uint64(header.Number), nil
+}
@@ -0,0 +1,99 @@
+package ethutil
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFinalizedBlock(t *testing.T) {
+	client := &mockBlockTagCaller{
+		blocks: map[string]string{
+			"finalized": `{"number":"0x64"}`,
+			"safe":      `{"number":"0x6e"}`,
+		},
+	}
+
+	blockNumber, err := FinalizedBlock(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if blockNumber != 100 {
+		t.Errorf(
+			"unexpected block number\nexpected: [%v]\nactual:   [%v]",
+			100,
+			blockNumber,
+		)
+	}
+
+	expectedArgs := []interface{}{"finalized", false}
+	if !reflect.DeepEqual(expectedArgs, client.args) {
+		t.Errorf(
+			"unexpected arguments\nexpected: [%v]\nactual:   [%v]",
+			expectedArgs,
+			client.args,
+		)
+	}
+}
+
+func TestSafeBlock(t *testing.T) {
+	client := &mockBlockTagCaller{
+		blocks: map[string]string{
+			"finalized": `{"number":"0x64"}`,
+			"safe":      `{"number":"0x6e"}`,
+		},
+	}
+
+	blockNumber, err := SafeBlock(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if blockNumber != 110 {
+		t.Errorf(
+			"unexpected block number\nexpected: [%v]\nactual:   [%v]",
+			110,
+			blockNumber,
+		)
+	}
+}
+
+func TestFinalizedBlock_NotFound(t *testing.T) {
+	client := &mockBlockTagCaller{
+		blocks: map[string]string{},
+	}
+
+	_, err := FinalizedBlock(context.Background(), client)
+
+	expectedError := "[finalized] block not found"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
+type mockBlockTagCaller struct {
+	blocks map[string]string
+	args   []interface{}
+}
+
+func (mbtc *mockBlockTagCaller) CallContext(
+	ctx context.Context,
+	result interface{},
+	method string,
+	args ...interface{},
+) error {
+	mbtc.args = args
+
+	block, ok := mbtc.blocks[args[0].(string)]
+	if !ok {
+		block = "null"
+	}
+
+	return json.Unmarshal([]byte(block), result)
+}
@@ -0,0 +1,327 @@
+package ethutil
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type callLabelKey struct{}
+
+// WithCallLabel returns a copy of the parent context carrying the given call
+// label, e.g. "StakingContract.balanceOf". The client wrapped with
+// WrapCallMetrics reports the label along with the name of the client method
+// so that the metrics of calls made through the same client method, e.g. all
+// the CallContract calls, can be attributed to the contract methods they
+// execute.
+func WithCallLabel(parent context.Context, label string) context.Context {
+	return context.WithValue(parent, callLabelKey{}, label)
+}
+
+// CallLabel returns the call label carried by the given context or an empty
+// string if the context carries no call label.
+func CallLabel(ctx context.Context) string {
+	label, _ := ctx.Value(callLabelKey{}).(string)
+	return label
+}
+
+// WithCallLabelOpts returns a copy of the given caller options whose context
+// carries the given call label. It lets the generated contract bindings label
+// the calls of the contract methods without changing the caller options
+// shared by all the calls.
+func WithCallLabelOpts(opts *bind.CallOpts, label string) *bind.CallOpts {
+	labeledOpts := new(bind.CallOpts)
+	if opts != nil {
+		*labeledOpts = *opts
+	}
+
+	ctx := labeledOpts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	labeledOpts.Context = WithCallLabel(ctx, label)
+
+	return labeledOpts
+}
+
+// CallMetricsReporter receives the metrics of the calls made through a client
+// created with WrapCallMetrics. The reporter is called from the goroutines
+// making the calls so it must be safe for concurrent use.
+type CallMetricsReporter interface {
+	// ReportCall reports the duration of a completed call of the given client
+	// method and the error the call failed with, if any. The label is the
+	// call label carried by the context of the call or an empty string if
+	// the context carries no call label.
+	ReportCall(method string, label string, duration time.Duration, err error)
+}
+
+type callMetrics struct {
+	EthereumClient
+
+	reporter CallMetricsReporter
+}
+
+// WrapCallMetrics wraps the given client with the reporting of the duration
+// and the outcome of all the calls to the provided reporter. Each report
+// includes the name of the called client method and the call label attached
+// to the call context with WithCallLabel, if any. Actual functionality is
+// delegated to the passed client.
+func WrapCallMetrics(
+	client EthereumClient,
+	reporter CallMetricsReporter,
+) EthereumClient {
+	return &callMetrics{
+		EthereumClient: client,
+		reporter:       reporter,
+	}
+}
+
+// report reports the call of the given client method started at the given
+// time with the label carried by the given context.
+func (cm *callMetrics) report(
+	ctx context.Context,
+	method string,
+	startedAt time.Time,
+	err error,
+) {
+	cm.reporter.ReportCall(method, CallLabel(ctx), time.Since(startedAt), err)
+}
+
+func (cm *callMetrics) CodeAt(
+	ctx context.Context,
+	contract common.Address,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.CodeAt(ctx, contract, blockNumber)
+	cm.report(ctx, "CodeAt", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) CallContract(
+	ctx context.Context,
+	call ethereum.CallMsg,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.CallContract(ctx, call, blockNumber)
+	cm.report(ctx, "CallContract", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) PendingCodeAt(
+	ctx context.Context,
+	account common.Address,
+) ([]byte, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.PendingCodeAt(ctx, account)
+	cm.report(ctx, "PendingCodeAt", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) PendingNonceAt(
+	ctx context.Context,
+	account common.Address,
+) (uint64, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.PendingNonceAt(ctx, account)
+	cm.report(ctx, "PendingNonceAt", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) SuggestGasPrice(
+	ctx context.Context,
+) (*big.Int, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.SuggestGasPrice(ctx)
+	cm.report(ctx, "SuggestGasPrice", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) SuggestGasTipCap(
+	ctx context.Context,
+) (*big.Int, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.SuggestGasTipCap(ctx)
+	cm.report(ctx, "SuggestGasTipCap", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) EstimateGas(
+	ctx context.Context,
+	call ethereum.CallMsg,
+) (uint64, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.EstimateGas(ctx, call)
+	cm.report(ctx, "EstimateGas", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) SendTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) error {
+	startedAt := time.Now()
+	err := cm.EthereumClient.SendTransaction(ctx, tx)
+	cm.report(ctx, "SendTransaction", startedAt, err)
+
+	return err
+}
+
+func (cm *callMetrics) FilterLogs(
+	ctx context.Context,
+	query ethereum.FilterQuery,
+) ([]types.Log, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.FilterLogs(ctx, query)
+	cm.report(ctx, "FilterLogs", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) SubscribeFilterLogs(
+	ctx context.Context,
+	query ethereum.FilterQuery,
+	ch chan<- types.Log,
+) (ethereum.Subscription, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.SubscribeFilterLogs(ctx, query, ch)
+	cm.report(ctx, "SubscribeFilterLogs", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) BlockByHash(
+	ctx context.Context,
+	hash common.Hash,
+) (*types.Block, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.BlockByHash(ctx, hash)
+	cm.report(ctx, "BlockByHash", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) BlockByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*types.Block, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.BlockByNumber(ctx, number)
+	cm.report(ctx, "BlockByNumber", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) HeaderByHash(
+	ctx context.Context,
+	hash common.Hash,
+) (*types.Header, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.HeaderByHash(ctx, hash)
+	cm.report(ctx, "HeaderByHash", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) HeaderByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*types.Header, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.HeaderByNumber(ctx, number)
+	cm.report(ctx, "HeaderByNumber", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) TransactionCount(
+	ctx context.Context,
+	blockHash common.Hash,
+) (uint, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.TransactionCount(ctx, blockHash)
+	cm.report(ctx, "TransactionCount", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) TransactionInBlock(
+	ctx context.Context,
+	blockHash common.Hash,
+	index uint,
+) (*types.Transaction, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.TransactionInBlock(ctx, blockHash, index)
+	cm.report(ctx, "TransactionInBlock", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) SubscribeNewHead(
+	ctx context.Context,
+	ch chan<- *types.Header,
+) (ethereum.Subscription, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.SubscribeNewHead(ctx, ch)
+	cm.report(ctx, "SubscribeNewHead", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) TransactionByHash(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Transaction, bool, error) {
+	startedAt := time.Now()
+	tx, isPending, err := cm.EthereumClient.TransactionByHash(ctx, txHash)
+	cm.report(ctx, "TransactionByHash", startedAt, err)
+
+	return tx, isPending, err
+}
+
+func (cm *callMetrics) TransactionReceipt(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Receipt, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.TransactionReceipt(ctx, txHash)
+	cm.report(ctx, "TransactionReceipt", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) BalanceAt(
+	ctx context.Context,
+	account common.Address,
+	blockNumber *big.Int,
+) (*big.Int, error) {
+	startedAt := time.Now()
+	result, err := cm.EthereumClient.BalanceAt(ctx, account, blockNumber)
+	cm.report(ctx, "BalanceAt", startedAt, err)
+
+	return result, err
+}
+
+func (cm *callMetrics) SubscribePendingTransactions(
+	ctx context.Context,
+	ch chan<- common.Hash,
+) (ethereum.Subscription, error) {
+	startedAt := time.Now()
+	subscription, err := SubscribePendingTransactions(ctx, cm.EthereumClient, ch)
+	cm.report(ctx, "SubscribePendingTransactions", startedAt, err)
+
+	return subscription, err
+}
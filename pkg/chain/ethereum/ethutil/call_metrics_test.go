@@ -0,0 +1,250 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestWrapCallMetrics(t *testing.T) {
+	var tests = map[string]struct {
+		callFn          func(client EthereumClient) error
+		expectedReports []callReport
+	}{
+		"unlabeled call": {
+			callFn: func(client EthereumClient) error {
+				_, err := client.CallContract(
+					context.Background(),
+					ethereum.CallMsg{},
+					nil,
+				)
+				return err
+			},
+			expectedReports: []callReport{
+				{method: "CallContract", label: ""},
+			},
+		},
+		"labeled calls": {
+			callFn: func(client EthereumClient) error {
+				ctx := WithCallLabel(
+					context.Background(),
+					"StakingContract.balanceOf",
+				)
+				if _, err := client.CallContract(
+					ctx,
+					ethereum.CallMsg{},
+					nil,
+				); err != nil {
+					return err
+				}
+
+				ctx = WithCallLabel(
+					context.Background(),
+					"StakingContract.owner",
+				)
+				_, err := client.CallContract(ctx, ethereum.CallMsg{}, nil)
+				return err
+			},
+			expectedReports: []callReport{
+				{method: "CallContract", label: "StakingContract.balanceOf"},
+				{method: "CallContract", label: "StakingContract.owner"},
+			},
+		},
+		"labeled caller options": {
+			callFn: func(client EthereumClient) error {
+				opts := WithCallLabelOpts(
+					&bind.CallOpts{},
+					"StakingContract.balanceOf",
+				)
+				_, err := client.CodeAt(opts.Context, common.Address{}, nil)
+				return err
+			},
+			expectedReports: []callReport{
+				{method: "CodeAt", label: "StakingContract.balanceOf"},
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			reporter := &mockCallMetricsReporter{}
+			client := WrapCallMetrics(&mockEthereumClient{}, reporter)
+
+			if err := test.callFn(client); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(test.expectedReports, reporter.reports) {
+				t.Errorf(
+					"unexpected reports\nexpected: [%v]\nactual:   [%v]",
+					test.expectedReports,
+					reporter.reports,
+				)
+			}
+		})
+	}
+}
+
+func TestWrapCallMetrics_Error(t *testing.T) {
+	expectedErr := fmt.Errorf("connection refused")
+
+	reporter := &mockCallMetricsReporter{}
+	client := WrapCallMetrics(
+		&mockFailingBalanceEthereumClient{
+			mockEthereumClient: &mockEthereumClient{},
+			err:                expectedErr,
+		},
+		reporter,
+	)
+
+	ctx := WithCallLabel(context.Background(), "balance")
+	if _, err := client.BalanceAt(ctx, common.Address{}, nil); err != expectedErr {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedErr,
+			err,
+		)
+	}
+
+	expectedReports := []callReport{
+		{method: "BalanceAt", label: "balance", err: expectedErr},
+	}
+	if !reflect.DeepEqual(expectedReports, reporter.reports) {
+		t.Errorf(
+			"unexpected reports\nexpected: [%v]\nactual:   [%v]",
+			expectedReports,
+			reporter.reports,
+		)
+	}
+}
+
+func TestCallAtBlockWithContext_CallLabel(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(accessListTestABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reporter := &mockCallMetricsReporter{}
+	client := WrapCallMetrics(&mockEthereumClient{}, reporter)
+
+	var result *big.Int
+	err = CallAtBlockWithContext(
+		WithCallLabel(context.Background(), "StakingContract.balanceOf"),
+		accessListTestAccount,
+		big.NewInt(100),
+		nil,
+		&contractABI,
+		client,
+		NewErrorResolver(client, &contractABI, &accessListTestContract),
+		accessListTestContract,
+		"balanceOf",
+		&result,
+		accessListTestAccount,
+	)
+	// The mock client returns no code for the contract.
+	if err != bind.ErrNoCode {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			bind.ErrNoCode,
+			err,
+		)
+	}
+
+	expectedReports := []callReport{
+		{method: "CallContract", label: "StakingContract.balanceOf"},
+		{method: "CodeAt", label: "StakingContract.balanceOf"},
+	}
+	if !reflect.DeepEqual(expectedReports, reporter.reports) {
+		t.Errorf(
+			"unexpected reports\nexpected: [%v]\nactual:   [%v]",
+			expectedReports,
+			reporter.reports,
+		)
+	}
+}
+
+func TestWithCallLabelOpts(t *testing.T) {
+	type contextKey struct{}
+
+	parentCtx := context.WithValue(context.Background(), contextKey{}, "value")
+	opts := &bind.CallOpts{
+		From:    common.HexToAddress("0x1"),
+		Context: parentCtx,
+	}
+
+	labeledOpts := WithCallLabelOpts(opts, "Contract.method")
+
+	if labeledOpts == opts {
+		t.Fatal("caller options have not been copied")
+	}
+	if opts.Context != parentCtx {
+		t.Fatal("original caller options have been modified")
+	}
+	if labeledOpts.From != opts.From {
+		t.Errorf(
+			"unexpected from\nexpected: [%v]\nactual:   [%v]",
+			opts.From,
+			labeledOpts.From,
+		)
+	}
+	if label := CallLabel(labeledOpts.Context); label != "Contract.method" {
+		t.Errorf(
+			"unexpected label\nexpected: [%v]\nactual:   [%v]",
+			"Contract.method",
+			label,
+		)
+	}
+	if labeledOpts.Context.Value(contextKey{}) != "value" {
+		t.Errorf("labeled context does not inherit from the parent context")
+	}
+}
+
+type callReport struct {
+	method string
+	label  string
+	err    error
+}
+
+type mockCallMetricsReporter struct {
+	mutex   sync.Mutex
+	reports []callReport
+}
+
+func (mcmr *mockCallMetricsReporter) ReportCall(
+	method string,
+	label string,
+	duration time.Duration,
+	err error,
+) {
+	mcmr.mutex.Lock()
+	defer mcmr.mutex.Unlock()
+
+	mcmr.reports = append(
+		mcmr.reports,
+		callReport{method: method, label: label, err: err},
+	)
+}
+
+type mockFailingBalanceEthereumClient struct {
+	*mockEthereumClient
+
+	err error
+}
+
+func (mfbec *mockFailingBalanceEthereumClient) BalanceAt(
+	ctx context.Context,
+	account common.Address,
+	blockNumber *big.Int,
+) (*big.Int, error) {
+	return nil, mfbec.err
+}
@@ -0,0 +1,119 @@
+package ethutil
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+)
+
+// ClientOptions configures the connections to the Ethereum node established
+// by ConnectClientsWithOptions. They allow to connect to authenticated
+// endpoints of hosted RPC providers.
+type ClientOptions struct {
+	// Headers are the HTTP headers sent with each request to the node, e.g.
+	// an API key header. Headers are supported only for HTTP(S) endpoints;
+	// WebSocket endpoints accept credentials passed in the URL.
+	Headers http.Header
+
+	// TLSConfig, if set, is the TLS configuration of the HTTPS and WSS
+	// connections, e.g. with a custom certificate authority or a client
+	// certificate.
+	TLSConfig *tls.Config
+}
+
+// ConnectClientsWithOptions works like ConnectClients but establishes
+// the connections with the given options. ConnectClients should be used for
+// unauthenticated local nodes.
+func ConnectClientsWithOptions(
+	url string,
+	urlRPC string,
+	options ClientOptions,
+) (*ethclient.Client, *rpc.Client, *rpc.Client, error) {
+	ctx := context.Background()
+
+	clientWS, err := dialWithOptions(ctx, url, options)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf(
+			"error Connecting to Geth Server: %s [%v]",
+			url,
+			err,
+		)
+	}
+
+	clientRPC, err := dialWithOptions(ctx, urlRPC, options)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf(
+			"error Connecting to Geth Server: %s [%v]",
+			urlRPC,
+			err,
+		)
+	}
+
+	return ethclient.NewClient(clientWS), clientWS, clientRPC, nil
+}
+
+// dialWithOptions connects the raw RPC client to the given endpoint with
+// the given options.
+func dialWithOptions(
+	ctx context.Context,
+	rawURL string,
+	options ClientOptions,
+) (*rpc.Client, error) {
+	endpointURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch endpointURL.Scheme {
+	case "http", "https":
+		httpClient := &http.Client{}
+		if options.TLSConfig != nil {
+			httpClient.Transport = &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: options.TLSConfig,
+			}
+		}
+
+		client, err := rpc.DialHTTPWithClient(rawURL, httpClient)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, values := range options.Headers {
+			client.SetHeader(key, strings.Join(values, ", "))
+		}
+
+		return client, nil
+	case "ws", "wss":
+		if len(options.Headers) > 0 {
+			return nil, fmt.Errorf(
+				"headers are not supported for WebSocket endpoints; " +
+					"pass the credentials in the URL instead",
+			)
+		}
+
+		dialer := websocket.Dialer{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: options.TLSConfig,
+		}
+
+		return rpc.DialWebsocketWithDialer(ctx, rawURL, "", dialer)
+	default:
+		if len(options.Headers) > 0 || options.TLSConfig != nil {
+			return nil, fmt.Errorf(
+				"headers and TLS configuration are not supported for "+
+					"[%v] endpoints",
+				rawURL,
+			)
+		}
+
+		return rpc.DialContext(ctx, rawURL)
+	}
+}
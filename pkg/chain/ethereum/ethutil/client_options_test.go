@@ -0,0 +1,121 @@
+package ethutil
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestConnectClientsWithOptions(t *testing.T) {
+	var mutex sync.Mutex
+	var apiKeys []string
+
+	server := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			apiKeys = append(apiKeys, r.Header.Get("X-Api-Key"))
+			mutex.Unlock()
+
+			var request struct {
+				ID json.RawMessage `json:"id"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      request.ID,
+				"result":  "0x5",
+			})
+		},
+	))
+	defer server.Close()
+
+	// The server certificate is self-signed so the connection succeeds only
+	// with the TLS configuration trusting it.
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	client, clientWS, clientRPC, err := ConnectClientsWithOptions(
+		server.URL,
+		server.URL,
+		ClientOptions{
+			Headers:   http.Header{"X-Api-Key": []string{"secret"}},
+			TLSConfig: tlsConfig,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientWS.Close()
+	defer clientRPC.Close()
+
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chainID.Int64() != 5 {
+		t.Errorf(
+			"unexpected chain ID\nexpected: [%v]\nactual:   [%v]",
+			5,
+			chainID,
+		)
+	}
+
+	var result string
+	if err := clientRPC.Call(&result, "eth_chainId"); err != nil {
+		t.Fatal(err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	expectedAPIKeys := []string{"secret", "secret"}
+	if len(apiKeys) != len(expectedAPIKeys) ||
+		apiKeys[0] != expectedAPIKeys[0] ||
+		apiKeys[1] != expectedAPIKeys[1] {
+		t.Errorf(
+			"unexpected API key headers\nexpected: [%v]\nactual:   [%v]",
+			expectedAPIKeys,
+			apiKeys,
+		)
+	}
+}
+
+func TestConnectClientsWithOptions_UntrustedCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {},
+	))
+	defer server.Close()
+
+	client, _, _, err := ConnectClientsWithOptions(
+		server.URL,
+		server.URL,
+		ClientOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ChainID(context.Background()); err == nil {
+		t.Fatal("expected certificate verification error")
+	}
+}
+
+func TestConnectClientsWithOptions_WebSocketHeaders(t *testing.T) {
+	_, _, _, err := ConnectClientsWithOptions(
+		"ws://127.0.0.1:8546",
+		"ws://127.0.0.1:8546",
+		ClientOptions{
+			Headers: http.Header{"X-Api-Key": []string{"secret"}},
+		},
+	)
+	if err == nil {
+		t.Fatal("expected error for headers of a WebSocket endpoint")
+	}
+}
@@ -0,0 +1,56 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+)
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of the parent context carrying the given
+// correlation ID. The mining waiter attaches the correlation ID carried by
+// the context to all its log lines and passes it to the resubmission
+// interceptor so that logs of many transactions in flight can be attributed
+// to the operations they belong to.
+func WithCorrelationID(parent context.Context, correlationID string) context.Context {
+	return context.WithValue(parent, correlationIDKey{}, correlationID)
+}
+
+// CorrelationID returns the correlation ID carried by the given context or
+// an empty string if the context carries no correlation ID.
+func CorrelationID(ctx context.Context) string {
+	correlationID, _ := ctx.Value(correlationIDKey{}).(string)
+	return correlationID
+}
+
+// correlatedLogger prefixes log lines with the correlation ID, if any.
+type correlatedLogger struct {
+	prefix string
+}
+
+func loggerFor(ctx context.Context) *correlatedLogger {
+	correlationID := CorrelationID(ctx)
+	if correlationID == "" {
+		return &correlatedLogger{}
+	}
+
+	return &correlatedLogger{
+		prefix: fmt.Sprintf("[correlation ID: %s] ", correlationID),
+	}
+}
+
+func (cl *correlatedLogger) Debugf(format string, args ...interface{}) {
+	logger.Debugf(cl.prefix+format, args...)
+}
+
+func (cl *correlatedLogger) Infof(format string, args ...interface{}) {
+	logger.Infof(cl.prefix+format, args...)
+}
+
+func (cl *correlatedLogger) Warningf(format string, args ...interface{}) {
+	logger.Warningf(cl.prefix+format, args...)
+}
+
+func (cl *correlatedLogger) Errorf(format string, args ...interface{}) {
+	logger.Errorf(cl.prefix+format, args...)
+}
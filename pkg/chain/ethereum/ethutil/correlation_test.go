@@ -0,0 +1,47 @@
+package ethutil
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCorrelationID(t *testing.T) {
+	var tests = map[string]struct {
+		ctx            context.Context
+		expectedID     string
+		expectedPrefix string
+	}{
+		"no correlation ID": {
+			ctx:            context.Background(),
+			expectedID:     "",
+			expectedPrefix: "",
+		},
+		"correlation ID set": {
+			ctx:            WithCorrelationID(context.Background(), "req-1"),
+			expectedID:     "req-1",
+			expectedPrefix: "[correlation ID: req-1] ",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			correlationID := CorrelationID(test.ctx)
+			if correlationID != test.expectedID {
+				t.Errorf(
+					"unexpected correlation ID\nexpected: [%v]\nactual:   [%v]",
+					test.expectedID,
+					correlationID,
+				)
+			}
+
+			prefix := loggerFor(test.ctx).prefix
+			if prefix != test.expectedPrefix {
+				t.Errorf(
+					"unexpected log prefix\nexpected: [%v]\nactual:   [%v]",
+					test.expectedPrefix,
+					prefix,
+				)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -37,6 +38,19 @@ type ErrorResolver struct {
 	contractCaller ethereum.ContractCaller
 	abi            *abi.ABI
 	address        *common.Address
+
+	// errorMethodByID looks up the error method with the given 4-byte id
+	// in the error ABI.
+	errorMethodByID func(id []byte) (*abi.Method, error)
+
+	errorMethodsMutex sync.RWMutex
+	errorMethods      map[[4]byte]errorMethodLookup
+}
+
+// errorMethodLookup is the cached result of the error method lookup.
+type errorMethodLookup struct {
+	method *abi.Method
+	err    error
 }
 
 // NewErrorResolver returns an ErroResolver for the given Ethereum client,
@@ -46,7 +60,37 @@ func NewErrorResolver(
 	abi *abi.ABI,
 	address *common.Address,
 ) *ErrorResolver {
-	return &ErrorResolver{contractCaller, abi, address}
+	return &ErrorResolver{
+		contractCaller:  contractCaller,
+		abi:             abi,
+		address:         address,
+		errorMethodByID: errorABI.MethodById,
+		errorMethods:    make(map[[4]byte]errorMethodLookup),
+	}
+}
+
+// lookupErrorMethod returns the error method with the given 4-byte id.
+// Lookup results are cached so that repeated reverts with the same error id
+// do not scan the error ABI again.
+func (er *ErrorResolver) lookupErrorMethod(id []byte) (*abi.Method, error) {
+	var key [4]byte
+	copy(key[:], id)
+
+	er.errorMethodsMutex.RLock()
+	lookup, ok := er.errorMethods[key]
+	er.errorMethodsMutex.RUnlock()
+
+	if ok {
+		return lookup.method, lookup.err
+	}
+
+	method, err := er.errorMethodByID(id)
+
+	er.errorMethodsMutex.Lock()
+	er.errorMethods[key] = errorMethodLookup{method, err}
+	er.errorMethodsMutex.Unlock()
+
+	return method, err
 }
 
 // ResolveError resolves the given transaction error to a standard error that,
@@ -104,7 +148,7 @@ func (er *ErrorResolver) ResolveError(
 
 	errorID, encodedReturns := response[0:4], response[4:]
 
-	errorMethod, err := errorABI.MethodById(errorID)
+	errorMethod, err := er.lookupErrorMethod(errorID)
 	if err != nil {
 		return fmt.Errorf("got [%v] while resolving original error [%v] on return [%v]", err, originalErr, response)
 	}
@@ -0,0 +1,115 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestErrorResolverCachesErrorMethodLookups(t *testing.T) {
+	// Error(string) selector followed by an empty error message.
+	errorResponse := append(
+		[]byte{8, 195, 121, 160},
+		make([]byte, 64)...,
+	)
+	errorResponse[35] = 32 // data offset, fixed
+
+	unknownResponse := []byte{1, 2, 3, 4}
+
+	testABI := &abi.ABI{
+		Methods: map[string]abi.Method{"Test": {Name: "Test"}},
+	}
+	address := common.Address{}
+
+	var tests = map[string]struct {
+		response        []byte
+		expectedLookups map[[4]byte]int
+	}{
+		"known error selector": {
+			response:        errorResponse,
+			expectedLookups: map[[4]byte]int{{8, 195, 121, 160}: 1},
+		},
+		"unknown error selector": {
+			response:        unknownResponse,
+			expectedLookups: map[[4]byte]int{{1, 2, 3, 4}: 1},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			resolver := NewErrorResolver(
+				&fixedResponseCaller{test.response},
+				testABI,
+				&address,
+			)
+
+			lookupsMutex := sync.Mutex{}
+			lookups := make(map[[4]byte]int)
+			resolver.errorMethodByID = func(id []byte) (*abi.Method, error) {
+				lookupsMutex.Lock()
+				var key [4]byte
+				copy(key[:], id)
+				lookups[key]++
+				lookupsMutex.Unlock()
+
+				return errorABI.MethodById(id)
+			}
+
+			originalErr := fmt.Errorf("original error")
+
+			firstErr := resolver.ResolveError(
+				originalErr,
+				common.Address{},
+				nil,
+				"Test",
+			)
+
+			for i := 0; i < 5; i++ {
+				err := resolver.ResolveError(
+					originalErr,
+					common.Address{},
+					nil,
+					"Test",
+				)
+				if err.Error() != firstErr.Error() {
+					t.Fatalf(
+						"unexpected resolved error\n"+
+							"expected: [%v]\nactual:   [%v]",
+						firstErr,
+						err,
+					)
+				}
+			}
+
+			for selector, expectedCount := range test.expectedLookups {
+				if lookups[selector] != expectedCount {
+					t.Errorf(
+						"unexpected number of lookups of [%v]\n"+
+							"expected: [%v]\nactual:   [%v]",
+						selector,
+						expectedCount,
+						lookups[selector],
+					)
+				}
+			}
+		})
+	}
+}
+
+type fixedResponseCaller struct {
+	response []byte
+}
+
+func (frc *fixedResponseCaller) CallContract(
+	ctx context.Context,
+	call ethereum.CallMsg,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	return frc.response, nil
+}
@@ -4,9 +4,11 @@ package ethutil
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"strings"
 
 	chainEthereum "github.com/keep-network/keep-common/pkg/chain/ethereum"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -38,13 +41,36 @@ type EthereumClient interface {
 	) (*big.Int, error)
 }
 
+// RPCCaller is the subset of the raw RPC client used to execute calls which
+// are not exposed by the typed client. It is implemented by *rpc.Client.
+type RPCCaller interface {
+	CallContext(
+		ctx context.Context,
+		result interface{},
+		method string,
+		args ...interface{},
+	) error
+}
+
+// RPCBatchCaller is the subset of the raw RPC client able to send multiple
+// calls in a single request. It is implemented by *rpc.Client.
+type RPCBatchCaller interface {
+	RPCCaller
+
+	BatchCallContext(ctx context.Context, batch []rpc.BatchElem) error
+}
+
+var _ RPCBatchCaller = (*rpc.Client)(nil)
+
 // AddressFromHex converts the passed string to a common.Address and returns it,
 // unless it is not a valid address, in which case it returns an error. Compare
 // to common.HexToAddress, which assumes the address is valid and does not
-// provide for an error return.
+// provide for an error return. Surrounding whitespace is ignored and the 0x
+// prefix is optional so that addresses piped from other tools are accepted.
 func AddressFromHex(hex string) (common.Address, error) {
-	if common.IsHexAddress(hex) {
-		return common.HexToAddress(hex), nil
+	trimmed := strings.TrimSpace(hex)
+	if common.IsHexAddress(trimmed) {
+		return common.HexToAddress(trimmed), nil
 	}
 
 	return common.Address{}, fmt.Errorf("[%v] is not a valid Ethereum address", hex)
@@ -65,6 +91,55 @@ func DecryptKeyFile(keyFile, password string) (*keystore.Key, error) {
 	return key, nil
 }
 
+var (
+	// ErrKeyFileUnreadable is returned from VerifyKeyFilePassword when the
+	// key file could not be read.
+	ErrKeyFileUnreadable = errors.New("unable to read key file")
+
+	// ErrKeyFileWrongPassword is returned from VerifyKeyFilePassword when
+	// the key file could not be decrypted with the provided password.
+	ErrKeyFileWrongPassword = errors.New("wrong key file password")
+)
+
+// VerifyKeyFilePassword checks if the key file can be decrypted with the
+// provided password. The decrypted key is discarded and its private key
+// material zeroed right away so that the function can be used to validate
+// the credentials without holding the key in memory. The returned error
+// wraps ErrKeyFileUnreadable if the key file could not be read and
+// ErrKeyFileWrongPassword if the password is not correct.
+func VerifyKeyFilePassword(keyFile, password string) error {
+	// #nosec G304 (file path provided as taint input)
+	// This line is used to read a local key file. There is no user input.
+	data, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("%w [%s]: [%v]", ErrKeyFileUnreadable, keyFile, err)
+	}
+
+	key, err := keystore.DecryptKey(data, password)
+	if errors.Is(err, keystore.ErrDecrypt) {
+		return fmt.Errorf("%w for [%s]", ErrKeyFileWrongPassword, keyFile)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to decrypt [%s]: [%v]", keyFile, err)
+	}
+
+	zeroKey(key)
+
+	return nil
+}
+
+// zeroKey overwrites the private key material of the given key with zeros.
+func zeroKey(key *keystore.Key) {
+	if key.PrivateKey == nil || key.PrivateKey.D == nil {
+		return
+	}
+
+	bits := key.PrivateKey.D.Bits()
+	for i := range bits {
+		bits[i] = 0
+	}
+}
+
 // ConnectClients takes HTTP and RPC URLs and returns initialized versions of
 // standard, WebSocket, and RPC clients for the Ethereum node at that address.
 func ConnectClients(url string, urlRPC string) (*ethclient.Client, *rpc.Client, *rpc.Client, error) {
@@ -102,6 +177,9 @@ func ConnectClients(url string, urlRPC string) (*ethclient.Client, *rpc.Client,
 // particular block. It papers over the fact that abigen bindings don't directly
 // support calling at a particular block, and is mostly meant for use from
 // generated contract code.
+//
+// If the node does not have the state at the given block because it is not
+// an archive node, the returned error wraps ErrArchiveStateUnavailable.
 func CallAtBlock(
 	fromAddress common.Address,
 	blockNumber *big.Int,
@@ -113,6 +191,102 @@ func CallAtBlock(
 	method string,
 	result interface{},
 	parameters ...interface{},
+) error {
+	return CallAtBlockWithContext(
+		context.TODO(),
+		fromAddress,
+		blockNumber,
+		value,
+		contractABI,
+		caller,
+		errorResolver,
+		contractAddress,
+		method,
+		result,
+		parameters...,
+	)
+}
+
+// CallAtBlockWithContext works just like CallAtBlock but executes the call
+// with the given context. It lets the generated contract code attach a call
+// label to the context with WithCallLabel, so that the calls at a block are
+// attributed to the contract methods they execute.
+func CallAtBlockWithContext(
+	ctx context.Context,
+	fromAddress common.Address,
+	blockNumber *big.Int,
+	value *big.Int,
+	contractABI *abi.ABI,
+	caller bind.ContractCaller,
+	errorResolver *ErrorResolver,
+	contractAddress common.Address,
+	method string,
+	result interface{},
+	parameters ...interface{},
+) error {
+	return callAtBlock(
+		ctx,
+		fromAddress,
+		blockNumber,
+		value,
+		nil,
+		contractABI,
+		caller,
+		errorResolver,
+		contractAddress,
+		method,
+		result,
+		parameters...,
+	)
+}
+
+// CallAtBlockWithAccessList works just like CallAtBlock but simulates the
+// call with the given EIP-2930 access list, so that the simulation accounts
+// for the gas the same way as the submitted transaction carrying the access
+// list does. The access list for the call can be computed with
+// CreateAccessList.
+func CallAtBlockWithAccessList(
+	fromAddress common.Address,
+	blockNumber *big.Int,
+	value *big.Int,
+	accessList types.AccessList,
+	contractABI *abi.ABI,
+	caller bind.ContractCaller,
+	errorResolver *ErrorResolver,
+	contractAddress common.Address,
+	method string,
+	result interface{},
+	parameters ...interface{},
+) error {
+	return callAtBlock(
+		context.TODO(),
+		fromAddress,
+		blockNumber,
+		value,
+		accessList,
+		contractABI,
+		caller,
+		errorResolver,
+		contractAddress,
+		method,
+		result,
+		parameters...,
+	)
+}
+
+func callAtBlock(
+	ctx context.Context,
+	fromAddress common.Address,
+	blockNumber *big.Int,
+	value *big.Int,
+	accessList types.AccessList,
+	contractABI *abi.ABI,
+	caller bind.ContractCaller,
+	errorResolver *ErrorResolver,
+	contractAddress common.Address,
+	method string,
+	result interface{},
+	parameters ...interface{},
 ) error {
 	input, err := contractABI.Pack(method, parameters...)
 	if err != nil {
@@ -121,19 +295,23 @@ func CallAtBlock(
 
 	var (
 		msg = ethereum.CallMsg{
-			From:  fromAddress,
-			To:    &contractAddress,
-			Data:  input,
-			Value: value,
+			From:       fromAddress,
+			To:         &contractAddress,
+			Data:       input,
+			Value:      value,
+			AccessList: accessList,
 		}
 		code   []byte
 		output []byte
 	)
 
-	output, err = caller.CallContract(context.TODO(), msg, blockNumber)
+	output, err = caller.CallContract(ctx, msg, blockNumber)
+	if blockNumber != nil && isArchiveStateError(err) {
+		return wrapArchiveStateError(err, blockNumber)
+	}
 	if err == nil && len(output) == 0 {
 		// Make sure we have a contract to operate on, and bail out otherwise.
-		if code, err = caller.CodeAt(context.TODO(), contractAddress, nil); err != nil {
+		if code, err = caller.CodeAt(ctx, contractAddress, nil); err != nil {
 			return err
 		} else if len(code) == 0 {
 			return bind.ErrNoCode
@@ -154,6 +332,28 @@ func CallAtBlock(
 	return nil
 }
 
+// IsContractDeployed checks whether there is a contract deployed at
+// the given address, that is, whether the code at the address is non-empty
+// at the latest block. It allows to catch misconfigurations, such as a wrong
+// network or a contract that has not been deployed yet, before interacting
+// with the contract.
+func IsContractDeployed(
+	ctx context.Context,
+	caller bind.ContractCaller,
+	address common.Address,
+) (bool, error) {
+	code, err := caller.CodeAt(ctx, address, nil)
+	if err != nil {
+		return false, fmt.Errorf(
+			"failed to get code at address [%v]: [%v]",
+			address.Hex(),
+			err,
+		)
+	}
+
+	return len(code) > 0, nil
+}
+
 // EstimateGas tries to estimate the gas needed to execute a specific transaction based on
 // the current pending state of the backend blockchain. There is no guarantee that this is
 // the true gas limit requirement as other transactions may be added or removed by miners,
@@ -165,6 +365,29 @@ func EstimateGas(
 	contractABI *abi.ABI,
 	transactor bind.ContractTransactor,
 	parameters ...interface{},
+) (uint64, error) {
+	return EstimateGasWithValue(
+		from,
+		to,
+		nil,
+		method,
+		contractABI,
+		transactor,
+		parameters...,
+	)
+}
+
+// EstimateGasWithValue works just like EstimateGas but estimates the gas
+// needed to execute the transaction transferring the given value, so that
+// the gas of payable contract methods can be estimated.
+func EstimateGasWithValue(
+	from common.Address,
+	to common.Address,
+	value *big.Int,
+	method string,
+	contractABI *abi.ABI,
+	transactor bind.ContractTransactor,
+	parameters ...interface{},
 ) (uint64, error) {
 	input, err := contractABI.Pack(method, parameters...)
 	if err != nil {
@@ -172,9 +395,10 @@ func EstimateGas(
 	}
 
 	msg := ethereum.CallMsg{
-		From: from,
-		To:   &to,
-		Data: input,
+		From:  from,
+		To:    &to,
+		Data:  input,
+		Value: value,
 	}
 
 	gas, err := transactor.EstimateGas(context.TODO(), msg)
@@ -185,10 +409,13 @@ func EstimateGas(
 	return gas, nil
 }
 
-// NewBlockCounter creates a new BlockCounter instance for the provided
+// NewBlockCounter creates a new EthereumBlockCounter instance for the provided
 // Ethereum client.
-func NewBlockCounter(client EthereumClient) (*chainEthereum.BlockCounter, error) {
-	return chainEthereum.CreateBlockCounter(&ethereumAdapter{client})
+func NewBlockCounter(
+	client EthereumClient,
+	options ...chainEthereum.BlockCounterOption,
+) (*chainEthereum.EthereumBlockCounter, error) {
+	return chainEthereum.CreateBlockCounter(&ethereumAdapter{client}, options...)
 }
 
 // NewNonceManager creates NonceManager instance for the provided account
@@ -196,9 +423,11 @@ func NewBlockCounter(client EthereumClient) (*chainEthereum.BlockCounter, error)
 func NewNonceManager(
 	client EthereumClient,
 	account common.Address,
+	options ...chainEthereum.NonceManagerOption,
 ) *chainEthereum.NonceManager {
 	return chainEthereum.NewNonceManager(
 		&ethereumAdapter{client},
 		chainEthereum.Address(account),
+		options...,
 	)
 }
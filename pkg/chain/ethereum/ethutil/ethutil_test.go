@@ -1,9 +1,14 @@
 package ethutil_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/big"
+	"reflect"
 	"testing"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
 )
@@ -60,6 +65,47 @@ func TestKeyFileDecryption(t *testing.T) {
 	}
 }
 
+func TestVerifyKeyFilePassword(t *testing.T) {
+	goodKeyFile := "./testdata/UTC--2018-02-15T19-57-35.216297214Z--6ffba2d0f4c8fd7961f516af43c55fe2d56f6044"
+	badKeyFile := "./testdata/nonexistent-file.booyan"
+
+	tests := map[string]struct {
+		keyFile       string
+		password      string
+		expectedError error
+	}{
+		"good password": {
+			keyFile:       goodKeyFile,
+			password:      "password",
+			expectedError: nil,
+		},
+		"bad file": {
+			keyFile:       badKeyFile,
+			password:      "password",
+			expectedError: ethutil.ErrKeyFileUnreadable,
+		},
+		"bad password": {
+			keyFile:       goodKeyFile,
+			password:      "nanananana",
+			expectedError: ethutil.ErrKeyFileWrongPassword,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			err := ethutil.VerifyKeyFilePassword(test.keyFile, test.password)
+
+			if !errors.Is(err, test.expectedError) {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
+
 func TestAddressFromHex(t *testing.T) {
 	tests := map[string]struct {
 		hex          string
@@ -71,6 +117,21 @@ func TestAddressFromHex(t *testing.T) {
 			errorMessage: "",
 			address:      common.Address([20]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 18, 52}),
 		},
+		"address without prefix": {
+			hex:          "0000000000000000000000000000000000001234",
+			errorMessage: "",
+			address:      common.Address([20]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 18, 52}),
+		},
+		"address with surrounding whitespace": {
+			hex:          " 0x0000000000000000000000000000000000001234\n",
+			errorMessage: "",
+			address:      common.Address([20]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 18, 52}),
+		},
+		"address without prefix with surrounding whitespace": {
+			hex:          "\t0000000000000000000000000000000000001234 ",
+			errorMessage: "",
+			address:      common.Address([20]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 18, 52}),
+		},
 		"short address": {
 			hex:          "0x1234",
 			errorMessage: "[0x1234] is not a valid Ethereum address",
@@ -125,3 +186,81 @@ func TestAddressFromHex(t *testing.T) {
 		})
 	}
 }
+
+func TestIsContractDeployed(t *testing.T) {
+	var tests = map[string]struct {
+		code             []byte
+		codeErr          error
+		expectedDeployed bool
+		expectedError    error
+	}{
+		"non-empty code": {
+			code:             []byte{0x60, 0x80, 0x60, 0x40},
+			expectedDeployed: true,
+		},
+		"empty code": {
+			code:             []byte{},
+			expectedDeployed: false,
+		},
+		"no code": {
+			code:             nil,
+			expectedDeployed: false,
+		},
+		"code check failed": {
+			codeErr: fmt.Errorf("connection refused"),
+			expectedError: fmt.Errorf(
+				"failed to get code at address " +
+					"[0x0000000000000000000000000000000000000001]: " +
+					"[connection refused]",
+			),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			caller := &mockCodeCaller{code: test.code, err: test.codeErr}
+
+			deployed, err := ethutil.IsContractDeployed(
+				context.Background(),
+				caller,
+				common.HexToAddress("0x1"),
+			)
+			if !reflect.DeepEqual(test.expectedError, err) {
+				t.Fatalf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+
+			if deployed != test.expectedDeployed {
+				t.Errorf(
+					"unexpected result\nexpected: [%v]\nactual:   [%v]",
+					test.expectedDeployed,
+					deployed,
+				)
+			}
+		})
+	}
+}
+
+type mockCodeCaller struct {
+	code []byte
+	err  error
+}
+
+func (mcc *mockCodeCaller) CodeAt(
+	ctx context.Context,
+	contract common.Address,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	return mcc.code, mcc.err
+}
+
+func (mcc *mockCodeCaller) CallContract(
+	ctx context.Context,
+	call ethereum.CallMsg,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	return nil, fmt.Errorf("not implemented")
+}
@@ -0,0 +1,153 @@
+package ethutil
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// EventPosition is the position of an event on the chain. Events are ordered
+// by the number of the block they were emitted in, then by the index of
+// the transaction emitting them in the block, and then by the index of
+// the event log in the block.
+type EventPosition struct {
+	BlockNumber uint64
+	TxIndex     uint
+	LogIndex    uint
+}
+
+// Before returns true if the position is before the other position.
+func (ep EventPosition) Before(other EventPosition) bool {
+	if ep.BlockNumber != other.BlockNumber {
+		return ep.BlockNumber < other.BlockNumber
+	}
+	if ep.TxIndex != other.TxIndex {
+		return ep.TxIndex < other.TxIndex
+	}
+	return ep.LogIndex < other.LogIndex
+}
+
+// pendingEvent is an event buffered by OrderEvents until it is delivered.
+type pendingEvent[E any] struct {
+	event      E
+	position   EventPosition
+	receivedAt time.Time
+}
+
+// OrderEvents reads the events from the given channel and passes them to
+// the handler in strict chain position order. It blocks until the context is
+// done or the channel is closed, in which case all the buffered events are
+// passed to the handler before the function returns.
+//
+// Each event is held for the given delay after it is received so that events
+// received out of order, e.g. live events of the subscription and past events
+// pulled by the subscription monitoring, are sorted before they are passed to
+// the handler. Once an event is passed to the handler, all the buffered events
+// positioned before it are passed too. The events positioned at or before
+// the last event passed to the handler are dropped; these are duplicates of
+// the already handled events or events received too late to keep the order.
+func OrderEvents[E any](
+	ctx context.Context,
+	events <-chan E,
+	position func(event E) EventPosition,
+	delay time.Duration,
+	handler func(event E),
+) {
+	var pending []*pendingEvent[E]
+	var lastDelivered *EventPosition
+
+	// deliver passes to the handler the buffered events up to the last event
+	// received not later than the given time. All the events are delivered
+	// if the time is zero.
+	deliver := func(receivedUntil time.Time) {
+		sort.SliceStable(pending, func(i, j int) bool {
+			return pending[i].position.Before(pending[j].position)
+		})
+
+		count := 0
+		for i, p := range pending {
+			if receivedUntil.IsZero() || !p.receivedAt.After(receivedUntil) {
+				count = i + 1
+			}
+		}
+
+		for _, p := range pending[:count] {
+			handler(p.event)
+			position := p.position
+			lastDelivered = &position
+		}
+
+		pending = pending[count:]
+	}
+
+	isPending := func(position EventPosition) bool {
+		for _, p := range pending {
+			if p.position == position {
+				return true
+			}
+		}
+		return false
+	}
+
+	timer := time.NewTimer(0)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	defer timer.Stop()
+
+	for {
+		var release <-chan time.Time
+		if len(pending) > 0 {
+			earliest := pending[0].receivedAt
+			for _, p := range pending {
+				if p.receivedAt.Before(earliest) {
+					earliest = p.receivedAt
+				}
+			}
+			timer.Reset(time.Until(earliest.Add(delay)))
+			release = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if release != nil && !timer.Stop() {
+				<-timer.C
+			}
+
+			if !ok {
+				deliver(time.Time{})
+				return
+			}
+
+			eventPosition := position(event)
+
+			if lastDelivered != nil && !lastDelivered.Before(eventPosition) {
+				if *lastDelivered != eventPosition {
+					logger.Warningf(
+						"dropping event at block [%v], transaction [%v], "+
+							"log [%v] received after a later event has "+
+							"already been handled",
+						eventPosition.BlockNumber,
+						eventPosition.TxIndex,
+						eventPosition.LogIndex,
+					)
+				}
+				continue
+			}
+
+			if isPending(eventPosition) {
+				continue
+			}
+
+			pending = append(pending, &pendingEvent[E]{
+				event:      event,
+				position:   eventPosition,
+				receivedAt: time.Now(),
+			})
+		case now := <-release:
+			deliver(now.Add(-delay))
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package ethutil
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOrderEvents(t *testing.T) {
+	var tests = map[string]struct {
+		batches        [][]EventPosition
+		expectedEvents []EventPosition
+	}{
+		"interleaved live and past events": {
+			batches: [][]EventPosition{
+				// live events
+				{{10, 0, 3}, {12, 1, 7}},
+				// past events pulled by the subscription monitoring
+				{{9, 2, 1}, {10, 0, 3}, {10, 0, 2}, {11, 4, 5}, {12, 1, 7}},
+				// live event
+				{{12, 0, 4}},
+			},
+			expectedEvents: []EventPosition{
+				{9, 2, 1},
+				{10, 0, 2},
+				{10, 0, 3},
+				{11, 4, 5},
+				{12, 0, 4},
+				{12, 1, 7},
+			},
+		},
+		"duplicates of handled events": {
+			batches: [][]EventPosition{
+				{{10, 0, 1}, {10, 1, 2}},
+				nil, // wait until the events are handled
+				{{10, 0, 1}, {10, 1, 2}, {11, 0, 0}},
+			},
+			expectedEvents: []EventPosition{
+				{10, 0, 1},
+				{10, 1, 2},
+				{11, 0, 0},
+			},
+		},
+		"event received too late": {
+			batches: [][]EventPosition{
+				{{10, 0, 1}},
+				nil, // wait until the event is handled
+				{{9, 0, 0}, {11, 0, 0}},
+			},
+			expectedEvents: []EventPosition{
+				{10, 0, 1},
+				{11, 0, 0},
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			delay := 50 * time.Millisecond
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			events := make(chan EventPosition)
+			var handled []EventPosition
+			done := make(chan struct{})
+
+			go func() {
+				defer close(done)
+				OrderEvents(
+					ctx,
+					events,
+					func(event EventPosition) EventPosition { return event },
+					delay,
+					func(event EventPosition) {
+						handled = append(handled, event)
+					},
+				)
+			}()
+
+			for _, batch := range test.batches {
+				if batch == nil {
+					time.Sleep(2 * delay)
+					continue
+				}
+
+				for _, event := range batch {
+					events <- event
+				}
+			}
+
+			time.Sleep(2 * delay)
+			close(events)
+			<-done
+
+			if !reflect.DeepEqual(test.expectedEvents, handled) {
+				t.Errorf(
+					"unexpected handled events\nexpected: [%v]\nactual:   [%v]",
+					test.expectedEvents,
+					handled,
+				)
+			}
+		})
+	}
+}
+
+func TestOrderEvents_HoldsEventsForDelay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan EventPosition)
+	handled := make(chan EventPosition, 10)
+
+	go OrderEvents(
+		ctx,
+		events,
+		func(event EventPosition) EventPosition { return event },
+		time.Hour,
+		func(event EventPosition) {
+			handled <- event
+		},
+	)
+
+	events <- EventPosition{BlockNumber: 10}
+
+	select {
+	case event := <-handled:
+		t.Fatalf("event handled before the delay passed: [%v]", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Closing the channel delivers all the buffered events.
+	close(events)
+
+	select {
+	case event := <-handled:
+		if event.BlockNumber != 10 {
+			t.Errorf(
+				"unexpected block number\nexpected: [%v]\nactual:   [%v]",
+				10,
+				event.BlockNumber,
+			)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("buffered event not handled after closing the channel")
+	}
+}
+
+func TestEventPosition_Before(t *testing.T) {
+	var tests = map[string]struct {
+		position       EventPosition
+		other          EventPosition
+		expectedBefore bool
+	}{
+		"earlier block": {
+			position:       EventPosition{10, 5, 9},
+			other:          EventPosition{11, 0, 0},
+			expectedBefore: true,
+		},
+		"earlier transaction": {
+			position:       EventPosition{10, 1, 9},
+			other:          EventPosition{10, 2, 0},
+			expectedBefore: true,
+		},
+		"earlier log": {
+			position:       EventPosition{10, 1, 1},
+			other:          EventPosition{10, 1, 2},
+			expectedBefore: true,
+		},
+		"same position": {
+			position:       EventPosition{10, 1, 1},
+			other:          EventPosition{10, 1, 1},
+			expectedBefore: false,
+		},
+		"later block": {
+			position:       EventPosition{11, 0, 0},
+			other:          EventPosition{10, 5, 9},
+			expectedBefore: false,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			before := test.position.Before(test.other)
+			if before != test.expectedBefore {
+				t.Errorf(
+					"unexpected result\nexpected: [%v]\nactual:   [%v]",
+					test.expectedBefore,
+					before,
+				)
+			}
+		})
+	}
+}
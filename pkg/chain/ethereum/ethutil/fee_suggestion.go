@@ -0,0 +1,119 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// feeHistoryBlockCount is the number of the most recent blocks whose fee
+// history is taken into account when suggesting the transaction fees.
+const feeHistoryBlockCount = 20
+
+// FeeSuggestion holds the gas parameters suggested for a dynamic fee
+// transaction to be included in the chain within the target number of blocks.
+type FeeSuggestion struct {
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+
+	// RewardPercentile is the percentile of the priority fees paid in the
+	// recent blocks the suggested gas tip cap is based on. The higher
+	// the percentile, the more aggressive the suggestion is.
+	RewardPercentile float64
+}
+
+// SuggestFees suggests the gas fee cap and gas tip cap of a dynamic fee
+// transaction that should be included in the chain within the given number
+// of blocks. The suggestion is based on the fee history of the recent blocks
+// obtained with the eth_feeHistory method of the raw RPC client, since the
+// typed client does not expose it.
+//
+// The gas tip cap is the median of the priority fees paid in the recent
+// blocks at the reward percentile chosen for the target; the shorter the
+// target, the higher the percentile. The gas fee cap is the gas tip cap
+// increased by the base fee of the next block, assuming the base fee grows
+// by the maximum of 12.5% in each block of the target.
+//
+// The suggestion is meant to be the starting point for the transaction fees
+// so that fewer resubmissions are needed by the mining waiter.
+func SuggestFees(
+	ctx context.Context,
+	client RPCCaller,
+	targetBlocks uint64,
+) (*FeeSuggestion, error) {
+	if targetBlocks == 0 {
+		return nil, fmt.Errorf("target number of blocks must be positive")
+	}
+
+	rewardPercentile := rewardPercentileForTarget(targetBlocks)
+
+	var feeHistory struct {
+		BaseFee []*hexutil.Big   `json:"baseFeePerGas"`
+		Reward  [][]*hexutil.Big `json:"reward"`
+	}
+
+	err := client.CallContext(
+		ctx,
+		&feeHistory,
+		"eth_feeHistory",
+		hexutil.Uint64(feeHistoryBlockCount),
+		"latest",
+		[]float64{rewardPercentile},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee history: [%v]", err)
+	}
+
+	// The last base fee returned in the fee history is the base fee of
+	// the next block.
+	if len(feeHistory.BaseFee) == 0 {
+		return nil, fmt.Errorf("fee history does not contain base fees")
+	}
+	nextBaseFee := (*big.Int)(feeHistory.BaseFee[len(feeHistory.BaseFee)-1])
+
+	var rewards []*big.Int
+	for _, blockRewards := range feeHistory.Reward {
+		if len(blockRewards) > 0 && blockRewards[0] != nil {
+			rewards = append(rewards, (*big.Int)(blockRewards[0]))
+		}
+	}
+	if len(rewards) == 0 {
+		return nil, fmt.Errorf("fee history does not contain rewards")
+	}
+
+	sort.Slice(rewards, func(i, j int) bool {
+		return rewards[i].Cmp(rewards[j]) < 0
+	})
+	gasTipCap := new(big.Int).Set(rewards[len(rewards)/2])
+
+	// The base fee can increase by 12.5% at maximum within a single block.
+	maxBaseFee := new(big.Int).Set(nextBaseFee)
+	for i := uint64(1); i < targetBlocks; i++ {
+		maxBaseFee.Add(maxBaseFee, new(big.Int).Div(maxBaseFee, big.NewInt(8)))
+	}
+
+	return &FeeSuggestion{
+		GasFeeCap:        new(big.Int).Add(maxBaseFee, gasTipCap),
+		GasTipCap:        gasTipCap,
+		RewardPercentile: rewardPercentile,
+	}, nil
+}
+
+// rewardPercentileForTarget returns the percentile of the priority fees paid
+// in the recent blocks that should be offered for the transaction to be
+// included within the given number of blocks.
+func rewardPercentileForTarget(targetBlocks uint64) float64 {
+	switch {
+	case targetBlocks <= 1:
+		return 90
+	case targetBlocks <= 3:
+		return 75
+	case targetBlocks <= 10:
+		return 50
+	default:
+		return 25
+	}
+}
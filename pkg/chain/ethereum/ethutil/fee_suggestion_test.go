@@ -0,0 +1,145 @@
+package ethutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+const testFeeHistory = `{
+	"oldestBlock": "0x64",
+	"baseFeePerGas": ["0x12a05f200", "0x1dcd65000", "0x2540be400"],
+	"gasUsedRatio": [0.5, 0.9],
+	"reward": [["0xb2d05e00"], ["0x3b9aca00"], ["0x77359400"]]
+}`
+
+func TestSuggestFees(t *testing.T) {
+	var tests = map[string]struct {
+		targetBlocks       uint64
+		expectedSuggestion *FeeSuggestion
+	}{
+		"next block": {
+			targetBlocks: 1,
+			expectedSuggestion: &FeeSuggestion{
+				// 10 Gwei base fee + 2 Gwei tip
+				GasFeeCap:        big.NewInt(12000000000),
+				GasTipCap:        big.NewInt(2000000000),
+				RewardPercentile: 90,
+			},
+		},
+		"within three blocks": {
+			targetBlocks: 3,
+			expectedSuggestion: &FeeSuggestion{
+				// 10 Gwei base fee * 1.125 * 1.125 + 2 Gwei tip
+				GasFeeCap:        big.NewInt(14656250000),
+				GasTipCap:        big.NewInt(2000000000),
+				RewardPercentile: 75,
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			client := &mockFeeHistoryCaller{response: testFeeHistory}
+
+			suggestion, err := SuggestFees(
+				context.Background(),
+				client,
+				test.targetBlocks,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(test.expectedSuggestion, suggestion) {
+				t.Errorf(
+					"unexpected suggestion\nexpected: [%+v]\nactual:   [%+v]",
+					test.expectedSuggestion,
+					suggestion,
+				)
+			}
+
+			expectedPercentiles := []float64{test.expectedSuggestion.RewardPercentile}
+			if !reflect.DeepEqual(expectedPercentiles, client.args[2]) {
+				t.Errorf(
+					"unexpected reward percentiles\n"+
+						"expected: [%v]\nactual:   [%v]",
+					expectedPercentiles,
+					client.args[2],
+				)
+			}
+		})
+	}
+}
+
+func TestSuggestFees_Errors(t *testing.T) {
+	var tests = map[string]struct {
+		targetBlocks  uint64
+		response      string
+		callErr       error
+		expectedError string
+	}{
+		"zero target": {
+			targetBlocks:  0,
+			response:      testFeeHistory,
+			expectedError: "target number of blocks must be positive",
+		},
+		"call failed": {
+			targetBlocks:  1,
+			callErr:       fmt.Errorf("method not found"),
+			expectedError: "failed to get fee history: [method not found]",
+		},
+		"no rewards": {
+			targetBlocks:  1,
+			response:      `{"baseFeePerGas": ["0x1"], "reward": []}`,
+			expectedError: "fee history does not contain rewards",
+		},
+		"no base fees": {
+			targetBlocks:  1,
+			response:      `{"baseFeePerGas": [], "reward": [["0x1"]]}`,
+			expectedError: "fee history does not contain base fees",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			client := &mockFeeHistoryCaller{
+				response: test.response,
+				err:      test.callErr,
+			}
+
+			_, err := SuggestFees(context.Background(), client, test.targetBlocks)
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
+
+type mockFeeHistoryCaller struct {
+	response string
+	err      error
+	args     []interface{}
+}
+
+func (mfhc *mockFeeHistoryCaller) CallContext(
+	ctx context.Context,
+	result interface{},
+	method string,
+	args ...interface{},
+) error {
+	mfhc.args = args
+
+	if mfhc.err != nil {
+		return mfhc.err
+	}
+
+	return json.Unmarshal([]byte(mfhc.response), result)
+}
@@ -0,0 +1,78 @@
+package ethutil
+
+import (
+	"math/big"
+	"sync"
+)
+
+// GasBudget is the cumulative amount of wei the mining waiters are allowed to
+// spend on the gas of transaction resubmissions. The budget is safe for
+// concurrent use so a single budget can be shared by multiple mining waiters,
+// putting a hard ceiling on the gas expenditure of the whole process.
+//
+// The spend of a resubmission is estimated as the gas limit of the transaction
+// multiplied by the gas price for legacy transactions and by the gas fee cap
+// for dynamic fee transactions, that is, the maximum amount the resubmitted
+// transaction can cost.
+type GasBudget struct {
+	mutex sync.Mutex
+	limit *big.Int
+	spent *big.Int
+}
+
+// NewGasBudget creates a new GasBudget allowing to spend up to the given
+// amount of wei on resubmissions.
+func NewGasBudget(limit *big.Int) *GasBudget {
+	return &GasBudget{
+		limit: new(big.Int).Set(limit),
+		spent: big.NewInt(0),
+	}
+}
+
+// Limit returns the total amount of wei allowed to be spent.
+func (gb *GasBudget) Limit() *big.Int {
+	return new(big.Int).Set(gb.limit)
+}
+
+// Spent returns the amount of wei spent so far.
+func (gb *GasBudget) Spent() *big.Int {
+	gb.mutex.Lock()
+	defer gb.mutex.Unlock()
+
+	return new(big.Int).Set(gb.spent)
+}
+
+// Remaining returns the amount of wei that can still be spent.
+func (gb *GasBudget) Remaining() *big.Int {
+	gb.mutex.Lock()
+	defer gb.mutex.Unlock()
+
+	return new(big.Int).Sub(gb.limit, gb.spent)
+}
+
+// reserve adds the given amount to the spent amount if the result does not
+// exceed the limit. It returns false and leaves the budget intact otherwise.
+func (gb *GasBudget) reserve(amount *big.Int) bool {
+	gb.mutex.Lock()
+	defer gb.mutex.Unlock()
+
+	spent := new(big.Int).Add(gb.spent, amount)
+	if spent.Cmp(gb.limit) > 0 {
+		return false
+	}
+
+	gb.spent = spent
+	return true
+}
+
+// release returns the given previously reserved amount to the budget. It is
+// used when the reserved resubmission has not been accepted by the chain.
+func (gb *GasBudget) release(amount *big.Int) {
+	gb.mutex.Lock()
+	defer gb.mutex.Unlock()
+
+	gb.spent.Sub(gb.spent, amount)
+	if gb.spent.Sign() < 0 {
+		gb.spent.SetInt64(0)
+	}
+}
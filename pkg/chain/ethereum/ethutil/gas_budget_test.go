@@ -0,0 +1,72 @@
+package ethutil
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestGasBudget_ConcurrentReservations(t *testing.T) {
+	budget := NewGasBudget(big.NewInt(1000))
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	reserved := 0
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if budget.reserve(big.NewInt(30)) {
+				mutex.Lock()
+				reserved++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 33 * 30 = 990 fits into the budget, 34 * 30 = 1020 does not.
+	if reserved != 33 {
+		t.Errorf(
+			"unexpected number of reservations\nexpected: [%v]\nactual:   [%v]",
+			33,
+			reserved,
+		)
+	}
+
+	expectedSpent := big.NewInt(990)
+	if budget.Spent().Cmp(expectedSpent) != 0 {
+		t.Errorf(
+			"unexpected spent budget\nexpected: [%v]\nactual:   [%v]",
+			expectedSpent,
+			budget.Spent(),
+		)
+	}
+}
+
+func TestGasBudget_Release(t *testing.T) {
+	budget := NewGasBudget(big.NewInt(100))
+
+	if !budget.reserve(big.NewInt(80)) {
+		t.Fatal("reservation within the budget refused")
+	}
+	if budget.reserve(big.NewInt(30)) {
+		t.Fatal("reservation exceeding the budget accepted")
+	}
+
+	budget.release(big.NewInt(80))
+
+	if !budget.reserve(big.NewInt(30)) {
+		t.Fatal("reservation within the released budget refused")
+	}
+
+	expectedRemaining := big.NewInt(70)
+	if budget.Remaining().Cmp(expectedRemaining) != 0 {
+		t.Errorf(
+			"unexpected remaining budget\nexpected: [%v]\nactual:   [%v]",
+			expectedRemaining,
+			budget.Remaining(),
+		)
+	}
+}
@@ -0,0 +1,120 @@
+package ethutil
+
+import (
+	"math/big"
+)
+
+// GasBumpStrategy computes the gas parameters of transactions resubmitted by
+// the mining waiter. It makes the resubmission economics pluggable so that
+// chains and fee markets with different characteristics can use different
+// bump algorithms, e.g. linear, geometric with a different factor or driven
+// by a gas price oracle.
+//
+// The mining waiter always caps the returned values at the max gas fee cap
+// and gives up on a transaction if the returned gas price or fees do not
+// fulfill the replacement threshold required by miners.
+type GasBumpStrategy interface {
+	// NextLegacyGasPrice returns the gas price of the resubmitted legacy
+	// transaction given the gas price of the previous transaction and
+	// the maximum allowed gas price.
+	NextLegacyGasPrice(oldGasPrice, maxGasPrice *big.Int) *big.Int
+
+	// NextDynamicFees returns the gas fee cap and the gas tip cap of
+	// the resubmitted dynamic fee transaction given the fees of the previous
+	// transaction, the latest base fee, and the maximum allowed gas fee cap.
+	NextDynamicFees(
+		oldGasFeeCap *big.Int,
+		oldGasTipCap *big.Int,
+		baseFee *big.Int,
+		maxGasFeeCap *big.Int,
+	) (gasFeeCap *big.Int, gasTipCap *big.Int)
+}
+
+// DefaultGasBumpStrategy is the gas bump strategy used by the mining waiter
+// if no other strategy is configured. It bumps up the gas price of legacy
+// pre EIP-1559 transactions by 20%. For dynamic fee post EIP-1559
+// transactions, it bumps up the gas tip cap by 20% and sets the gas fee cap to
+// 2 * baseFee + gasTipCap, raised to the replacement threshold if necessary.
+// Both the gas price and the gas fee cap are capped at the max allowed value.
+type DefaultGasBumpStrategy struct {
+	// MinGasTipCap, if set, is the floor for the gas tip cap of
+	// the resubmitted dynamic fee transactions.
+	MinGasTipCap *big.Int
+}
+
+// NextLegacyGasPrice adds 20% to the previous gas price.
+func (dgbs *DefaultGasBumpStrategy) NextLegacyGasPrice(
+	oldGasPrice *big.Int,
+	maxGasPrice *big.Int,
+) *big.Int {
+	twentyPercent := new(big.Int).Div(oldGasPrice, big.NewInt(5))
+	gasPrice := new(big.Int).Add(oldGasPrice, twentyPercent)
+
+	// If we reached the maximum allowed gas price, submit one more time
+	// with the maximum.
+	if gasPrice.Cmp(maxGasPrice) > 0 {
+		gasPrice = maxGasPrice
+	}
+
+	return gasPrice
+}
+
+// NextDynamicFees adds 20% to the previous gas tip cap and computes the gas
+// fee cap from the latest base fee and the new gas tip cap.
+func (dgbs *DefaultGasBumpStrategy) NextDynamicFees(
+	oldGasFeeCap *big.Int,
+	oldGasTipCap *big.Int,
+	baseFee *big.Int,
+	maxGasFeeCap *big.Int,
+) (*big.Int, *big.Int) {
+	// Increase the gas tip cap by 20%. A minimum increase by 10% comparing
+	// to the previous value is required for transaction replacement to be
+	// accepted by miners as mentioned in:
+	// https://github.com/ethereum/go-ethereum/pull/22898/files#r636583352.
+	// We increase it even more than the required level to greatly increase
+	// the transaction's chance for being picked up by miners.
+	gasTipCap := new(big.Int).Add(
+		oldGasTipCap,
+		new(big.Int).Div(oldGasTipCap, big.NewInt(5)), // + 20%
+	)
+
+	// The original gas tip cap may be far below the level required by
+	// miners so no reasonable bump makes the transaction attractive.
+	// Lift the gas tip cap to the configured floor in such a case.
+	if dgbs.MinGasTipCap != nil && gasTipCap.Cmp(dgbs.MinGasTipCap) < 0 {
+		gasTipCap = dgbs.MinGasTipCap
+	}
+
+	// Compute new value of gas fee cap using the latest base fee
+	// and new gas tip cap. The `gasFeeCap = 2 * baseFee + gasTipCap`
+	// equation originates from `go-ethereum` which estimates this
+	// parameter in that way.
+	// See: https://github.com/ethereum/go-ethereum/pull/23038.
+	// Having the `baseFee` taken twice means the `gasFeeCap` should
+	// be resilient for six consecutive increases of the `baseFee`.
+	// This is because `baseFee` can be increased by 12.5% at maximum
+	// within a single increase.
+	gasFeeCap := new(big.Int).Add(
+		new(big.Int).Mul(baseFee, big.NewInt(2)),
+		gasTipCap,
+	)
+
+	// The new gas fee cap value needs to be at least 10% bigger
+	// than the old value. Otherwise, the transaction replacement
+	// won't be accepted by miners. If that's the case (e.g. the `baseFee`
+	// dramatically decreased since the previous transaction) we need to
+	// set the new gas fee cap value to the minimum value acceptable
+	// by miners.
+	requiredGasFeeCapThreshold := minReplacementValue(oldGasFeeCap)
+	if gasFeeCap.Cmp(requiredGasFeeCapThreshold) < 0 {
+		gasFeeCap = requiredGasFeeCapThreshold
+	}
+
+	// If we reached the maximum allowed gas fee cap, submit one more time
+	// with the maximum.
+	if gasFeeCap.Cmp(maxGasFeeCap) > 0 {
+		gasFeeCap = maxGasFeeCap
+	}
+
+	return gasFeeCap, gasTipCap
+}
@@ -0,0 +1,129 @@
+package ethutil
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDefaultGasBumpStrategy_NextLegacyGasPrice(t *testing.T) {
+	var tests = map[string]struct {
+		oldGasPrice      *big.Int
+		maxGasPrice      *big.Int
+		expectedGasPrice *big.Int
+	}{
+		"below the max": {
+			oldGasPrice:      big.NewInt(20000000000), // 20 Gwei
+			maxGasPrice:      big.NewInt(45000000000), // 45 Gwei
+			expectedGasPrice: big.NewInt(24000000000), // + 20%
+		},
+		"above the max": {
+			oldGasPrice:      big.NewInt(41472000000), // 41.472 Gwei
+			maxGasPrice:      big.NewInt(45000000000), // 45 Gwei
+			expectedGasPrice: big.NewInt(45000000000), // max allowed
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			strategy := &DefaultGasBumpStrategy{}
+
+			gasPrice := strategy.NextLegacyGasPrice(
+				test.oldGasPrice,
+				test.maxGasPrice,
+			)
+
+			if gasPrice.Cmp(test.expectedGasPrice) != 0 {
+				t.Errorf(
+					"unexpected gas price\nexpected: [%v]\nactual:   [%v]",
+					test.expectedGasPrice,
+					gasPrice,
+				)
+			}
+		})
+	}
+}
+
+func TestDefaultGasBumpStrategy_NextDynamicFees(t *testing.T) {
+	var tests = map[string]struct {
+		minGasTipCap      *big.Int
+		oldGasFeeCap      *big.Int
+		oldGasTipCap      *big.Int
+		baseFee           *big.Int
+		maxGasFeeCap      *big.Int
+		expectedGasFeeCap *big.Int
+		expectedGasTipCap *big.Int
+	}{
+		"base fee unchanged": {
+			oldGasFeeCap:      big.NewInt(24000000000), // 24 Gwei
+			oldGasTipCap:      big.NewInt(4000000000),  // 4 Gwei
+			baseFee:           big.NewInt(10000000000), // 10 Gwei
+			maxGasFeeCap:      big.NewInt(45000000000), // 45 Gwei
+			expectedGasFeeCap: big.NewInt(26400000000), // 2 * baseFee + tip
+			expectedGasTipCap: big.NewInt(4800000000),  // + 20%
+		},
+		"base fee increased": {
+			oldGasFeeCap:      big.NewInt(24000000000), // 24 Gwei
+			oldGasTipCap:      big.NewInt(4000000000),  // 4 Gwei
+			baseFee:           big.NewInt(15000000000), // 15 Gwei
+			maxGasFeeCap:      big.NewInt(45000000000), // 45 Gwei
+			expectedGasFeeCap: big.NewInt(34800000000), // 2 * baseFee + tip
+			expectedGasTipCap: big.NewInt(4800000000),  // + 20%
+		},
+		"base fee decreased": {
+			oldGasFeeCap:      big.NewInt(24000000000), // 24 Gwei
+			oldGasTipCap:      big.NewInt(4000000000),  // 4 Gwei
+			baseFee:           big.NewInt(5000000000),  // 5 Gwei
+			maxGasFeeCap:      big.NewInt(45000000000), // 45 Gwei
+			expectedGasFeeCap: big.NewInt(26400000000), // replacement threshold
+			expectedGasTipCap: big.NewInt(4800000000),  // + 20%
+		},
+		"max gas fee cap reached": {
+			oldGasFeeCap:      big.NewInt(24000000000), // 24 Gwei
+			oldGasTipCap:      big.NewInt(4000000000),  // 4 Gwei
+			baseFee:           big.NewInt(30000000000), // 30 Gwei
+			maxGasFeeCap:      big.NewInt(45000000000), // 45 Gwei
+			expectedGasFeeCap: big.NewInt(45000000000), // max allowed
+			expectedGasTipCap: big.NewInt(4800000000),  // + 20%
+		},
+		"min gas tip cap": {
+			minGasTipCap:      big.NewInt(6000000000),  // 6 Gwei
+			oldGasFeeCap:      big.NewInt(24000000000), // 24 Gwei
+			oldGasTipCap:      big.NewInt(1000000000),  // 1 Gwei
+			baseFee:           big.NewInt(10000000000), // 10 Gwei
+			maxGasFeeCap:      big.NewInt(45000000000), // 45 Gwei
+			expectedGasFeeCap: big.NewInt(26400000000), // replacement threshold
+			expectedGasTipCap: big.NewInt(6000000000),  // min gas tip cap
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			strategy := &DefaultGasBumpStrategy{
+				MinGasTipCap: test.minGasTipCap,
+			}
+
+			gasFeeCap, gasTipCap := strategy.NextDynamicFees(
+				test.oldGasFeeCap,
+				test.oldGasTipCap,
+				test.baseFee,
+				test.maxGasFeeCap,
+			)
+
+			if gasFeeCap.Cmp(test.expectedGasFeeCap) != 0 {
+				t.Errorf(
+					"unexpected gas fee cap\nexpected: [%v]\nactual:   [%v]",
+					test.expectedGasFeeCap,
+					gasFeeCap,
+				)
+			}
+
+			if gasTipCap.Cmp(test.expectedGasTipCap) != 0 {
+				t.Errorf(
+					"unexpected gas tip cap\nexpected: [%v]\nactual:   [%v]",
+					test.expectedGasTipCap,
+					gasTipCap,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package ethutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultHealthCheckStaleness is the default maximum age of the latest block
+// header for the node to be considered healthy. It is used by Healthy if no
+// other value is provided.
+const DefaultHealthCheckStaleness = 5 * time.Minute
+
+var (
+	// ErrNodeUnreachable is returned from Healthy when the latest block
+	// header could not be fetched from the node.
+	ErrNodeUnreachable = errors.New("node is unreachable")
+
+	// ErrNodeStale is returned from Healthy when the latest block header
+	// known to the node is older than the staleness threshold which usually
+	// means the node is stuck or still syncing.
+	ErrNodeStale = errors.New("node is stale or syncing")
+)
+
+// Healthy checks if the node behind the given client is reachable and
+// reasonably synced. The node is considered synced if the timestamp of its
+// latest block header is not older than maxStaleness compared to the
+// wall-clock time. DefaultHealthCheckStaleness is used if maxStaleness is not
+// positive. The returned error wraps ErrNodeUnreachable if the latest header
+// could not be fetched and ErrNodeStale if the header is too old.
+//
+// The client can be wrapped with the rate limiter so that health checks count
+// against the limits of the node as all the other requests.
+func Healthy(
+	ctx context.Context,
+	client EthereumClient,
+	maxStaleness time.Duration,
+) error {
+	if maxStaleness <= 0 {
+		maxStaleness = DefaultHealthCheckStaleness
+	}
+
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: [%v]", ErrNodeUnreachable, err)
+	}
+	if header == nil {
+		return fmt.Errorf("%w: [latest header not returned]", ErrNodeUnreachable)
+	}
+
+	headerTime := time.Unix(int64(header.Time), 0)
+	if age := time.Since(headerTime); age > maxStaleness {
+		return fmt.Errorf(
+			"%w: latest block [%v] is [%v] old; maximum allowed age is [%v]",
+			ErrNodeStale,
+			header.Number,
+			age.Round(time.Second),
+			maxStaleness,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package ethutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestHealthy(t *testing.T) {
+	var tests = map[string]struct {
+		headerAge     time.Duration
+		headerErr     error
+		maxStaleness  time.Duration
+		expectedError error
+	}{
+		"recent header": {
+			headerAge:     10 * time.Second,
+			maxStaleness:  time.Minute,
+			expectedError: nil,
+		},
+		"stale header": {
+			headerAge:     2 * time.Minute,
+			maxStaleness:  time.Minute,
+			expectedError: ErrNodeStale,
+		},
+		"default staleness threshold": {
+			headerAge:     DefaultHealthCheckStaleness + time.Minute,
+			maxStaleness:  0,
+			expectedError: ErrNodeStale,
+		},
+		"unreachable node": {
+			headerErr:     fmt.Errorf("connection refused"),
+			maxStaleness:  time.Minute,
+			expectedError: ErrNodeUnreachable,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			client := &mockHeaderEthereumClient{
+				mockEthereumClient: &mockEthereumClient{},
+				header: &types.Header{
+					Number: big.NewInt(100),
+					Time:   uint64(time.Now().Add(-test.headerAge).Unix()),
+				},
+				err: test.headerErr,
+			}
+
+			err := Healthy(context.Background(), client, test.maxStaleness)
+			if !errors.Is(err, test.expectedError) {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
+
+type mockHeaderEthereumClient struct {
+	*mockEthereumClient
+
+	header *types.Header
+	err    error
+}
+
+func (mhec *mockHeaderEthereumClient) HeaderByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*types.Header, error) {
+	return mhec.header, mhec.err
+}
@@ -0,0 +1,40 @@
+package ethutil
+
+import "sync"
+
+// ImmutableValue memoizes a value that never changes once fetched, e.g.
+// the result of a contract view method returning a constant or an immutable
+// variable. Only the first successfully fetched value is cached; failed
+// fetches are retried on the next call. The zero value is ready to use and
+// safe for concurrent use.
+//
+// Caching a value that may change makes the holder serve a stale value
+// forever, so only values known to be immutable should be cached.
+type ImmutableValue[T any] struct {
+	mutex  sync.Mutex
+	value  T
+	cached bool
+}
+
+// Get returns the cached value or, if no value has been cached yet, fetches
+// the value with the given function and caches it if the fetch succeeded.
+// Concurrent calls wait for the pending fetch instead of fetching the value
+// again.
+func (iv *ImmutableValue[T]) Get(fetch func() (T, error)) (T, error) {
+	iv.mutex.Lock()
+	defer iv.mutex.Unlock()
+
+	if iv.cached {
+		return iv.value, nil
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+
+	iv.value = value
+	iv.cached = true
+
+	return value, nil
+}
@@ -0,0 +1,74 @@
+package ethutil
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestImmutableValue_Get(t *testing.T) {
+	var immutableValue ImmutableValue[uint8]
+
+	var fetches int32
+	fetch := func() (uint8, error) {
+		atomic.AddInt32(&fetches, 1)
+		return 18, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			value, err := immutableValue.Get(fetch)
+			if err != nil {
+				t.Error(err)
+			}
+			if value != 18 {
+				t.Errorf(
+					"unexpected value\nexpected: [%v]\nactual:   [%v]",
+					18,
+					value,
+				)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if fetches != 1 {
+		t.Errorf(
+			"unexpected number of fetches\nexpected: [%v]\nactual:   [%v]",
+			1,
+			fetches,
+		)
+	}
+}
+
+func TestImmutableValue_GetFailed(t *testing.T) {
+	var immutableValue ImmutableValue[string]
+
+	_, err := immutableValue.Get(func() (string, error) {
+		return "", fmt.Errorf("connection refused")
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	// The failed fetch is not cached so the value is fetched again.
+	value, err := immutableValue.Get(func() (string, error) {
+		return "KEEP", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value != "KEEP" {
+		t.Errorf(
+			"unexpected value\nexpected: [%v]\nactual:   [%v]",
+			"KEEP",
+			value,
+		)
+	}
+}
@@ -3,6 +3,7 @@ package ethutil
 import (
 	"context"
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ipfs/go-log"
 	"math/big"
 )
@@ -50,3 +51,10 @@ func WrapCallLogging(
 ) EthereumClient {
 	return &loggingWrapper{client, logger}
 }
+
+func (lw *loggingWrapper) SubscribePendingTransactions(
+	ctx context.Context,
+	ch chan<- common.Hash,
+) (ethereum.Subscription, error) {
+	return SubscribePendingTransactions(ctx, lw.EthereumClient, ch)
+}
@@ -2,10 +2,14 @@ package ethutil
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
+	"sync"
 	"time"
 
+	goEthereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/keep-network/keep-common/pkg/chain/ethereum"
@@ -25,21 +29,75 @@ var (
 	// attempts are performed. This value can be overwritten in the
 	// configuration file.
 	DefaultMaxGasFeeCap = *ethereum.WrapWei(big.NewInt(500000000000)) // 500 Gwei
+
+	// DefaultForceMiningConcurrencyLimit is the default maximum number of
+	// transactions force mined at the same time by ForceMiningAll.
+	// This value can be overwritten in the configuration file.
+	DefaultForceMiningConcurrencyLimit = 10
+
+	// ReceiptRetryInitialBackoff is the initial delay before the transaction
+	// receipt is polled again after the receipt request failed due to
+	// a transport or provider problem. The delay doubles with each
+	// consecutive failure, up to ReceiptRetryMaxBackoff.
+	ReceiptRetryInitialBackoff = 2 * time.Second
+
+	// ReceiptRetryMaxBackoff is the maximum delay before the transaction
+	// receipt is polled again after the receipt request failed due to
+	// a transport or provider problem.
+	ReceiptRetryMaxBackoff = 30 * time.Second
 )
 
+// receiptPollInterval is the interval in which the transaction receipt is
+// polled while the transaction is not yet mined.
+const receiptPollInterval = time.Second
+
+// ErrForceMiningTimeout is returned from ForceMining when the transaction has
+// not been mined within the configured force mining timeout.
+var ErrForceMiningTimeout = errors.New("force mining timed out")
+
+// ErrTransactionReverted is returned from ForceMining when the transaction
+// has been mined but its execution has been reverted and the mining waiter
+// has been configured to fail on reverts.
+var ErrTransactionReverted = errors.New("transaction reverted")
+
+// ErrUnknownGasProfile is returned when the transaction is force mined with
+// a gas profile that has not been configured.
+var ErrUnknownGasProfile = errors.New("unknown gas profile")
+
 // MiningWaiter allows to block the execution until the given transaction is
 // mined as well as monitor the transaction and perform an appropriate action
 // in case it is not mined in the given timeout. This action is meant to
 // increase the transaction's chance for being picked up by miners.
 //
-// Specific action depends on transaction type:
+// Specific action depends on transaction type and the gas bump strategy.
+// With the DefaultGasBumpStrategy:
 // - legacy pre EIP-1559 transaction: bumps up the gas price by 20%
 // - dynamic fee post EIP-1559 transaction: bumps up the gas tip cap by 20%
 //   and adjusts the gas fee cap accordingly
 type MiningWaiter struct {
-	client        EthereumClient
-	checkInterval time.Duration
-	maxGasFeeCap  *big.Int
+	client                EthereumClient
+	checkInterval         time.Duration
+	initialCheckInterval  time.Duration
+	maxGasFeeCap          *big.Int
+	emergencyMaxGasFeeCap *big.Int
+	gasProfiles           map[string]*big.Int
+	gasBumpStrategy       GasBumpStrategy
+	gasFeeCapHeadroom     *big.Int
+	forceMiningTimeout    time.Duration
+	concurrencyLimit      int
+
+	resubmissionDisabled    bool
+	resubmissionInterceptor ResubmissionInterceptor
+	gaveUpCallback          GaveUpCallback
+	nonceProvider           NonceProvider
+	gasBudget               *GasBudget
+
+	stuckTransactionCancellation *stuckTransactionCancellation
+
+	failOnRevert bool
+
+	receiptRetryInitialBackoff time.Duration
+	receiptRetryMaxBackoff     time.Duration
 }
 
 // NewMiningWaiter creates a new MiningWaiter instance for the provided
@@ -50,13 +108,52 @@ type MiningWaiter struct {
 // transaction is not mined within that time, the mining waiter performs
 // appropriate actions to increase their chance for being picked up by miners.
 //
+// Initial check interval, if set, is the time given for the transaction to be
+// mined before the first resubmission. The check interval is used for the
+// first wait if it is not set.
+//
 // Max gas fee cap specifies the maximum price the client is willing to pay
 // per gas, for the transaction to be mined. The offered price can not
 // be higher than this value. If the maximum allowed price is reached, no
 // further resubmission attempts are performed.
+//
+// Emergency max gas fee cap, if set, replaces the max gas fee cap for
+// a transaction whose original price already meets or exceeds the max gas
+// fee cap, so that the transaction can still be resubmitted. It is ignored if
+// it is not higher than the max gas fee cap.
+//
+// Min gas tip cap, if set, is the floor for the gas tip cap of resubmitted
+// EIP-1559 transactions. It is ignored if it is negative or not lower than
+// the max gas fee cap. It is applied by the DefaultGasBumpStrategy and
+// ignored if another strategy is set with WithGasBumpStrategy.
+//
+// Gas fee cap headroom, if set, specifies the minimum margin the gas fee cap
+// of a resubmitted EIP-1559 transaction must keep over the latest base fee.
+// If the margin can not be kept, no further resubmission attempts are
+// performed.
+//
+// Force mining timeout, if set, bounds the total time of the ForceMining
+// operation regardless of whether the max gas fee cap has been reached.
+//
+// If resubmissions are disabled, the mining waiter only waits for the
+// transaction to be mined and never resubmits it.
+//
+// Force mining concurrency limit sets the maximum number of transactions
+// ForceMiningAll force mines at the same time.
+//
+// Gas profiles, if set, define max gas fee caps applied instead of the default
+// one to transactions force mined with ForceMiningWithGasProfile.
+//
+// If failing on revert is enabled, transactions mined with a failed execution
+// status are reported as failures instead of being treated as successfully
+// mined.
+//
+// Additional options, such as WithGasBudget, can be passed to further
+// configure the mining waiter.
 func NewMiningWaiter(
 	client EthereumClient,
 	config ethereum.Config,
+	options ...MiningWaiterOption,
 ) *MiningWaiter {
 	checkInterval := DefaultMiningCheckInterval
 	maxGasFeeCap := DefaultMaxGasFeeCap
@@ -70,39 +167,583 @@ func NewMiningWaiter(
 	logger.Infof("using [%v] mining check interval", checkInterval)
 	logger.Infof("using [%v] wei max gas fee cap", maxGasFeeCap)
 
-	return &MiningWaiter{
-		client,
-		checkInterval,
-		maxGasFeeCap.Int,
+	initialCheckInterval := checkInterval
+	if config.InitialMiningCheckInterval != 0 {
+		initialCheckInterval = config.InitialMiningCheckInterval
+		logger.Infof(
+			"using [%v] initial mining check interval",
+			initialCheckInterval,
+		)
+	}
+
+	emergencyMaxGasFeeCap := config.EmergencyMaxGasFeeCap.Int
+	if emergencyMaxGasFeeCap != nil {
+		if emergencyMaxGasFeeCap.Cmp(maxGasFeeCap.Int) <= 0 {
+			logger.Warningf(
+				"ignoring emergency max gas fee cap [%v] wei; the value "+
+					"must be higher than the max gas fee cap",
+				emergencyMaxGasFeeCap,
+			)
+			emergencyMaxGasFeeCap = nil
+		} else {
+			logger.Infof(
+				"using [%v] wei emergency max gas fee cap",
+				emergencyMaxGasFeeCap,
+			)
+		}
+	}
+
+	minGasTipCap := config.MinGasTipCap.Int
+	if minGasTipCap != nil {
+		if minGasTipCap.Sign() < 0 || minGasTipCap.Cmp(maxGasFeeCap.Int) >= 0 {
+			logger.Warningf(
+				"ignoring min gas tip cap [%v] wei; the value must be "+
+					"non-negative and lower than the max gas fee cap",
+				minGasTipCap,
+			)
+			minGasTipCap = nil
+		} else {
+			logger.Infof("using [%v] wei min gas tip cap", minGasTipCap)
+		}
+	}
+
+	if config.GasFeeCapHeadroom.Int != nil {
+		logger.Infof(
+			"using [%v] wei gas fee cap headroom",
+			config.GasFeeCapHeadroom,
+		)
+	}
+
+	if config.ForceMiningTimeout != 0 {
+		logger.Infof(
+			"using [%v] force mining timeout",
+			config.ForceMiningTimeout,
+		)
+	}
+
+	if config.DisableResubmission {
+		logger.Infof("transaction resubmissions are disabled")
+	}
+
+	if config.FailOnRevert {
+		logger.Infof("reverted transactions are reported as failures")
+	}
+
+	concurrencyLimit := DefaultForceMiningConcurrencyLimit
+	if config.ForceMiningConcurrencyLimit > 0 {
+		concurrencyLimit = config.ForceMiningConcurrencyLimit
+	}
+
+	gasProfiles := make(map[string]*big.Int, len(config.GasProfiles))
+	for name, profile := range config.GasProfiles {
+		profileMaxGasFeeCap := maxGasFeeCap.Int
+		if profile.MaxGasFeeCap.Int != nil {
+			profileMaxGasFeeCap = profile.MaxGasFeeCap.Int
+		}
+
+		logger.Infof(
+			"using [%v] wei max gas fee cap for gas profile [%v]",
+			profileMaxGasFeeCap,
+			name,
+		)
+
+		gasProfiles[strings.ToLower(name)] = profileMaxGasFeeCap
+	}
+
+	miningWaiter := &MiningWaiter{
+		client:                client,
+		checkInterval:         checkInterval,
+		initialCheckInterval:  initialCheckInterval,
+		maxGasFeeCap:          maxGasFeeCap.Int,
+		emergencyMaxGasFeeCap: emergencyMaxGasFeeCap,
+		gasProfiles:           gasProfiles,
+		gasBumpStrategy:       &DefaultGasBumpStrategy{MinGasTipCap: minGasTipCap},
+		gasFeeCapHeadroom:     config.GasFeeCapHeadroom.Int,
+		forceMiningTimeout:    config.ForceMiningTimeout,
+		concurrencyLimit:      concurrencyLimit,
+
+		resubmissionDisabled: config.DisableResubmission,
+		failOnRevert:         config.FailOnRevert,
+
+		receiptRetryInitialBackoff: ReceiptRetryInitialBackoff,
+		receiptRetryMaxBackoff:     ReceiptRetryMaxBackoff,
+	}
+
+	for _, option := range options {
+		option(miningWaiter)
+	}
+
+	if miningWaiter.gasBudget != nil {
+		logger.Infof(
+			"using [%v] wei resubmission gas budget",
+			miningWaiter.gasBudget.Limit(),
+		)
+	}
+
+	if miningWaiter.stuckTransactionCancellation != nil {
+		logger.Infof(
+			"stuck transactions are cancelled when giving up for reasons %v",
+			miningWaiter.stuckTransactionCancellation.reasonList(),
+		)
+	}
+
+	return miningWaiter
+}
+
+// MaxGasFeeCap returns the max gas fee cap of resubmitted transactions used
+// when no gas profile is selected. The returned value is a copy and can be
+// modified freely.
+func (mw *MiningWaiter) MaxGasFeeCap() *big.Int {
+	return new(big.Int).Set(mw.maxGasFeeCap)
+}
+
+// CheckInterval returns the interval in which the mining waiter checks if
+// the transaction has been mined before resubmitting it.
+func (mw *MiningWaiter) CheckInterval() time.Duration {
+	return mw.checkInterval
+}
+
+// MiningWaiterOption is an option of the mining waiter passed to
+// NewMiningWaiter.
+type MiningWaiterOption func(*MiningWaiter)
+
+// WithGasBudget sets the budget of the gas spent on transaction
+// resubmissions. Once the budget is exhausted, the mining waiter refuses
+// further resubmissions and gives up on the transactions with
+// the GaveUpGasBudgetExhausted reason. The same budget can be passed to
+// multiple mining waiters to limit their total gas expenditure.
+func WithGasBudget(budget *GasBudget) MiningWaiterOption {
+	return func(mw *MiningWaiter) {
+		mw.gasBudget = budget
+	}
+}
+
+// WithGasBumpStrategy sets the strategy computing the gas parameters of
+// resubmitted transactions. The DefaultGasBumpStrategy is used if no
+// strategy is set.
+func WithGasBumpStrategy(strategy GasBumpStrategy) MiningWaiterOption {
+	return func(mw *MiningWaiter) {
+		mw.gasBumpStrategy = strategy
+	}
+}
+
+// reserveGasBudget reserves the estimated cost of a resubmission with the
+// given gas limit and price per gas in the gas budget, if one is set. It
+// returns the reserved amount that should be released if the resubmission
+// is not accepted, and false if the budget has been exhausted.
+func (mw *MiningWaiter) reserveGasBudget(
+	gasLimit uint64,
+	pricePerGas *big.Int,
+) (*big.Int, bool) {
+	if mw.gasBudget == nil {
+		return nil, true
+	}
+
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), pricePerGas)
+	if !mw.gasBudget.reserve(cost) {
+		return nil, false
 	}
+
+	return cost, true
+}
+
+// releaseGasBudget returns the given amount reserved with reserveGasBudget
+// to the gas budget, if one is set.
+func (mw *MiningWaiter) releaseGasBudget(reserved *big.Int) {
+	if mw.gasBudget == nil || reserved == nil {
+		return
+	}
+
+	mw.gasBudget.release(reserved)
+}
+
+// ResubmitParams are the gas parameters of the transaction resubmitted by
+// the mining waiter. GasPrice is set for legacy transactions, GasFeeCap and
+// GasTipCap are set for dynamic fee transactions.
+type ResubmitParams struct {
+	GasPrice  *big.Int
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+
+	// CorrelationID is the correlation ID of the force mining operation,
+	// if one has been supplied. It is informational only and it is ignored
+	// in the parameters returned from the interceptor.
+	CorrelationID string
+}
+
+// ResubmissionInterceptor is called by the mining waiter with the computed
+// parameters of the transaction just before the transaction is resubmitted.
+// If the interceptor returns false, the resubmission is skipped and the
+// mining waiter keeps waiting for the previously submitted transaction.
+// Otherwise, the returned parameters are used for the resubmission; nil
+// parameters mean the computed ones should be used. The returned parameters
+// are rejected in favor of the computed ones if they do not bump the price of
+// the previously submitted transaction by the replacement threshold or if
+// they exceed the max gas fee cap.
+type ResubmissionInterceptor func(params *ResubmitParams) (*ResubmitParams, bool)
+
+// SetResubmissionInterceptor sets the interceptor allowing to inspect, adjust,
+// or veto the parameters of each transaction resubmission. It should be set
+// before the mining waiter is used.
+func (mw *MiningWaiter) SetResubmissionInterceptor(
+	interceptor ResubmissionInterceptor,
+) {
+	mw.resubmissionInterceptor = interceptor
+}
+
+// NonceProvider returns the nonce the resubmission of the given transaction
+// should be submitted with. It lets clients managing nonces externally set
+// the current nonce of the transaction, e.g. after resynchronizing it with
+// the chain. The transaction is the last one submitted by the mining waiter.
+type NonceProvider func(transaction *types.Transaction) (*big.Int, error)
+
+// SetNonceProvider sets the provider consulted for the nonce before each
+// resubmission. If the provider is not set or it fails, the resubmission uses
+// the nonce of the original transactor options, which replaces the original
+// transaction. It should be set before the mining waiter is used.
+func (mw *MiningWaiter) SetNonceProvider(provider NonceProvider) {
+	mw.nonceProvider = provider
+}
+
+// provideNonce sets the nonce returned by the nonce provider, if one is set,
+// in the transactor options of the resubmission of the given transaction.
+func (mw *MiningWaiter) provideNonce(
+	ctx context.Context,
+	transactorOptions *bind.TransactOpts,
+	transaction *types.Transaction,
+) {
+	if mw.nonceProvider == nil {
+		return
+	}
+
+	nonce, err := mw.nonceProvider(transaction)
+	if err != nil {
+		loggerFor(ctx).Warningf(
+			"could not get nonce for resubmission of TX [%v]; "+
+				"using the original nonce: [%v]",
+			transaction.Hash().TerminalString(),
+			err,
+		)
+		return
+	}
+
+	transactorOptions.Nonce = nonce
+}
+
+// Reasons passed to the GaveUpCallback when the mining waiter stops
+// force mining a transaction that has not been mined.
+const (
+	GaveUpOriginalAboveMaxGasFeeCap = "original transaction priced at or above the max gas fee cap"
+	GaveUpMaxGasFeeCapReached       = "max gas fee cap reached"
+	GaveUpThresholdUnsatisfiable    = "replacement threshold above the max gas fee cap"
+	GaveUpHeadroomExceeded          = "base fee with headroom above the affordable gas fee cap"
+	GaveUpResubmissionFailed        = "resubmission failed"
+	GaveUpForceMiningTimeout        = "force mining timeout"
+	GaveUpForceMiningCancelled      = "force mining cancelled"
+	GaveUpUnsupportedTransaction    = "unsupported transaction type"
+	GaveUpGasBudgetExhausted        = "resubmission gas budget exhausted"
+)
+
+// GaveUpCallback is called by the mining waiter when it stops force mining
+// a transaction that has not been mined. The reason is one of the GaveUp
+// constants and the transaction is the last one submitted by the mining
+// waiter. The callback may be called concurrently if transactions are force
+// mined concurrently.
+type GaveUpCallback func(reason string, lastTx *types.Transaction)
+
+// OnGaveUp registers the callback notified whenever the mining waiter gives
+// up on a transaction without having it mined, for example because the max
+// gas fee cap has been reached. It lets the client alert operators about
+// abandoned transactions. It should be set before the mining waiter is used.
+func (mw *MiningWaiter) OnGaveUp(callback GaveUpCallback) {
+	mw.gaveUpCallback = callback
+}
+
+// gaveUp notifies the registered callback, if any, that the mining waiter
+// gave up on the given transaction for the given reason. The reason and
+// the transaction are recorded in the stuck transaction record carried by
+// the context, if any, so that the transaction can be cancelled once the
+// force mining completes.
+func (mw *MiningWaiter) gaveUp(
+	ctx context.Context,
+	reason string,
+	lastTx *types.Transaction,
+) {
+	if record, ok := ctx.Value(stuckTransactionRecordKey{}).(*stuckTransactionRecord); ok {
+		record.reason = reason
+		record.lastTx = lastTx
+	}
+
+	if mw.gaveUpCallback == nil {
+		return
+	}
+
+	mw.gaveUpCallback(reason, lastTx)
+}
+
+// gaveUpOnContextDone gives up on the given transaction once the force
+// mining context is done. The operation is considered cancelled if the
+// context passed by the caller is done and timed out otherwise. It returns
+// the error of the caller's context or ErrForceMiningTimeout, respectively.
+func (mw *MiningWaiter) gaveUpOnContextDone(
+	ctx context.Context,
+	transaction *types.Transaction,
+) error {
+	txLogger := loggerFor(ctx)
+
+	if parentCtx, ok := ctx.Value(forceMiningParentKey{}).(context.Context); ok &&
+		parentCtx.Err() != nil {
+		txLogger.Warningf(
+			"force mining of transaction [%v] cancelled; "+
+				"stopping resubmissions",
+			transaction.Hash().TerminalString(),
+		)
+		mw.gaveUp(ctx, GaveUpForceMiningCancelled, transaction)
+		return parentCtx.Err()
+	}
+
+	txLogger.Warningf(
+		"transaction [%v] not mined within the force mining "+
+			"timeout; stopping resubmissions",
+		transaction.Hash().TerminalString(),
+	)
+	mw.gaveUp(ctx, GaveUpForceMiningTimeout, transaction)
+	return ErrForceMiningTimeout
+}
+
+// interceptResubmission passes the computed resubmission parameters through
+// the resubmission interceptor, if one is set. Parameters left nil by the
+// interceptor keep their computed values. Overrides that do not replace the
+// given previously submitted transaction or exceed the max gas fee cap are
+// rejected and the computed parameters are used instead. It returns the
+// parameters that should be used for the resubmission and false if the
+// resubmission should be skipped.
+func (mw *MiningWaiter) interceptResubmission(
+	ctx context.Context,
+	transaction *types.Transaction,
+	params *ResubmitParams,
+) (*ResubmitParams, bool) {
+	if mw.resubmissionInterceptor == nil {
+		return params, true
+	}
+
+	intercepted, ok := mw.resubmissionInterceptor(params)
+	if !ok {
+		return nil, false
+	}
+
+	if intercepted == nil {
+		return params, true
+	}
+
+	overridden := &ResubmitParams{
+		GasPrice:      params.GasPrice,
+		GasFeeCap:     params.GasFeeCap,
+		GasTipCap:     params.GasTipCap,
+		CorrelationID: params.CorrelationID,
+	}
+	if intercepted.GasPrice != nil {
+		overridden.GasPrice = intercepted.GasPrice
+	}
+	if intercepted.GasFeeCap != nil {
+		overridden.GasFeeCap = intercepted.GasFeeCap
+	}
+	if intercepted.GasTipCap != nil {
+		overridden.GasTipCap = intercepted.GasTipCap
+	}
+
+	if err := mw.validateResubmitParams(
+		ctx,
+		transaction,
+		overridden,
+	); err != nil {
+		loggerFor(ctx).Warningf(
+			"rejecting parameters returned by the resubmission interceptor "+
+				"for TX [%v]: [%v]; using the computed parameters",
+			transaction.Hash().TerminalString(),
+			err,
+		)
+		return params, true
+	}
+
+	return overridden, true
+}
+
+// validateResubmitParams checks whether the resubmission with the given
+// parameters can replace the given previously submitted transaction and
+// whether it is priced within the max gas fee cap. The parameters must set
+// either the gas price or both the gas fee cap and the gas tip cap.
+func (mw *MiningWaiter) validateResubmitParams(
+	ctx context.Context,
+	transaction *types.Transaction,
+	params *ResubmitParams,
+) error {
+	maxGasFeeCap := mw.maxGasFeeCapFor(ctx)
+
+	if params.GasPrice != nil {
+		if params.GasPrice.Cmp(maxGasFeeCap) > 0 {
+			return fmt.Errorf(
+				"gas price [%v] is above the max gas fee cap [%v]",
+				params.GasPrice,
+				maxGasFeeCap,
+			)
+		}
+
+		requiredGasPrice, _, _ := minReplacementGas(transaction, false)
+		if params.GasPrice.Cmp(requiredGasPrice) < 0 {
+			return fmt.Errorf(
+				"gas price [%v] is below the replacement threshold [%v]",
+				params.GasPrice,
+				requiredGasPrice,
+			)
+		}
+
+		return nil
+	}
+
+	if params.GasFeeCap == nil || params.GasTipCap == nil {
+		return fmt.Errorf(
+			"neither the gas price nor the gas fee cap and gas tip cap are set",
+		)
+	}
+
+	if params.GasTipCap.Cmp(params.GasFeeCap) > 0 {
+		return fmt.Errorf(
+			"gas tip cap [%v] is higher than the gas fee cap [%v]",
+			params.GasTipCap,
+			params.GasFeeCap,
+		)
+	}
+
+	if params.GasFeeCap.Cmp(maxGasFeeCap) > 0 {
+		return fmt.Errorf(
+			"gas fee cap [%v] is above the max gas fee cap [%v]",
+			params.GasFeeCap,
+			maxGasFeeCap,
+		)
+	}
+
+	_, requiredGasFeeCap, requiredGasTipCap := minReplacementGas(
+		transaction,
+		true,
+	)
+	if params.GasFeeCap.Cmp(requiredGasFeeCap) < 0 {
+		return fmt.Errorf(
+			"gas fee cap [%v] is below the replacement threshold [%v]",
+			params.GasFeeCap,
+			requiredGasFeeCap,
+		)
+	}
+	if params.GasTipCap.Cmp(requiredGasTipCap) < 0 {
+		return fmt.Errorf(
+			"gas tip cap [%v] is below the replacement threshold [%v]",
+			params.GasTipCap,
+			requiredGasTipCap,
+		)
+	}
+
+	return nil
+}
+
+// receiptResult returns the result of force mining a transaction mined with
+// the given receipt. The mined transaction is a success unless it has been
+// reverted and the mining waiter is configured to fail on reverts.
+func (mw *MiningWaiter) receiptResult(receipt *types.Receipt) error {
+	if mw.failOnRevert && receipt.Status == types.ReceiptStatusFailed {
+		return fmt.Errorf(
+			"%w: transaction [%v] at block [%v]",
+			ErrTransactionReverted,
+			receipt.TxHash.TerminalString(),
+			receipt.BlockNumber,
+		)
+	}
+
+	return nil
+}
+
+// minedTransactionReceipt queries receipts of the given transactions once and
+// returns the receipt of the first mined one. It returns nil if none of the
+// transactions has been mined or the receipts could not be fetched.
+func (mw *MiningWaiter) minedTransactionReceipt(
+	ctx context.Context,
+	transactions []*types.Transaction,
+) *types.Receipt {
+	txLogger := loggerFor(ctx)
+
+	for _, transaction := range transactions {
+		receipt, err := mw.client.TransactionReceipt(ctx, transaction.Hash())
+		if err != nil {
+			if !errors.Is(err, goEthereum.NotFound) {
+				txLogger.Debugf(
+					"could not get receipt of transaction [%v]: [%v]",
+					transaction.Hash().TerminalString(),
+					err,
+				)
+			}
+			continue
+		}
+
+		if receipt != nil {
+			return receipt
+		}
+	}
+
+	return nil
 }
 
 // waitMined blocks the current execution until the transaction with the given
-// hash is mined. Execution is blocked until the transaction is mined or until
-// the given timeout passes.
+// hash is mined. Execution is blocked until the transaction is mined, until
+// the given timeout passes or until the parent context is done.
+//
+// If the receipt request fails for other reason than the receipt not being
+// found, the next poll is delayed with an exponential backoff so that
+// a provider outage is not hammered with requests.
 func (mw *MiningWaiter) waitMined(
+	parentCtx context.Context,
 	timeout time.Duration,
 	transaction *types.Transaction,
 ) (*types.Receipt, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	txLogger := loggerFor(parentCtx)
+
+	ctx, cancel := context.WithTimeout(parentCtx, timeout)
 	defer cancel()
 
-	queryTicker := time.NewTicker(time.Second)
-	defer queryTicker.Stop()
+	backoff := mw.receiptRetryInitialBackoff
 
 	for {
-		receipt, _ := mw.client.TransactionReceipt(
-			context.TODO(),
+		receipt, err := mw.client.TransactionReceipt(
+			ctx,
 			transaction.Hash(),
 		)
 		if receipt != nil {
 			return receipt, nil
 		}
 
+		delay := receiptPollInterval
+		if err != nil && !errors.Is(err, goEthereum.NotFound) {
+			txLogger.Debugf(
+				"failed to get receipt of transaction [%v]; "+
+					"retrying in [%v]: [%v]",
+				transaction.Hash().TerminalString(),
+				backoff,
+				err,
+			)
+
+			delay = backoff
+			backoff *= 2
+			if backoff > mw.receiptRetryMaxBackoff {
+				backoff = mw.receiptRetryMaxBackoff
+			}
+		} else {
+			backoff = mw.receiptRetryInitialBackoff
+		}
+
+		timer := time.NewTimer(delay)
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			return nil, ctx.Err()
-		case <-queryTicker.C:
+		case <-timer.C:
 		}
 	}
 }
@@ -119,38 +760,411 @@ type ResubmitTransactionFn func(
 // in case the transaction has not been mined yet. It accepts the original
 // transaction reference and the function responsible for executing transaction
 // resubmission.
+//
+// If the force mining timeout is configured and the transaction has not been
+// mined within it, ForceMining gives up and returns ErrForceMiningTimeout.
+// Otherwise, it returns nil once the transaction is mined or no further
+// resubmission attempts are performed.
 func (mw *MiningWaiter) ForceMining(
 	originalTransaction *types.Transaction,
 	originalTransactorOptions *bind.TransactOpts,
 	resubmitFn ResubmitTransactionFn,
-) {
+) error {
+	return mw.forceMining(
+		context.Background(),
+		originalTransaction,
+		originalTransactorOptions,
+		resubmitFn,
+	)
+}
+
+// ForceMiningWithCorrelationID works just like ForceMining but attaches
+// the given correlation ID to all the log lines of the operation and passes
+// it to the resubmission interceptor. This allows to attribute logs when many
+// transactions are force mined at the same time.
+func (mw *MiningWaiter) ForceMiningWithCorrelationID(
+	correlationID string,
+	originalTransaction *types.Transaction,
+	originalTransactorOptions *bind.TransactOpts,
+	resubmitFn ResubmitTransactionFn,
+) error {
+	return mw.forceMining(
+		WithCorrelationID(context.Background(), correlationID),
+		originalTransaction,
+		originalTransactorOptions,
+		resubmitFn,
+	)
+}
+
+// ForceMiningWithGasProfile works just like ForceMining but applies the max
+// gas fee cap of the given gas profile instead of the default one. This allows
+// to apply different economic limits to different classes of transactions,
+// e.g. a high max gas fee cap to time-critical transactions and a low one to
+// routine maintenance transactions. An error wrapping ErrUnknownGasProfile is
+// returned if the gas profile is not configured.
+func (mw *MiningWaiter) ForceMiningWithGasProfile(
+	gasProfile string,
+	originalTransaction *types.Transaction,
+	originalTransactorOptions *bind.TransactOpts,
+	resubmitFn ResubmitTransactionFn,
+) error {
+	ctx, err := mw.withGasProfile(context.Background(), gasProfile)
+	if err != nil {
+		return err
+	}
+
+	return mw.forceMining(
+		ctx,
+		originalTransaction,
+		originalTransactorOptions,
+		resubmitFn,
+	)
+}
+
+type gasProfileMaxGasFeeCapKey struct{}
+
+// withGasProfile returns a copy of the parent context carrying the max gas
+// fee cap of the given gas profile. The parent context is returned as is if
+// the gas profile name is empty.
+func (mw *MiningWaiter) withGasProfile(
+	parent context.Context,
+	gasProfile string,
+) (context.Context, error) {
+	if gasProfile == "" {
+		return parent, nil
+	}
+
+	maxGasFeeCap, ok := mw.gasProfiles[strings.ToLower(gasProfile)]
+	if !ok {
+		return nil, fmt.Errorf("%w [%v]", ErrUnknownGasProfile, gasProfile)
+	}
+
+	return context.WithValue(
+		parent,
+		gasProfileMaxGasFeeCapKey{},
+		maxGasFeeCap,
+	), nil
+}
+
+// maxGasFeeCapFor returns the max gas fee cap of the gas profile carried by
+// the given context or the default max gas fee cap if the context carries
+// no gas profile.
+func (mw *MiningWaiter) maxGasFeeCapFor(ctx context.Context) *big.Int {
+	if maxGasFeeCap, ok := ctx.Value(gasProfileMaxGasFeeCapKey{}).(*big.Int); ok {
+		return maxGasFeeCap
+	}
+
+	return mw.maxGasFeeCap
+}
+
+// withEmergencyMaxGasFeeCap returns a context carrying the emergency max gas
+// fee cap if the original price of a transaction meets or exceeds the max
+// gas fee cap carried by the given context. The emergency max gas fee cap
+// is then used instead of the max gas fee cap for all resubmissions of
+// the transaction. It returns false if the emergency max gas fee cap is not
+// set or it leaves no room for resubmissions.
+func (mw *MiningWaiter) withEmergencyMaxGasFeeCap(
+	parent context.Context,
+	originalPrice *big.Int,
+) (context.Context, bool) {
+	if mw.emergencyMaxGasFeeCap == nil ||
+		mw.emergencyMaxGasFeeCap.Cmp(mw.maxGasFeeCapFor(parent)) <= 0 ||
+		originalPrice.Cmp(mw.emergencyMaxGasFeeCap) >= 0 {
+		return nil, false
+	}
+
+	return context.WithValue(
+		parent,
+		gasProfileMaxGasFeeCapKey{},
+		mw.emergencyMaxGasFeeCap,
+	), true
+}
+
+type forceMiningParentKey struct{}
+
+// forceMining performs the ForceMining operation until it completes or the
+// parent context is done. If the parent context is done before the
+// transaction is mined, the context error is returned.
+func (mw *MiningWaiter) forceMining(
+	parentCtx context.Context,
+	originalTransaction *types.Transaction,
+	originalTransactorOptions *bind.TransactOpts,
+	resubmitFn ResubmitTransactionFn,
+) error {
+	// Keep the caller's context reachable so that the cancellation of
+	// the operation can be told apart from the force mining timeout.
+	ctx := context.WithValue(parentCtx, forceMiningParentKey{}, parentCtx)
+
+	var record *stuckTransactionRecord
+	if mw.stuckTransactionCancellation != nil {
+		record = &stuckTransactionRecord{}
+		ctx = context.WithValue(ctx, stuckTransactionRecordKey{}, record)
+	}
+
+	if mw.forceMiningTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, mw.forceMiningTimeout)
+		defer cancel()
+	}
+
+	err := mw.forceMiningWithContext(
+		ctx,
+		originalTransaction,
+		originalTransactorOptions,
+		resubmitFn,
+	)
+	if parentCtx.Err() != nil {
+		return err
+	}
+
+	if record != nil {
+		mw.cancelStuckTransaction(parentCtx, record, originalTransactorOptions)
+	}
+
+	return err
+}
+
+func (mw *MiningWaiter) forceMiningWithContext(
+	ctx context.Context,
+	originalTransaction *types.Transaction,
+	originalTransactorOptions *bind.TransactOpts,
+	resubmitFn ResubmitTransactionFn,
+) error {
+	txLogger := loggerFor(ctx)
+
+	if mw.resubmissionDisabled {
+		return mw.waitWithoutResubmission(ctx, originalTransaction)
+	}
+
 	switch originalTransaction.Type() {
-	case types.LegacyTxType, types.AccessListTxType:
-		mw.forceMiningLegacyTx(
+	case types.LegacyTxType:
+		return mw.forceMiningLegacyTx(
+			ctx,
+			originalTransaction,
+			originalTransactorOptions,
+			resubmitFn,
+		)
+	case types.AccessListTxType:
+		// EIP-2930 transactions are priced with a gas price. However, some
+		// providers treat them as EIP-1559 compatible. We route them
+		// based on the pricing parameters the transaction was submitted with
+		// so that resubmissions bump the right parameters.
+		if isDynamicFeePriced(originalTransactorOptions) {
+			return mw.forceMiningDynamicFeeTx(
+				ctx,
+				originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+		}
+
+		return mw.forceMiningLegacyTx(
+			ctx,
 			originalTransaction,
 			originalTransactorOptions,
 			resubmitFn,
 		)
 	case types.DynamicFeeTxType:
-		mw.forceMiningDynamicFeeTx(
+		return mw.forceMiningDynamicFeeTx(
+			ctx,
 			originalTransaction,
 			originalTransactorOptions,
 			resubmitFn,
 		)
 	default:
-		logger.Errorf(
+		txLogger.Errorf(
 			"could not start mining waiter; unsupported transaction type [%v]",
-			originalTransaction.Type,
+			TransactionTypeName(originalTransaction.Type()),
 		)
+		mw.gaveUp(ctx, GaveUpUnsupportedTransaction, originalTransaction)
+		return nil
+	}
+}
+
+// waitWithoutResubmission blocks until the transaction is mined or the
+// context is done. The transaction is never resubmitted.
+func (mw *MiningWaiter) waitWithoutResubmission(
+	ctx context.Context,
+	transaction *types.Transaction,
+) error {
+	txLogger := loggerFor(ctx)
+
+	txLogger.Infof(
+		"starting mining waiter without resubmissions for transaction: [%v]",
+		transaction.Hash().TerminalString(),
+	)
+
+	for {
+		receipt, err := mw.waitMined(ctx, mw.checkInterval, transaction)
+
+		if receipt != nil {
+			txLogger.Infof(
+				"transaction [%v] mined with status [%v] at block [%v]",
+				transaction.Hash().TerminalString(),
+				receipt.Status,
+				receipt.BlockNumber,
+			)
+			return mw.receiptResult(receipt)
+		}
+
+		if ctx.Err() != nil {
+			return mw.gaveUpOnContextDone(ctx, transaction)
+		}
+
+		txLogger.Infof(
+			"transaction [%v] not yet mined: [%v]",
+			transaction.Hash().TerminalString(),
+			err,
+		)
+	}
+}
+
+// TxSpec describes a single transaction force mined by ForceMiningAll.
+type TxSpec struct {
+	// Transaction is the original transaction reference.
+	Transaction *types.Transaction
+	// TransactorOptions are the options the original transaction was
+	// submitted with.
+	TransactorOptions *bind.TransactOpts
+	// ResubmitFn is the function responsible for executing transaction
+	// resubmission.
+	ResubmitFn ResubmitTransactionFn
+	// CorrelationID, if set, is attached to the log lines of the transaction
+	// force mining. Otherwise, the correlation ID carried by the context
+	// passed to ForceMiningAll, if any, is used.
+	CorrelationID string
+	// GasProfile, if set, is the name of the gas profile whose max gas fee
+	// cap is applied to the transaction instead of the default one.
+	GasProfile string
+}
+
+// ForceMiningResult is the outcome of force mining a single transaction
+// by ForceMiningAll.
+type ForceMiningResult struct {
+	// Transaction is the original transaction reference.
+	Transaction *types.Transaction
+	// Err is the error ForceMining completed with, if any.
+	Err error
+}
+
+// ForceMiningAll force mines the given transactions concurrently and blocks
+// until all of them complete or the context is done. No more than the
+// configured force mining concurrency limit of transactions are force mined
+// at the same time. The returned results are in the same order as the given
+// transaction specifications. If the context is done, the transactions whose
+// force mining has not completed yet get the context error as the result.
+func (mw *MiningWaiter) ForceMiningAll(
+	ctx context.Context,
+	txs []TxSpec,
+) []ForceMiningResult {
+	results := make([]ForceMiningResult, len(txs))
+	semaphore := make(chan struct{}, mw.concurrencyLimit)
+
+	var wg sync.WaitGroup
+	wg.Add(len(txs))
+
+	for i, tx := range txs {
+		results[i].Transaction = tx.Transaction
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			wg.Done()
+			continue
+		}
+
+		go func(i int, tx TxSpec) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			txCtx := ctx
+			if tx.CorrelationID != "" {
+				txCtx = WithCorrelationID(ctx, tx.CorrelationID)
+			}
+
+			txCtx, err := mw.withGasProfile(txCtx, tx.GasProfile)
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+
+			results[i].Err = mw.forceMining(
+				txCtx,
+				tx.Transaction,
+				tx.TransactorOptions,
+				tx.ResubmitFn,
+			)
+		}(i, tx)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// isDynamicFeePriced determines whether the given transactor options price
+// the transaction with EIP-1559 gas fee cap and gas tip cap rather than with
+// a gas price.
+func isDynamicFeePriced(transactorOptions *bind.TransactOpts) bool {
+	return transactorOptions.GasPrice == nil &&
+		(transactorOptions.GasFeeCap != nil || transactorOptions.GasTipCap != nil)
+}
+
+// benignSubmitErrors lists messages of transaction pool errors returned on
+// the transaction submission which do not mean the previously submitted
+// transaction is lost. The errors are usually received over the RPC so they
+// can be recognized only by their messages.
+var benignSubmitErrors = []string{
+	"already known",
+	"replacement transaction underpriced",
+}
+
+// isBenignSubmitError returns true if the given transaction submission error
+// means the transaction pool rejected the submitted transaction but the
+// previously submitted transaction with the same nonce is still in the pool,
+// so it makes sense to keep waiting for it to be mined.
+func isBenignSubmitError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, benignError := range benignSubmitErrors {
+		if strings.Contains(message, benignError) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TransactionTypeName returns a human-readable name of the given transaction
+// type, as defined by go-ethereum, so that it can be used in logs and error
+// messages instead of the raw type number. Types not known to go-ethereum are
+// named UnknownTx with the raw type number, e.g. UnknownTx(100).
+func TransactionTypeName(transactionType uint8) string {
+	switch transactionType {
+	case types.LegacyTxType:
+		return "LegacyTx"
+	case types.AccessListTxType:
+		return "AccessListTx"
+	case types.DynamicFeeTxType:
+		return "DynamicFeeTx"
+	default:
+		return fmt.Sprintf("UnknownTx(%v)", transactionType)
 	}
 }
 
 func (mw *MiningWaiter) forceMiningLegacyTx(
+	ctx context.Context,
 	originalTransaction *types.Transaction,
 	originalTransactorOptions *bind.TransactOpts,
 	resubmitFn ResubmitTransactionFn,
-) {
-	logger.Infof(
+) error {
+	txLogger := loggerFor(ctx)
+
+	txLogger.Infof(
 		"starting mining waiter for legacy transaction: [%v]",
 		originalTransaction.Hash().TerminalString(),
 	)
@@ -158,213 +1172,389 @@ func (mw *MiningWaiter) forceMiningLegacyTx(
 	// For legacy transactions, the `maxGasFeeCap` is considered to be the same
 	// as `maxGasPrice`. This is because both parameters means the same:
 	// the maximum possible price per gas.
-	maxGasPrice := mw.maxGasFeeCap
+	maxGasPrice := mw.maxGasFeeCapFor(ctx)
 
 	// If the original transaction's gas price was higher or equal the max
-	// allowed we do nothing; we need to wait for it to be mined.
+	// allowed we do nothing unless the emergency cap leaves some room; we
+	// need to wait for it to be mined.
 	if originalTransaction.GasPrice().Cmp(maxGasPrice) >= 0 {
-		logger.Infof(
-			"original transaction gas price is higher than the max allowed; " +
-				"skipping resubmissions",
+		emergencyCtx, ok := mw.withEmergencyMaxGasFeeCap(
+			ctx,
+			originalTransaction.GasPrice(),
+		)
+		if !ok {
+			txLogger.Infof(
+				"original transaction gas price is higher than the max " +
+					"allowed; skipping resubmissions",
+			)
+			mw.gaveUp(ctx, GaveUpOriginalAboveMaxGasFeeCap, originalTransaction)
+			return nil
+		}
+
+		ctx = emergencyCtx
+		maxGasPrice = mw.maxGasFeeCapFor(ctx)
+
+		txLogger.Warningf(
+			"original transaction gas price is higher than the max "+
+				"allowed; using [%v] wei emergency max gas fee cap",
+			maxGasPrice,
 		)
-		return
 	}
 
 	transaction := originalTransaction
+	submittedTransactions := []*types.Transaction{originalTransaction}
+	// The first wait uses the initial check interval and the subsequent
+	// ones use the regular check interval.
+	waitInterval := mw.initialCheckInterval
 	for {
-		receipt, err := mw.waitMined(mw.checkInterval, transaction)
+		receipt, err := mw.waitMined(ctx, waitInterval, transaction)
+		waitInterval = mw.checkInterval
 		if err != nil {
-			logger.Infof(
+			txLogger.Infof(
 				"transaction [%v] not yet mined: [%v]",
 				transaction.Hash().TerminalString(),
 				err,
 			)
 		}
 
+		// The transaction has not been mined within the force mining
+		// timeout or the force mining has been cancelled; we give up.
+		if receipt == nil && ctx.Err() != nil {
+			return mw.gaveUpOnContextDone(ctx, transaction)
+		}
+
 		// Transaction mined, we are good.
 		if receipt != nil {
-			logger.Infof(
+			txLogger.Infof(
 				"transaction [%v] mined with status [%v] at block [%v]",
 				transaction.Hash().TerminalString(),
 				receipt.Status,
 				receipt.BlockNumber,
 			)
-			return
+			return mw.receiptResult(receipt)
 		}
 
 		// Transaction not yet mined, if the previous gas price was the maximum
 		// one, we no longer resubmit.
 		gasPrice := transaction.GasPrice()
 		if gasPrice.Cmp(maxGasPrice) == 0 {
-			logger.Infof(
+			txLogger.Infof(
 				"reached the maximum allowed gas price; " +
 					"stopping resubmissions",
 			)
-			return
+			mw.gaveUp(ctx, GaveUpMaxGasFeeCapReached, transaction)
+			return nil
 		}
 
-		// If we still have some margin, add 20% to the previous gas price.
-		twentyPercent := new(big.Int).Div(gasPrice, big.NewInt(5))
-		gasPrice = new(big.Int).Add(gasPrice, twentyPercent)
+		// If we still have some margin, bump the previous gas price.
+		gasPrice = mw.gasBumpStrategy.NextLegacyGasPrice(gasPrice, maxGasPrice)
 
-		// If we reached the maximum allowed gas price, submit one more time
-		// with the maximum.
+		// Never go beyond the maximum allowed gas price, regardless of
+		// the strategy.
 		if gasPrice.Cmp(maxGasPrice) > 0 {
 			gasPrice = maxGasPrice
 		}
 
+		// A minimum increase of the gas price by 10% comparing to
+		// the previous value is required for transaction replacement to be
+		// accepted by miners. If the gas price is below the threshold,
+		// e.g. because the maximum allowed gas price is below it, there is
+		// no sense to submit the transaction as it won't be accepted.
+		requiredGasPrice, _, _ := minReplacementGas(transaction, false)
+		if gasPrice.Cmp(requiredGasPrice) < 0 {
+			txLogger.Infof(
+				"gas price [%v] does not fulfill the required gas price "+
+					"threshold [%v]; stopping resubmissions",
+				gasPrice,
+				requiredGasPrice,
+			)
+			mw.gaveUp(ctx, GaveUpThresholdUnsatisfiable, transaction)
+			return nil
+		}
+
+		params, ok := mw.interceptResubmission(
+			ctx,
+			transaction,
+			&ResubmitParams{
+				GasPrice:      gasPrice,
+				CorrelationID: CorrelationID(ctx),
+			},
+		)
+		if !ok {
+			txLogger.Infof(
+				"resubmission of TX [%v] skipped by the interceptor",
+				transaction.Hash().TerminalString(),
+			)
+			continue
+		}
+		gasPrice = params.GasPrice
+
+		// One of the submitted transactions might have been mined in
+		// the meantime. Resubmitting with the same nonce would fail or,
+		// worse, replace another transaction if the nonce has been reused.
+		if receipt := mw.minedTransactionReceipt(
+			ctx,
+			submittedTransactions,
+		); receipt != nil {
+			txLogger.Infof(
+				"transaction [%v] mined with status [%v] at block [%v] "+
+					"just before the resubmission",
+				receipt.TxHash.TerminalString(),
+				receipt.Status,
+				receipt.BlockNumber,
+			)
+			return mw.receiptResult(receipt)
+		}
+
 		// Transaction not yet mined and we are still under the maximum allowed
 		// gas price; resubmitting transaction with 20% higher gas price
 		// evaluated earlier.
-		logger.Infof(
+		txLogger.Infof(
 			"resubmitting previous transaction [%v] "+
 				"with a higher gas price [%v]",
 			transaction.Hash().TerminalString(),
 			gasPrice,
 		)
 
+		reservedBudget, ok := mw.reserveGasBudget(transaction.Gas(), gasPrice)
+		if !ok {
+			txLogger.Warningf(
+				"resubmission gas budget exhausted; giving up on TX [%v]",
+				transaction.Hash().TerminalString(),
+			)
+			mw.gaveUp(ctx, GaveUpGasBudgetExhausted, transaction)
+			return nil
+		}
+
 		// Copy transactor options.
 		newTransactorOptions := new(bind.TransactOpts)
 		*newTransactorOptions = *originalTransactorOptions
 		newTransactorOptions.GasPrice = gasPrice
+		mw.provideNonce(ctx, newTransactorOptions, transaction)
 
-		transaction, err = resubmitFn(newTransactorOptions)
+		resubmittedTransaction, err := resubmitFn(newTransactorOptions)
 		if err != nil {
-			logger.Warningf(
+			mw.releaseGasBudget(reservedBudget)
+
+			if isBenignSubmitError(err) {
+				txLogger.Infof(
+					"resubmission of TX [%v] not accepted: [%v]; "+
+						"continuing to wait for the existing transaction",
+					transaction.Hash().TerminalString(),
+					err,
+				)
+				continue
+			}
+
+			txLogger.Warningf(
 				"could not resubmit TX with a higher gas price: [%v]",
 				err,
 			)
-			return
+			mw.gaveUp(ctx, GaveUpResubmissionFailed, transaction)
+			return nil
 		}
+
+		transaction = resubmittedTransaction
+		submittedTransactions = append(submittedTransactions, transaction)
 	}
 }
 
 func (mw *MiningWaiter) forceMiningDynamicFeeTx(
+	ctx context.Context,
 	originalTransaction *types.Transaction,
 	originalTransactorOptions *bind.TransactOpts,
 	resubmitFn ResubmitTransactionFn,
-) {
-	logger.Infof(
+) error {
+	txLogger := loggerFor(ctx)
+
+	txLogger.Infof(
 		"starting mining waiter for dynamic fee transaction: [%v]",
 		originalTransaction.Hash().TerminalString(),
 	)
 
+	maxGasFeeCap := mw.maxGasFeeCapFor(ctx)
+
 	// If the original transaction's gas fee cap was higher or equal the max
-	// allowed we do nothing; we need to wait for it to be mined.
-	if originalTransaction.GasFeeCap().Cmp(mw.maxGasFeeCap) >= 0 {
-		logger.Infof(
-			"original transaction gas fee cap is higher than the max allowed; " +
-				"skipping resubmissions",
+	// allowed we do nothing unless the emergency cap leaves some room; we
+	// need to wait for it to be mined.
+	if originalTransaction.GasFeeCap().Cmp(maxGasFeeCap) >= 0 {
+		emergencyCtx, ok := mw.withEmergencyMaxGasFeeCap(
+			ctx,
+			originalTransaction.GasFeeCap(),
+		)
+		if !ok {
+			txLogger.Infof(
+				"original transaction gas fee cap is higher than the max " +
+					"allowed; skipping resubmissions",
+			)
+			mw.gaveUp(ctx, GaveUpOriginalAboveMaxGasFeeCap, originalTransaction)
+			return nil
+		}
+
+		ctx = emergencyCtx
+		maxGasFeeCap = mw.maxGasFeeCapFor(ctx)
+
+		txLogger.Warningf(
+			"original transaction gas fee cap is higher than the max "+
+				"allowed; using [%v] wei emergency max gas fee cap",
+			maxGasFeeCap,
 		)
-		return
 	}
 
 	transaction := originalTransaction
+	submittedTransactions := []*types.Transaction{originalTransaction}
+	// The first wait uses the initial check interval and the subsequent
+	// ones use the regular check interval.
+	waitInterval := mw.initialCheckInterval
 	for {
-		receipt, err := mw.waitMined(mw.checkInterval, transaction)
+		receipt, err := mw.waitMined(ctx, waitInterval, transaction)
+		waitInterval = mw.checkInterval
 		if err != nil {
-			logger.Infof(
+			txLogger.Infof(
 				"transaction [%v] not yet mined: [%v]",
 				transaction.Hash().TerminalString(),
 				err,
 			)
 		}
 
+		// The transaction has not been mined within the force mining
+		// timeout or the force mining has been cancelled; we give up.
+		if receipt == nil && ctx.Err() != nil {
+			return mw.gaveUpOnContextDone(ctx, transaction)
+		}
+
 		// Transaction mined, we are good.
 		if receipt != nil {
-			logger.Infof(
+			txLogger.Infof(
 				"transaction [%v] mined with status [%v] at block [%v]",
 				transaction.Hash().TerminalString(),
 				receipt.Status,
 				receipt.BlockNumber,
 			)
-			return
+			return mw.receiptResult(receipt)
 		}
 
 		// Transaction not yet mined, if the previous gas fee cap was the
 		// maximum one, we no longer resubmit.
 		oldGasFeeCap := transaction.GasFeeCap()
-		if oldGasFeeCap.Cmp(mw.maxGasFeeCap) == 0 {
-			logger.Infof(
+		if oldGasFeeCap.Cmp(maxGasFeeCap) == 0 {
+			txLogger.Infof(
 				"reached the maximum allowed gas fee cap; " +
 					"stopping resubmissions",
 			)
-			return
+			mw.gaveUp(ctx, GaveUpMaxGasFeeCapReached, transaction)
+			return nil
 		}
 
-		// Increase the gas tip cap by 20%. A minimum increase by 10% comparing
-		// to the previous value is required for transaction replacement to be
-		// accepted by miners as mentioned in:
-		// https://github.com/ethereum/go-ethereum/pull/22898/files#r636583352.
-		// We increase it even more than the required level to greatly increase
-		// the transaction's chance for being picked up by miners.
-		oldGasTipCap := transaction.GasTipCap()
-		newGasTipCap := new(big.Int).Add(
-			oldGasTipCap,
-			new(big.Int).Div(oldGasTipCap, big.NewInt(5)), // + 20%
-		)
-
 		// Fetch latest base fee from the chain. It's needed to compute the
 		// new value of gas fee cap.
 		latestBaseFee, err := mw.latestBaseFee()
 		if err != nil {
-			logger.Errorf("could not get latest base fee: [%v]", err)
+			txLogger.Errorf("could not get latest base fee: [%v]", err)
 			continue
 		}
 
-		// Compute new value of gas fee cap using the latest base fee
-		// and new gas tip cap. The `gasFeeCap = 2 * baseFee + gasTipCap`
-		// equation originates from `go-ethereum` which estimates this
-		// parameter in that way.
-		// See: https://github.com/ethereum/go-ethereum/pull/23038.
-		// Having the `baseFee` taken twice means the `gasFeeCap` should
-		// be resilient for six consecutive increases of the `baseFee`.
-		// This is because `baseFee` can be increased by 12.5% at maximum
-		// within a single increase.
-		newGasFeeCap := new(big.Int).Add(
-			new(big.Int).Mul(latestBaseFee, big.NewInt(2)),
-			newGasTipCap,
+		newGasFeeCap, newGasTipCap := mw.gasBumpStrategy.NextDynamicFees(
+			oldGasFeeCap,
+			transaction.GasTipCap(),
+			latestBaseFee,
+			maxGasFeeCap,
 		)
 
-		// The new gas fee cap value needs to be at least 10% bigger
-		// than the old value. Otherwise, the transaction replacement
-		// won't be accepted by miners as mentioned in:
-		// https://github.com/ethereum/go-ethereum/pull/22898/files#r636583352.
-		// If that's the case (e.g. the `baseFee` dramatically decreased since
-		// the previous transaction) we need to set the new gas fee cap value
-		// to the minimum value acceptable by miners.
-		requiredGasFeeCapThreshold := new(big.Int).Add(
-			oldGasFeeCap,
-			new(big.Int).Div(oldGasFeeCap, big.NewInt(10)),
-		)
-		if newGasFeeCap.Cmp(requiredGasFeeCapThreshold) < 0 {
-			newGasFeeCap = requiredGasFeeCapThreshold
-		}
-
-		// If we reached the maximum allowed gas fee cap, submit one more time
-		// with the maximum.
-		if newGasFeeCap.Cmp(mw.maxGasFeeCap) > 0 {
-			newGasFeeCap = mw.maxGasFeeCap
-
-			// Check if the threshold condition is fulfilled once again.
-			// If the maximum allowed gas fee cap is below the threshold,
-			// there is no sense to submit the transaction as it won't
-			// be accepted by the miners.
-			if newGasFeeCap.Cmp(requiredGasFeeCapThreshold) < 0 {
-				logger.Infof(
-					"could not fulfill required gas fee cap threshold as " +
-						"the maximum gas fee cap value defined in config " +
-						"has been reached; " +
+		// Never go beyond the maximum allowed gas fee cap, regardless of
+		// the strategy.
+		if newGasFeeCap.Cmp(maxGasFeeCap) > 0 {
+			newGasFeeCap = maxGasFeeCap
+		}
+
+		// A minimum increase of both the gas fee cap and the gas tip cap by
+		// 10% comparing to the previous values is required for transaction
+		// replacement to be accepted by miners. If any of them is below
+		// the threshold, e.g. because the maximum allowed gas fee cap is below
+		// it, there is no sense to submit the transaction as it won't be
+		// accepted.
+		_, requiredGasFeeCap, requiredGasTipCap := minReplacementGas(
+			transaction,
+			true,
+		)
+		if newGasFeeCap.Cmp(requiredGasFeeCap) < 0 ||
+			newGasTipCap.Cmp(requiredGasTipCap) < 0 {
+			txLogger.Infof(
+				"gas fee cap [%v] and tip cap [%v] do not fulfill "+
+					"the required thresholds [%v] and [%v]; "+
+					"stopping resubmissions",
+				newGasFeeCap,
+				newGasTipCap,
+				requiredGasFeeCap,
+				requiredGasTipCap,
+			)
+			mw.gaveUp(ctx, GaveUpThresholdUnsatisfiable, transaction)
+			return nil
+		}
+
+		// If the latest base fee increased by the configured headroom already
+		// exceeds the gas fee cap we can afford, the resubmitted transaction
+		// would be underpriced right after the submission. There is no sense
+		// to burn more resources on resubmissions in such a fee market.
+		if mw.gasFeeCapHeadroom != nil {
+			requiredGasFeeCap := new(big.Int).Add(
+				latestBaseFee,
+				mw.gasFeeCapHeadroom,
+			)
+			if newGasFeeCap.Cmp(requiredGasFeeCap) < 0 {
+				txLogger.Warningf(
+					"latest base fee [%v] with headroom [%v] exceeds "+
+						"the affordable gas fee cap [%v]; "+
 						"stopping resubmissions",
+					latestBaseFee,
+					mw.gasFeeCapHeadroom,
+					newGasFeeCap,
 				)
-				return
+				mw.gaveUp(ctx, GaveUpHeadroomExceeded, transaction)
+				return nil
 			}
 		}
 
+		params, ok := mw.interceptResubmission(
+			ctx,
+			transaction,
+			&ResubmitParams{
+				GasFeeCap:     newGasFeeCap,
+				GasTipCap:     newGasTipCap,
+				CorrelationID: CorrelationID(ctx),
+			},
+		)
+		if !ok {
+			txLogger.Infof(
+				"resubmission of TX [%v] skipped by the interceptor",
+				transaction.Hash().TerminalString(),
+			)
+			continue
+		}
+		newGasFeeCap = params.GasFeeCap
+		newGasTipCap = params.GasTipCap
+
+		// One of the submitted transactions might have been mined in
+		// the meantime. Resubmitting with the same nonce would fail or,
+		// worse, replace another transaction if the nonce has been reused.
+		if receipt := mw.minedTransactionReceipt(
+			ctx,
+			submittedTransactions,
+		); receipt != nil {
+			txLogger.Infof(
+				"transaction [%v] mined with status [%v] at block [%v] "+
+					"just before the resubmission",
+				receipt.TxHash.TerminalString(),
+				receipt.Status,
+				receipt.BlockNumber,
+			)
+			return mw.receiptResult(receipt)
+		}
+
 		// Transaction not yet mined and we are still under the maximum allowed
 		// gas fee cap; resubmitting transaction with gas fee and tip parameters
 		// evaluated earlier.
-		logger.Infof(
+		txLogger.Infof(
 			"resubmitting previous transaction [%v] "+
 				"with a higher gas fee cap [%v] and tip cap [%v]",
 			transaction.Hash().TerminalString(),
@@ -372,21 +1562,51 @@ func (mw *MiningWaiter) forceMiningDynamicFeeTx(
 			newGasTipCap,
 		)
 
+		reservedBudget, ok := mw.reserveGasBudget(
+			transaction.Gas(),
+			newGasFeeCap,
+		)
+		if !ok {
+			txLogger.Warningf(
+				"resubmission gas budget exhausted; giving up on TX [%v]",
+				transaction.Hash().TerminalString(),
+			)
+			mw.gaveUp(ctx, GaveUpGasBudgetExhausted, transaction)
+			return nil
+		}
+
 		// Copy transactor options.
 		newTransactorOptions := new(bind.TransactOpts)
 		*newTransactorOptions = *originalTransactorOptions
 		newTransactorOptions.GasFeeCap = newGasFeeCap
 		newTransactorOptions.GasTipCap = newGasTipCap
+		mw.provideNonce(ctx, newTransactorOptions, transaction)
 
-		transaction, err = resubmitFn(newTransactorOptions)
+		resubmittedTransaction, err := resubmitFn(newTransactorOptions)
 		if err != nil {
-			logger.Warningf(
+			mw.releaseGasBudget(reservedBudget)
+
+			if isBenignSubmitError(err) {
+				txLogger.Infof(
+					"resubmission of TX [%v] not accepted: [%v]; "+
+						"continuing to wait for the existing transaction",
+					transaction.Hash().TerminalString(),
+					err,
+				)
+				continue
+			}
+
+			txLogger.Warningf(
 				"could not resubmit TX with a higher "+
 					"gas fee cap and tip cap: [%v]",
 				err,
 			)
-			return
+			mw.gaveUp(ctx, GaveUpResubmissionFailed, transaction)
+			return nil
 		}
+
+		transaction = resubmittedTransaction
+		submittedTransactions = append(submittedTransactions, transaction)
 	}
 }
 
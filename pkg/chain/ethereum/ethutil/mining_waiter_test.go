@@ -2,8 +2,12 @@ package ethutil
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -169,13 +173,14 @@ func TestForceMining_Legacy_MaxAllowedPriceReached(t *testing.T) {
 
 	var resubmissions []*bind.TransactOpts
 
-	expectedAttempts := 5
+	// The next 20% bump to 49.77 Gwei is capped at 45 Gwei which is below
+	// the replacement threshold of 45.62 Gwei.
+	expectedAttempts := 4
 	expectedResubmissionGasPrices := []*big.Int{
 		big.NewInt(24000000000), // + 20%
 		big.NewInt(28800000000), // + 20%
 		big.NewInt(34560000000), // + 20%
 		big.NewInt(41472000000), // + 20%
-		big.NewInt(45000000000), // max allowed
 	}
 
 	resubmitFn := func(
@@ -756,6 +761,2368 @@ func TestForceMining_DynamicFee_OriginalPriceHigherThanMaxAllowed(t *testing.T)
 	}
 }
 
+func TestForceMining_EmergencyMaxGasFeeCap(t *testing.T) {
+	// Original transactions are priced at 46 Gwei, the maximum allowed gas
+	// fee cap is 45 Gwei.
+	var tests = map[string]struct {
+		originalTransaction   *types.Transaction
+		emergencyMaxGasFeeCap *big.Int
+		expectedPrices        []*big.Int
+	}{
+		"legacy, emergency cap not set": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(46000000000), // 46 Gwei
+			),
+			expectedPrices: []*big.Int{},
+		},
+		"legacy, emergency cap lower than the max gas fee cap": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(46000000000), // 46 Gwei
+			),
+			emergencyMaxGasFeeCap: big.NewInt(40000000000), // 40 Gwei
+			expectedPrices:        []*big.Int{},
+		},
+		"legacy, emergency cap equal to the original price": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(46000000000), // 46 Gwei
+			),
+			emergencyMaxGasFeeCap: big.NewInt(46000000000), // 46 Gwei
+			expectedPrices:        []*big.Int{},
+		},
+		"legacy, emergency cap higher than the original price": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(46000000000), // 46 Gwei
+			),
+			emergencyMaxGasFeeCap: big.NewInt(60000000000), // 60 Gwei
+			expectedPrices: []*big.Int{
+				big.NewInt(55200000000), // 55.2 Gwei
+				// The next gas price of 60 Gwei does not fulfill the
+				// replacement threshold of 60.72 Gwei.
+			},
+		},
+		"dynamic fee, emergency cap not set": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(46000000000), // 46 Gwei
+				big.NewInt(4000000000),  // 4 Gwei
+			),
+			expectedPrices: []*big.Int{},
+		},
+		"dynamic fee, emergency cap higher than the original price": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(46000000000), // 46 Gwei
+				big.NewInt(4000000000),  // 4 Gwei
+			),
+			emergencyMaxGasFeeCap: big.NewInt(60000000000), // 60 Gwei
+			expectedPrices: []*big.Int{
+				big.NewInt(50600000000), // 50.6 Gwei
+				// The next gas fee cap of 60 Gwei does not fulfill the
+				// replacement threshold of 61.226 Gwei.
+				big.NewInt(55660000000), // 55.66 Gwei
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			chain := &mockAdaptedEthereumClientWithReceipt{
+				mockAdaptedEthereumClient: &mockAdaptedEthereumClient{
+					blocks:        []*big.Int{big.NewInt(1)},
+					blocksBaseFee: []*big.Int{big.NewInt(10000000000)}, // 10 Gwei
+				},
+			}
+
+			prices := []*big.Int{}
+
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				// Not setting mockBackend.receipt, mining takes a very
+				// long time.
+				if newTransactorOptions.GasPrice != nil {
+					prices = append(prices, newTransactorOptions.GasPrice)
+					return createLegacyTransaction(
+						newTransactorOptions.GasPrice,
+					), nil
+				}
+
+				prices = append(prices, newTransactorOptions.GasFeeCap)
+				return createDynamicFeeTransaction(
+					newTransactorOptions.GasFeeCap,
+					newTransactorOptions.GasTipCap,
+				), nil
+			}
+
+			emergencyConfig := config
+			emergencyConfig.EmergencyMaxGasFeeCap = *ethereum.WrapWei(
+				test.emergencyMaxGasFeeCap,
+			)
+
+			waiter := NewMiningWaiter(chain, emergencyConfig)
+			err := waiter.ForceMining(
+				test.originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(test.expectedPrices, prices) {
+				t.Errorf(
+					"unexpected resubmission prices\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedPrices,
+					prices,
+				)
+			}
+		})
+	}
+}
+
+func TestForceMining_DynamicFee_MinGasTipCap(t *testing.T) {
+	originalBaseFee := big.NewInt(10000000000)   // 10 Gwei
+	originalGasTipCap := big.NewInt(4000000000)  // 4 Gwei
+	originalGasFeeCap := big.NewInt(24000000000) // 24 Gwei (2 * baseFee + gasTipCap)
+
+	var tests = map[string]struct {
+		minGasTipCap      *big.Int
+		expectedGasFeeCap *big.Int
+		expectedGasTipCap *big.Int
+	}{
+		"floor above the bumped gas tip cap": {
+			minGasTipCap: big.NewInt(6000000000), // 6 Gwei
+			// Gas fee cap should be computed as: 2 * 10 Gwei + 6 Gwei = 26 Gwei.
+			// However, this value doesn't fulfill the required base fee bump
+			// threshold. In result, the new gas fee value should be bumped up
+			// by 10% to satisfy the condition: 24 Gwei * 1.1 = 26.4 Gwei
+			expectedGasFeeCap: big.NewInt(26400000000),
+			// Gas tip cap should be lifted to the floor.
+			expectedGasTipCap: big.NewInt(6000000000),
+		},
+		"floor below the bumped gas tip cap": {
+			minGasTipCap:      big.NewInt(1000000000), // 1 Gwei
+			expectedGasFeeCap: big.NewInt(26400000000),
+			// Gas tip cap should be bumped up by 20%: 4 Gwei * 1.2 = 4.8 Gwei
+			expectedGasTipCap: big.NewInt(4800000000),
+		},
+		"floor above the max gas fee cap": {
+			minGasTipCap:      big.NewInt(50000000000), // 50 Gwei
+			expectedGasFeeCap: big.NewInt(26400000000),
+			// Invalid floor is ignored so the gas tip cap should be bumped
+			// up by 20%: 4 Gwei * 1.2 = 4.8 Gwei
+			expectedGasTipCap: big.NewInt(4800000000),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			originalTransaction := createDynamicFeeTransaction(
+				originalGasFeeCap,
+				originalGasTipCap,
+			)
+
+			chain := &mockAdaptedEthereumClientWithReceipt{
+				mockAdaptedEthereumClient: &mockAdaptedEthereumClient{},
+			}
+
+			chain.blocks = append(chain.blocks, big.NewInt(1))
+			chain.blocksBaseFee = append(chain.blocksBaseFee, originalBaseFee)
+
+			var resubmissions []*bind.TransactOpts
+
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				resubmissions = append(resubmissions, newTransactorOptions)
+				// First resubmission succeeded.
+				chain.receipt = &types.Receipt{}
+				return createDynamicFeeTransaction(
+					newTransactorOptions.GasFeeCap,
+					newTransactorOptions.GasTipCap,
+				), nil
+			}
+
+			minGasTipCapConfig := config
+			minGasTipCapConfig.MinGasTipCap = *ethereum.WrapWei(test.minGasTipCap)
+
+			waiter := NewMiningWaiter(chain, minGasTipCapConfig)
+			waiter.ForceMining(
+				originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+
+			resubmissionCount := len(resubmissions)
+			if resubmissionCount != 1 {
+				t.Fatalf(
+					"expected one resubmission; has: [%v]",
+					resubmissionCount,
+				)
+			}
+
+			resubmission := resubmissions[0]
+
+			if resubmission.GasFeeCap.Cmp(test.expectedGasFeeCap) != 0 {
+				t.Fatalf(
+					"unexpected gas fee cap value\n"+
+						"expected: [%v]\n"+
+						"actual:   [%v]",
+					test.expectedGasFeeCap,
+					resubmission.GasFeeCap,
+				)
+			}
+
+			if resubmission.GasTipCap.Cmp(test.expectedGasTipCap) != 0 {
+				t.Fatalf(
+					"unexpected gas tip cap value\n"+
+						"expected: [%v]\n"+
+						"actual:   [%v]",
+					test.expectedGasTipCap,
+					resubmission.GasTipCap,
+				)
+			}
+		})
+	}
+}
+
+func TestForceMining_DynamicFee_BaseFeeExceedsHeadroom(t *testing.T) {
+	originalGasTipCap := big.NewInt(4000000000)  // 4 Gwei
+	originalGasFeeCap := big.NewInt(24000000000) // 24 Gwei
+
+	var tests = map[string]struct {
+		nextBaseFee           *big.Int
+		expectedResubmissions int
+	}{
+		"base fee with headroom below affordable gas fee cap": {
+			// Gas fee cap should be computed as: 2 * 15 Gwei + 4.8 Gwei =
+			// 34.8 Gwei. Base fee with headroom is 20 Gwei.
+			nextBaseFee:           big.NewInt(15000000000),
+			expectedResubmissions: 1,
+		},
+		"base fee with headroom above affordable gas fee cap": {
+			// Gas fee cap should be computed as: 2 * 42 Gwei + 4.8 Gwei =
+			// 88.8 Gwei but it is limited by the max gas fee cap to 45 Gwei.
+			// Base fee with headroom is 47 Gwei.
+			nextBaseFee:           big.NewInt(42000000000),
+			expectedResubmissions: 0,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			originalTransaction := createDynamicFeeTransaction(
+				originalGasFeeCap,
+				originalGasTipCap,
+			)
+
+			chain := &mockAdaptedEthereumClientWithReceipt{
+				mockAdaptedEthereumClient: &mockAdaptedEthereumClient{},
+			}
+
+			chain.blocks = append(chain.blocks, big.NewInt(1))
+			chain.blocksBaseFee = append(chain.blocksBaseFee, test.nextBaseFee)
+
+			var resubmissions []*bind.TransactOpts
+
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				resubmissions = append(resubmissions, newTransactorOptions)
+				// First resubmission succeeded.
+				chain.receipt = &types.Receipt{}
+				return createDynamicFeeTransaction(
+					newTransactorOptions.GasFeeCap,
+					newTransactorOptions.GasTipCap,
+				), nil
+			}
+
+			headroomConfig := config
+			headroomConfig.GasFeeCapHeadroom = *ethereum.WrapWei(
+				big.NewInt(5000000000), // 5 Gwei
+			)
+
+			waiter := NewMiningWaiter(chain, headroomConfig)
+			waiter.ForceMining(
+				originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+
+			resubmissionCount := len(resubmissions)
+			if resubmissionCount != test.expectedResubmissions {
+				t.Fatalf(
+					"expected [%v] resubmissions; has: [%v]",
+					test.expectedResubmissions,
+					resubmissionCount,
+				)
+			}
+		})
+	}
+}
+
+func TestForceMining_Timeout(t *testing.T) {
+	originalTransaction := createLegacyTransaction(big.NewInt(20000000000)) // 20 Gwei
+
+	chain := &mockAdaptedEthereumClientWithReceipt{}
+
+	var resubmissions []*bind.TransactOpts
+
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		resubmissions = append(resubmissions, newTransactorOptions)
+		return createLegacyTransaction(newTransactorOptions.GasPrice), nil
+	}
+
+	timeoutConfig := config
+	timeoutConfig.MiningCheckInterval = time.Minute
+	timeoutConfig.ForceMiningTimeout = 50 * time.Millisecond
+
+	waiter := NewMiningWaiter(chain, timeoutConfig)
+	err := waiter.ForceMining(
+		originalTransaction,
+		originalTransactorOptions,
+		resubmitFn,
+	)
+	if !errors.Is(err, ErrForceMiningTimeout) {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			ErrForceMiningTimeout,
+			err,
+		)
+	}
+
+	resubmissionCount := len(resubmissions)
+	if resubmissionCount != 0 {
+		t.Fatalf("expected no resubmissions; has: [%v]", resubmissionCount)
+	}
+}
+
+func TestForceMining_InitialCheckInterval(t *testing.T) {
+	var tests = map[string]struct {
+		originalTransaction   *types.Transaction
+		checkInterval         time.Duration
+		initialCheckInterval  time.Duration
+		expectedResubmissions int
+	}{
+		"legacy, short initial check interval": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			checkInterval:         time.Minute,
+			initialCheckInterval:  time.Nanosecond,
+			expectedResubmissions: 1,
+		},
+		"legacy, long initial check interval": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			checkInterval:         time.Nanosecond,
+			initialCheckInterval:  time.Minute,
+			expectedResubmissions: 0,
+		},
+		"dynamic fee, short initial check interval": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(24000000000), // 24 Gwei
+				big.NewInt(4000000000),  // 4 Gwei
+			),
+			checkInterval:         time.Minute,
+			initialCheckInterval:  time.Nanosecond,
+			expectedResubmissions: 1,
+		},
+		"dynamic fee, long initial check interval": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(24000000000), // 24 Gwei
+				big.NewInt(4000000000),  // 4 Gwei
+			),
+			checkInterval:         time.Nanosecond,
+			initialCheckInterval:  time.Minute,
+			expectedResubmissions: 0,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			chain := &mockAdaptedEthereumClientWithReceipt{
+				mockAdaptedEthereumClient: &mockAdaptedEthereumClient{
+					blocks:        []*big.Int{big.NewInt(1)},
+					blocksBaseFee: []*big.Int{big.NewInt(10000000000)}, // 10 Gwei
+				},
+			}
+
+			var resubmissions []*bind.TransactOpts
+
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				resubmissions = append(resubmissions, newTransactorOptions)
+
+				// Not setting mockBackend.receipt, mining takes a very
+				// long time.
+				if newTransactorOptions.GasPrice != nil {
+					return createLegacyTransaction(
+						newTransactorOptions.GasPrice,
+					), nil
+				}
+				return createDynamicFeeTransaction(
+					newTransactorOptions.GasFeeCap,
+					newTransactorOptions.GasTipCap,
+				), nil
+			}
+
+			intervalConfig := config
+			intervalConfig.MiningCheckInterval = test.checkInterval
+			intervalConfig.InitialMiningCheckInterval = test.initialCheckInterval
+			intervalConfig.ForceMiningTimeout = 200 * time.Millisecond
+
+			waiter := NewMiningWaiter(chain, intervalConfig)
+			err := waiter.ForceMining(
+				test.originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+			if !errors.Is(err, ErrForceMiningTimeout) {
+				t.Fatalf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					ErrForceMiningTimeout,
+					err,
+				)
+			}
+
+			if len(resubmissions) != test.expectedResubmissions {
+				t.Errorf(
+					"unexpected number of resubmissions\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedResubmissions,
+					len(resubmissions),
+				)
+			}
+		})
+	}
+}
+
+func TestForceMining_InitialCheckIntervalDefault(t *testing.T) {
+	waiter := NewMiningWaiter(&mockAdaptedEthereumClientWithReceipt{}, config)
+
+	if waiter.initialCheckInterval != waiter.checkInterval {
+		t.Errorf(
+			"unexpected initial check interval\n"+
+				"expected: [%v]\nactual:   [%v]",
+			waiter.checkInterval,
+			waiter.initialCheckInterval,
+		)
+	}
+}
+
+func TestMiningWaiter_Accessors(t *testing.T) {
+	var tests = map[string]struct {
+		config                ethereum.Config
+		expectedMaxGasFeeCap  *big.Int
+		expectedCheckInterval time.Duration
+	}{
+		"configured values": {
+			config:                config,
+			expectedMaxGasFeeCap:  big.NewInt(45000000000), // 45 Gwei
+			expectedCheckInterval: config.MiningCheckInterval,
+		},
+		"default values": {
+			config:                ethereum.Config{},
+			expectedMaxGasFeeCap:  big.NewInt(500000000000), // 500 Gwei
+			expectedCheckInterval: DefaultMiningCheckInterval,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			waiter := NewMiningWaiter(
+				&mockAdaptedEthereumClientWithReceipt{},
+				test.config,
+			)
+
+			maxGasFeeCap := waiter.MaxGasFeeCap()
+			if maxGasFeeCap.Cmp(test.expectedMaxGasFeeCap) != 0 {
+				t.Errorf(
+					"unexpected max gas fee cap\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedMaxGasFeeCap,
+					maxGasFeeCap,
+				)
+			}
+
+			// Modifying the returned value must not affect the waiter.
+			maxGasFeeCap.SetInt64(1)
+			if waiter.MaxGasFeeCap().Cmp(test.expectedMaxGasFeeCap) != 0 {
+				t.Errorf(
+					"max gas fee cap modified through the returned value\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedMaxGasFeeCap,
+					waiter.MaxGasFeeCap(),
+				)
+			}
+
+			if waiter.CheckInterval() != test.expectedCheckInterval {
+				t.Errorf(
+					"unexpected check interval\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedCheckInterval,
+					waiter.CheckInterval(),
+				)
+			}
+		})
+	}
+}
+
+func TestWaitMined_ReceiptRetryBackoff(t *testing.T) {
+	transaction := createLegacyTransaction(big.NewInt(20000000000)) // 20 Gwei
+
+	chain := &mockFailingReceiptEthereumClient{
+		failures: 3,
+		receipt:  &types.Receipt{},
+	}
+
+	waiter := NewMiningWaiter(chain, config)
+	waiter.receiptRetryInitialBackoff = 10 * time.Millisecond
+	waiter.receiptRetryMaxBackoff = 20 * time.Millisecond
+
+	startTime := time.Now()
+
+	receipt, err := waiter.waitMined(
+		context.Background(),
+		time.Minute,
+		transaction,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if receipt != chain.receipt {
+		t.Errorf("unexpected receipt")
+	}
+
+	if chain.calls != 4 {
+		t.Errorf(
+			"unexpected number of receipt requests\n"+
+				"expected: [%v]\nactual:   [%v]",
+			4,
+			chain.calls,
+		)
+	}
+
+	// Backoffs of 10ms, 20ms and 20ms are expected; the regular receipt
+	// poll interval is never applied.
+	elapsed := time.Since(startTime)
+	if elapsed < 50*time.Millisecond || elapsed >= receiptPollInterval {
+		t.Errorf("unexpected wait time: [%v]", elapsed)
+	}
+}
+
+func TestForceMining_ResubmissionDisabled(t *testing.T) {
+	var tests = map[string]struct {
+		originalTransaction *types.Transaction
+	}{
+		"legacy transaction": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+		},
+		"dynamic fee transaction": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+				big.NewInt(2000000000),  // 2 Gwei
+			),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			chain := &mockAdaptedEthereumClientWithReceipt{
+				receipt: &types.Receipt{},
+			}
+
+			var resubmissions []*bind.TransactOpts
+
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				resubmissions = append(resubmissions, newTransactorOptions)
+				return test.originalTransaction, nil
+			}
+
+			disabledConfig := config
+			disabledConfig.DisableResubmission = true
+
+			waiter := NewMiningWaiter(chain, disabledConfig)
+			err := waiter.ForceMining(
+				test.originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resubmissionCount := len(resubmissions)
+			if resubmissionCount != 0 {
+				t.Fatalf(
+					"expected no resubmissions; has: [%v]",
+					resubmissionCount,
+				)
+			}
+		})
+	}
+}
+
+func TestForceMining_ResubmissionDisabled_Timeout(t *testing.T) {
+	originalTransaction := createLegacyTransaction(big.NewInt(20000000000)) // 20 Gwei
+
+	chain := &mockAdaptedEthereumClientWithReceipt{}
+
+	var resubmissions []*bind.TransactOpts
+
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		resubmissions = append(resubmissions, newTransactorOptions)
+		return createLegacyTransaction(newTransactorOptions.GasPrice), nil
+	}
+
+	disabledConfig := config
+	disabledConfig.MiningCheckInterval = 10 * time.Millisecond
+	disabledConfig.ForceMiningTimeout = 50 * time.Millisecond
+	disabledConfig.DisableResubmission = true
+
+	waiter := NewMiningWaiter(chain, disabledConfig)
+	err := waiter.ForceMining(
+		originalTransaction,
+		originalTransactorOptions,
+		resubmitFn,
+	)
+	if !errors.Is(err, ErrForceMiningTimeout) {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			ErrForceMiningTimeout,
+			err,
+		)
+	}
+
+	resubmissionCount := len(resubmissions)
+	if resubmissionCount != 0 {
+		t.Fatalf("expected no resubmissions; has: [%v]", resubmissionCount)
+	}
+}
+
+func TestForceMining_FailOnRevert(t *testing.T) {
+	var tests = map[string]struct {
+		failOnRevert  bool
+		receiptStatus uint64
+		expectedError error
+	}{
+		"reverted transaction with failing on revert disabled": {
+			failOnRevert:  false,
+			receiptStatus: types.ReceiptStatusFailed,
+		},
+		"reverted transaction with failing on revert enabled": {
+			failOnRevert:  true,
+			receiptStatus: types.ReceiptStatusFailed,
+			expectedError: ErrTransactionReverted,
+		},
+		"successful transaction with failing on revert enabled": {
+			failOnRevert:  true,
+			receiptStatus: types.ReceiptStatusSuccessful,
+		},
+	}
+
+	transactions := map[string]*types.Transaction{
+		"legacy transaction": createLegacyTransaction(
+			big.NewInt(20000000000), // 20 Gwei
+		),
+		"dynamic fee transaction": createDynamicFeeTransaction(
+			big.NewInt(20000000000), // 20 Gwei
+			big.NewInt(2000000000),  // 2 Gwei
+		),
+	}
+
+	for testName, test := range tests {
+		for transactionName, transaction := range transactions {
+			t.Run(testName+" for "+transactionName, func(t *testing.T) {
+				chain := &mockAdaptedEthereumClientWithReceipt{
+					mockAdaptedEthereumClient: &mockAdaptedEthereumClient{
+						blocksBaseFee: []*big.Int{
+							big.NewInt(10000000000), // 10 Gwei
+						},
+					},
+					receipt: &types.Receipt{
+						Status:      test.receiptStatus,
+						BlockNumber: big.NewInt(1),
+					},
+				}
+
+				resubmitFn := func(
+					newTransactorOptions *bind.TransactOpts,
+				) (*types.Transaction, error) {
+					return transaction, nil
+				}
+
+				revertConfig := config
+				revertConfig.FailOnRevert = test.failOnRevert
+
+				waiter := NewMiningWaiter(chain, revertConfig)
+				err := waiter.ForceMining(
+					transaction,
+					originalTransactorOptions,
+					resubmitFn,
+				)
+
+				if !errors.Is(err, test.expectedError) {
+					t.Errorf(
+						"unexpected error\nexpected: [%v]\nactual:   [%v]",
+						test.expectedError,
+						err,
+					)
+				}
+			})
+		}
+	}
+}
+
+func TestForceMiningAll(t *testing.T) {
+	chain := &mockAdaptedEthereumClientWithReceipt{
+		receipt: &types.Receipt{},
+	}
+
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		t.Error("unexpected resubmission")
+		return nil, nil
+	}
+
+	var txs []TxSpec
+	for i := 0; i < 5; i++ {
+		txs = append(txs, TxSpec{
+			Transaction: createLegacyTransaction(
+				big.NewInt(int64(20000000000 + i)),
+			),
+			TransactorOptions: originalTransactorOptions,
+			ResubmitFn:        resubmitFn,
+		})
+	}
+
+	allConfig := config
+	allConfig.ForceMiningConcurrencyLimit = 2
+
+	waiter := NewMiningWaiter(chain, allConfig)
+	results := waiter.ForceMiningAll(context.Background(), txs)
+
+	if len(results) != len(txs) {
+		t.Fatalf(
+			"unexpected number of results\nexpected: [%v]\nactual:   [%v]",
+			len(txs),
+			len(results),
+		)
+	}
+
+	for i, result := range results {
+		if result.Transaction != txs[i].Transaction {
+			t.Errorf("unexpected transaction of result [%v]", i)
+		}
+		if result.Err != nil {
+			t.Errorf("unexpected error of result [%v]: [%v]", i, result.Err)
+		}
+	}
+}
+
+func TestForceMiningAll_ContextDone(t *testing.T) {
+	chain := &mockAdaptedEthereumClientWithReceipt{}
+
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		t.Error("unexpected resubmission")
+		return nil, nil
+	}
+
+	var txs []TxSpec
+	for i := 0; i < 3; i++ {
+		txs = append(txs, TxSpec{
+			Transaction: createLegacyTransaction(
+				big.NewInt(int64(20000000000 + i)),
+			),
+			TransactorOptions: originalTransactorOptions,
+			ResubmitFn:        resubmitFn,
+		})
+	}
+
+	allConfig := config
+	allConfig.MiningCheckInterval = time.Minute
+	allConfig.ForceMiningConcurrencyLimit = 1
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		50*time.Millisecond,
+	)
+	defer cancel()
+
+	waiter := NewMiningWaiter(chain, allConfig)
+	results := waiter.ForceMiningAll(ctx, txs)
+
+	for i, result := range results {
+		if !errors.Is(result.Err, context.DeadlineExceeded) {
+			t.Errorf(
+				"unexpected error of result [%v]\n"+
+					"expected: [%v]\nactual:   [%v]",
+				i,
+				context.DeadlineExceeded,
+				result.Err,
+			)
+		}
+	}
+}
+
+func TestForceMining_AccessList_GasPrice(t *testing.T) {
+	originalTransaction := createAccessListTransaction(big.NewInt(20000000000)) // 20 Gwei
+
+	chain := &mockAdaptedEthereumClientWithReceipt{}
+
+	var resubmissions []*bind.TransactOpts
+
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		resubmissions = append(resubmissions, newTransactorOptions)
+		// first resubmission succeeded
+		chain.receipt = &types.Receipt{}
+		return createAccessListTransaction(newTransactorOptions.GasPrice), nil
+	}
+
+	transactorOptions := &bind.TransactOpts{
+		Nonce:    originalTransactorOptions.Nonce,
+		GasPrice: big.NewInt(20000000000), // 20 Gwei
+	}
+
+	waiter := NewMiningWaiter(chain, config)
+	waiter.ForceMining(
+		originalTransaction,
+		transactorOptions,
+		resubmitFn,
+	)
+
+	resubmissionCount := len(resubmissions)
+	if resubmissionCount != 1 {
+		t.Fatalf("expected one resubmission; has: [%v]", resubmissionCount)
+	}
+
+	resubmission := resubmissions[0]
+
+	assertNonceUnchanged(t, resubmission)
+
+	if resubmission.GasFeeCap != nil || resubmission.GasTipCap != nil {
+		t.Fatalf("gas fee and tip cap should be nil")
+	}
+
+	expectedGasPrice := big.NewInt(24000000000) // + 20%
+	if resubmission.GasPrice.Cmp(expectedGasPrice) != 0 {
+		t.Fatalf(
+			"unexpected gas price value\n"+
+				"expected: [%v]\n"+
+				"actual:   [%v]",
+			expectedGasPrice,
+			resubmission.GasPrice,
+		)
+	}
+}
+
+func TestForceMining_AccessList_DynamicFee(t *testing.T) {
+	originalBaseFee := big.NewInt(1000000000)  // 1 Gwei
+	originalGasPrice := big.NewInt(5000000000) // 5 Gwei
+
+	originalTransaction := createAccessListTransaction(originalGasPrice)
+
+	chain := &mockAdaptedEthereumClientWithReceipt{
+		mockAdaptedEthereumClient: &mockAdaptedEthereumClient{},
+	}
+
+	// Base fee remains unchanged.
+	chain.blocks = append(chain.blocks, big.NewInt(1))
+	chain.blocksBaseFee = append(chain.blocksBaseFee, originalBaseFee)
+
+	var resubmissions []*bind.TransactOpts
+
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		resubmissions = append(resubmissions, newTransactorOptions)
+		// First resubmission succeeded.
+		chain.receipt = &types.Receipt{}
+		return createDynamicFeeTransaction(
+			newTransactorOptions.GasFeeCap,
+			newTransactorOptions.GasTipCap,
+		), nil
+	}
+
+	transactorOptions := &bind.TransactOpts{
+		Nonce:     originalTransactorOptions.Nonce,
+		GasFeeCap: originalGasPrice,
+		GasTipCap: originalGasPrice,
+	}
+
+	waiter := NewMiningWaiter(chain, config)
+	waiter.ForceMining(
+		originalTransaction,
+		transactorOptions,
+		resubmitFn,
+	)
+
+	resubmissionCount := len(resubmissions)
+	if resubmissionCount != 1 {
+		t.Fatalf("expected one resubmission; has: [%v]", resubmissionCount)
+	}
+
+	resubmission := resubmissions[0]
+
+	assertNonceUnchanged(t, resubmission)
+
+	if resubmission.GasPrice != nil {
+		t.Fatalf("gas price should be nil")
+	}
+
+	// Access list transaction reports its gas price as both the gas fee cap
+	// and gas tip cap. Gas tip cap should be bumped up by 20%:
+	// 5 Gwei * 1.2 = 6 Gwei.
+	expectedGasTipCap := big.NewInt(6000000000)
+	if resubmission.GasTipCap.Cmp(expectedGasTipCap) != 0 {
+		t.Fatalf(
+			"unexpected gas tip cap value\n"+
+				"expected: [%v]\n"+
+				"actual:   [%v]",
+			expectedGasTipCap,
+			resubmission.GasTipCap,
+		)
+	}
+
+	// Gas fee cap should be computed as: 2 * 1 Gwei + 6 Gwei = 8 Gwei.
+	expectedGasFeeCap := big.NewInt(8000000000)
+	if resubmission.GasFeeCap.Cmp(expectedGasFeeCap) != 0 {
+		t.Fatalf(
+			"unexpected gas fee cap value\n"+
+				"expected: [%v]\n"+
+				"actual:   [%v]",
+			expectedGasFeeCap,
+			resubmission.GasFeeCap,
+		)
+	}
+}
+
+func TestForceMining_BenignResubmissionError(t *testing.T) {
+	var tests = map[string]struct {
+		originalTransaction *types.Transaction
+		resubmitErr         error
+	}{
+		"legacy, already known": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			resubmitErr: fmt.Errorf("already known"),
+		},
+		"dynamic fee, replacement transaction underpriced": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+				big.NewInt(2000000000),  // 2 Gwei
+			),
+			resubmitErr: fmt.Errorf("replacement transaction underpriced"),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			chain := &mockAdaptedEthereumClientWithReceipt{
+				mockAdaptedEthereumClient: &mockAdaptedEthereumClient{
+					blocks:        []*big.Int{big.NewInt(1)},
+					blocksBaseFee: []*big.Int{big.NewInt(5000000000)}, // 5 Gwei
+				},
+			}
+
+			resubmissions := 0
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				resubmissions++
+
+				// The first resubmission is rejected by the pool; the
+				// original transaction gets mined in the meantime.
+				if resubmissions == 1 {
+					return nil, test.resubmitErr
+				}
+
+				chain.receipt = &types.Receipt{}
+				return createLegacyTransaction(newTransactorOptions.GasPrice), nil
+			}
+
+			waiter := NewMiningWaiter(chain, config)
+			err := waiter.ForceMining(
+				test.originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resubmissions != 2 {
+				t.Errorf(
+					"unexpected number of resubmissions\n"+
+						"expected: [%v]\nactual:   [%v]",
+					2,
+					resubmissions,
+				)
+			}
+		})
+	}
+}
+
+func TestForceMining_ResubmissionInterceptor(t *testing.T) {
+	var tests = map[string]struct {
+		originalTransaction *types.Transaction
+		interceptor         ResubmissionInterceptor
+		expectedParams      *ResubmitParams
+	}{
+		"legacy, computed parameters used": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			interceptor: func(params *ResubmitParams) (*ResubmitParams, bool) {
+				return nil, true
+			},
+			expectedParams: &ResubmitParams{
+				GasPrice: big.NewInt(24000000000), // 24 Gwei
+			},
+		},
+		"legacy, gas price overridden": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			interceptor: func(params *ResubmitParams) (*ResubmitParams, bool) {
+				return &ResubmitParams{GasPrice: big.NewInt(30000000000)}, true
+			},
+			expectedParams: &ResubmitParams{
+				GasPrice: big.NewInt(30000000000), // 30 Gwei
+			},
+		},
+		"legacy, gas price override above the max rejected": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			interceptor: func(params *ResubmitParams) (*ResubmitParams, bool) {
+				return &ResubmitParams{GasPrice: big.NewInt(90000000000)}, true
+			},
+			expectedParams: &ResubmitParams{
+				GasPrice: big.NewInt(24000000000), // 24 Gwei
+			},
+		},
+		"legacy, gas price override below the replacement threshold rejected": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			interceptor: func(params *ResubmitParams) (*ResubmitParams, bool) {
+				return &ResubmitParams{GasPrice: big.NewInt(21000000000)}, true
+			},
+			expectedParams: &ResubmitParams{
+				GasPrice: big.NewInt(24000000000), // 24 Gwei
+			},
+		},
+		"legacy, nil gas price keeps the computed one": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			interceptor: func(params *ResubmitParams) (*ResubmitParams, bool) {
+				return &ResubmitParams{}, true
+			},
+			expectedParams: &ResubmitParams{
+				GasPrice: big.NewInt(24000000000), // 24 Gwei
+			},
+		},
+		"dynamic fee, gas tip cap overridden": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+				big.NewInt(2000000000),  // 2 Gwei
+			),
+			interceptor: func(params *ResubmitParams) (*ResubmitParams, bool) {
+				return &ResubmitParams{
+					GasFeeCap: params.GasFeeCap,
+					GasTipCap: big.NewInt(3000000000), // 3 Gwei
+				}, true
+			},
+			expectedParams: &ResubmitParams{
+				GasFeeCap: big.NewInt(22000000000), // 20 Gwei * 1.1
+				GasTipCap: big.NewInt(3000000000),  // 3 Gwei
+			},
+		},
+		"dynamic fee, gas tip cap override below the replacement threshold rejected": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+				big.NewInt(2000000000),  // 2 Gwei
+			),
+			interceptor: func(params *ResubmitParams) (*ResubmitParams, bool) {
+				return &ResubmitParams{
+					GasFeeCap: params.GasFeeCap,
+					GasTipCap: big.NewInt(2100000000), // 2.1 Gwei
+				}, true
+			},
+			expectedParams: &ResubmitParams{
+				GasFeeCap: big.NewInt(22000000000), // 20 Gwei * 1.1
+				GasTipCap: big.NewInt(2400000000),  // 2 Gwei * 1.2
+			},
+		},
+		"dynamic fee, nil gas fee cap keeps the computed one": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+				big.NewInt(2000000000),  // 2 Gwei
+			),
+			interceptor: func(params *ResubmitParams) (*ResubmitParams, bool) {
+				return &ResubmitParams{
+					GasTipCap: big.NewInt(3000000000), // 3 Gwei
+				}, true
+			},
+			expectedParams: &ResubmitParams{
+				GasFeeCap: big.NewInt(22000000000), // 20 Gwei * 1.1
+				GasTipCap: big.NewInt(3000000000),  // 3 Gwei
+			},
+		},
+		"dynamic fee, gas fee cap override below the replacement threshold rejected": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+				big.NewInt(2000000000),  // 2 Gwei
+			),
+			interceptor: func(params *ResubmitParams) (*ResubmitParams, bool) {
+				return &ResubmitParams{
+					GasFeeCap: big.NewInt(18000000000), // 18 Gwei
+					GasTipCap: params.GasTipCap,
+				}, true
+			},
+			expectedParams: &ResubmitParams{
+				GasFeeCap: big.NewInt(22000000000), // 20 Gwei * 1.1
+				GasTipCap: big.NewInt(2400000000),  // 2 Gwei * 1.2
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			chain := &mockAdaptedEthereumClientWithReceipt{
+				mockAdaptedEthereumClient: &mockAdaptedEthereumClient{
+					blocks:        []*big.Int{big.NewInt(1)},
+					blocksBaseFee: []*big.Int{big.NewInt(5000000000)}, // 5 Gwei
+				},
+			}
+
+			var resubmissions []*bind.TransactOpts
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				resubmissions = append(resubmissions, newTransactorOptions)
+				chain.receipt = &types.Receipt{}
+				return createLegacyTransaction(newTransactorOptions.GasPrice), nil
+			}
+
+			// A gas budget makes sure the resubmission parameters are all
+			// set when the budget is reserved.
+			waiter := NewMiningWaiter(
+				chain,
+				config,
+				WithGasBudget(NewGasBudget(big.NewInt(1000000000000000000))),
+			)
+			waiter.SetResubmissionInterceptor(test.interceptor)
+
+			err := waiter.ForceMining(
+				test.originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(resubmissions) != 1 {
+				t.Fatalf(
+					"unexpected number of resubmissions\n"+
+						"expected: [%v]\nactual:   [%v]",
+					1,
+					len(resubmissions),
+				)
+			}
+
+			actualParams := &ResubmitParams{
+				GasPrice:  resubmissions[0].GasPrice,
+				GasFeeCap: resubmissions[0].GasFeeCap,
+				GasTipCap: resubmissions[0].GasTipCap,
+			}
+			if !reflect.DeepEqual(test.expectedParams, actualParams) {
+				t.Errorf(
+					"unexpected resubmission parameters\n"+
+						"expected: [%+v]\nactual:   [%+v]",
+					test.expectedParams,
+					actualParams,
+				)
+			}
+		})
+	}
+}
+
+func TestMiningWaiter_ValidateResubmitParams(t *testing.T) {
+	var tests = map[string]struct {
+		transaction   *types.Transaction
+		params        *ResubmitParams
+		expectedError string
+	}{
+		"legacy, valid": {
+			transaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			params: &ResubmitParams{GasPrice: big.NewInt(22000000000)},
+		},
+		"dynamic fee, valid": {
+			transaction: createDynamicFeeTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+				big.NewInt(2000000000),  // 2 Gwei
+			),
+			params: &ResubmitParams{
+				GasFeeCap: big.NewInt(22000000000),
+				GasTipCap: big.NewInt(2200000000),
+			},
+		},
+		"no gas price parameters": {
+			transaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			params: &ResubmitParams{},
+			expectedError: "neither the gas price nor the gas fee cap and " +
+				"gas tip cap are set",
+		},
+		"dynamic fee, nil gas tip cap": {
+			transaction: createDynamicFeeTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+				big.NewInt(2000000000),  // 2 Gwei
+			),
+			params: &ResubmitParams{GasFeeCap: big.NewInt(22000000000)},
+			expectedError: "neither the gas price nor the gas fee cap and " +
+				"gas tip cap are set",
+		},
+		"dynamic fee, gas tip cap below the replacement threshold": {
+			transaction: createDynamicFeeTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+				big.NewInt(2000000000),  // 2 Gwei
+			),
+			params: &ResubmitParams{
+				GasFeeCap: big.NewInt(22000000000),
+				GasTipCap: big.NewInt(2100000000),
+			},
+			expectedError: "gas tip cap [2100000000] is below the " +
+				"replacement threshold [2200000000]",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			waiter := NewMiningWaiter(&mockAdaptedEthereumClient{}, config)
+
+			err := waiter.validateResubmitParams(
+				context.Background(),
+				test.transaction,
+				test.params,
+			)
+
+			actualError := ""
+			if err != nil {
+				actualError = err.Error()
+			}
+			if test.expectedError != actualError {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					actualError,
+				)
+			}
+		})
+	}
+}
+
+func TestForceMining_ResubmissionInterceptor_Skip(t *testing.T) {
+	originalTransaction := createLegacyTransaction(big.NewInt(20000000000)) // 20 Gwei
+
+	chain := &mockAdaptedEthereumClientWithReceipt{}
+
+	interceptions := 0
+	interceptor := func(params *ResubmitParams) (*ResubmitParams, bool) {
+		interceptions++
+
+		// Skip the first resubmission and let the original transaction
+		// be mined in the meantime.
+		if interceptions == 1 {
+			chain.receipt = &types.Receipt{}
+		}
+
+		return nil, false
+	}
+
+	resubmissions := 0
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		resubmissions++
+		return createLegacyTransaction(newTransactorOptions.GasPrice), nil
+	}
+
+	waiter := NewMiningWaiter(chain, config)
+	waiter.SetResubmissionInterceptor(interceptor)
+
+	err := waiter.ForceMining(
+		originalTransaction,
+		originalTransactorOptions,
+		resubmitFn,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if interceptions != 1 {
+		t.Errorf(
+			"unexpected number of interceptions\n"+
+				"expected: [%v]\nactual:   [%v]",
+			1,
+			interceptions,
+		)
+	}
+
+	if resubmissions != 0 {
+		t.Errorf(
+			"unexpected number of resubmissions\n"+
+				"expected: [%v]\nactual:   [%v]",
+			0,
+			resubmissions,
+		)
+	}
+}
+
+func TestForceMining_MinedJustBeforeResubmission(t *testing.T) {
+	var tests = map[string]struct {
+		originalTransaction *types.Transaction
+	}{
+		"legacy": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+		},
+		"dynamic fee": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+				big.NewInt(2000000000),  // 2 Gwei
+			),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			chain := &mockAdaptedEthereumClientWithReceipt{
+				mockAdaptedEthereumClient: &mockAdaptedEthereumClient{
+					blocks:        []*big.Int{big.NewInt(1)},
+					blocksBaseFee: []*big.Int{big.NewInt(5000000000)}, // 5 Gwei
+				},
+			}
+
+			waiter := NewMiningWaiter(chain, config)
+
+			// The interceptor is called after the resubmission parameters
+			// are computed, just before the resubmission. Use it to simulate
+			// the transaction being mined in the meantime.
+			waiter.SetResubmissionInterceptor(
+				func(params *ResubmitParams) (*ResubmitParams, bool) {
+					chain.receipt = &types.Receipt{}
+					return nil, true
+				},
+			)
+
+			resubmissions := 0
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				resubmissions++
+				return createLegacyTransaction(newTransactorOptions.GasPrice), nil
+			}
+
+			err := waiter.ForceMining(
+				test.originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resubmissions != 0 {
+				t.Errorf(
+					"unexpected number of resubmissions\n"+
+						"expected: [%v]\nactual:   [%v]",
+					0,
+					resubmissions,
+				)
+			}
+		})
+	}
+}
+
+func TestForceMining_CorrelationID(t *testing.T) {
+	chain := &mockAdaptedEthereumClientWithReceipt{}
+
+	var correlationIDs []string
+	waiter := NewMiningWaiter(chain, config)
+	waiter.SetResubmissionInterceptor(
+		func(params *ResubmitParams) (*ResubmitParams, bool) {
+			correlationIDs = append(correlationIDs, params.CorrelationID)
+			return nil, true
+		},
+	)
+
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		chain.receipt = &types.Receipt{}
+		return createLegacyTransaction(newTransactorOptions.GasPrice), nil
+	}
+
+	err := waiter.ForceMiningWithCorrelationID(
+		"req-1",
+		createLegacyTransaction(big.NewInt(20000000000)), // 20 Gwei
+		originalTransactorOptions,
+		resubmitFn,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain.receipt = nil
+
+	results := waiter.ForceMiningAll(
+		WithCorrelationID(context.Background(), "batch-1"),
+		[]TxSpec{
+			{
+				Transaction:       createLegacyTransaction(big.NewInt(20000000000)),
+				TransactorOptions: originalTransactorOptions,
+				ResubmitFn:        resubmitFn,
+				CorrelationID:     "req-2",
+			},
+		},
+	)
+	if results[0].Err != nil {
+		t.Fatal(results[0].Err)
+	}
+
+	expectedCorrelationIDs := []string{"req-1", "req-2"}
+	if !reflect.DeepEqual(expectedCorrelationIDs, correlationIDs) {
+		t.Errorf(
+			"unexpected correlation IDs\nexpected: [%v]\nactual:   [%v]",
+			expectedCorrelationIDs,
+			correlationIDs,
+		)
+	}
+}
+
+func TestForceMining_GasProfile(t *testing.T) {
+	profileConfig := config
+	profileConfig.GasProfiles = map[string]ethereum.GasProfile{
+		"routine": {
+			MaxGasFeeCap: *ethereum.WrapWei(big.NewInt(30000000000)), // 30 Gwei
+		},
+	}
+
+	chain := &mockAdaptedEthereumClientWithReceipt{}
+	waiter := NewMiningWaiter(chain, profileConfig)
+
+	var resubmissions []*bind.TransactOpts
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		resubmissions = append(resubmissions, newTransactorOptions)
+		// not setting mockBackend.receipt, mining takes a very long time
+		return createLegacyTransaction(newTransactorOptions.GasPrice), nil
+	}
+
+	err := waiter.ForceMiningWithGasProfile(
+		"Routine",
+		createLegacyTransaction(big.NewInt(20000000000)), // 20 Gwei
+		originalTransactorOptions,
+		resubmitFn,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The next gas price of 30 Gwei, max allowed by the profile, does not
+	// fulfill the replacement threshold of 31.68 Gwei.
+	expectedResubmissionGasPrices := []*big.Int{
+		big.NewInt(24000000000), // + 20%
+		big.NewInt(28800000000), // + 20%
+	}
+
+	var resubmissionGasPrices []*big.Int
+	for _, resubmission := range resubmissions {
+		resubmissionGasPrices = append(resubmissionGasPrices, resubmission.GasPrice)
+	}
+
+	if !reflect.DeepEqual(expectedResubmissionGasPrices, resubmissionGasPrices) {
+		t.Errorf(
+			"unexpected resubmission gas prices\n"+
+				"expected: [%v]\nactual:   [%v]",
+			expectedResubmissionGasPrices,
+			resubmissionGasPrices,
+		)
+	}
+
+	err = waiter.ForceMiningWithGasProfile(
+		"liveness",
+		createLegacyTransaction(big.NewInt(20000000000)), // 20 Gwei
+		originalTransactorOptions,
+		resubmitFn,
+	)
+	if !errors.Is(err, ErrUnknownGasProfile) {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			ErrUnknownGasProfile,
+			err,
+		)
+	}
+
+	results := waiter.ForceMiningAll(
+		context.Background(),
+		[]TxSpec{
+			{
+				Transaction:       createLegacyTransaction(big.NewInt(20000000000)),
+				TransactorOptions: originalTransactorOptions,
+				ResubmitFn:        resubmitFn,
+				GasProfile:        "liveness",
+			},
+		},
+	)
+	if !errors.Is(results[0].Err, ErrUnknownGasProfile) {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			ErrUnknownGasProfile,
+			results[0].Err,
+		)
+	}
+}
+
+func TestForceMining_NonceProvider(t *testing.T) {
+	var tests = map[string]struct {
+		originalTransaction *types.Transaction
+		providerErr         error
+		expectedNonces      []*big.Int
+	}{
+		"legacy": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			expectedNonces: []*big.Int{big.NewInt(200), big.NewInt(201)},
+		},
+		"dynamic fee": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+				big.NewInt(2000000000),  // 2 Gwei
+			),
+			expectedNonces: []*big.Int{big.NewInt(200), big.NewInt(201)},
+		},
+		"provider failed": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			providerErr: fmt.Errorf("nonce manager out of sync"),
+			expectedNonces: []*big.Int{
+				originalTransactorOptions.Nonce,
+				originalTransactorOptions.Nonce,
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			chain := &mockAdaptedEthereumClientWithReceipt{
+				mockAdaptedEthereumClient: &mockAdaptedEthereumClient{
+					blocks:        []*big.Int{big.NewInt(1)},
+					blocksBaseFee: []*big.Int{big.NewInt(5000000000)}, // 5 Gwei
+				},
+			}
+
+			var resubmissions []*bind.TransactOpts
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				resubmissions = append(resubmissions, newTransactorOptions)
+
+				// The second resubmission gets mined.
+				if len(resubmissions) == 2 {
+					chain.receipt = &types.Receipt{}
+				}
+
+				if newTransactorOptions.GasPrice != nil {
+					return createLegacyTransaction(
+						newTransactorOptions.GasPrice,
+					), nil
+				}
+				return createDynamicFeeTransaction(
+					newTransactorOptions.GasFeeCap,
+					newTransactorOptions.GasTipCap,
+				), nil
+			}
+
+			var providedFor []*types.Transaction
+			nextNonce := int64(200)
+
+			waiter := NewMiningWaiter(chain, config)
+			waiter.SetNonceProvider(
+				func(transaction *types.Transaction) (*big.Int, error) {
+					providedFor = append(providedFor, transaction)
+
+					if test.providerErr != nil {
+						return nil, test.providerErr
+					}
+
+					nonce := big.NewInt(nextNonce)
+					nextNonce++
+					return nonce, nil
+				},
+			)
+
+			err := waiter.ForceMining(
+				test.originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var nonces []*big.Int
+			for _, resubmission := range resubmissions {
+				nonces = append(nonces, resubmission.Nonce)
+			}
+
+			if !reflect.DeepEqual(test.expectedNonces, nonces) {
+				t.Errorf(
+					"unexpected resubmission nonces\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedNonces,
+					nonces,
+				)
+			}
+
+			if len(providedFor) != 2 || providedFor[0] != test.originalTransaction {
+				t.Errorf("nonce should be provided for the last submitted transaction")
+			}
+
+			if originalTransactorOptions.Nonce.Cmp(big.NewInt(100)) != 0 {
+				t.Errorf("original transactor options should not be modified")
+			}
+		})
+	}
+}
+
+func TestForceMining_GaveUp(t *testing.T) {
+	var tests = map[string]struct {
+		originalTransaction *types.Transaction
+		baseFee             *big.Int
+		gasFeeCapHeadroom   *big.Int
+		resubmitErr         error
+		forceMiningTimeout  time.Duration
+		mined               bool
+		expectedReason      string
+		expectedGasFeeCap   *big.Int
+	}{
+		"legacy, mined": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			mined: true,
+		},
+		"legacy, original price higher than max allowed": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(46000000000), // 46 Gwei
+			),
+			expectedReason:    GaveUpOriginalAboveMaxGasFeeCap,
+			expectedGasFeeCap: big.NewInt(46000000000),
+		},
+		"legacy, max allowed price reached": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(37500000000), // 37.5 Gwei
+			),
+			expectedReason:    GaveUpMaxGasFeeCapReached,
+			expectedGasFeeCap: big.NewInt(45000000000),
+		},
+		"legacy, replacement threshold above max allowed": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			expectedReason: GaveUpThresholdUnsatisfiable,
+			// The last resubmission before the next required 10% bump
+			// exceeds the max gas price.
+			expectedGasFeeCap: big.NewInt(41472000000),
+		},
+		"legacy, resubmission failed": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			resubmitErr:       fmt.Errorf("insufficient funds"),
+			expectedReason:    GaveUpResubmissionFailed,
+			expectedGasFeeCap: big.NewInt(20000000000),
+		},
+		"legacy, force mining timeout": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			forceMiningTimeout: 50 * time.Millisecond,
+			expectedReason:     GaveUpForceMiningTimeout,
+			expectedGasFeeCap:  big.NewInt(20000000000),
+		},
+		"dynamic fee, mined": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(24000000000), // 24 Gwei
+				big.NewInt(4000000000),  // 4 Gwei
+			),
+			baseFee: big.NewInt(10000000000), // 10 Gwei
+			mined:   true,
+		},
+		"dynamic fee, original price higher than max allowed": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(46000000000), // 46 Gwei
+				big.NewInt(4000000000),  // 4 Gwei
+			),
+			baseFee:           big.NewInt(10000000000), // 10 Gwei
+			expectedReason:    GaveUpOriginalAboveMaxGasFeeCap,
+			expectedGasFeeCap: big.NewInt(46000000000),
+		},
+		"dynamic fee, max allowed price reached": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(24000000000), // 24 Gwei
+				big.NewInt(4000000000),  // 4 Gwei
+			),
+			baseFee:           big.NewInt(30000000000), // 30 Gwei
+			expectedReason:    GaveUpMaxGasFeeCapReached,
+			expectedGasFeeCap: big.NewInt(45000000000),
+		},
+		"dynamic fee, replacement threshold above max allowed": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(24000000000), // 24 Gwei
+				big.NewInt(4000000000),  // 4 Gwei
+			),
+			baseFee:        big.NewInt(10000000000), // 10 Gwei
+			expectedReason: GaveUpThresholdUnsatisfiable,
+			// The last resubmission before the next required 10% bump
+			// exceeds the max gas fee cap.
+			expectedGasFeeCap: big.NewInt(42517464000),
+		},
+		"dynamic fee, base fee exceeds headroom": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(24000000000), // 24 Gwei
+				big.NewInt(4000000000),  // 4 Gwei
+			),
+			baseFee:           big.NewInt(42000000000), // 42 Gwei
+			gasFeeCapHeadroom: big.NewInt(5000000000),  // 5 Gwei
+			expectedReason:    GaveUpHeadroomExceeded,
+			expectedGasFeeCap: big.NewInt(24000000000),
+		},
+		"dynamic fee, resubmission failed": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(24000000000), // 24 Gwei
+				big.NewInt(4000000000),  // 4 Gwei
+			),
+			baseFee:           big.NewInt(10000000000), // 10 Gwei
+			resubmitErr:       fmt.Errorf("insufficient funds"),
+			expectedReason:    GaveUpResubmissionFailed,
+			expectedGasFeeCap: big.NewInt(24000000000),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			chain := &mockAdaptedEthereumClientWithReceipt{
+				mockAdaptedEthereumClient: &mockAdaptedEthereumClient{},
+			}
+			if test.baseFee != nil {
+				chain.blocks = append(chain.blocks, big.NewInt(1))
+				chain.blocksBaseFee = append(chain.blocksBaseFee, test.baseFee)
+			}
+			if test.mined {
+				chain.receipt = &types.Receipt{}
+			}
+
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				if test.resubmitErr != nil {
+					return nil, test.resubmitErr
+				}
+
+				// Not setting mockBackend.receipt, mining takes a very
+				// long time.
+				if newTransactorOptions.GasPrice != nil {
+					return createLegacyTransaction(
+						newTransactorOptions.GasPrice,
+					), nil
+				}
+				return createDynamicFeeTransaction(
+					newTransactorOptions.GasFeeCap,
+					newTransactorOptions.GasTipCap,
+				), nil
+			}
+
+			gaveUpConfig := config
+			if test.gasFeeCapHeadroom != nil {
+				gaveUpConfig.GasFeeCapHeadroom = *ethereum.WrapWei(
+					test.gasFeeCapHeadroom,
+				)
+			}
+			if test.forceMiningTimeout != 0 {
+				gaveUpConfig.MiningCheckInterval = time.Minute
+				gaveUpConfig.ForceMiningTimeout = test.forceMiningTimeout
+			}
+
+			var reasons []string
+			var lastTransactions []*types.Transaction
+
+			waiter := NewMiningWaiter(chain, gaveUpConfig)
+			waiter.OnGaveUp(func(reason string, lastTx *types.Transaction) {
+				reasons = append(reasons, reason)
+				lastTransactions = append(lastTransactions, lastTx)
+			})
+			waiter.ForceMining(
+				test.originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+
+			if test.expectedReason == "" {
+				if len(reasons) != 0 {
+					t.Fatalf("unexpected gave up callbacks: [%v]", reasons)
+				}
+				return
+			}
+
+			if len(reasons) != 1 {
+				t.Fatalf(
+					"unexpected number of gave up callbacks\n"+
+						"expected: [%v]\nactual:   [%v]",
+					1,
+					len(reasons),
+				)
+			}
+
+			if reasons[0] != test.expectedReason {
+				t.Errorf(
+					"unexpected reason\nexpected: [%v]\nactual:   [%v]",
+					test.expectedReason,
+					reasons[0],
+				)
+			}
+
+			if lastTransactions[0].GasFeeCap().Cmp(test.expectedGasFeeCap) != 0 {
+				t.Errorf(
+					"unexpected last transaction gas fee cap\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedGasFeeCap,
+					lastTransactions[0].GasFeeCap(),
+				)
+			}
+		})
+	}
+}
+
+func TestForceMining_GaveUp_Cancelled(t *testing.T) {
+	chain := &mockAdaptedEthereumClientWithReceipt{}
+
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		t.Error("unexpected resubmission")
+		return nil, nil
+	}
+
+	cancelledConfig := config
+	cancelledConfig.MiningCheckInterval = time.Minute
+	cancelledConfig.ForceMiningTimeout = time.Minute
+
+	var reasons []string
+
+	waiter := NewMiningWaiter(chain, cancelledConfig)
+	waiter.OnGaveUp(func(reason string, lastTx *types.Transaction) {
+		reasons = append(reasons, reason)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	results := waiter.ForceMiningAll(
+		ctx,
+		[]TxSpec{
+			{
+				Transaction: createLegacyTransaction(
+					big.NewInt(20000000000), // 20 Gwei
+				),
+				TransactorOptions: originalTransactorOptions,
+				ResubmitFn:        resubmitFn,
+			},
+		},
+	)
+
+	if !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			context.Canceled,
+			results[0].Err,
+		)
+	}
+
+	expectedReasons := []string{GaveUpForceMiningCancelled}
+	if !reflect.DeepEqual(expectedReasons, reasons) {
+		t.Errorf(
+			"unexpected reasons\nexpected: [%v]\nactual:   [%v]",
+			expectedReasons,
+			reasons,
+		)
+	}
+}
+func TestForceMining_GaveUpCallbackNotSet(t *testing.T) {
+	chain := &mockAdaptedEthereumClientWithReceipt{}
+
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		return nil, fmt.Errorf("insufficient funds")
+	}
+
+	waiter := NewMiningWaiter(chain, config)
+	err := waiter.ForceMining(
+		createLegacyTransaction(big.NewInt(20000000000)), // 20 Gwei
+		originalTransactorOptions,
+		resubmitFn,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestForceMining_GasBudget(t *testing.T) {
+	var tests = map[string]struct {
+		originalTransaction   *types.Transaction
+		budget                *big.Int
+		expectedResubmissions int
+		expectedSpent         *big.Int
+		expectedLastGasFeeCap *big.Int
+	}{
+		"legacy, budget exhausted": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			// Allows one resubmission: 25000 gas * 24 Gwei.
+			budget:                big.NewInt(1000000000000000),
+			expectedResubmissions: 1,
+			expectedSpent:         big.NewInt(600000000000000),
+			expectedLastGasFeeCap: big.NewInt(24000000000),
+		},
+		"legacy, no budget": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(20000000000), // 20 Gwei
+			),
+			budget:                big.NewInt(0),
+			expectedResubmissions: 0,
+			expectedSpent:         big.NewInt(0),
+			expectedLastGasFeeCap: big.NewInt(20000000000),
+		},
+		"dynamic fee, budget exhausted": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(24000000000), // 24 Gwei
+				big.NewInt(4000000000),  // 4 Gwei
+			),
+			// Allows two resubmissions: 35000 gas * 26.4 Gwei and
+			// 35000 gas * 29.04 Gwei.
+			budget:                big.NewInt(2000000000000000),
+			expectedResubmissions: 2,
+			expectedSpent:         big.NewInt(1940400000000000),
+			expectedLastGasFeeCap: big.NewInt(29040000000),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			chain := &mockAdaptedEthereumClientWithReceipt{
+				mockAdaptedEthereumClient: &mockAdaptedEthereumClient{
+					blocks:        []*big.Int{big.NewInt(1)},
+					blocksBaseFee: []*big.Int{big.NewInt(10000000000)}, // 10 Gwei
+				},
+			}
+
+			resubmissions := 0
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				resubmissions++
+
+				// Not setting mockBackend.receipt, mining takes a very
+				// long time.
+				if newTransactorOptions.GasPrice != nil {
+					return createLegacyTransaction(
+						newTransactorOptions.GasPrice,
+					), nil
+				}
+				return createDynamicFeeTransaction(
+					newTransactorOptions.GasFeeCap,
+					newTransactorOptions.GasTipCap,
+				), nil
+			}
+
+			budget := NewGasBudget(test.budget)
+
+			var reasons []string
+			var lastTransactions []*types.Transaction
+
+			waiter := NewMiningWaiter(chain, config, WithGasBudget(budget))
+			waiter.OnGaveUp(func(reason string, lastTx *types.Transaction) {
+				reasons = append(reasons, reason)
+				lastTransactions = append(lastTransactions, lastTx)
+			})
+			err := waiter.ForceMining(
+				test.originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resubmissions != test.expectedResubmissions {
+				t.Errorf(
+					"unexpected number of resubmissions\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedResubmissions,
+					resubmissions,
+				)
+			}
+
+			if budget.Spent().Cmp(test.expectedSpent) != 0 {
+				t.Errorf(
+					"unexpected spent budget\nexpected: [%v]\nactual:   [%v]",
+					test.expectedSpent,
+					budget.Spent(),
+				)
+			}
+
+			expectedReasons := []string{GaveUpGasBudgetExhausted}
+			if !reflect.DeepEqual(expectedReasons, reasons) {
+				t.Fatalf(
+					"unexpected gave up reasons\nexpected: [%v]\nactual:   [%v]",
+					expectedReasons,
+					reasons,
+				)
+			}
+
+			if lastTransactions[0].GasFeeCap().Cmp(test.expectedLastGasFeeCap) != 0 {
+				t.Errorf(
+					"unexpected last transaction gas fee cap\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedLastGasFeeCap,
+					lastTransactions[0].GasFeeCap(),
+				)
+			}
+		})
+	}
+}
+
+func TestForceMining_GasBudgetShared(t *testing.T) {
+	chain := &mockAdaptedEthereumClientWithReceipt{}
+
+	resubmissions := 0
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		resubmissions++
+		// not setting mockBackend.receipt, mining takes a very long time
+		return createLegacyTransaction(newTransactorOptions.GasPrice), nil
+	}
+
+	// Allows two resubmissions of the first transaction:
+	// 25000 gas * 24 Gwei and 25000 gas * 28.8 Gwei.
+	budget := NewGasBudget(big.NewInt(1500000000000000))
+
+	firstWaiter := NewMiningWaiter(chain, config, WithGasBudget(budget))
+	secondWaiter := NewMiningWaiter(chain, config, WithGasBudget(budget))
+
+	firstWaiter.ForceMining(
+		createLegacyTransaction(big.NewInt(20000000000)), // 20 Gwei
+		originalTransactorOptions,
+		resubmitFn,
+	)
+
+	if resubmissions != 2 {
+		t.Fatalf(
+			"unexpected number of first waiter resubmissions\n"+
+				"expected: [%v]\nactual:   [%v]",
+			2,
+			resubmissions,
+		)
+	}
+
+	// The remaining budget is too low for the second waiter to resubmit.
+	secondWaiter.ForceMining(
+		createLegacyTransaction(big.NewInt(20000000000)), // 20 Gwei
+		originalTransactorOptions,
+		resubmitFn,
+	)
+
+	if resubmissions != 2 {
+		t.Fatalf(
+			"unexpected number of second waiter resubmissions\n"+
+				"expected: [%v]\nactual:   [%v]",
+			0,
+			resubmissions-2,
+		)
+	}
+
+	expectedRemaining := big.NewInt(180000000000000)
+	if budget.Remaining().Cmp(expectedRemaining) != 0 {
+		t.Errorf(
+			"unexpected remaining budget\nexpected: [%v]\nactual:   [%v]",
+			expectedRemaining,
+			budget.Remaining(),
+		)
+	}
+}
+
+func TestForceMining_GasBumpStrategy(t *testing.T) {
+	var tests = map[string]struct {
+		originalTransaction *types.Transaction
+		// for legacy transactions the gas fee cap is the gas price
+		expectedGasFeeCaps []*big.Int
+		expectedGasTipCaps []*big.Int
+		expectedReason     string
+	}{
+		"legacy": {
+			originalTransaction: createLegacyTransaction(
+				big.NewInt(30000000000), // 30 Gwei
+			),
+			expectedGasFeeCaps: []*big.Int{
+				big.NewInt(34000000000), // + 4 Gwei
+				big.NewInt(38000000000), // + 4 Gwei
+				big.NewInt(42000000000), // + 4 Gwei
+			},
+			// The next 4 Gwei bump to 46 Gwei is capped at 45 Gwei which
+			// is below the replacement threshold of 46.2 Gwei.
+			expectedReason: GaveUpThresholdUnsatisfiable,
+		},
+		"dynamic fee": {
+			originalTransaction: createDynamicFeeTransaction(
+				big.NewInt(30000000000), // 30 Gwei
+				big.NewInt(2000000000),  // 2 Gwei
+			),
+			expectedGasFeeCaps: []*big.Int{
+				big.NewInt(34000000000), // + 4 Gwei
+				big.NewInt(38000000000), // + 4 Gwei
+				big.NewInt(42000000000), // + 4 Gwei
+			},
+			expectedGasTipCaps: []*big.Int{
+				big.NewInt(3000000000), // + 1 Gwei
+				big.NewInt(4000000000), // + 1 Gwei
+				big.NewInt(5000000000), // + 1 Gwei
+			},
+			// The next 4 Gwei bump to 46 Gwei is capped at 45 Gwei which
+			// is below the replacement threshold of 46.2 Gwei.
+			expectedReason: GaveUpThresholdUnsatisfiable,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			chain := &mockAdaptedEthereumClientWithReceipt{
+				mockAdaptedEthereumClient: &mockAdaptedEthereumClient{
+					blocks:        []*big.Int{big.NewInt(1)},
+					blocksBaseFee: []*big.Int{big.NewInt(10000000000)}, // 10 Gwei
+				},
+			}
+
+			var resubmissions []*bind.TransactOpts
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				resubmissions = append(resubmissions, newTransactorOptions)
+
+				// Not setting mockBackend.receipt, mining takes a very
+				// long time.
+				if newTransactorOptions.GasPrice != nil {
+					return createLegacyTransaction(
+						newTransactorOptions.GasPrice,
+					), nil
+				}
+				return createDynamicFeeTransaction(
+					newTransactorOptions.GasFeeCap,
+					newTransactorOptions.GasTipCap,
+				), nil
+			}
+
+			var reasons []string
+
+			waiter := NewMiningWaiter(
+				chain,
+				config,
+				WithGasBumpStrategy(&linearGasBumpStrategy{
+					gasPriceStep:  big.NewInt(4000000000), // 4 Gwei
+					gasFeeCapStep: big.NewInt(4000000000), // 4 Gwei
+					gasTipCapStep: big.NewInt(1000000000), // 1 Gwei
+				}),
+			)
+			waiter.OnGaveUp(func(reason string, lastTx *types.Transaction) {
+				reasons = append(reasons, reason)
+			})
+			err := waiter.ForceMining(
+				test.originalTransaction,
+				originalTransactorOptions,
+				resubmitFn,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(resubmissions) != len(test.expectedGasFeeCaps) {
+				t.Fatalf(
+					"unexpected number of resubmissions\n"+
+						"expected: [%v]\nactual:   [%v]",
+					len(test.expectedGasFeeCaps),
+					len(resubmissions),
+				)
+			}
+
+			for index, resubmission := range resubmissions {
+				gasFeeCap := resubmission.GasFeeCap
+				if gasFeeCap == nil {
+					gasFeeCap = resubmission.GasPrice
+				}
+
+				if gasFeeCap.Cmp(test.expectedGasFeeCaps[index]) != 0 {
+					t.Errorf(
+						"unexpected resubmission [%v] gas fee cap\n"+
+							"expected: [%v]\nactual:   [%v]",
+						index,
+						test.expectedGasFeeCaps[index],
+						gasFeeCap,
+					)
+				}
+
+				if test.expectedGasTipCaps != nil &&
+					resubmission.GasTipCap.Cmp(test.expectedGasTipCaps[index]) != 0 {
+					t.Errorf(
+						"unexpected resubmission [%v] gas tip cap\n"+
+							"expected: [%v]\nactual:   [%v]",
+						index,
+						test.expectedGasTipCaps[index],
+						resubmission.GasTipCap,
+					)
+				}
+			}
+
+			expectedReasons := []string{test.expectedReason}
+			if !reflect.DeepEqual(expectedReasons, reasons) {
+				t.Errorf(
+					"unexpected gave up reasons\nexpected: [%v]\nactual:   [%v]",
+					expectedReasons,
+					reasons,
+				)
+			}
+		})
+	}
+}
+
+func TestIsBenignSubmitError(t *testing.T) {
+	var tests = map[string]struct {
+		err            error
+		expectedResult bool
+	}{
+		"nil error": {
+			err:            nil,
+			expectedResult: false,
+		},
+		"already known": {
+			err:            fmt.Errorf("already known"),
+			expectedResult: true,
+		},
+		"wrapped already known": {
+			err:            fmt.Errorf("could not submit: [Already Known]"),
+			expectedResult: true,
+		},
+		"replacement transaction underpriced": {
+			err:            fmt.Errorf("replacement transaction underpriced"),
+			expectedResult: true,
+		},
+		"nonce too low": {
+			err:            fmt.Errorf("nonce too low"),
+			expectedResult: false,
+		},
+		"insufficient funds": {
+			err:            fmt.Errorf("insufficient funds for gas * price + value"),
+			expectedResult: false,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			result := isBenignSubmitError(test.err)
+
+			if result != test.expectedResult {
+				t.Errorf(
+					"unexpected result\nexpected: [%v]\nactual:   [%v]",
+					test.expectedResult,
+					result,
+				)
+			}
+		})
+	}
+}
+
+func TestTransactionTypeName(t *testing.T) {
+	var tests = map[string]struct {
+		transactionType uint8
+		expectedName    string
+	}{
+		"legacy": {
+			transactionType: types.LegacyTxType,
+			expectedName:    "LegacyTx",
+		},
+		"access list": {
+			transactionType: types.AccessListTxType,
+			expectedName:    "AccessListTx",
+		},
+		"dynamic fee": {
+			transactionType: types.DynamicFeeTxType,
+			expectedName:    "DynamicFeeTx",
+		},
+		"unknown": {
+			transactionType: 100,
+			expectedName:    "UnknownTx(100)",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			name := TransactionTypeName(test.transactionType)
+
+			if name != test.expectedName {
+				t.Errorf(
+					"\nexpected: %s\nactual:   %s",
+					test.expectedName,
+					name,
+				)
+			}
+		})
+	}
+}
+
 func assertNonceUnchanged(
 	t *testing.T,
 	newTransactionOptions *bind.TransactOpts,
@@ -772,6 +3139,13 @@ func createLegacyTransaction(gasPrice *big.Int) *types.Transaction {
 	})
 }
 
+func createAccessListTransaction(gasPrice *big.Int) *types.Transaction {
+	return types.NewTx(&types.AccessListTx{
+		GasPrice: gasPrice,
+		Gas:      25000,
+	})
+}
+
 func createDynamicFeeTransaction(gasFeeCap, gasTipCap *big.Int) *types.Transaction {
 	return types.NewTx(&types.DynamicFeeTx{
 		GasFeeCap: gasFeeCap,
@@ -792,3 +3166,50 @@ func (maecwr *mockAdaptedEthereumClientWithReceipt) TransactionReceipt(
 ) (*types.Receipt, error) {
 	return maecwr.receipt, nil
 }
+
+type mockFailingReceiptEthereumClient struct {
+	*mockAdaptedEthereumClient
+
+	failures int
+	calls    int
+	receipt  *types.Receipt
+}
+
+func (mfrec *mockFailingReceiptEthereumClient) TransactionReceipt(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Receipt, error) {
+	mfrec.calls++
+
+	if mfrec.calls <= mfrec.failures {
+		return nil, fmt.Errorf("connection refused")
+	}
+
+	return mfrec.receipt, nil
+}
+
+// linearGasBumpStrategy bumps the gas parameters by fixed steps. It does not
+// cap the values at the max allowed one to let the tests verify the mining
+// waiter does it.
+type linearGasBumpStrategy struct {
+	gasPriceStep  *big.Int
+	gasFeeCapStep *big.Int
+	gasTipCapStep *big.Int
+}
+
+func (lgbs *linearGasBumpStrategy) NextLegacyGasPrice(
+	oldGasPrice *big.Int,
+	maxGasPrice *big.Int,
+) *big.Int {
+	return new(big.Int).Add(oldGasPrice, lgbs.gasPriceStep)
+}
+
+func (lgbs *linearGasBumpStrategy) NextDynamicFees(
+	oldGasFeeCap *big.Int,
+	oldGasTipCap *big.Int,
+	baseFee *big.Int,
+	maxGasFeeCap *big.Int,
+) (*big.Int, *big.Int) {
+	return new(big.Int).Add(oldGasFeeCap, lgbs.gasFeeCapStep),
+		new(big.Int).Add(oldGasTipCap, lgbs.gasTipCapStep)
+}
@@ -0,0 +1,103 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type multiEndpoint struct {
+	EthereumClient
+
+	broadcastClients []EthereumClient
+}
+
+// WrapMultiEndpoint wraps the given primary client so that the signed
+// transactions passed to SendTransaction are broadcast to the primary client
+// and all the given broadcast clients, each connected to a different
+// endpoint. The submission succeeds if any of the endpoints accepts the
+// transaction, so a flaky endpoint does not abort the submission or
+// the resubmission done by the mining waiter. An endpoint reporting
+// the transaction as already known is considered to accept it, since
+// the transaction has already propagated to it from another endpoint.
+//
+// All the other calls are delegated to the primary client.
+func WrapMultiEndpoint(
+	primary EthereumClient,
+	broadcastClients ...EthereumClient,
+) EthereumClient {
+	return &multiEndpoint{primary, broadcastClients}
+}
+
+func (me *multiEndpoint) SendTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) error {
+	clients := append(
+		[]EthereumClient{me.EthereumClient},
+		me.broadcastClients...,
+	)
+
+	errs := make([]error, len(clients))
+
+	wg := &sync.WaitGroup{}
+	wg.Add(len(clients))
+	for i, client := range clients {
+		go func(i int, client EthereumClient) {
+			defer wg.Done()
+			errs[i] = client.SendTransaction(ctx, tx)
+		}(i, client)
+	}
+	wg.Wait()
+
+	accepted := false
+	var benignErr error
+	var rejections []string
+	for i, err := range errs {
+		if err == nil {
+			accepted = true
+			continue
+		}
+
+		if isBenignSubmitError(err) {
+			if benignErr == nil {
+				benignErr = err
+			}
+			continue
+		}
+
+		logger.Warningf(
+			"endpoint [%v] rejected transaction [%v]: [%v]",
+			i,
+			tx.Hash().TerminalString(),
+			err,
+		)
+		rejections = append(rejections, err.Error())
+	}
+
+	if accepted {
+		return nil
+	}
+
+	if benignErr != nil {
+		return benignErr
+	}
+
+	return fmt.Errorf(
+		"all endpoints rejected transaction [%v]: [%v]",
+		tx.Hash().TerminalString(),
+		strings.Join(rejections, "; "),
+	)
+}
+
+func (me *multiEndpoint) SubscribePendingTransactions(
+	ctx context.Context,
+	ch chan<- common.Hash,
+) (ethereum.Subscription, error) {
+	return SubscribePendingTransactions(ctx, me.EthereumClient, ch)
+}
@@ -0,0 +1,136 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestMultiEndpoint_SendTransaction(t *testing.T) {
+	var tests = map[string]struct {
+		sendErrs      []error
+		expectedError string
+	}{
+		"all endpoints accept": {
+			sendErrs: []error{nil, nil, nil},
+		},
+		"primary endpoint fails": {
+			sendErrs: []error{fmt.Errorf("connection refused"), nil, nil},
+		},
+		"broadcast endpoint fails": {
+			sendErrs: []error{nil, fmt.Errorf("connection refused"), nil},
+		},
+		"transaction already known by broadcast endpoint": {
+			sendErrs: []error{nil, fmt.Errorf("already known"), nil},
+		},
+		"transaction already known": {
+			sendErrs: []error{
+				fmt.Errorf("connection refused"),
+				fmt.Errorf("already known"),
+				fmt.Errorf("connection refused"),
+			},
+			expectedError: "already known",
+		},
+		"all endpoints fail": {
+			sendErrs: []error{
+				fmt.Errorf("connection refused"),
+				fmt.Errorf("nonce too low"),
+				fmt.Errorf("timeout"),
+			},
+			expectedError: "connection refused; nonce too low; timeout",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			clients := make([]*mockSendingClient, len(test.sendErrs))
+			for i, sendErr := range test.sendErrs {
+				clients[i] = &mockSendingClient{
+					EthereumClient: &mockEthereumClient{},
+					sendErr:        sendErr,
+				}
+			}
+
+			client := WrapMultiEndpoint(
+				clients[0],
+				clients[1],
+				clients[2],
+			)
+
+			err := client.SendTransaction(
+				context.Background(),
+				createLegacyTransaction(big.NewInt(10)),
+			)
+
+			if test.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: [%v]", err)
+				}
+			} else {
+				if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+					t.Errorf(
+						"unexpected error\nexpected: [%v]\nactual:   [%v]",
+						test.expectedError,
+						err,
+					)
+				}
+			}
+
+			for i, c := range clients {
+				if sent := atomic.LoadUint64(&c.sent); sent != 1 {
+					t.Errorf(
+						"unexpected number of submissions to endpoint [%v]\n"+
+							"expected: [%v]\nactual:   [%v]",
+						i,
+						1,
+						sent,
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiEndpoint_BenignErrorPreserved(t *testing.T) {
+	client := WrapMultiEndpoint(
+		&mockSendingClient{
+			EthereumClient: &mockEthereumClient{},
+			sendErr:        fmt.Errorf("replacement transaction underpriced"),
+		},
+		&mockSendingClient{
+			EthereumClient: &mockEthereumClient{},
+			sendErr:        fmt.Errorf("connection refused"),
+		},
+	)
+
+	err := client.SendTransaction(
+		context.Background(),
+		createLegacyTransaction(big.NewInt(10)),
+	)
+
+	if !isBenignSubmitError(err) {
+		t.Errorf("error should be recognized as benign: [%v]", err)
+	}
+}
+
+// mockSendingClient counts the submitted transactions and fails their
+// submission with the configured error.
+type mockSendingClient struct {
+	EthereumClient
+
+	sendErr error
+	sent    uint64
+}
+
+func (msc *mockSendingClient) SendTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) error {
+	atomic.AddUint64(&msc.sent, 1)
+	return msc.sendErr
+}
@@ -0,0 +1,62 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// nonceReader is the subset of the client able to read account nonces at
+// the latest and the pending block.
+type nonceReader interface {
+	NonceAt(
+		ctx context.Context,
+		account common.Address,
+		blockNumber *big.Int,
+	) (uint64, error)
+
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceStatus returns the nonce of the given account at the latest block,
+// that is, the number of mined transactions of the account, and the pending
+// nonce of the account which also counts the account transactions waiting in
+// the mempool. The stuck count is the number of the account transactions in
+// the mempool; a growing stuck count means the transactions are not being
+// mined, e.g. because they are underpriced or there is a nonce gap.
+//
+// The nonces are read with two separate calls so the pending nonce may be
+// lower than the latest nonce if a block has been mined in between, or if
+// the calls have been served by different nodes. The stuck count is zero
+// in such a case.
+func NonceStatus(
+	ctx context.Context,
+	client nonceReader,
+	account common.Address,
+) (latestNonce uint64, pendingNonce uint64, stuckCount uint64, err error) {
+	latestNonce, err = client.NonceAt(ctx, account, nil)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf(
+			"could not get latest nonce of account [%v]: [%v]",
+			account.Hex(),
+			err,
+		)
+	}
+
+	pendingNonce, err = client.PendingNonceAt(ctx, account)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf(
+			"could not get pending nonce of account [%v]: [%v]",
+			account.Hex(),
+			err,
+		)
+	}
+
+	if pendingNonce > latestNonce {
+		stuckCount = pendingNonce - latestNonce
+	}
+
+	return latestNonce, pendingNonce, stuckCount, nil
+}
@@ -0,0 +1,140 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var noncesTestAccount = common.HexToAddress(
+	"0x2b5ad5c4795c026514f8317c7a215e218dccd6cf",
+)
+
+func TestNonceStatus(t *testing.T) {
+	var tests = map[string]struct {
+		latestNonce          uint64
+		pendingNonce         uint64
+		latestErr            error
+		pendingErr           error
+		expectedLatestNonce  uint64
+		expectedPendingNonce uint64
+		expectedStuckCount   uint64
+		expectedError        string
+	}{
+		"no stuck transactions": {
+			latestNonce:          10,
+			pendingNonce:         10,
+			expectedLatestNonce:  10,
+			expectedPendingNonce: 10,
+			expectedStuckCount:   0,
+		},
+		"stuck transactions": {
+			latestNonce:          10,
+			pendingNonce:         13,
+			expectedLatestNonce:  10,
+			expectedPendingNonce: 13,
+			expectedStuckCount:   3,
+		},
+		"pending nonce behind latest nonce": {
+			latestNonce:          11,
+			pendingNonce:         10,
+			expectedLatestNonce:  11,
+			expectedPendingNonce: 10,
+			expectedStuckCount:   0,
+		},
+		"latest nonce failed": {
+			latestErr: fmt.Errorf("connection refused"),
+			expectedError: fmt.Sprintf(
+				"could not get latest nonce of account [%v]: "+
+					"[connection refused]",
+				noncesTestAccount.Hex(),
+			),
+		},
+		"pending nonce failed": {
+			pendingErr: fmt.Errorf("connection refused"),
+			expectedError: fmt.Sprintf(
+				"could not get pending nonce of account [%v]: "+
+					"[connection refused]",
+				noncesTestAccount.Hex(),
+			),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			client := &mockNonceReader{
+				latestNonce:  test.latestNonce,
+				pendingNonce: test.pendingNonce,
+				latestErr:    test.latestErr,
+				pendingErr:   test.pendingErr,
+			}
+
+			latestNonce, pendingNonce, stuckCount, err := NonceStatus(
+				context.Background(),
+				client,
+				noncesTestAccount,
+			)
+
+			if test.expectedError != "" {
+				if err == nil || err.Error() != test.expectedError {
+					t.Fatalf(
+						"unexpected error\nexpected: [%v]\nactual:   [%v]",
+						test.expectedError,
+						err,
+					)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if latestNonce != test.expectedLatestNonce {
+				t.Errorf(
+					"unexpected latest nonce\nexpected: [%v]\nactual:   [%v]",
+					test.expectedLatestNonce,
+					latestNonce,
+				)
+			}
+			if pendingNonce != test.expectedPendingNonce {
+				t.Errorf(
+					"unexpected pending nonce\nexpected: [%v]\nactual:   [%v]",
+					test.expectedPendingNonce,
+					pendingNonce,
+				)
+			}
+			if stuckCount != test.expectedStuckCount {
+				t.Errorf(
+					"unexpected stuck count\nexpected: [%v]\nactual:   [%v]",
+					test.expectedStuckCount,
+					stuckCount,
+				)
+			}
+		})
+	}
+}
+
+type mockNonceReader struct {
+	latestNonce  uint64
+	pendingNonce uint64
+	latestErr    error
+	pendingErr   error
+}
+
+func (mnr *mockNonceReader) NonceAt(
+	ctx context.Context,
+	account common.Address,
+	blockNumber *big.Int,
+) (uint64, error) {
+	return mnr.latestNonce, mnr.latestErr
+}
+
+func (mnr *mockNonceReader) PendingNonceAt(
+	ctx context.Context,
+	account common.Address,
+) (uint64, error) {
+	return mnr.pendingNonce, mnr.pendingErr
+}
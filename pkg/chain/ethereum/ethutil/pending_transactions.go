@@ -0,0 +1,115 @@
+package ethutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrPendingTransactionsUnsupported is returned when subscribing to pending
+// transactions is not supported by the client or the Ethereum node.
+var ErrPendingTransactionsUnsupported = errors.New(
+	"pending transactions subscription not supported",
+)
+
+// methodNotFoundErrorCode is the JSON-RPC error code returned by the node
+// for methods and subscriptions it does not know.
+const methodNotFoundErrorCode = -32601
+
+// PendingTransactionSubscriber allows to monitor transactions entering
+// the mempool of the Ethereum node, e.g. to detect transactions competing
+// with the ones submitted by the client.
+type PendingTransactionSubscriber interface {
+	// SubscribePendingTransactions subscribes to hashes of transactions
+	// entering the mempool of the Ethereum node. The hashes are delivered to
+	// the given channel until the subscription is unsubscribed or fails.
+	SubscribePendingTransactions(
+		ctx context.Context,
+		ch chan<- common.Hash,
+	) (ethereum.Subscription, error)
+}
+
+// rpcSubscriber is the subset of the raw RPC client used to create
+// subscriptions which are not exposed by the typed client.
+type rpcSubscriber interface {
+	EthSubscribe(
+		ctx context.Context,
+		channel interface{},
+		args ...interface{},
+	) (*rpc.ClientSubscription, error)
+}
+
+type pendingTransactionsWrapper struct {
+	EthereumClient
+
+	subscriber rpcSubscriber
+}
+
+// WrapPendingTransactions wraps the given client so that it supports
+// subscribing to pending transactions. The typed client does not expose
+// the subscription so it is created with the eth_subscribe method of the given
+// raw RPC client, which must be connected over WebSocket or IPC. All other
+// calls are delegated to the passed client.
+//
+// Not all the providers support the subscription. If the node does not
+// support it, ErrPendingTransactionsUnsupported is returned from
+// SubscribePendingTransactions.
+func WrapPendingTransactions(
+	client EthereumClient,
+	rpcClient *rpc.Client,
+) EthereumClient {
+	return &pendingTransactionsWrapper{client, rpcClient}
+}
+
+func (ptw *pendingTransactionsWrapper) SubscribePendingTransactions(
+	ctx context.Context,
+	ch chan<- common.Hash,
+) (ethereum.Subscription, error) {
+	subscription, err := ptw.subscriber.EthSubscribe(
+		ctx,
+		ch,
+		"newPendingTransactions",
+	)
+	if err != nil {
+		var rpcError rpc.Error
+		if errors.Is(err, rpc.ErrNotificationsUnsupported) ||
+			(errors.As(err, &rpcError) &&
+				rpcError.ErrorCode() == methodNotFoundErrorCode) {
+			return nil, fmt.Errorf(
+				"%w: [%v]",
+				ErrPendingTransactionsUnsupported,
+				err,
+			)
+		}
+
+		return nil, fmt.Errorf(
+			"could not subscribe to pending transactions: [%v]",
+			err,
+		)
+	}
+
+	return subscription, nil
+}
+
+// SubscribePendingTransactions subscribes to hashes of transactions entering
+// the mempool of the Ethereum node using the given client. The client must
+// support the subscription, e.g. it must have been wrapped with
+// WrapPendingTransactions, otherwise ErrPendingTransactionsUnsupported is
+// returned. Clients returned from other wrappers in this package support the
+// subscription if the client they wrap does.
+func SubscribePendingTransactions(
+	ctx context.Context,
+	client EthereumClient,
+	ch chan<- common.Hash,
+) (ethereum.Subscription, error) {
+	subscriber, ok := client.(PendingTransactionSubscriber)
+	if !ok {
+		return nil, ErrPendingTransactionsUnsupported
+	}
+
+	return subscriber.SubscribePendingTransactions(ctx, ch)
+}
@@ -0,0 +1,132 @@
+package ethutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/keep-network/keep-common/pkg/rate"
+)
+
+var pendingTransactionHash = common.HexToHash(
+	"0x6e1e7fb1fa3b55aa5e3a25e5a1e07d1fb5b8f5d3a6e3c9b5d2e1f0a9b8c7d6e5",
+)
+
+// pendingTransactionsService is a fake eth namespace of the Ethereum node
+// notifying about a single pending transaction.
+type pendingTransactionsService struct{}
+
+func (pts *pendingTransactionsService) NewPendingTransactions(
+	ctx context.Context,
+) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	subscription := notifier.CreateSubscription()
+	go func() {
+		_ = notifier.Notify(subscription.ID, pendingTransactionHash)
+	}()
+
+	return subscription, nil
+}
+
+// noSubscriptionsService is a fake eth namespace of the Ethereum node
+// supporting no subscriptions.
+type noSubscriptionsService struct{}
+
+func (nss *noSubscriptionsService) ChainId() string {
+	return "0x1"
+}
+
+func TestSubscribePendingTransactions(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+
+	if err := server.RegisterName(
+		"eth",
+		&pendingTransactionsService{},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	rpcClient := rpc.DialInProc(server)
+	defer rpcClient.Close()
+
+	client := WrapRateLimiting(
+		WrapPendingTransactions(&mockEthereumClient{}, rpcClient),
+		&rate.LimiterConfig{RequestsPerSecondLimit: 10},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	hashes := make(chan common.Hash)
+	subscription, err := SubscribePendingTransactions(ctx, client, hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer subscription.Unsubscribe()
+
+	select {
+	case hash := <-hashes:
+		if hash != pendingTransactionHash {
+			t.Errorf(
+				"unexpected hash\nexpected: [%v]\nactual:   [%v]",
+				pendingTransactionHash,
+				hash,
+			)
+		}
+	case err := <-subscription.Err():
+		t.Fatal(err)
+	case <-ctx.Done():
+		t.Fatal("pending transaction not received")
+	}
+}
+
+func TestSubscribePendingTransactions_Unsupported(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+
+	if err := server.RegisterName(
+		"eth",
+		&noSubscriptionsService{},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	rpcClient := rpc.DialInProc(server)
+	defer rpcClient.Close()
+
+	var tests = map[string]struct {
+		client EthereumClient
+	}{
+		"client not supporting the subscription": {
+			client: &mockEthereumClient{},
+		},
+		"node not supporting the subscription": {
+			client: WrapPendingTransactions(&mockEthereumClient{}, rpcClient),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			_, err := SubscribePendingTransactions(
+				context.Background(),
+				test.client,
+				make(chan common.Hash),
+			)
+			if !errors.Is(err, ErrPendingTransactionsUnsupported) {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					ErrPendingTransactionsUnsupported,
+					err,
+				)
+			}
+		})
+	}
+}
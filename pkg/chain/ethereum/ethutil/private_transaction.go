@@ -0,0 +1,136 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	chainEthereum "github.com/keep-network/keep-common/pkg/chain/ethereum"
+)
+
+// PrivateTransactionSender submits signed transactions through a private
+// transaction relay instead of the public mempool.
+type PrivateTransactionSender interface {
+	// SendPrivateTransaction submits the signed transaction to the private
+	// transaction relay.
+	SendPrivateTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// relaySender is a PrivateTransactionSender posting signed transactions to
+// a private transaction RPC endpoint, such as Flashbots Protect, using
+// the standard eth_sendRawTransaction method. Relays which require signed
+// requests, such as the eth_sendPrivateTransaction method of the Flashbots
+// relay authenticated with the X-Flashbots-Signature header, are not
+// supported.
+type relaySender struct {
+	client RPCCaller
+}
+
+// NewRelaySender connects to the private transaction RPC endpoint at the given
+// URL, for example https://rpc.flashbots.net, and returns
+// a PrivateTransactionSender submitting transactions to it using
+// the eth_sendRawTransaction method.
+func NewRelaySender(url string) (PrivateTransactionSender, error) {
+	client, err := rpc.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error connecting to private transaction relay: %s [%v]",
+			url,
+			err,
+		)
+	}
+
+	return &relaySender{client}, nil
+}
+
+func (rs *relaySender) SendPrivateTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) error {
+	rawTransaction, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction: [%v]", err)
+	}
+
+	var result interface{}
+	err = rs.client.CallContext(
+		ctx,
+		&result,
+		"eth_sendRawTransaction",
+		hexutil.Encode(rawTransaction),
+	)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to send transaction [%v] to private relay: [%v]",
+			tx.Hash().TerminalString(),
+			err,
+		)
+	}
+
+	return nil
+}
+
+type privateTransactionWrapper struct {
+	EthereumClient
+
+	sender PrivateTransactionSender
+}
+
+// WrapPrivateTransactions wraps the given client so that the signed
+// transactions passed to SendTransaction are submitted through the given
+// private transaction sender instead of the public mempool. All other calls
+// are delegated to the passed client. A mining waiter created for the wrapped
+// client resubmits transactions through the same private sender.
+//
+// Once the transaction gets mined, its receipt is available from the chain as
+// for any other transaction, so receipt polling works normally.
+func WrapPrivateTransactions(
+	client EthereumClient,
+	sender PrivateTransactionSender,
+) EthereumClient {
+	return &privateTransactionWrapper{client, sender}
+}
+
+// WrapPrivateTransactionsFromConfig wraps the given client so that the
+// transactions are submitted through the private transaction relay set in
+// the configuration. The client is returned unchanged if no relay is
+// configured.
+func WrapPrivateTransactionsFromConfig(
+	client EthereumClient,
+	config *chainEthereum.Config,
+) (EthereumClient, error) {
+	if config.PrivateTransactionRelayURL == "" {
+		return client, nil
+	}
+
+	sender, err := NewRelaySender(config.PrivateTransactionRelayURL)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof(
+		"transactions will be submitted through private relay [%v]",
+		config.PrivateTransactionRelayURL,
+	)
+
+	return WrapPrivateTransactions(client, sender), nil
+}
+
+func (ptw *privateTransactionWrapper) SendTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) error {
+	return ptw.sender.SendPrivateTransaction(ctx, tx)
+}
+
+func (ptw *privateTransactionWrapper) SubscribePendingTransactions(
+	ctx context.Context,
+	ch chan<- common.Hash,
+) (ethereum.Subscription, error) {
+	return SubscribePendingTransactions(ctx, ptw.EthereumClient, ch)
+}
@@ -0,0 +1,116 @@
+package ethutil
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestRelaySender_SendPrivateTransaction(t *testing.T) {
+	caller := &mockRPCCaller{}
+	sender := &relaySender{caller}
+
+	transaction := createLegacyTransaction(big.NewInt(20000000000))
+
+	err := sender.SendPrivateTransaction(context.Background(), transaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedMethod := "eth_sendRawTransaction"
+	if caller.method != expectedMethod {
+		t.Errorf(
+			"unexpected method\nexpected: [%v]\nactual:   [%v]",
+			expectedMethod,
+			caller.method,
+		)
+	}
+
+	rawTransaction, err := transaction.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedArgs := []interface{}{hexutil.Encode(rawTransaction)}
+	if !reflect.DeepEqual(expectedArgs, caller.args) {
+		t.Errorf(
+			"unexpected arguments\nexpected: [%v]\nactual:   [%v]",
+			expectedArgs,
+			caller.args,
+		)
+	}
+}
+
+func TestWrapPrivateTransactions(t *testing.T) {
+	client := &mockEthereumClient{}
+	sender := &mockPrivateTransactionSender{}
+
+	wrappedClient := WrapPrivateTransactions(client, sender)
+
+	transaction := createLegacyTransaction(big.NewInt(20000000000))
+
+	err := wrappedClient.SendTransaction(context.Background(), transaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sender.transactions) != 1 {
+		t.Fatalf(
+			"unexpected number of private transactions\n"+
+				"expected: [%v]\nactual:   [%v]",
+			1,
+			len(sender.transactions),
+		)
+	}
+
+	if sender.transactions[0].Hash() != transaction.Hash() {
+		t.Errorf("unexpected private transaction")
+	}
+
+	if len(client.events) != 0 {
+		t.Errorf("transaction should not be sent to the public mempool")
+	}
+}
+
+type mockRPCCaller struct {
+	response string
+	err      error
+	method   string
+	args     []interface{}
+}
+
+func (mrc *mockRPCCaller) CallContext(
+	ctx context.Context,
+	result interface{},
+	method string,
+	args ...interface{},
+) error {
+	mrc.method = method
+	mrc.args = args
+
+	if mrc.err != nil {
+		return mrc.err
+	}
+	if len(mrc.response) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(mrc.response), result)
+}
+
+type mockPrivateTransactionSender struct {
+	transactions []*types.Transaction
+}
+
+func (mpts *mockPrivateTransactionSender) SendPrivateTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) error {
+	mpts.transactions = append(mpts.transactions, tx)
+	return nil
+}
@@ -11,26 +11,124 @@ import (
 	"github.com/keep-network/keep-common/pkg/rate"
 )
 
+// DefaultRateLimitingBypassedMethods lists the names of client methods which
+// bypass the call timeout of the rate limiter by default. Subscriptions are
+// long-lived so the call timeout must not cancel them. A subscribe call still
+// acquires a rate limiter permit but releases it as soon as the subscription
+// is established so that the subscription does not hold the permit for its
+// entire lifetime.
+var DefaultRateLimitingBypassedMethods = []string{
+	"SubscribeNewHead",
+	"SubscribeFilterLogs",
+	"SubscribePendingTransactions",
+}
+
+// RateLimitingUtilization reports how close the rate-limited client is to
+// its limits. Clients returned from WrapRateLimiting and
+// WrapRateLimitingWithBypass implement this interface so that the utilization
+// can be obtained with a type assertion, e.g. to report it from an admin
+// endpoint. Reading the utilization does not affect the rate limiter state.
+type RateLimitingUtilization interface {
+	// AvailablePermits returns the number of requests which can be executed
+	// at the moment without waiting for other requests to complete or -1
+	// if the concurrency is not limited.
+	AvailablePermits() int
+
+	// TokensAvailable returns the approximate number of requests which can
+	// be started at the moment without exceeding the requests per second
+	// limit or +Inf if the requests per second are not limited.
+	TokensAvailable() float64
+}
+
+// RateLimitingReconfiguration allows to change the limits of the rate-limited
+// client at runtime, e.g. to throttle the client during an incident without
+// reconstructing the client stack and dropping its subscriptions. Clients
+// returned from WrapRateLimiting and WrapRateLimitingWithBypass implement
+// this interface so that the limits can be changed with a type assertion.
+type RateLimitingReconfiguration interface {
+	// SetConcurrencyLimit changes the maximum number of concurrent requests.
+	// A non-positive limit disables the concurrency limit.
+	SetConcurrencyLimit(limit int)
+
+	// SetRequestsPerSecond changes the maximum average number of requests
+	// per second. A non-positive limit disables the requests per second
+	// limit.
+	SetRequestsPerSecond(limit float64)
+}
+
+// RateLimitingDrainer allows to gracefully shut down the rate-limited client.
+// Clients returned from WrapRateLimiting and WrapRateLimitingWithBypass
+// implement this interface so that the client can be drained with a type
+// assertion.
+type RateLimitingDrainer interface {
+	// Drain makes all subsequent requests fail immediately with
+	// rate.ErrLimiterClosed and blocks until all requests in progress
+	// complete or until the provided context is done.
+	Drain(ctx context.Context) error
+}
+
 type rateLimiter struct {
 	EthereumClient
 
 	*rate.Limiter
+
+	bypassedMethods map[string]bool
 }
 
 // WrapRateLimiting wraps the given contract backend with rate limiting
 // capabilities with respect to the provided configuration.
 // All types of requests to the contract are rate-limited,
-// including view function calls.
+// including view function calls. The methods listed in
+// DefaultRateLimitingBypassedMethods bypass the call timeout.
 func WrapRateLimiting(
 	client EthereumClient,
 	config *rate.LimiterConfig,
 ) EthereumClient {
+	return WrapRateLimitingWithBypass(
+		client,
+		config,
+		DefaultRateLimitingBypassedMethods,
+	)
+}
+
+// WrapRateLimitingWithBypass wraps the given contract backend with rate
+// limiting capabilities with respect to the provided configuration.
+// All types of requests to the contract are rate-limited, including view
+// function calls. Calls to the client methods whose names are listed in
+// bypassedMethods are executed without the call timeout of the rate limiter.
+// They acquire a rate limiter permit which is released as soon as the call
+// returns, e.g. once a subscription is established.
+func WrapRateLimitingWithBypass(
+	client EthereumClient,
+	config *rate.LimiterConfig,
+	bypassedMethods []string,
+) EthereumClient {
+	bypassed := make(map[string]bool, len(bypassedMethods))
+	for _, method := range bypassedMethods {
+		bypassed[method] = true
+	}
+
 	return &rateLimiter{
-		EthereumClient: client,
-		Limiter:        rate.NewLimiter(config),
+		EthereumClient:  client,
+		Limiter:         rate.NewLimiter(config),
+		bypassedMethods: bypassed,
 	}
 }
 
+// callContext returns the context the call of the given client method should
+// be executed with. The call timeout of the rate limiter is not applied to
+// the bypassed methods.
+func (rl *rateLimiter) callContext(
+	ctx context.Context,
+	method string,
+) (context.Context, context.CancelFunc) {
+	if rl.bypassedMethods[method] {
+		return ctx, func() {}
+	}
+
+	return rl.Limiter.CallContext(ctx)
+}
+
 func (rl *rateLimiter) CodeAt(
 	ctx context.Context,
 	contract common.Address,
@@ -38,10 +136,13 @@ func (rl *rateLimiter) CodeAt(
 ) ([]byte, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "CodeAt")
+	defer cancel()
+
 	return rl.EthereumClient.CodeAt(ctx, contract, blockNumber)
 }
 
@@ -52,10 +153,13 @@ func (rl *rateLimiter) CallContract(
 ) ([]byte, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "CallContract")
+	defer cancel()
+
 	return rl.EthereumClient.CallContract(ctx, call, blockNumber)
 }
 
@@ -65,10 +169,13 @@ func (rl *rateLimiter) PendingCodeAt(
 ) ([]byte, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "PendingCodeAt")
+	defer cancel()
+
 	return rl.EthereumClient.PendingCodeAt(ctx, account)
 }
 
@@ -78,10 +185,13 @@ func (rl *rateLimiter) PendingNonceAt(
 ) (uint64, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return 0, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return 0, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "PendingNonceAt")
+	defer cancel()
+
 	return rl.EthereumClient.PendingNonceAt(ctx, account)
 }
 
@@ -90,10 +200,13 @@ func (rl *rateLimiter) SuggestGasPrice(
 ) (*big.Int, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "SuggestGasPrice")
+	defer cancel()
+
 	return rl.EthereumClient.SuggestGasPrice(ctx)
 }
 
@@ -102,10 +215,13 @@ func (rl *rateLimiter) SuggestGasTipCap(
 ) (*big.Int, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "SuggestGasTipCap")
+	defer cancel()
+
 	return rl.EthereumClient.SuggestGasTipCap(ctx)
 }
 
@@ -115,10 +231,13 @@ func (rl *rateLimiter) EstimateGas(
 ) (uint64, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return 0, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return 0, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "EstimateGas")
+	defer cancel()
+
 	return rl.EthereumClient.EstimateGas(ctx, call)
 }
 
@@ -128,10 +247,13 @@ func (rl *rateLimiter) SendTransaction(
 ) error {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "SendTransaction")
+	defer cancel()
+
 	return rl.EthereumClient.SendTransaction(ctx, tx)
 }
 
@@ -141,10 +263,13 @@ func (rl *rateLimiter) FilterLogs(
 ) ([]types.Log, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "FilterLogs")
+	defer cancel()
+
 	return rl.EthereumClient.FilterLogs(ctx, query)
 }
 
@@ -155,10 +280,13 @@ func (rl *rateLimiter) SubscribeFilterLogs(
 ) (ethereum.Subscription, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "SubscribeFilterLogs")
+	defer cancel()
+
 	return rl.EthereumClient.SubscribeFilterLogs(ctx, query, ch)
 }
 
@@ -168,10 +296,13 @@ func (rl *rateLimiter) BlockByHash(
 ) (*types.Block, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "BlockByHash")
+	defer cancel()
+
 	return rl.EthereumClient.BlockByHash(ctx, hash)
 }
 
@@ -181,10 +312,13 @@ func (rl *rateLimiter) BlockByNumber(
 ) (*types.Block, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "BlockByNumber")
+	defer cancel()
+
 	return rl.EthereumClient.BlockByNumber(ctx, number)
 }
 
@@ -194,10 +328,13 @@ func (rl *rateLimiter) HeaderByHash(
 ) (*types.Header, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "HeaderByHash")
+	defer cancel()
+
 	return rl.EthereumClient.HeaderByHash(ctx, hash)
 }
 
@@ -207,10 +344,13 @@ func (rl *rateLimiter) HeaderByNumber(
 ) (*types.Header, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "HeaderByNumber")
+	defer cancel()
+
 	return rl.EthereumClient.HeaderByNumber(ctx, number)
 }
 
@@ -220,10 +360,13 @@ func (rl *rateLimiter) TransactionCount(
 ) (uint, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return 0, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return 0, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "TransactionCount")
+	defer cancel()
+
 	return rl.EthereumClient.TransactionCount(ctx, blockHash)
 }
 
@@ -234,10 +377,13 @@ func (rl *rateLimiter) TransactionInBlock(
 ) (*types.Transaction, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "TransactionInBlock")
+	defer cancel()
+
 	return rl.EthereumClient.TransactionInBlock(ctx, blockHash, index)
 }
 
@@ -247,10 +393,13 @@ func (rl *rateLimiter) SubscribeNewHead(
 ) (ethereum.Subscription, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "SubscribeNewHead")
+	defer cancel()
+
 	return rl.EthereumClient.SubscribeNewHead(ctx, ch)
 }
 
@@ -260,10 +409,13 @@ func (rl *rateLimiter) TransactionByHash(
 ) (*types.Transaction, bool, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, false, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, false, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "TransactionByHash")
+	defer cancel()
+
 	return rl.EthereumClient.TransactionByHash(ctx, txHash)
 }
 
@@ -273,10 +425,13 @@ func (rl *rateLimiter) TransactionReceipt(
 ) (*types.Receipt, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "TransactionReceipt")
+	defer cancel()
+
 	return rl.EthereumClient.TransactionReceipt(ctx, txHash)
 }
 
@@ -287,9 +442,28 @@ func (rl *rateLimiter) BalanceAt(
 ) (*big.Int, error) {
 	err := rl.Limiter.AcquirePermit()
 	if err != nil {
-		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%v]", err)
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
 	}
 	defer rl.Limiter.ReleasePermit()
 
+	ctx, cancel := rl.callContext(ctx, "BalanceAt")
+	defer cancel()
+
 	return rl.EthereumClient.BalanceAt(ctx, account, blockNumber)
 }
+
+func (rl *rateLimiter) SubscribePendingTransactions(
+	ctx context.Context,
+	ch chan<- common.Hash,
+) (ethereum.Subscription, error) {
+	err := rl.Limiter.AcquirePermit()
+	if err != nil {
+		return nil, fmt.Errorf("cannot acquire rate limiter permit: [%w]", err)
+	}
+	defer rl.Limiter.ReleasePermit()
+
+	ctx, cancel := rl.callContext(ctx, "SubscribePendingTransactions")
+	defer cancel()
+
+	return SubscribePendingTransactions(ctx, rl.EthereumClient, ch)
+}
@@ -2,6 +2,7 @@ package ethutil
 
 import (
 	"context"
+	"errors"
 	"math/big"
 	"strings"
 	"sync"
@@ -27,13 +28,15 @@ func TestRateLimiter(t *testing.T) {
 		sync.Mutex{},
 	}
 
-	rateLimitingClient := WrapRateLimiting(
+	// Do not bypass any method so that all of them are verified.
+	rateLimitingClient := WrapRateLimitingWithBypass(
 		client,
 		&rate.LimiterConfig{
 			RequestsPerSecondLimit: requestsPerSecondLimit,
 			ConcurrencyLimit:       concurrencyLimit,
 			AcquirePermitTimeout:   acquirePermitTimeout,
 		},
+		nil,
 	)
 
 	for testName, test := range getTests(rateLimitingClient) {
@@ -116,13 +119,15 @@ func TestRateLimiter_RequestsPerSecondLimitOnly(t *testing.T) {
 		sync.Mutex{},
 	}
 
-	rateLimitingClient := WrapRateLimiting(
+	// Do not bypass any method so that all of them are verified.
+	rateLimitingClient := WrapRateLimitingWithBypass(
 		client,
 		&rate.LimiterConfig{
 			RequestsPerSecondLimit: requestsPerSecondLimit,
 			ConcurrencyLimit:       concurrencyLimit,
 			AcquirePermitTimeout:   acquirePermitTimeout,
 		},
+		nil,
 	)
 
 	for testName, test := range getTests(rateLimitingClient) {
@@ -183,13 +188,15 @@ func TestRateLimiter_ConcurrencyLimitOnly(t *testing.T) {
 		sync.Mutex{},
 	}
 
-	rateLimitingClient := WrapRateLimiting(
+	// Do not bypass any method so that all of them are verified.
+	rateLimitingClient := WrapRateLimitingWithBypass(
 		client,
 		&rate.LimiterConfig{
 			RequestsPerSecondLimit: requestsPerSecondLimit,
 			ConcurrencyLimit:       concurrencyLimit,
 			AcquirePermitTimeout:   acquirePermitTimeout,
 		},
+		nil,
 	)
 
 	for testName, test := range getTests(rateLimitingClient) {
@@ -306,6 +313,233 @@ func TestRateLimiter_AcquirePermitTimout(t *testing.T) {
 	}
 }
 
+func TestRateLimiter_BypassedSubscription(t *testing.T) {
+	requestsPerSecondLimit := 0 // disable the requests per second limit
+	concurrencyLimit := 1
+	acquirePermitTimeout := 100 * time.Millisecond
+	callTimeout := 50 * time.Millisecond
+	requestDuration := 10 * time.Millisecond
+
+	client := &mockSubscribingEthereumClient{
+		mockEthereumClient: &mockEthereumClient{
+			requestDuration,
+			make([]string, 0),
+			sync.Mutex{},
+		},
+	}
+
+	rateLimitingClient := WrapRateLimiting(
+		client,
+		&rate.LimiterConfig{
+			RequestsPerSecondLimit: requestsPerSecondLimit,
+			ConcurrencyLimit:       concurrencyLimit,
+			AcquirePermitTimeout:   acquirePermitTimeout,
+			CallTimeout:            callTimeout,
+		},
+	)
+
+	utilization := rateLimitingClient.(RateLimitingUtilization)
+
+	var availablePermitsOnSubscribe int
+	var deadlineOnSubscribe bool
+	client.onSubscribe = func(ctx context.Context) {
+		availablePermitsOnSubscribe = utilization.AvailablePermits()
+		_, deadlineOnSubscribe = ctx.Deadline()
+	}
+
+	_, err := rateLimitingClient.SubscribeNewHead(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected subscription error: [%v]", err)
+	}
+
+	// The subscribe call holds the only available permit.
+	if availablePermitsOnSubscribe != 0 {
+		t.Errorf(
+			"unexpected available permits on subscribe\n"+
+				"expected: [%v]\nactual:   [%v]",
+			0,
+			availablePermitsOnSubscribe,
+		)
+	}
+
+	// The call timeout must not limit the lifetime of the subscription.
+	if deadlineOnSubscribe {
+		t.Errorf("subscription context must not have a deadline")
+	}
+
+	// The subscription is established and lives on but it does not hold
+	// the permit anymore. Other requests must not be starved.
+	for i := 0; i < 3; i++ {
+		_, err := rateLimitingClient.CodeAt(context.Background(), [20]byte{}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: [%v]", err)
+		}
+	}
+}
+
+func TestRateLimiter_Drained(t *testing.T) {
+	client := &mockEthereumClient{
+		10 * time.Millisecond,
+		make([]string, 0),
+		sync.Mutex{},
+	}
+
+	rateLimitingClient := WrapRateLimiting(
+		client,
+		&rate.LimiterConfig{
+			ConcurrencyLimit: 1,
+		},
+	)
+
+	err := rateLimitingClient.(RateLimitingDrainer).Drain(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected drain error: [%v]", err)
+	}
+
+	_, err = rateLimitingClient.CodeAt(context.Background(), [20]byte{}, nil)
+	if !errors.Is(err, rate.ErrLimiterClosed) {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			rate.ErrLimiterClosed,
+			err,
+		)
+	}
+}
+
+func TestRateLimiter_CallTimeout(t *testing.T) {
+	client := &mockHangingEthereumClient{
+		mockEthereumClient: &mockEthereumClient{
+			10 * time.Millisecond,
+			make([]string, 0),
+			sync.Mutex{},
+		},
+	}
+
+	rateLimitingClient := WrapRateLimiting(
+		client,
+		&rate.LimiterConfig{
+			ConcurrencyLimit:     1,
+			AcquirePermitTimeout: time.Second,
+			CallTimeout:          50 * time.Millisecond,
+		},
+	)
+
+	// Each call hangs until its context is done. Without the call timeout,
+	// the first call would hold the only available permit indefinitely and
+	// the next one could not acquire it.
+	for i := 0; i < 2; i++ {
+		_, err := rateLimitingClient.CallContract(
+			context.Background(),
+			ethereum.CallMsg{},
+			nil,
+		)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf(
+				"unexpected error\nexpected: [%v]\nactual:   [%v]",
+				context.DeadlineExceeded,
+				err,
+			)
+		}
+	}
+}
+
+func TestRateLimiter_Utilization(t *testing.T) {
+	client := &mockHangingEthereumClient{
+		mockEthereumClient: &mockEthereumClient{
+			10 * time.Millisecond,
+			make([]string, 0),
+			sync.Mutex{},
+		},
+	}
+
+	rateLimitingClient := WrapRateLimiting(
+		client,
+		&rate.LimiterConfig{
+			ConcurrencyLimit:     2,
+			AcquirePermitTimeout: time.Second,
+		},
+	)
+
+	utilization, ok := rateLimitingClient.(RateLimitingUtilization)
+	if !ok {
+		t.Fatal("rate limiting client should expose the utilization")
+	}
+
+	waitForAvailablePermits := func(expected int) {
+		deadline := time.Now().Add(time.Second)
+		for utilization.AvailablePermits() != expected {
+			if time.Now().After(deadline) {
+				t.Fatalf(
+					"unexpected available permits\n"+
+						"expected: [%v]\nactual:   [%v]",
+					expected,
+					utilization.AvailablePermits(),
+				)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitForAvailablePermits(2)
+
+	// The call hangs and holds the permit until the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = rateLimitingClient.CallContract(ctx, ethereum.CallMsg{}, nil)
+	}()
+
+	waitForAvailablePermits(1)
+
+	cancel()
+	<-done
+
+	waitForAvailablePermits(2)
+}
+
+func TestRateLimiter_Reconfiguration(t *testing.T) {
+	client := &mockEthereumClient{
+		10 * time.Millisecond,
+		make([]string, 0),
+		sync.Mutex{},
+	}
+
+	rateLimitingClient := WrapRateLimiting(
+		client,
+		&rate.LimiterConfig{
+			ConcurrencyLimit:     2,
+			AcquirePermitTimeout: time.Second,
+		},
+	)
+
+	reconfiguration, ok := rateLimitingClient.(RateLimitingReconfiguration)
+	if !ok {
+		t.Fatal("rate limiting client should allow to change the limits")
+	}
+
+	reconfiguration.SetConcurrencyLimit(5)
+
+	utilization := rateLimitingClient.(RateLimitingUtilization)
+	if available := utilization.AvailablePermits(); available != 5 {
+		t.Fatalf(
+			"unexpected available permits\nexpected: [%v]\nactual:   [%v]",
+			5,
+			available,
+		)
+	}
+
+	reconfiguration.SetRequestsPerSecond(100)
+
+	if tokens := utilization.TokensAvailable(); tokens != 1 {
+		t.Fatalf(
+			"unexpected available tokens\nexpected: [%v]\nactual:   [%v]",
+			1,
+			tokens,
+		)
+	}
+}
+
 type mockEthereumClient struct {
 	requestDuration time.Duration
 
@@ -488,6 +722,20 @@ func (mec *mockEthereumClient) BalanceAt(
 	return nil, nil
 }
 
+type mockSubscribingEthereumClient struct {
+	*mockEthereumClient
+
+	onSubscribe func(ctx context.Context)
+}
+
+func (msec *mockSubscribingEthereumClient) SubscribeNewHead(
+	ctx context.Context,
+	ch chan<- *types.Header,
+) (ethereum.Subscription, error) {
+	msec.onSubscribe(ctx)
+	return nil, nil
+}
+
 func getTests(
 	client EthereumClient,
 ) map[string]struct{ function func() error } {
@@ -677,3 +925,16 @@ func getTests(
 		},
 	}
 }
+
+type mockHangingEthereumClient struct {
+	*mockEthereumClient
+}
+
+func (mhec *mockHangingEthereumClient) CallContract(
+	ctx context.Context,
+	call ethereum.CallMsg,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
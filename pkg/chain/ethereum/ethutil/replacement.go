@@ -0,0 +1,160 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// replacementPriceBumpPercent is the minimum price bump, in percents, required
+// for a transaction replacement to be accepted by miners as mentioned in:
+// https://github.com/ethereum/go-ethereum/pull/22898/files#r636583352.
+const replacementPriceBumpPercent = 10
+
+// cancellationGasLimit is the gas limit of the zero-value self-transfer used
+// to cancel a pending transaction.
+const cancellationGasLimit = 21000
+
+// MinReplacementGas returns the minimum gas price parameters a transaction
+// replacing the given one must meet to be accepted by miners. For
+// transactions priced with a gas price (legacy and access list transactions)
+// only the gas price is returned and the gas fee cap and gas tip cap are nil.
+// For dynamic fee transactions the gas fee cap and gas tip cap are returned
+// and the gas price is nil.
+func MinReplacementGas(
+	old *types.Transaction,
+) (gasPrice, gasFeeCap, gasTipCap *big.Int) {
+	return minReplacementGas(old, old.Type() == types.DynamicFeeTxType)
+}
+
+// MinReplacementGasForOptions works just like MinReplacementGas but takes
+// into account the transactor options the given transaction has been
+// submitted with. Access list transactions submitted with a gas fee cap and
+// gas tip cap are replaced the same way as dynamic fee transactions, so
+// the gas fee cap and gas tip cap are returned for them.
+func MinReplacementGasForOptions(
+	old *types.Transaction,
+	transactorOptions *bind.TransactOpts,
+) (gasPrice, gasFeeCap, gasTipCap *big.Int) {
+	feeCapPriced := old.Type() == types.DynamicFeeTxType ||
+		(old.Type() == types.AccessListTxType &&
+			isDynamicFeePriced(transactorOptions))
+
+	return minReplacementGas(old, feeCapPriced)
+}
+
+// minReplacementGas returns the minimum gas price parameters of
+// the replacement of the given transaction. If the transaction is priced with
+// a gas fee cap and gas tip cap, the minimum gas fee cap and gas tip cap are
+// returned. Otherwise, the minimum gas price is returned.
+func minReplacementGas(
+	old *types.Transaction,
+	feeCapPriced bool,
+) (gasPrice, gasFeeCap, gasTipCap *big.Int) {
+	if feeCapPriced {
+		return nil,
+			minReplacementValue(old.GasFeeCap()),
+			minReplacementValue(old.GasTipCap())
+	}
+
+	return minReplacementValue(old.GasPrice()), nil, nil
+}
+
+// minReplacementValue returns the given value increased by
+// replacementPriceBumpPercent.
+func minReplacementValue(value *big.Int) *big.Int {
+	return new(big.Int).Add(
+		value,
+		new(big.Int).Div(
+			new(big.Int).Mul(value, big.NewInt(replacementPriceBumpPercent)),
+			big.NewInt(100),
+		),
+	)
+}
+
+// CancelTransaction cancels the pending original transaction by submitting
+// a zero-value transfer from the transactor to itself with the same nonce.
+// The gas price parameters of the cancellation transaction are the minimum
+// ones required by miners to replace the original transaction, as returned
+// by MinReplacementGas, unless the transactor options specify higher ones.
+// The cancellation transaction is a dynamic fee transaction if the original
+// one is and a legacy transaction otherwise. The submitted cancellation
+// transaction is returned so that the caller can wait for it to be mined.
+// The original transaction is not cancelled if it gets mined first.
+func CancelTransaction(
+	ctx context.Context,
+	client bind.ContractTransactor,
+	opts *bind.TransactOpts,
+	originalTx *types.Transaction,
+) (*types.Transaction, error) {
+	if opts.Signer == nil {
+		return nil, fmt.Errorf(
+			"no signer to authorize the cancellation transaction",
+		)
+	}
+
+	gasPrice, gasFeeCap, gasTipCap := MinReplacementGas(originalTx)
+
+	var cancellationTx *types.Transaction
+	switch originalTx.Type() {
+	case types.DynamicFeeTxType:
+		gasTipCap = maxValue(gasTipCap, opts.GasTipCap)
+		gasFeeCap = maxValue(maxValue(gasFeeCap, opts.GasFeeCap), gasTipCap)
+
+		cancellationTx = types.NewTx(&types.DynamicFeeTx{
+			Nonce:     originalTx.Nonce(),
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+			Gas:       cancellationGasLimit,
+			To:        &opts.From,
+			Value:     big.NewInt(0),
+		})
+	default:
+		cancellationTx = types.NewTx(&types.LegacyTx{
+			Nonce:    originalTx.Nonce(),
+			GasPrice: maxValue(gasPrice, opts.GasPrice),
+			Gas:      cancellationGasLimit,
+			To:       &opts.From,
+			Value:    big.NewInt(0),
+		})
+	}
+
+	signedTx, err := opts.Signer(opts.From, cancellationTx)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to sign cancellation transaction: [%v]",
+			err,
+		)
+	}
+
+	err = client.SendTransaction(ctx, signedTx)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to submit cancellation transaction for [%v]: [%v]",
+			originalTx.Hash().TerminalString(),
+			err,
+		)
+	}
+
+	logger.Infof(
+		"submitted cancellation transaction [%v] for transaction [%v] "+
+			"with nonce [%v]",
+		signedTx.Hash().TerminalString(),
+		originalTx.Hash().TerminalString(),
+		originalTx.Nonce(),
+	)
+
+	return signedTx, nil
+}
+
+// maxValue returns the greater of the given values; nil values are ignored.
+func maxValue(a, b *big.Int) *big.Int {
+	if b == nil || (a != nil && a.Cmp(b) >= 0) {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,286 @@
+package ethutil
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestMinReplacementGas_Legacy(t *testing.T) {
+	transaction := createLegacyTransaction(big.NewInt(20000000000)) // 20 Gwei
+
+	gasPrice, gasFeeCap, gasTipCap := MinReplacementGas(transaction)
+
+	expectedGasPrice := big.NewInt(22000000000) // + 10%
+	if gasPrice.Cmp(expectedGasPrice) != 0 {
+		t.Errorf(
+			"unexpected gas price\nexpected: [%v]\nactual:   [%v]",
+			expectedGasPrice,
+			gasPrice,
+		)
+	}
+
+	if gasFeeCap != nil || gasTipCap != nil {
+		t.Errorf("gas fee cap and gas tip cap should be nil")
+	}
+}
+
+func TestMinReplacementGas_AccessList(t *testing.T) {
+	transaction := types.NewTx(&types.AccessListTx{
+		GasPrice: big.NewInt(15), // rounded down after the bump
+		Gas:      25000,
+	})
+
+	gasPrice, gasFeeCap, gasTipCap := MinReplacementGas(transaction)
+
+	expectedGasPrice := big.NewInt(16) // + 10%
+	if gasPrice.Cmp(expectedGasPrice) != 0 {
+		t.Errorf(
+			"unexpected gas price\nexpected: [%v]\nactual:   [%v]",
+			expectedGasPrice,
+			gasPrice,
+		)
+	}
+
+	if gasFeeCap != nil || gasTipCap != nil {
+		t.Errorf("gas fee cap and gas tip cap should be nil")
+	}
+}
+
+func TestMinReplacementGasForOptions_AccessList_GasFeeCap(t *testing.T) {
+	transaction := createAccessListTransaction(big.NewInt(20000000000)) // 20 Gwei
+
+	gasPrice, gasFeeCap, gasTipCap := MinReplacementGasForOptions(
+		transaction,
+		&bind.TransactOpts{
+			GasFeeCap: big.NewInt(20000000000), // 20 Gwei
+			GasTipCap: big.NewInt(20000000000), // 20 Gwei
+		},
+	)
+
+	if gasPrice != nil {
+		t.Errorf("gas price should be nil")
+	}
+
+	expectedGasFeeCap := big.NewInt(22000000000) // + 10%
+	if gasFeeCap.Cmp(expectedGasFeeCap) != 0 {
+		t.Errorf(
+			"unexpected gas fee cap\nexpected: [%v]\nactual:   [%v]",
+			expectedGasFeeCap,
+			gasFeeCap,
+		)
+	}
+
+	expectedGasTipCap := big.NewInt(22000000000) // + 10%
+	if gasTipCap.Cmp(expectedGasTipCap) != 0 {
+		t.Errorf(
+			"unexpected gas tip cap\nexpected: [%v]\nactual:   [%v]",
+			expectedGasTipCap,
+			gasTipCap,
+		)
+	}
+}
+
+func TestMinReplacementGasForOptions_AccessList_GasPrice(t *testing.T) {
+	transaction := createAccessListTransaction(big.NewInt(20000000000)) // 20 Gwei
+
+	gasPrice, gasFeeCap, gasTipCap := MinReplacementGasForOptions(
+		transaction,
+		&bind.TransactOpts{GasPrice: big.NewInt(20000000000)}, // 20 Gwei
+	)
+
+	expectedGasPrice := big.NewInt(22000000000) // + 10%
+	if gasPrice.Cmp(expectedGasPrice) != 0 {
+		t.Errorf(
+			"unexpected gas price\nexpected: [%v]\nactual:   [%v]",
+			expectedGasPrice,
+			gasPrice,
+		)
+	}
+
+	if gasFeeCap != nil || gasTipCap != nil {
+		t.Errorf("gas fee cap and gas tip cap should be nil")
+	}
+}
+
+func TestMinReplacementGas_DynamicFee(t *testing.T) {
+	transaction := createDynamicFeeTransaction(
+		big.NewInt(24000000000), // 24 Gwei
+		big.NewInt(4000000000),  // 4 Gwei
+	)
+
+	gasPrice, gasFeeCap, gasTipCap := MinReplacementGas(transaction)
+
+	if gasPrice != nil {
+		t.Errorf("gas price should be nil")
+	}
+
+	expectedGasFeeCap := big.NewInt(26400000000) // + 10%
+	if gasFeeCap.Cmp(expectedGasFeeCap) != 0 {
+		t.Errorf(
+			"unexpected gas fee cap\nexpected: [%v]\nactual:   [%v]",
+			expectedGasFeeCap,
+			gasFeeCap,
+		)
+	}
+
+	expectedGasTipCap := big.NewInt(4400000000) // + 10%
+	if gasTipCap.Cmp(expectedGasTipCap) != 0 {
+		t.Errorf(
+			"unexpected gas tip cap\nexpected: [%v]\nactual:   [%v]",
+			expectedGasTipCap,
+			gasTipCap,
+		)
+	}
+}
+
+func TestCancelTransaction(t *testing.T) {
+	var tests = map[string]struct {
+		originalTx        *types.Transaction
+		optsGasPrice      *big.Int
+		expectedType      uint8
+		expectedGasPrice  *big.Int
+		expectedGasFeeCap *big.Int
+		expectedGasTipCap *big.Int
+	}{
+		"legacy transaction": {
+			originalTx:        createLegacyTransaction(big.NewInt(20000000000)),
+			expectedType:      types.LegacyTxType,
+			expectedGasPrice:  big.NewInt(22000000000),
+			expectedGasFeeCap: big.NewInt(22000000000),
+			expectedGasTipCap: big.NewInt(22000000000),
+		},
+		"legacy transaction with higher options gas price": {
+			originalTx:        createLegacyTransaction(big.NewInt(20000000000)),
+			optsGasPrice:      big.NewInt(30000000000),
+			expectedType:      types.LegacyTxType,
+			expectedGasPrice:  big.NewInt(30000000000),
+			expectedGasFeeCap: big.NewInt(30000000000),
+			expectedGasTipCap: big.NewInt(30000000000),
+		},
+		"dynamic fee transaction": {
+			originalTx: createDynamicFeeTransaction(
+				big.NewInt(24000000000),
+				big.NewInt(4000000000),
+			),
+			expectedType:      types.DynamicFeeTxType,
+			expectedGasPrice:  big.NewInt(26400000000),
+			expectedGasFeeCap: big.NewInt(26400000000),
+			expectedGasTipCap: big.NewInt(4400000000),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			key, err := crypto.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			chainID := big.NewInt(1337)
+			opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts.GasPrice = test.optsGasPrice
+
+			client := &mockSendingEthereumClient{
+				mockEthereumClient: &mockEthereumClient{},
+			}
+
+			cancellationTx, err := CancelTransaction(
+				context.Background(),
+				client,
+				opts,
+				test.originalTx,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if client.sentTx != cancellationTx {
+				t.Fatalf("cancellation transaction has not been submitted")
+			}
+
+			sender, err := types.Sender(
+				types.LatestSignerForChainID(chainID),
+				cancellationTx,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if sender != opts.From || *cancellationTx.To() != opts.From {
+				t.Errorf(
+					"unexpected transfer\nexpected: [%v -> %v]\nactual:   [%v -> %v]",
+					opts.From,
+					opts.From,
+					sender,
+					cancellationTx.To(),
+				)
+			}
+
+			if cancellationTx.Value().Sign() != 0 {
+				t.Errorf("unexpected value: [%v]", cancellationTx.Value())
+			}
+
+			if cancellationTx.Nonce() != test.originalTx.Nonce() {
+				t.Errorf(
+					"unexpected nonce\nexpected: [%v]\nactual:   [%v]",
+					test.originalTx.Nonce(),
+					cancellationTx.Nonce(),
+				)
+			}
+
+			if cancellationTx.Type() != test.expectedType {
+				t.Errorf(
+					"unexpected type\nexpected: [%v]\nactual:   [%v]",
+					test.expectedType,
+					cancellationTx.Type(),
+				)
+			}
+
+			if cancellationTx.GasPrice().Cmp(test.expectedGasPrice) != 0 {
+				t.Errorf(
+					"unexpected gas price\nexpected: [%v]\nactual:   [%v]",
+					test.expectedGasPrice,
+					cancellationTx.GasPrice(),
+				)
+			}
+
+			if cancellationTx.GasFeeCap().Cmp(test.expectedGasFeeCap) != 0 {
+				t.Errorf(
+					"unexpected gas fee cap\nexpected: [%v]\nactual:   [%v]",
+					test.expectedGasFeeCap,
+					cancellationTx.GasFeeCap(),
+				)
+			}
+
+			if cancellationTx.GasTipCap().Cmp(test.expectedGasTipCap) != 0 {
+				t.Errorf(
+					"unexpected gas tip cap\nexpected: [%v]\nactual:   [%v]",
+					test.expectedGasTipCap,
+					cancellationTx.GasTipCap(),
+				)
+			}
+		})
+	}
+}
+
+type mockSendingEthereumClient struct {
+	*mockEthereumClient
+
+	sentTx *types.Transaction
+}
+
+func (msec *mockSendingEthereumClient) SendTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) error {
+	msec.sentTx = tx
+	return nil
+}
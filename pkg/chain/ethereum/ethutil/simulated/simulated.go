@@ -0,0 +1,136 @@
+// Package simulated provides an in-process simulated Ethereum chain
+// satisfying the ethutil.EthereumClient interface, so that the generated
+// contract bindings and the ethutil utilities can be tested without
+// a running Ethereum node.
+package simulated
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+)
+
+// DefaultGasLimit is the default gas limit of the simulated chain blocks.
+const DefaultGasLimit = 8000000
+
+// burnCode is the init code of a contract creation looping forever. Such
+// a transaction consumes all the gas it has been given.
+var burnCode = common.FromHex("0x5b600056") // JUMPDEST PUSH1 0x00 JUMP
+
+// burnerBalance is the balance of the account paying for the transactions
+// consuming the block gas in order to raise the base fee.
+var burnerBalance = new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)
+
+// Backend is a simulated Ethereum chain. Transactions submitted to the chain
+// are included in the pending block which is mined with Commit or MineBlocks.
+type Backend struct {
+	*backends.SimulatedBackend
+
+	burnerKey *ecdsa.PrivateKey
+}
+
+var _ ethutil.EthereumClient = (*Backend)(nil)
+
+// NewBackend creates a simulated chain with the given genesis allocation
+// and the given gas limit of blocks.
+func NewBackend(alloc core.GenesisAlloc, gasLimit uint64) (*Backend, error) {
+	burnerKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate burner key: [%v]", err)
+	}
+
+	genesisAlloc := make(core.GenesisAlloc, len(alloc)+1)
+	for address, account := range alloc {
+		genesisAlloc[address] = account
+	}
+	genesisAlloc[crypto.PubkeyToAddress(burnerKey.PublicKey)] = core.GenesisAccount{
+		Balance: burnerBalance,
+	}
+
+	return &Backend{
+		SimulatedBackend: backends.NewSimulatedBackend(genesisAlloc, gasLimit),
+		burnerKey:        burnerKey,
+	}, nil
+}
+
+// ChainID returns the identifier of the simulated chain.
+func (b *Backend) ChainID(ctx context.Context) (*big.Int, error) {
+	return b.Blockchain().Config().ChainID, nil
+}
+
+// MineBlocks mines the given number of blocks. The pending transactions are
+// included in the first mined block.
+func (b *Backend) MineBlocks(count int) {
+	for i := 0; i < count; i++ {
+		b.Commit()
+	}
+}
+
+// BaseFee returns the base fee of the latest block.
+func (b *Backend) BaseFee() *big.Int {
+	return b.Blockchain().CurrentHeader().BaseFee
+}
+
+// NextBaseFee returns the base fee of the pending block.
+func (b *Backend) NextBaseFee() *big.Int {
+	return misc.CalcBaseFee(
+		b.Blockchain().Config(),
+		b.Blockchain().CurrentHeader(),
+	)
+}
+
+// RaiseBaseFee mines the given number of blocks whose entire gas is consumed,
+// so that the base fee grows by 12.5% with each of them. It lets the dynamic
+// fee transactions be outbid by the base fee. RaiseBaseFee must be called
+// when there are no pending transactions.
+func (b *Backend) RaiseBaseFee(blocks int) error {
+	ctx := context.Background()
+
+	chainID, err := b.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	signer := types.LatestSignerForChainID(chainID)
+	burner := crypto.PubkeyToAddress(b.burnerKey.PublicKey)
+
+	for i := 0; i < blocks; i++ {
+		nonce, err := b.PendingNonceAt(ctx, burner)
+		if err != nil {
+			return fmt.Errorf("could not get burner nonce: [%v]", err)
+		}
+
+		transaction, err := types.SignNewTx(
+			b.burnerKey,
+			signer,
+			&types.DynamicFeeTx{
+				ChainID:   chainID,
+				Nonce:     nonce,
+				GasTipCap: big.NewInt(0),
+				GasFeeCap: new(big.Int).Mul(b.NextBaseFee(), big.NewInt(2)),
+				Gas:       b.Blockchain().CurrentHeader().GasLimit,
+				Data:      burnCode,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("could not sign burn transaction: [%v]", err)
+		}
+
+		if err := b.SendTransaction(ctx, transaction); err != nil {
+			return fmt.Errorf("could not send burn transaction: [%v]", err)
+		}
+
+		b.Commit()
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package simulated
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestMineBlocks(t *testing.T) {
+	backend, err := NewBackend(core.GenesisAlloc{}, DefaultGasLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+
+	backend.MineBlocks(3)
+
+	block, err := backend.BlockByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if block.NumberU64() != 3 {
+		t.Errorf(
+			"unexpected block number\nexpected: [%v]\nactual:   [%v]",
+			3,
+			block.NumberU64(),
+		)
+	}
+}
+
+func TestRaiseBaseFee(t *testing.T) {
+	backend, err := NewBackend(core.GenesisAlloc{}, DefaultGasLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+
+	initialBaseFee := backend.NextBaseFee()
+
+	if err := backend.RaiseBaseFee(2); err != nil {
+		t.Fatal(err)
+	}
+
+	// The base fee grows by 12.5% with each block whose entire gas has been
+	// consumed.
+	expectedBaseFee := new(big.Int).Set(initialBaseFee)
+	for i := 0; i < 2; i++ {
+		expectedBaseFee.Add(expectedBaseFee, new(big.Int).Div(expectedBaseFee, big.NewInt(8)))
+	}
+
+	if expectedBaseFee.Cmp(backend.NextBaseFee()) != 0 {
+		t.Errorf(
+			"unexpected next base fee\nexpected: [%v]\nactual:   [%v]",
+			expectedBaseFee,
+			backend.NextBaseFee(),
+		)
+	}
+}
+
+func TestDynamicFeeTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := crypto.PubkeyToAddress(key.PublicKey)
+
+	backend, err := NewBackend(
+		core.GenesisAlloc{from: {Balance: big.NewInt(1e18)}},
+		DefaultGasLimit,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+
+	ctx := context.Background()
+
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction, err := types.SignNewTx(
+		key,
+		types.LatestSignerForChainID(chainID),
+		&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     0,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: new(big.Int).Mul(backend.NextBaseFee(), big.NewInt(2)),
+			Gas:       21000,
+			To:        &to,
+			Value:     big.NewInt(1),
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.SendTransaction(ctx, transaction); err != nil {
+		t.Fatal(err)
+	}
+	backend.MineBlocks(1)
+
+	receipt, err := backend.TransactionReceipt(ctx, transaction.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Errorf(
+			"unexpected receipt status\nexpected: [%v]\nactual:   [%v]",
+			types.ReceiptStatusSuccessful,
+			receipt.Status,
+		)
+	}
+}
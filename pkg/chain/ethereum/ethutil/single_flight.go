@@ -0,0 +1,260 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/sync/singleflight"
+)
+
+type singleFlight struct {
+	EthereumClient
+
+	group singleflight.Group
+}
+
+// WrapSingleFlight wraps the given client so that concurrent identical read
+// requests are coalesced into a single request to the node and all the
+// callers share its result. Requests are identical if they call the same
+// method with the same arguments. The coalesced request is executed with
+// a context detached from the callers' contexts, carrying only the values of
+// the context of the caller who started it, so that a caller giving up does
+// not fail the request for the other callers waiting for it. Each caller
+// stops waiting once its own context is done. The coalesced request is not
+// cancelled when all callers give up, so the wrapped client should enforce
+// a call timeout, as the rate limiter does. The shared results must not be
+// modified by the callers.
+//
+// Transaction submission and subscriptions are never coalesced. The wrapper
+// can be combined with the rate limiter to cut the redundant load on the
+// node; when wrapping the rate-limited client, coalesced requests acquire
+// a single permit.
+func WrapSingleFlight(client EthereumClient) EthereumClient {
+	return &singleFlight{EthereumClient: client}
+}
+
+// coalesce executes the given function unless an identical request, as
+// determined by the method and the arguments, is already in flight, in which
+// case it waits for that request and returns its result. The function is
+// executed with a context detached from the caller's context. The caller
+// stops waiting for the result and returns the context error once the
+// provided context is done.
+func coalesce[T any](
+	ctx context.Context,
+	sf *singleFlight,
+	fn func(ctx context.Context) (T, error),
+	method string,
+	args ...interface{},
+) (T, error) {
+	key := method + fmt.Sprintln(args...)
+
+	resultChan := sf.group.DoChan(key, func() (interface{}, error) {
+		return fn(detachedContext{ctx})
+	})
+
+	var zero T
+	select {
+	case result := <-resultChan:
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// detachedContext carries the values of the parent context but is never
+// cancelled and has no deadline.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (dc detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (dc detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (dc detachedContext) Err() error {
+	return nil
+}
+
+func (dc detachedContext) Value(key interface{}) interface{} {
+	return dc.parent.Value(key)
+}
+
+func (sf *singleFlight) CodeAt(
+	ctx context.Context,
+	contract common.Address,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) ([]byte, error) {
+		return sf.EthereumClient.CodeAt(ctx, contract, blockNumber)
+	}, "CodeAt", contract, blockNumber)
+}
+
+func (sf *singleFlight) CallContract(
+	ctx context.Context,
+	call ethereum.CallMsg,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) ([]byte, error) {
+		return sf.EthereumClient.CallContract(ctx, call, blockNumber)
+	}, "CallContract", call, blockNumber)
+}
+
+func (sf *singleFlight) PendingCodeAt(
+	ctx context.Context,
+	account common.Address,
+) ([]byte, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) ([]byte, error) {
+		return sf.EthereumClient.PendingCodeAt(ctx, account)
+	}, "PendingCodeAt", account)
+}
+
+func (sf *singleFlight) PendingNonceAt(
+	ctx context.Context,
+	account common.Address,
+) (uint64, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) (uint64, error) {
+		return sf.EthereumClient.PendingNonceAt(ctx, account)
+	}, "PendingNonceAt", account)
+}
+
+func (sf *singleFlight) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) (*big.Int, error) {
+		return sf.EthereumClient.SuggestGasPrice(ctx)
+	}, "SuggestGasPrice")
+}
+
+func (sf *singleFlight) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) (*big.Int, error) {
+		return sf.EthereumClient.SuggestGasTipCap(ctx)
+	}, "SuggestGasTipCap")
+}
+
+func (sf *singleFlight) EstimateGas(
+	ctx context.Context,
+	call ethereum.CallMsg,
+) (uint64, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) (uint64, error) {
+		return sf.EthereumClient.EstimateGas(ctx, call)
+	}, "EstimateGas", call)
+}
+
+func (sf *singleFlight) FilterLogs(
+	ctx context.Context,
+	query ethereum.FilterQuery,
+) ([]types.Log, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) ([]types.Log, error) {
+		return sf.EthereumClient.FilterLogs(ctx, query)
+	}, "FilterLogs", query)
+}
+
+func (sf *singleFlight) BlockByHash(
+	ctx context.Context,
+	hash common.Hash,
+) (*types.Block, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) (*types.Block, error) {
+		return sf.EthereumClient.BlockByHash(ctx, hash)
+	}, "BlockByHash", hash)
+}
+
+func (sf *singleFlight) BlockByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*types.Block, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) (*types.Block, error) {
+		return sf.EthereumClient.BlockByNumber(ctx, number)
+	}, "BlockByNumber", number)
+}
+
+func (sf *singleFlight) HeaderByHash(
+	ctx context.Context,
+	hash common.Hash,
+) (*types.Header, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) (*types.Header, error) {
+		return sf.EthereumClient.HeaderByHash(ctx, hash)
+	}, "HeaderByHash", hash)
+}
+
+func (sf *singleFlight) HeaderByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*types.Header, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) (*types.Header, error) {
+		return sf.EthereumClient.HeaderByNumber(ctx, number)
+	}, "HeaderByNumber", number)
+}
+
+func (sf *singleFlight) TransactionCount(
+	ctx context.Context,
+	blockHash common.Hash,
+) (uint, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) (uint, error) {
+		return sf.EthereumClient.TransactionCount(ctx, blockHash)
+	}, "TransactionCount", blockHash)
+}
+
+func (sf *singleFlight) TransactionInBlock(
+	ctx context.Context,
+	blockHash common.Hash,
+	index uint,
+) (*types.Transaction, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) (*types.Transaction, error) {
+		return sf.EthereumClient.TransactionInBlock(ctx, blockHash, index)
+	}, "TransactionInBlock", blockHash, index)
+}
+
+func (sf *singleFlight) TransactionByHash(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Transaction, bool, error) {
+	type result struct {
+		tx        *types.Transaction
+		isPending bool
+	}
+
+	r, err := coalesce(ctx, sf, func(ctx context.Context) (result, error) {
+		tx, isPending, err := sf.EthereumClient.TransactionByHash(ctx, txHash)
+		return result{tx, isPending}, err
+	}, "TransactionByHash", txHash)
+
+	return r.tx, r.isPending, err
+}
+
+func (sf *singleFlight) TransactionReceipt(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Receipt, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) (*types.Receipt, error) {
+		return sf.EthereumClient.TransactionReceipt(ctx, txHash)
+	}, "TransactionReceipt", txHash)
+}
+
+func (sf *singleFlight) BalanceAt(
+	ctx context.Context,
+	account common.Address,
+	blockNumber *big.Int,
+) (*big.Int, error) {
+	return coalesce(ctx, sf, func(ctx context.Context) (*big.Int, error) {
+		return sf.EthereumClient.BalanceAt(ctx, account, blockNumber)
+	}, "BalanceAt", account, blockNumber)
+}
+
+// SubscribePendingTransactions is never coalesced as each subscriber needs
+// its own subscription.
+func (sf *singleFlight) SubscribePendingTransactions(
+	ctx context.Context,
+	ch chan<- common.Hash,
+) (ethereum.Subscription, error) {
+	return SubscribePendingTransactions(ctx, sf.EthereumClient, ch)
+}
@@ -0,0 +1,198 @@
+package ethutil
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSingleFlight(t *testing.T) {
+	const concurrentCalls = 10
+
+	var tests = map[string]struct {
+		callFn                  func(client EthereumClient, i int) error
+		expectedUnderlyingCalls int
+	}{
+		"identical reads": {
+			callFn: func(client EthereumClient, i int) error {
+				_, err := client.CodeAt(
+					context.Background(),
+					common.HexToAddress("0x1"),
+					big.NewInt(100),
+				)
+				return err
+			},
+			expectedUnderlyingCalls: 1,
+		},
+		"reads with different arguments": {
+			callFn: func(client EthereumClient, i int) error {
+				_, err := client.CodeAt(
+					context.Background(),
+					common.HexToAddress("0x1"),
+					big.NewInt(int64(i)),
+				)
+				return err
+			},
+			expectedUnderlyingCalls: concurrentCalls,
+		},
+		"transaction submissions": {
+			callFn: func(client EthereumClient, i int) error {
+				return client.SendTransaction(
+					context.Background(),
+					createLegacyTransaction(big.NewInt(1)),
+				)
+			},
+			expectedUnderlyingCalls: concurrentCalls,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			underlyingClient := &mockEthereumClient{
+				requestDuration: 100 * time.Millisecond,
+			}
+			client := WrapSingleFlight(underlyingClient)
+
+			var wg sync.WaitGroup
+			wg.Add(concurrentCalls)
+
+			for i := 0; i < concurrentCalls; i++ {
+				go func(i int) {
+					defer wg.Done()
+
+					if err := test.callFn(client, i); err != nil {
+						t.Error(err)
+					}
+				}(i)
+			}
+
+			wg.Wait()
+
+			underlyingCalls := 0
+			for _, event := range underlyingClient.events {
+				if event == "start" {
+					underlyingCalls++
+				}
+			}
+
+			if underlyingCalls != test.expectedUnderlyingCalls {
+				t.Errorf(
+					"unexpected number of underlying calls\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedUnderlyingCalls,
+					underlyingCalls,
+				)
+			}
+		})
+	}
+}
+
+func TestSingleFlight_TransactionByHash(t *testing.T) {
+	client := WrapSingleFlight(&mockPendingTransactionEthereumClient{
+		mockEthereumClient: &mockEthereumClient{},
+		tx:                 createLegacyTransaction(big.NewInt(1)),
+	})
+
+	tx, isPending, err := client.TransactionByHash(
+		context.Background(),
+		common.Hash{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tx == nil || !isPending {
+		t.Errorf(
+			"unexpected result\nexpected: [pending transaction]\n"+
+				"actual:   [%v, pending: %v]",
+			tx,
+			isPending,
+		)
+	}
+}
+
+func TestSingleFlight_CallerContextCancelled(t *testing.T) {
+	client := WrapSingleFlight(&mockContextAwareEthereumClient{
+		mockEthereumClient: &mockEthereumClient{},
+		requestDuration:    100 * time.Millisecond,
+	})
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+
+	firstErrChan := make(chan error, 1)
+	go func() {
+		_, err := client.CodeAt(ctx, common.HexToAddress("0x1"), nil)
+		firstErrChan <- err
+	}()
+
+	// Give the first call the time to start the coalesced request.
+	time.Sleep(10 * time.Millisecond)
+
+	secondErrChan := make(chan error, 1)
+	go func() {
+		_, err := client.CodeAt(
+			context.Background(),
+			common.HexToAddress("0x1"),
+			nil,
+		)
+		secondErrChan <- err
+	}()
+
+	// Give the second call the time to join the coalesced request.
+	time.Sleep(10 * time.Millisecond)
+
+	cancelCtx()
+
+	if err := <-firstErrChan; err != context.Canceled {
+		t.Errorf(
+			"unexpected first call error\nexpected: [%v]\nactual:   [%v]",
+			context.Canceled,
+			err,
+		)
+	}
+
+	if err := <-secondErrChan; err != nil {
+		t.Errorf(
+			"unexpected second call error\nexpected: [%v]\nactual:   [%v]",
+			nil,
+			err,
+		)
+	}
+}
+
+type mockContextAwareEthereumClient struct {
+	*mockEthereumClient
+
+	requestDuration time.Duration
+}
+
+func (mcaec *mockContextAwareEthereumClient) CodeAt(
+	ctx context.Context,
+	contract common.Address,
+	blockNumber *big.Int,
+) ([]byte, error) {
+	select {
+	case <-time.After(mcaec.requestDuration):
+		return []byte{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type mockPendingTransactionEthereumClient struct {
+	*mockEthereumClient
+
+	tx *types.Transaction
+}
+
+func (mptec *mockPendingTransactionEthereumClient) TransactionByHash(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Transaction, bool, error) {
+	return mptec.tx, true, nil
+}
@@ -0,0 +1,130 @@
+package ethutil
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// CancellationCallback is called by the mining waiter after it attempted to
+// cancel a stuck transaction. The reason is the GaveUp constant the mining
+// waiter gave up on the transaction with, the stuck transaction is the last
+// one submitted by the mining waiter and the cancellation transaction is
+// the submitted zero-value self-transfer replacing it. If the cancellation
+// failed, the cancellation transaction is nil and the error is set.
+type CancellationCallback func(
+	reason string,
+	stuckTx *types.Transaction,
+	cancellationTx *types.Transaction,
+	err error,
+)
+
+// stuckTransactionCancellation is the policy of cancelling the transactions
+// the mining waiter gave up on.
+type stuckTransactionCancellation struct {
+	reasons  map[string]bool
+	callback CancellationCallback
+}
+
+// reasonList returns the sorted reasons the stuck transactions are cancelled
+// for.
+func (stc *stuckTransactionCancellation) reasonList() []string {
+	reasons := make([]string, 0, len(stc.reasons))
+	for reason := range stc.reasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	return reasons
+}
+
+// WithStuckTransactionCancellation enables the automated recovery of stuck
+// transactions. When the mining waiter gives up on a transaction for one of
+// the given reasons, it cancels the last submitted transaction with
+// CancelTransaction, freeing its nonce for subsequent transactions. If no
+// reasons are given, the transactions are cancelled when the max gas fee cap
+// has been reached, the replacement threshold is above the max gas fee cap,
+// or the force mining timeout has passed. The callback, if
+// set, is notified about each cancellation attempt.
+//
+// The cancellation transaction is priced just above the stuck transaction, as
+// required by miners to replace it, so it may exceed the max gas fee cap.
+// The cancellation is submitted with the signer of the original transactor
+// options and it is not attempted if the force mining has been aborted by
+// the caller.
+func WithStuckTransactionCancellation(
+	callback CancellationCallback,
+	reasons ...string,
+) MiningWaiterOption {
+	if len(reasons) == 0 {
+		reasons = []string{
+			GaveUpMaxGasFeeCapReached,
+			GaveUpThresholdUnsatisfiable,
+			GaveUpForceMiningTimeout,
+		}
+	}
+
+	cancellation := &stuckTransactionCancellation{
+		reasons:  make(map[string]bool, len(reasons)),
+		callback: callback,
+	}
+	for _, reason := range reasons {
+		cancellation.reasons[reason] = true
+	}
+
+	return func(mw *MiningWaiter) {
+		mw.stuckTransactionCancellation = cancellation
+	}
+}
+
+type stuckTransactionRecordKey struct{}
+
+// stuckTransactionRecord records the reason and the last submitted
+// transaction of a force mining operation the mining waiter gave up on.
+type stuckTransactionRecord struct {
+	reason string
+	lastTx *types.Transaction
+}
+
+// cancelStuckTransaction cancels the transaction recorded in the given stuck
+// transaction record if the mining waiter gave up on it for one of the reasons
+// configured with WithStuckTransactionCancellation.
+func (mw *MiningWaiter) cancelStuckTransaction(
+	ctx context.Context,
+	record *stuckTransactionRecord,
+	transactorOptions *bind.TransactOpts,
+) {
+	if record.lastTx == nil ||
+		!mw.stuckTransactionCancellation.reasons[record.reason] ||
+		ctx.Err() != nil {
+		return
+	}
+
+	txLogger := loggerFor(ctx)
+
+	txLogger.Infof(
+		"cancelling stuck transaction [%v] given up with reason [%v]",
+		record.lastTx.Hash().TerminalString(),
+		record.reason,
+	)
+
+	cancellationTx, err := CancelTransaction(
+		ctx,
+		mw.client,
+		transactorOptions,
+		record.lastTx,
+	)
+	if err != nil {
+		txLogger.Errorf(
+			"could not cancel stuck transaction [%v]: [%v]",
+			record.lastTx.Hash().TerminalString(),
+			err,
+		)
+	}
+
+	if callback := mw.stuckTransactionCancellation.callback; callback != nil {
+		callback(record.reason, record.lastTx, cancellationTx, err)
+	}
+}
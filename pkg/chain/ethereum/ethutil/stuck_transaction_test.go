@@ -0,0 +1,224 @@
+package ethutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestForceMining_StuckTransactionCancellation(t *testing.T) {
+	var tests = map[string]struct {
+		reasons              []string
+		resubmitErr          error
+		mined                bool
+		expectedCancellation bool
+		expectedReason       string
+	}{
+		"mined": {
+			mined:                true,
+			expectedCancellation: false,
+		},
+		"replacement threshold above max allowed with default reasons": {
+			expectedCancellation: true,
+			expectedReason:       GaveUpThresholdUnsatisfiable,
+		},
+		"resubmission failed with default reasons": {
+			resubmitErr:          fmt.Errorf("insufficient funds"),
+			expectedCancellation: false,
+		},
+		"resubmission failed with configured reason": {
+			reasons:              []string{GaveUpResubmissionFailed},
+			resubmitErr:          fmt.Errorf("insufficient funds"),
+			expectedCancellation: true,
+			expectedReason:       GaveUpResubmissionFailed,
+		},
+		"replacement threshold above max allowed with other configured reason": {
+			reasons:              []string{GaveUpResubmissionFailed},
+			expectedCancellation: false,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			key, err := crypto.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			chainID := big.NewInt(1337)
+			transactorOptions, err := bind.NewKeyedTransactorWithChainID(
+				key,
+				chainID,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			transactorOptions.Nonce = big.NewInt(100)
+
+			chain := &mockCancellingEthereumClient{
+				mockAdaptedEthereumClientWithReceipt: &mockAdaptedEthereumClientWithReceipt{
+					mockAdaptedEthereumClient: &mockAdaptedEthereumClient{
+						mockEthereumClient: &mockEthereumClient{},
+					},
+				},
+			}
+			if test.mined {
+				chain.receipt = &types.Receipt{}
+			}
+
+			resubmitFn := func(
+				newTransactorOptions *bind.TransactOpts,
+			) (*types.Transaction, error) {
+				if test.resubmitErr != nil {
+					return nil, test.resubmitErr
+				}
+
+				// Not setting mockBackend.receipt, mining takes a very
+				// long time.
+				return createLegacyTransaction(newTransactorOptions.GasPrice), nil
+			}
+
+			var cancellations int
+			var reason string
+			var stuckTx, cancellationTx *types.Transaction
+
+			waiter := NewMiningWaiter(
+				chain,
+				config,
+				WithStuckTransactionCancellation(
+					func(
+						callbackReason string,
+						callbackStuckTx *types.Transaction,
+						callbackCancellationTx *types.Transaction,
+						err error,
+					) {
+						if err != nil {
+							t.Errorf("unexpected cancellation error: [%v]", err)
+						}
+
+						cancellations++
+						reason = callbackReason
+						stuckTx = callbackStuckTx
+						cancellationTx = callbackCancellationTx
+					},
+					test.reasons...,
+				),
+			)
+
+			err = waiter.ForceMining(
+				createLegacyTransaction(big.NewInt(20000000000)), // 20 Gwei
+				transactorOptions,
+				resubmitFn,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !test.expectedCancellation {
+				if cancellations != 0 || len(chain.sentTxs) != 0 {
+					t.Fatalf("unexpected cancellation of [%v]", reason)
+				}
+				return
+			}
+
+			if cancellations != 1 {
+				t.Fatalf(
+					"unexpected number of cancellations\n"+
+						"expected: [%v]\nactual:   [%v]",
+					1,
+					cancellations,
+				)
+			}
+
+			if reason != test.expectedReason {
+				t.Errorf(
+					"unexpected reason\nexpected: [%v]\nactual:   [%v]",
+					test.expectedReason,
+					reason,
+				)
+			}
+
+			if len(chain.sentTxs) != 1 || chain.sentTxs[0] != cancellationTx {
+				t.Fatalf("cancellation transaction has not been submitted")
+			}
+
+			if cancellationTx.Nonce() != stuckTx.Nonce() {
+				t.Errorf(
+					"unexpected nonce\nexpected: [%v]\nactual:   [%v]",
+					stuckTx.Nonce(),
+					cancellationTx.Nonce(),
+				)
+			}
+
+			if *cancellationTx.To() != transactorOptions.From ||
+				cancellationTx.Value().Sign() != 0 {
+				t.Errorf(
+					"unexpected cancellation transfer of [%v] to [%v]",
+					cancellationTx.Value(),
+					cancellationTx.To(),
+				)
+			}
+		})
+	}
+}
+
+func TestForceMining_StuckTransactionCancellationFailed(t *testing.T) {
+	chain := &mockAdaptedEthereumClientWithReceipt{}
+
+	resubmitFn := func(
+		newTransactorOptions *bind.TransactOpts,
+	) (*types.Transaction, error) {
+		return nil, fmt.Errorf("insufficient funds")
+	}
+
+	var cancellationErr error
+	waiter := NewMiningWaiter(
+		chain,
+		config,
+		WithStuckTransactionCancellation(
+			func(
+				reason string,
+				stuckTx *types.Transaction,
+				cancellationTx *types.Transaction,
+				err error,
+			) {
+				cancellationErr = err
+			},
+			GaveUpResubmissionFailed,
+		),
+	)
+
+	// The original transactor options have no signer so the cancellation
+	// transaction can not be authorized.
+	err := waiter.ForceMining(
+		createLegacyTransaction(big.NewInt(20000000000)), // 20 Gwei
+		originalTransactorOptions,
+		resubmitFn,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cancellationErr == nil {
+		t.Errorf("expected cancellation error")
+	}
+}
+
+type mockCancellingEthereumClient struct {
+	*mockAdaptedEthereumClientWithReceipt
+
+	sentTxs []*types.Transaction
+}
+
+func (mcec *mockCancellingEthereumClient) SendTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) error {
+	mcec.sentTxs = append(mcec.sentTxs, tx)
+	return nil
+}
@@ -14,6 +14,11 @@ const (
 	// other value is provided in SubscribeOpts when creating the subscription.
 	DefaultSubscribeOptsPastBlocks = 100
 
+	// DefaultSubscribeOptsOrderingDelay is the default duration for which
+	// the ordered event handlers hold each received event if no other value
+	// is provided in SubscribeOpts when creating the subscription.
+	DefaultSubscribeOptsOrderingDelay = 5 * time.Second
+
 	// SubscriptionBackoffMax is the maximum backoff time between event
 	// resubscription attempts.
 	SubscriptionBackoffMax = 2 * time.Minute
@@ -29,4 +34,10 @@ const (
 	// to the logs alerting about potential problems with Ethereum client
 	// connection.
 	SubscriptionAlertThreshold = 15 * time.Minute
+
+	// PastEventsChunkSize is the number of blocks whose events are fetched
+	// in a single request when all past events since the contract deployment
+	// are fetched. Fetching events in chunks keeps the responses within
+	// the limits of Ethereum clients.
+	PastEventsChunkSize = 10000
 )
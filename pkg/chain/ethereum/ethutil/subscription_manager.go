@@ -0,0 +1,188 @@
+package ethutil
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// SubscriptionManager keeps track of the long-lived subscriptions created
+// through it so that all of them can be closed at once on shutdown. If
+// configured with a max subscription age, the manager also periodically
+// recycles the subscriptions, that is, establishes them again and closes
+// the old ones, so that resources leaked by providers on long-lived
+// connections, e.g. file descriptors of WebSocket subscriptions, are
+// released.
+type SubscriptionManager struct {
+	maxAge time.Duration
+
+	mutex         sync.Mutex
+	subscriptions map[uint64]*managedSubscription
+	nextID        uint64
+}
+
+// NewSubscriptionManager creates a new SubscriptionManager recycling
+// the subscriptions every maxAge. The subscriptions are never recycled if
+// maxAge is not positive.
+func NewSubscriptionManager(maxAge time.Duration) *SubscriptionManager {
+	return &SubscriptionManager{
+		maxAge:        maxAge,
+		subscriptions: make(map[uint64]*managedSubscription),
+	}
+}
+
+// Subscribe establishes a subscription with WithResubscription, using
+// the given parameters, and tracks it until it is unsubscribed. The returned
+// subscription stays valid when the manager recycles the underlying
+// subscription; its error channel is closed once the subscription is
+// unsubscribed directly or with UnsubscribeAll.
+func (sm *SubscriptionManager) Subscribe(
+	backoffMax time.Duration,
+	subscribeFn event.ResubscribeFunc,
+	alertThreshold time.Duration,
+	thresholdViolatedFn func(time.Duration),
+	subscriptionFailedFn func(error),
+) event.Subscription {
+	resubscribeFn := func() event.Subscription {
+		return WithResubscription(
+			backoffMax,
+			subscribeFn,
+			alertThreshold,
+			thresholdViolatedFn,
+			subscriptionFailedFn,
+		)
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	subscription := &managedSubscription{
+		manager:       sm,
+		id:            sm.nextID,
+		resubscribeFn: resubscribeFn,
+		current:       resubscribeFn(),
+		errChan:       make(chan error),
+	}
+	sm.nextID++
+	sm.subscriptions[subscription.id] = subscription
+
+	if sm.maxAge > 0 {
+		go subscription.recycleEvery(sm.maxAge)
+	}
+
+	return subscription
+}
+
+// ActiveSubscriptions returns the number of the tracked subscriptions that
+// have not been unsubscribed yet.
+func (sm *SubscriptionManager) ActiveSubscriptions() int {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	return len(sm.subscriptions)
+}
+
+// UnsubscribeAll unsubscribes all the tracked subscriptions. It should be
+// called on shutdown to gracefully close the subscriptions. Subscriptions can
+// still be created through the manager afterwards.
+func (sm *SubscriptionManager) UnsubscribeAll() {
+	sm.mutex.Lock()
+	subscriptions := make([]*managedSubscription, 0, len(sm.subscriptions))
+	for _, subscription := range sm.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	sm.mutex.Unlock()
+
+	for _, subscription := range subscriptions {
+		subscription.Unsubscribe()
+	}
+
+	logger.Infof("unsubscribed [%v] subscriptions", len(subscriptions))
+}
+
+// untrack stops tracking the subscription with the given identifier.
+func (sm *SubscriptionManager) untrack(id uint64) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	delete(sm.subscriptions, id)
+}
+
+// managedSubscription is a subscription tracked by the SubscriptionManager.
+// It delegates to the current underlying subscription which is replaced
+// whenever the subscription is recycled.
+type managedSubscription struct {
+	manager       *SubscriptionManager
+	id            uint64
+	resubscribeFn func() event.Subscription
+
+	mutex        sync.Mutex
+	current      event.Subscription
+	unsubscribed bool
+	errChan      chan error
+}
+
+// recycleEvery recycles the subscription in the given interval until it is
+// unsubscribed.
+func (ms *managedSubscription) recycleEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ms.errChan:
+			return
+		case <-ticker.C:
+			if !ms.recycle() {
+				return
+			}
+		}
+	}
+}
+
+// recycle establishes the subscription again and closes the previous
+// underlying subscription. The new subscription is established first so that
+// no events are missed while recycling; events delivered by both
+// subscriptions in the meantime may be received twice. It returns false if
+// the subscription has already been unsubscribed.
+func (ms *managedSubscription) recycle() bool {
+	ms.mutex.Lock()
+	if ms.unsubscribed {
+		ms.mutex.Unlock()
+		return false
+	}
+
+	previous := ms.current
+	ms.current = ms.resubscribeFn()
+	ms.mutex.Unlock()
+
+	previous.Unsubscribe()
+
+	logger.Debugf("recycled subscription [%v]", ms.id)
+
+	return true
+}
+
+// Unsubscribe closes the underlying subscription, stops tracking
+// the subscription and closes its error channel. It is safe to call it
+// multiple times.
+func (ms *managedSubscription) Unsubscribe() {
+	ms.mutex.Lock()
+	if ms.unsubscribed {
+		ms.mutex.Unlock()
+		return
+	}
+	ms.unsubscribed = true
+	current := ms.current
+	ms.mutex.Unlock()
+
+	current.Unsubscribe()
+	ms.manager.untrack(ms.id)
+	close(ms.errChan)
+}
+
+// Err returns the channel closed when the subscription is unsubscribed.
+func (ms *managedSubscription) Err() <-chan error {
+	return ms.errChan
+}
@@ -0,0 +1,175 @@
+package ethutil
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/event"
+)
+
+func TestSubscriptionManager_UnsubscribeAll(t *testing.T) {
+	backend := &mockSubscriptionBackend{}
+	manager := NewSubscriptionManager(0)
+
+	subscriptions := []event.Subscription{
+		backend.subscribe(manager),
+		backend.subscribe(manager),
+		backend.subscribe(manager),
+	}
+
+	if manager.ActiveSubscriptions() != 3 {
+		t.Fatalf(
+			"unexpected active subscriptions\nexpected: [%v]\nactual:   [%v]",
+			3,
+			manager.ActiveSubscriptions(),
+		)
+	}
+
+	backend.waitFor(t, 3, 0)
+
+	// Unsubscribing a single subscription stops tracking it.
+	subscriptions[0].Unsubscribe()
+
+	if manager.ActiveSubscriptions() != 2 {
+		t.Fatalf(
+			"unexpected active subscriptions\nexpected: [%v]\nactual:   [%v]",
+			2,
+			manager.ActiveSubscriptions(),
+		)
+	}
+
+	backend.waitFor(t, 3, 1)
+
+	manager.UnsubscribeAll()
+
+	if manager.ActiveSubscriptions() != 0 {
+		t.Fatalf(
+			"unexpected active subscriptions\nexpected: [%v]\nactual:   [%v]",
+			0,
+			manager.ActiveSubscriptions(),
+		)
+	}
+
+	for i, subscription := range subscriptions {
+		select {
+		case <-subscription.Err():
+		case <-time.After(time.Second):
+			t.Fatalf("subscription [%v] not closed", i)
+		}
+	}
+
+	// All the underlying subscriptions are closed.
+	backend.waitFor(t, 3, 3)
+}
+
+func TestSubscriptionManager_Recycle(t *testing.T) {
+	backend := &mockSubscriptionBackend{}
+	manager := NewSubscriptionManager(50 * time.Millisecond)
+
+	subscription := backend.subscribe(manager)
+
+	// The subscription has been established and recycled twice; each
+	// recycling closes the previous underlying subscription.
+	backend.waitFor(t, 3, 2)
+
+	select {
+	case <-subscription.Err():
+		t.Fatal("recycled subscription closed")
+	default:
+	}
+
+	if manager.ActiveSubscriptions() != 1 {
+		t.Fatalf(
+			"unexpected active subscriptions\nexpected: [%v]\nactual:   [%v]",
+			1,
+			manager.ActiveSubscriptions(),
+		)
+	}
+
+	manager.UnsubscribeAll()
+
+	subscribed, _ := backend.counts()
+
+	// The subscription is not recycled once unsubscribed.
+	time.Sleep(150 * time.Millisecond)
+
+	if resubscribed, _ := backend.counts(); resubscribed != subscribed {
+		t.Errorf(
+			"unexpected subscriptions after unsubscribing\n"+
+				"expected: [%v]\nactual:   [%v]",
+			subscribed,
+			resubscribed,
+		)
+	}
+}
+
+// mockSubscriptionBackend counts the established and closed underlying
+// subscriptions.
+type mockSubscriptionBackend struct {
+	mutex        sync.Mutex
+	subscribed   int
+	unsubscribed int
+}
+
+func (msb *mockSubscriptionBackend) subscribe(
+	manager *SubscriptionManager,
+) event.Subscription {
+	return manager.Subscribe(
+		time.Millisecond,
+		func(ctx context.Context) (event.Subscription, error) {
+			msb.mutex.Lock()
+			msb.subscribed++
+			msb.mutex.Unlock()
+
+			return event.NewSubscription(func(quit <-chan struct{}) error {
+				<-quit
+
+				msb.mutex.Lock()
+				msb.unsubscribed++
+				msb.mutex.Unlock()
+
+				return nil
+			}), nil
+		},
+		0,
+		func(time.Duration) {},
+		func(error) {},
+	)
+}
+
+func (msb *mockSubscriptionBackend) counts() (int, int) {
+	msb.mutex.Lock()
+	defer msb.mutex.Unlock()
+
+	return msb.subscribed, msb.unsubscribed
+}
+
+// waitFor waits until at least the given numbers of underlying subscriptions
+// have been established and closed.
+func (msb *mockSubscriptionBackend) waitFor(
+	t *testing.T,
+	subscribed int,
+	unsubscribed int,
+) {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		actualSubscribed, actualUnsubscribed := msb.counts()
+		if actualSubscribed >= subscribed && actualUnsubscribed >= unsubscribed {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	actualSubscribed, actualUnsubscribed := msb.counts()
+	t.Fatalf(
+		"unexpected underlying subscriptions\n"+
+			"expected: [%v established, %v closed]\n"+
+			"actual:   [%v established, %v closed]",
+		subscribed,
+		unsubscribed,
+		actualSubscribed,
+		actualUnsubscribed,
+	)
+}
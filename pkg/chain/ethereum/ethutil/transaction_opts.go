@@ -4,6 +4,8 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 // TransactionOptions represents custom transaction options which can be used
@@ -38,3 +40,25 @@ func (to TransactionOptions) Apply(transactorOptions *bind.TransactOpts) {
 		transactorOptions.GasTipCap = customGasTipCap
 	}
 }
+
+// UnsignedTransactorOptions returns a copy of the given bind.TransactOpts
+// which lets the contract bindings assemble the transaction, including
+// the resolution of its nonce, gas limit and fees, but neither sign nor
+// submit it. The transaction returned from the bindings can be then signed
+// offline, e.g. on an air-gapped machine.
+func UnsignedTransactorOptions(
+	transactorOptions *bind.TransactOpts,
+) *bind.TransactOpts {
+	unsignedOptions := new(bind.TransactOpts)
+	*unsignedOptions = *transactorOptions
+
+	unsignedOptions.Signer = func(
+		_ common.Address,
+		transaction *types.Transaction,
+	) (*types.Transaction, error) {
+		return transaction, nil
+	}
+	unsignedOptions.NoSend = true
+
+	return unsignedOptions
+}
@@ -0,0 +1,100 @@
+package ethutil_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil/simulated"
+)
+
+func TestUnsignedTransactorOptions(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	contractAddress := common.HexToAddress(
+		"0x6813eb9362372eef6200f3b1dbc3f819671cba69",
+	)
+
+	backend, err := simulated.NewBackend(
+		core.GenesisAlloc{
+			from: {Balance: big.NewInt(1e18)},
+			// The contract stops execution immediately.
+			contractAddress: {Code: []byte{0x00}, Balance: big.NewInt(0)},
+		},
+		simulated.DefaultGasLimit,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+
+	chainID, err := backend.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transactorOptions, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transactorOptions.Nonce = big.NewInt(7)
+	transactorOptions.Value = big.NewInt(1)
+
+	contract := bind.NewBoundContract(
+		contractAddress,
+		abi.ABI{},
+		backend,
+		backend,
+		backend,
+	)
+
+	transaction, err := contract.Transfer(
+		ethutil.UnsignedTransactorOptions(transactorOptions),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if transaction.Nonce() != 7 {
+		t.Errorf(
+			"unexpected nonce\nexpected: [%v]\nactual:   [%v]",
+			7,
+			transaction.Nonce(),
+		)
+	}
+
+	if transaction.Gas() == 0 {
+		t.Errorf("gas limit has not been resolved")
+	}
+
+	if transaction.GasFeeCap() == nil || transaction.GasFeeCap().Sign() == 0 {
+		t.Errorf("gas fee cap has not been resolved")
+	}
+
+	v, r, s := transaction.RawSignatureValues()
+	if v.Sign() != 0 || r.Sign() != 0 || s.Sign() != 0 {
+		t.Errorf("transaction should not be signed")
+	}
+
+	pendingNonce, err := backend.PendingNonceAt(context.Background(), from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pendingNonce != 0 {
+		t.Errorf("transaction should not be submitted")
+	}
+
+	if transactorOptions.NoSend {
+		t.Errorf("original transactor options should not be modified")
+	}
+}
@@ -0,0 +1,142 @@
+package ethutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	goEthereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TransactionWaitPollInterval is the interval in which WaitForTransaction
+// polls the status of the awaited transaction.
+var TransactionWaitPollInterval = time.Second
+
+// ErrTransactionDropped is returned from WaitForTransaction when the awaited
+// transaction is neither mined nor known to the client, e.g. because it has
+// been evicted from the mempool or replaced by another transaction with the
+// same nonce.
+var ErrTransactionDropped = errors.New("transaction dropped")
+
+// transactionWaiter is the subset of the client able to read the status of
+// transactions and the latest block header.
+type transactionWaiter interface {
+	goEthereum.TransactionReader
+
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// WaitForTransaction blocks until the transaction with the given hash is
+// mined and has the given number of confirmations, and returns its receipt.
+// The block the transaction has been mined in counts as the first
+// confirmation so zero and one confirmations both mean the receipt is
+// returned as soon as the transaction is mined. It is the monitoring-only
+// counterpart of ForceMining; the transaction is never resubmitted so only
+// its hash is needed, e.g. after a restart.
+//
+// If the transaction is not mined and the client does not know it either,
+// an error wrapping ErrTransactionDropped is returned. The transaction must
+// therefore be known to the client, e.g. submitted through it, before
+// waiting for it. If the context is done before the transaction is mined
+// with the given number of confirmations, the context error is returned.
+// Other failures of the client requests are logged and the requests are
+// retried in the next poll.
+func WaitForTransaction(
+	ctx context.Context,
+	client transactionWaiter,
+	txHash common.Hash,
+	confirmations uint64,
+) (*types.Receipt, error) {
+	ticker := time.NewTicker(TransactionWaitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := pollTransaction(ctx, client, txHash, confirmations)
+		if err != nil {
+			return nil, err
+		}
+
+		if receipt != nil {
+			return receipt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollTransaction checks once whether the transaction with the given hash is
+// mined with the given number of confirmations. It returns the receipt of
+// the transaction if it is, nil if the transaction should be polled again,
+// and an error wrapping ErrTransactionDropped if the transaction is lost.
+func pollTransaction(
+	ctx context.Context,
+	client transactionWaiter,
+	txHash common.Hash,
+	confirmations uint64,
+) (*types.Receipt, error) {
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if err == nil && receipt != nil {
+		if confirmations <= 1 {
+			return receipt, nil
+		}
+
+		header, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			logger.Debugf(
+				"could not get latest block header while waiting for "+
+					"transaction [%v]: [%v]",
+				txHash.TerminalString(),
+				err,
+			)
+			return nil, nil
+		}
+
+		// The receipt block number is not greater than the latest block
+		// number unless the client is behind another node in a pool.
+		receiptConfirmations := new(big.Int).Sub(
+			header.Number,
+			receipt.BlockNumber,
+		)
+		receiptConfirmations.Add(receiptConfirmations, big.NewInt(1))
+		if receiptConfirmations.Cmp(new(big.Int).SetUint64(confirmations)) >= 0 {
+			return receipt, nil
+		}
+
+		return nil, nil
+	}
+
+	if err != nil && !errors.Is(err, goEthereum.NotFound) {
+		logger.Debugf(
+			"could not get receipt of transaction [%v]: [%v]",
+			txHash.TerminalString(),
+			err,
+		)
+		return nil, nil
+	}
+
+	_, _, err = client.TransactionByHash(ctx, txHash)
+	if errors.Is(err, goEthereum.NotFound) {
+		return nil, fmt.Errorf(
+			"%w: transaction [%v] is not known to the client",
+			ErrTransactionDropped,
+			txHash.TerminalString(),
+		)
+	}
+	if err != nil {
+		logger.Debugf(
+			"could not get transaction [%v]: [%v]",
+			txHash.TerminalString(),
+			err,
+		)
+	}
+
+	return nil, nil
+}
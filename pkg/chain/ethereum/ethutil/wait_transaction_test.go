@@ -0,0 +1,218 @@
+package ethutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	goEthereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var waitTestTxHash = common.HexToHash(
+	"0x7b1e4f3a9d1c2e8f6a5b4c3d2e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f",
+)
+
+func TestWaitForTransaction(t *testing.T) {
+	var tests = map[string]struct {
+		minedAtPoll         int
+		minedAtBlock        int64
+		latestBlockAtPoll   func(poll int) int64
+		transactionKnown    bool
+		receiptErr          error
+		confirmations       uint64
+		expectedPolls       int
+		expectedBlockNumber int64
+		expectedError       error
+	}{
+		"mined": {
+			minedAtPoll:         3,
+			minedAtBlock:        100,
+			transactionKnown:    true,
+			confirmations:       0,
+			expectedPolls:       3,
+			expectedBlockNumber: 100,
+		},
+		"mined with one confirmation": {
+			minedAtPoll:         1,
+			minedAtBlock:        100,
+			transactionKnown:    true,
+			confirmations:       1,
+			expectedPolls:       1,
+			expectedBlockNumber: 100,
+		},
+		"mined with confirmations": {
+			minedAtPoll:  2,
+			minedAtBlock: 100,
+			latestBlockAtPoll: func(poll int) int64 {
+				return 98 + int64(poll)
+			},
+			transactionKnown:    true,
+			confirmations:       4,
+			expectedPolls:       5, // latest block 103 at the fifth poll
+			expectedBlockNumber: 100,
+		},
+		"mined after receipt request failures": {
+			minedAtPoll:         3,
+			minedAtBlock:        100,
+			receiptErr:          fmt.Errorf("connection refused"),
+			transactionKnown:    true,
+			confirmations:       0,
+			expectedPolls:       3,
+			expectedBlockNumber: 100,
+		},
+		"dropped": {
+			transactionKnown: false,
+			confirmations:    0,
+			expectedPolls:    1,
+			expectedError:    ErrTransactionDropped,
+		},
+	}
+
+	defer func(interval time.Duration) {
+		TransactionWaitPollInterval = interval
+	}(TransactionWaitPollInterval)
+	TransactionWaitPollInterval = time.Millisecond
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			client := &mockTransactionWaiter{
+				minedAtPoll:       test.minedAtPoll,
+				minedAtBlock:      test.minedAtBlock,
+				latestBlockAtPoll: test.latestBlockAtPoll,
+				transactionKnown:  test.transactionKnown,
+				receiptErr:        test.receiptErr,
+			}
+
+			receipt, err := WaitForTransaction(
+				context.Background(),
+				client,
+				waitTestTxHash,
+				test.confirmations,
+			)
+
+			if !errors.Is(err, test.expectedError) {
+				t.Fatalf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+
+			if client.polls != test.expectedPolls {
+				t.Errorf(
+					"unexpected number of polls\nexpected: [%v]\nactual:   [%v]",
+					test.expectedPolls,
+					client.polls,
+				)
+			}
+
+			if test.expectedError != nil {
+				return
+			}
+
+			if receipt.BlockNumber.Int64() != test.expectedBlockNumber {
+				t.Errorf(
+					"unexpected block number\nexpected: [%v]\nactual:   [%v]",
+					test.expectedBlockNumber,
+					receipt.BlockNumber,
+				)
+			}
+		})
+	}
+}
+
+func TestWaitForTransaction_ContextCancelled(t *testing.T) {
+	defer func(interval time.Duration) {
+		TransactionWaitPollInterval = interval
+	}(TransactionWaitPollInterval)
+	TransactionWaitPollInterval = time.Millisecond
+
+	client := &mockTransactionWaiter{
+		transactionKnown: true, // pending, never mined
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	receipt, err := WaitForTransaction(ctx, client, waitTestTxHash, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			context.DeadlineExceeded,
+			err,
+		)
+	}
+
+	if receipt != nil {
+		t.Errorf("unexpected receipt: [%v]", receipt)
+	}
+}
+
+// mockTransactionWaiter counts the polls as the receipt requests. The
+// transaction is mined at the given poll and the latest block number at each
+// poll is computed with the given function or equals the block number the
+// transaction has been mined at.
+type mockTransactionWaiter struct {
+	minedAtPoll       int
+	minedAtBlock      int64
+	latestBlockAtPoll func(poll int) int64
+	transactionKnown  bool
+	receiptErr        error
+
+	mutex sync.Mutex
+	polls int
+}
+
+func (mtw *mockTransactionWaiter) TransactionByHash(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Transaction, bool, error) {
+	if !mtw.transactionKnown {
+		return nil, false, goEthereum.NotFound
+	}
+
+	return createLegacyTransaction(big.NewInt(1)), true, nil
+}
+
+func (mtw *mockTransactionWaiter) TransactionReceipt(
+	ctx context.Context,
+	txHash common.Hash,
+) (*types.Receipt, error) {
+	mtw.mutex.Lock()
+	defer mtw.mutex.Unlock()
+
+	mtw.polls++
+
+	if mtw.minedAtPoll == 0 || mtw.polls < mtw.minedAtPoll {
+		if mtw.receiptErr != nil {
+			return nil, mtw.receiptErr
+		}
+		return nil, goEthereum.NotFound
+	}
+
+	return &types.Receipt{
+		TxHash:      txHash,
+		BlockNumber: big.NewInt(mtw.minedAtBlock),
+	}, nil
+}
+
+func (mtw *mockTransactionWaiter) HeaderByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*types.Header, error) {
+	mtw.mutex.Lock()
+	defer mtw.mutex.Unlock()
+
+	latestBlock := mtw.minedAtBlock
+	if mtw.latestBlockAtPoll != nil {
+		latestBlock = mtw.latestBlockAtPoll(mtw.polls)
+	}
+
+	return &types.Header{Number: big.NewInt(latestBlock)}, nil
+}
@@ -2,7 +2,11 @@ package ethereum
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
+
+	"github.com/keep-network/keep-common/pkg/persistence"
 )
 
 // The inactivity time after which the local nonce is refreshed with the value
@@ -11,6 +15,11 @@ import (
 // transaction sent.
 const localNonceTrustDuration = 30 * time.Second
 
+// nonceStateDirectory is the name of the persistence directory the state of
+// nonce managers is saved in. The state of each account is saved in a separate
+// file named after the account address.
+const nonceStateDirectory = "nonce"
+
 // NonceManager tracks the nonce for the account and allows to update it after
 // each successfully submitted transaction. Tracking the nonce locally is
 // required when transactions are submitted from multiple goroutines or when
@@ -32,6 +41,21 @@ type NonceManager struct {
 	transactor     ContractTransactor
 	localNonce     uint64
 	expirationDate time.Time
+
+	stateHandle persistence.RWHandle
+}
+
+// NonceManagerOption is an option of the nonce manager.
+type NonceManagerOption func(*NonceManager)
+
+// WithStatePersistence backs the nonce manager with the given persistence
+// handle. The local nonce is saved with each IncrementNonce call so that
+// the nonces allocated locally, but not yet seen by the chain, survive
+// restarts. The saved state should be restored with LoadState on startup.
+func WithStatePersistence(handle persistence.RWHandle) NonceManagerOption {
+	return func(nm *NonceManager) {
+		nm.stateHandle = handle
+	}
 }
 
 // NewNonceManager creates NonceManager instance for the provided account using
@@ -41,12 +65,19 @@ type NonceManager struct {
 func NewNonceManager(
 	transactor ContractTransactor,
 	account Address,
+	options ...NonceManagerOption,
 ) *NonceManager {
-	return &NonceManager{
+	nm := &NonceManager{
 		account:    account,
 		transactor: transactor,
 		localNonce: 0,
 	}
+
+	for _, option := range options {
+		option(nm)
+	}
+
+	return nm
 }
 
 // CurrentNonce returns the nonce value that should be used for the next
@@ -116,5 +147,141 @@ func (nm *NonceManager) CurrentNonce() (uint64, error) {
 // using this function to provide the required synchronization.
 func (nm *NonceManager) IncrementNonce() uint64 {
 	nm.localNonce++
+
+	if nm.stateHandle != nil {
+		if err := nm.SaveState(); err != nil {
+			logger.Errorf("could not save nonce manager state: [%v]", err)
+		}
+	}
+
 	return nm.localNonce
 }
+
+// SaveState saves the local nonce with the persistence handle the nonce
+// manager has been configured with.
+//
+// SaveState is NOT safe for concurrent use. It is up to the client code
+// using this function to provide the required synchronization.
+func (nm *NonceManager) SaveState() error {
+	if nm.stateHandle == nil {
+		return fmt.Errorf("nonce manager state persistence not configured")
+	}
+
+	err := nm.stateHandle.Save(
+		[]byte(strconv.FormatUint(nm.localNonce, 10)),
+		nonceStateDirectory,
+		nm.stateFileName(),
+	)
+	if err != nil {
+		return fmt.Errorf("could not save local nonce: [%v]", err)
+	}
+
+	return nil
+}
+
+// LoadState restores the local nonce saved with the persistence handle
+// the nonce manager has been configured with and reconciles it with
+// the pending nonce as seen by the chain. If the restored nonce is higher
+// than the pending one, the transactions with the locally allocated nonces
+// have not been seen by the chain yet and the restored nonce is used for
+// the next transaction, as long as it does not expire. Otherwise, the pending
+// nonce is used. LoadState does nothing if no state has been saved.
+//
+// LoadState is NOT safe for concurrent use. It is up to the client code
+// using this function to provide the required synchronization.
+func (nm *NonceManager) LoadState() error {
+	if nm.stateHandle == nil {
+		return fmt.Errorf("nonce manager state persistence not configured")
+	}
+
+	savedNonce, found, err := nm.readState()
+	if err != nil {
+		return fmt.Errorf("could not read local nonce: [%v]", err)
+	}
+	if !found {
+		return nil
+	}
+
+	pendingNonce, err := nm.transactor.PendingNonceAt(
+		context.TODO(),
+		nm.account,
+	)
+	if err != nil {
+		return fmt.Errorf("could not get pending nonce: [%v]", err)
+	}
+
+	if savedNonce > pendingNonce {
+		logger.Infof(
+			"restored local nonce [%v] is higher than pending [%v]; "+
+				"using the restored one",
+			savedNonce,
+			pendingNonce,
+		)
+
+		nm.localNonce = savedNonce
+		nm.expirationDate = time.Now().Add(localNonceTrustDuration)
+	} else {
+		nm.localNonce = pendingNonce
+	}
+
+	return nil
+}
+
+// readState reads the nonce saved in the state handle. Only the nonce state
+// file of the account is read. Errors of reading other data stored by
+// the handle are not attributable to the nonce state so they are only
+// logged; the nonce is considered not found if the state file could not be
+// read at all.
+func (nm *NonceManager) readState() (uint64, bool, error) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	stateFileName := nm.stateFileName()
+
+	descriptors, errors := persistence.ReadAllFilteredWithContext(
+		ctx,
+		nm.stateHandle,
+		func(dirName, fileName string) bool {
+			return dirName == nonceStateDirectory && fileName == stateFileName
+		},
+	)
+
+	var (
+		nonce uint64
+		found bool
+	)
+
+	for descriptors != nil || errors != nil {
+		select {
+		case descriptor, ok := <-descriptors:
+			if !ok {
+				descriptors = nil
+				continue
+			}
+
+			content, err := descriptor.Content()
+			if err != nil {
+				return 0, false, err
+			}
+
+			nonce, err = strconv.ParseUint(string(content), 10, 64)
+			if err != nil {
+				return 0, false, err
+			}
+			found = true
+		case err, ok := <-errors:
+			if !ok {
+				errors = nil
+				continue
+			}
+
+			logger.Warningf("error while reading nonce state: [%v]", err)
+		}
+	}
+
+	return nonce, found, nil
+}
+
+func (nm *NonceManager) stateFileName() string {
+	return fmt.Sprintf("0x%x", nm.account[:])
+}
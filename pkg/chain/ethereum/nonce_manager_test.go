@@ -2,8 +2,12 @@ package ethereum
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/keep-network/keep-common/pkg/persistence"
 )
 
 func TestResolveAndIncrement(t *testing.T) {
@@ -79,6 +83,188 @@ func TestResolveAndIncrement(t *testing.T) {
 	}
 }
 
+func TestNonceManagerState(t *testing.T) {
+	tests := map[string]struct {
+		savedNonce    *uint64
+		pendingNonce  uint64
+		expectedNonce uint64
+	}{
+		"no saved state": {
+			savedNonce:    nil,
+			pendingNonce:  10,
+			expectedNonce: 10,
+		},
+		"saved nonce higher than pending": {
+			savedNonce:    uint64Ptr(15),
+			pendingNonce:  10,
+			expectedNonce: 15,
+		},
+		"saved nonce equal to pending": {
+			savedNonce:    uint64Ptr(10),
+			pendingNonce:  10,
+			expectedNonce: 10,
+		},
+		"saved nonce lower than pending": {
+			savedNonce:    uint64Ptr(5),
+			pendingNonce:  10,
+			expectedNonce: 10,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			handle, err := persistence.NewBasicDiskHandle(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			account := Address{1, 2, 3}
+
+			if test.savedNonce != nil {
+				previousManager := NewNonceManager(
+					&mockContractTransactor{0},
+					account,
+					WithStatePersistence(handle),
+				)
+				previousManager.localNonce = *test.savedNonce
+				if err := previousManager.SaveState(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			manager := NewNonceManager(
+				&mockContractTransactor{test.pendingNonce},
+				account,
+				WithStatePersistence(handle),
+			)
+			if err := manager.LoadState(); err != nil {
+				t.Fatal(err)
+			}
+
+			nonce, err := manager.CurrentNonce()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if nonce != test.expectedNonce {
+				t.Errorf(
+					"unexpected nonce\nexpected: [%v]\nactual:  [%v]",
+					test.expectedNonce,
+					nonce,
+				)
+			}
+		})
+	}
+}
+
+func TestNonceManagerState_SavedOnIncrement(t *testing.T) {
+	handle, err := persistence.NewBasicDiskHandle(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account := Address{1, 2, 3}
+
+	manager := NewNonceManager(
+		&mockContractTransactor{10},
+		account,
+		WithStatePersistence(handle),
+	)
+	if _, err := manager.CurrentNonce(); err != nil {
+		t.Fatal(err)
+	}
+	manager.IncrementNonce()
+	manager.IncrementNonce()
+
+	// The restarted manager should not reuse the nonces allocated locally
+	// but not yet seen by the chain.
+	restartedManager := NewNonceManager(
+		&mockContractTransactor{10},
+		account,
+		WithStatePersistence(handle),
+	)
+	if err := restartedManager.LoadState(); err != nil {
+		t.Fatal(err)
+	}
+
+	nonce, err := restartedManager.CurrentNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nonce != 12 {
+		t.Errorf(
+			"unexpected nonce\nexpected: [%v]\nactual:  [%v]",
+			12,
+			nonce,
+		)
+	}
+}
+
+func TestNonceManagerState_OtherDataUnreadable(t *testing.T) {
+	dataDir := t.TempDir()
+	handle, err := persistence.NewBasicDiskHandle(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An entry of other data stored by the handle whose content can not be
+	// read. It must not prevent the nonce state from being loaded.
+	err = os.MkdirAll(filepath.Join(dataDir, "other", "unreadable"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account := Address{1, 2, 3}
+
+	previousManager := NewNonceManager(
+		&mockContractTransactor{0},
+		account,
+		WithStatePersistence(handle),
+	)
+	previousManager.localNonce = 15
+	if err := previousManager.SaveState(); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewNonceManager(
+		&mockContractTransactor{10},
+		account,
+		WithStatePersistence(handle),
+	)
+	if err := manager.LoadState(); err != nil {
+		t.Fatal(err)
+	}
+
+	nonce, err := manager.CurrentNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nonce != 15 {
+		t.Errorf(
+			"unexpected nonce\nexpected: [%v]\nactual:  [%v]",
+			15,
+			nonce,
+		)
+	}
+}
+
+func TestNonceManagerState_NotConfigured(t *testing.T) {
+	manager := NewNonceManager(&mockContractTransactor{10}, Address{})
+
+	if err := manager.SaveState(); err == nil {
+		t.Error("expected an error when saving the state")
+	}
+	if err := manager.LoadState(); err == nil {
+		t.Error("expected an error when loading the state")
+	}
+}
+
+func uint64Ptr(value uint64) *uint64 {
+	return &value
+}
+
 type mockContractTransactor struct {
 	nextNonce uint64
 }
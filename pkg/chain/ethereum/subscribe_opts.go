@@ -20,4 +20,11 @@ type SubscribeOpts struct {
 	// regular subscription missed them because of, for example, connectivity
 	// problems.
 	PastBlocks uint64
+
+	// OrderingDelay is the duration for which the ordered event handlers
+	// hold each received event so that events received out of order, e.g.
+	// the ones received from the regular watchLogs subscription and the ones
+	// pulled by the subscription monitoring mechanism, are handled in their
+	// chain order.
+	OrderingDelay time.Duration
 }
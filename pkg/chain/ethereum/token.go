@@ -108,3 +108,47 @@ func (t *Token) MarshalToken(units map[string]int64) string {
 
 	return result
 }
+
+// ToUnit returns the exact value of the token amount in the denomination
+// with the given exponent, e.g. 9 for gwei or 18 for ether, so that the amount
+// can be displayed in any denomination, including denominations of tokens
+// with an arbitrary number of decimals. It returns nil if the amount is not
+// set.
+func (t *Token) ToUnit(exponent int) *big.Rat {
+	if t.Int == nil {
+		return nil
+	}
+
+	value := new(big.Rat).SetInt(t.Int)
+
+	absExponent := exponent
+	if absExponent < 0 {
+		absExponent = -absExponent
+	}
+	factor := new(big.Rat).SetInt(
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absExponent)), nil),
+	)
+
+	if exponent >= 0 {
+		return value.Quo(value, factor)
+	}
+
+	return value.Mul(value, factor)
+}
+
+// ToUnitString returns the token amount in the denomination with the given
+// exponent, formatted with the given number of decimals. The last decimal is
+// rounded to nearest, with halves rounded away from zero. It returns an empty
+// string if the amount is not set.
+func (t *Token) ToUnitString(exponent int, decimals int) string {
+	value := t.ToUnit(exponent)
+	if value == nil {
+		return ""
+	}
+
+	if decimals < 0 {
+		decimals = 0
+	}
+
+	return value.FloatString(decimals)
+}
@@ -0,0 +1,166 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestTokenToUnit(t *testing.T) {
+	var tests = map[string]struct {
+		value          *big.Int
+		exponent       int
+		expectedResult *big.Rat
+	}{
+		"wei": {
+			value:          big.NewInt(1234),
+			exponent:       0,
+			expectedResult: big.NewRat(1234, 1),
+		},
+		"gwei": {
+			value:          big.NewInt(1500000000),
+			exponent:       9,
+			expectedResult: big.NewRat(3, 2),
+		},
+		"ether exact": {
+			value:          big.NewInt(7654300000000000000),
+			exponent:       18,
+			expectedResult: big.NewRat(76543, 10000),
+		},
+		"ether smallest fraction": {
+			value:          big.NewInt(1),
+			exponent:       18,
+			expectedResult: big.NewRat(1, 1e18),
+		},
+		"six decimals token": {
+			value:          big.NewInt(2500001),
+			exponent:       6,
+			expectedResult: big.NewRat(2500001, 1e6),
+		},
+		"negative exponent": {
+			value:          big.NewInt(3),
+			exponent:       -2,
+			expectedResult: big.NewRat(300, 1),
+		},
+		"ether 5000": {
+			value:          int5000ether,
+			exponent:       18,
+			expectedResult: big.NewRat(5000, 1),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			token := &Token{test.value}
+
+			result := token.ToUnit(test.exponent)
+
+			if test.expectedResult.Cmp(result) != 0 {
+				t.Errorf(
+					"invalid result\nexpected: %v\nactual:   %v",
+					test.expectedResult,
+					result,
+				)
+			}
+		})
+	}
+}
+
+func TestTokenToUnit_NotSet(t *testing.T) {
+	token := &Token{}
+
+	if result := token.ToUnit(18); result != nil {
+		t.Errorf("invalid result\nexpected: %v\nactual:   %v", nil, result)
+	}
+}
+
+func TestTokenToUnitString(t *testing.T) {
+	var tests = map[string]struct {
+		value          *big.Int
+		exponent       int
+		decimals       int
+		expectedResult string
+	}{
+		"not set": {
+			value:          nil,
+			exponent:       18,
+			decimals:       2,
+			expectedResult: "",
+		},
+		"zero": {
+			value:          big.NewInt(0),
+			exponent:       18,
+			decimals:       2,
+			expectedResult: "0.00",
+		},
+		"exact": {
+			value:          big.NewInt(7654300000000000000),
+			exponent:       18,
+			decimals:       4,
+			expectedResult: "7.6543",
+		},
+		"padded": {
+			value:          big.NewInt(1500000000),
+			exponent:       9,
+			decimals:       3,
+			expectedResult: "1.500",
+		},
+		"rounded down": {
+			value:          big.NewInt(7654300000000000000),
+			exponent:       18,
+			decimals:       2,
+			expectedResult: "7.65",
+		},
+		"rounded up": {
+			value:          big.NewInt(7656000000000000000),
+			exponent:       18,
+			decimals:       2,
+			expectedResult: "7.66",
+		},
+		"half rounded away from zero": {
+			value:          big.NewInt(7655000000000000000),
+			exponent:       18,
+			decimals:       2,
+			expectedResult: "7.66",
+		},
+		"negative half rounded away from zero": {
+			value:          big.NewInt(-7655000000000000000),
+			exponent:       18,
+			decimals:       2,
+			expectedResult: "-7.66",
+		},
+		"rounded to integer": {
+			value:          big.NewInt(2500001),
+			exponent:       6,
+			decimals:       0,
+			expectedResult: "3",
+		},
+		"negative decimals": {
+			value:          big.NewInt(2400000),
+			exponent:       6,
+			decimals:       -1,
+			expectedResult: "2",
+		},
+		"smallest fraction": {
+			value:          big.NewInt(1),
+			exponent:       18,
+			decimals:       18,
+			expectedResult: "0.000000000000000001",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			token := &Token{test.value}
+
+			result := token.ToUnitString(test.exponent, test.decimals)
+
+			if test.expectedResult != result {
+				t.Errorf(
+					"invalid result\nexpected: %v\nactual:   %v",
+					test.expectedResult,
+					result,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	"github.com/spf13/cobra"
+)
+
+const (
+	balanceFlag  string = "balance"
+	balanceShort string = "l"
+)
+
+var accountDescription = `The account command decrypts the key file configured
+	for the account and prints the address it resolves to.
+
+	The on-chain balance of the account can be printed as well by passing the
+	-l/--balance flag. In this mode, the command connects to the configured
+	Ethereum node.`
+
+// AccountCommand returns a command printing the address of the account
+// configured in the Ethereum configuration returned by configFn. If the
+// --balance flag is passed, the command prints the account's on-chain balance
+// as well. The returned command is meant to be added to the top-level command
+// tree along with the generated contract commands:
+//
+//	ModuleCommand.AddCommand(cmd.AccountCommand(ModuleCommand.GetConfig))
+func AccountCommand(configFn func() *ethereum.Config) *cobra.Command {
+	c := &cobra.Command{
+		Use:                   "account",
+		Short:                 "Prints the configured account information.",
+		Long:                  accountDescription,
+		Args:                  ArgCountChecker(0),
+		SilenceUsage:          true,
+		DisableFlagsInUseLine: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return printAccount(c, configFn())
+		},
+	}
+
+	c.Flags().BoolP(
+		balanceFlag,
+		balanceShort,
+		false,
+		"Print the on-chain balance of the account.",
+	)
+
+	return c
+}
+
+func printAccount(c *cobra.Command, cfg *ethereum.Config) error {
+	key, err := ethutil.DecryptKeyFile(
+		cfg.Account.KeyFile,
+		cfg.Account.KeyFilePassword,
+	)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to read KeyFile: %s: [%v]",
+			cfg.Account.KeyFile,
+			err,
+		)
+	}
+
+	fmt.Fprintf(c.OutOrStdout(), "address: %s\n", key.Address.Hex())
+
+	printBalance, err := c.Flags().GetBool(balanceFlag)
+	if err != nil {
+		return err
+	}
+
+	if !printBalance {
+		return nil
+	}
+
+	client, err := ethclient.Dial(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("error connecting to host chain node: [%v]", err)
+	}
+	defer client.Close()
+
+	balance, err := client.BalanceAt(context.Background(), key.Address, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get account balance: [%v]", err)
+	}
+
+	fmt.Fprintf(
+		c.OutOrStdout(),
+		"balance: %s\n",
+		ethereum.WrapWei(balance).String(),
+	)
+
+	return nil
+}
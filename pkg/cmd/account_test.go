@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/keep-network/keep-common/pkg/chain/ethereum"
+)
+
+func TestAccountCommand(t *testing.T) {
+	keyFile := "../chain/ethereum/ethutil/testdata/" +
+		"UTC--2018-02-15T19-57-35.216297214Z--6ffba2d0f4c8fd7961f516af43c55fe2d56f6044"
+
+	tests := map[string]struct {
+		password       string
+		expectedOutput string
+		expectedError  bool
+	}{
+		"good password": {
+			password:       "password",
+			expectedOutput: "address: 0x6FFBA2D0F4C8FD7961F516af43C55fe2d56f6044\n",
+		},
+		"bad password": {
+			password:      "nanananana",
+			expectedError: true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			config := &ethereum.Config{
+				Account: ethereum.Account{
+					KeyFile:         keyFile,
+					KeyFilePassword: test.password,
+				},
+			}
+
+			c := AccountCommand(func() *ethereum.Config { return config })
+
+			output := &bytes.Buffer{}
+			c.SetOut(output)
+			c.SetErr(&bytes.Buffer{})
+			c.SetArgs([]string{})
+
+			err := c.Execute()
+			if test.expectedError != (err != nil) {
+				t.Fatalf("unexpected error: [%v]", err)
+			}
+
+			if output.String() != test.expectedOutput {
+				t.Errorf(
+					"unexpected output\nexpected: [%v]\nactual:   [%v]",
+					test.expectedOutput,
+					output.String(),
+				)
+			}
+		})
+	}
+}
@@ -3,11 +3,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/keep-network/keep-common/pkg/chain/ethereum"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
 	"github.com/keep-network/keep-common/pkg/cmd/flag"
 	"github.com/spf13/cobra"
 )
@@ -22,34 +26,147 @@ const (
 	submitShort string = "s"
 	valueFlag   string = "value"
 	valueShort  string = "v"
+	timeoutFlag string = "timeout"
 )
 
 var (
 	// BlockFlagValue allows for reading the block flag included in ConstFlags,
 	// which represents the block at which to execute a contract interaction.
 	// The value, if that flag is passed on the command line, is stored in this
-	// variable.
-	BlockFlagValue flag.BigIntFlagValue
+	// variable. The block can be given as a number or as one of the symbolic
+	// tags: latest, pending, safe, finalized. Use ResolveBlockFlag to get
+	// the block number to execute the interaction at.
+	BlockFlagValue flag.BlockFlagValue
 	// ValueFlagValue allows for reading the value flag included in
 	// PayableFlags, which represents an amount of ETH to send with a contract
 	// interaction. The value, if that flag is passed on the command line, is
 	// stored in this variable.
 	ValueFlagValue ethereum.Wei
+	// TimeoutFlagValue allows for reading the timeout flag included in
+	// ConstFlags, which represents the maximum duration of a command
+	// execution, including all the calls to the Ethereum node. The value,
+	// if that flag is passed on the command line, is stored in this variable.
+	// Use CommandContext to get the context bounded by the timeout.
+	TimeoutFlagValue time.Duration
 )
 
 // InitConstFlags initializes flags useful for constant contract interactions,
 // meaning contract interactions that do not require transaction submission and
 // are used for inspecting chain state. These flags include:
-//   --block flag to check an interaction's result value at a specific block.
+//   --block flag to check an interaction's result value at a specific block,
+//   --timeout flag to bound the duration of the command execution.
 func InitConstFlags(cmd *cobra.Command) {
-	flag.BigIntVarPFlag(
+	flag.BlockVarPFlag(
 		cmd.Flags(),
 		&BlockFlagValue,
 		blockFlag,
 		blockShort,
-		nil,
-		"Retrieve the result of calling this method on `BLOCK`.",
+		"Retrieve the result of calling this method on `BLOCK`; "+
+			"accepts a block number or one of the block tags: "+
+			"latest, pending, safe, finalized.",
 	)
+
+	cmd.Flags().DurationVar(
+		&TimeoutFlagValue,
+		timeoutFlag,
+		0,
+		"Fail if the command does not complete within `TIMEOUT`, "+
+			"e.g. 30s; no timeout if not set.",
+	)
+}
+
+// CommandContext returns the context of the command execution bounded by
+// the timeout flag included in ConstFlags. The context is not bounded if
+// the flag is not set. The returned cancel function should be called once
+// the command completes.
+func CommandContext(c *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx := c.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if TimeoutFlagValue <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, TimeoutFlagValue)
+}
+
+// RunWithContext runs the given function and waits for it to complete
+// as long as the given context is not done. It lets bound the calls which
+// do not accept a context with the command context. If the context is done
+// first, the function keeps running in the background and the context error
+// is returned.
+func RunWithContext(ctx context.Context, fn func() error) error {
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- fn()
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("command interrupted: [%w]", ctx.Err())
+	}
+}
+
+// pendingBlockNumber is the block number understood by the Ethereum client
+// as the pending block.
+var pendingBlockNumber = big.NewInt(-1)
+
+// ResolveBlockFlag returns the block number to execute a contract interaction
+// at, based on the block flag included in ConstFlags. It returns `nil`,
+// meaning the latest block, if the flag is not set or set to the latest tag.
+// The pending tag is resolved to the value understood by the Ethereum client
+// as the pending block. The safe and finalized tags are resolved to the
+// current safe and finalized block numbers by querying the node at the given
+// URL with the raw RPC client, since the typed client can not call at these
+// tags.
+func ResolveBlockFlag(url string) (*big.Int, error) {
+	return ResolveBlockFlagWithContext(context.Background(), url)
+}
+
+// ResolveBlockFlagWithContext works just like ResolveBlockFlag but queries
+// the node with the given context, so that the query can be bounded with
+// the command context.
+func ResolveBlockFlagWithContext(
+	ctx context.Context,
+	url string,
+) (*big.Int, error) {
+	var resolveFn func(context.Context, *rpc.Client) (uint64, error)
+
+	switch BlockFlagValue.Tag {
+	case "":
+		return BlockFlagValue.Int, nil
+	case flag.LatestBlockTag:
+		return nil, nil
+	case flag.PendingBlockTag:
+		return pendingBlockNumber, nil
+	case flag.SafeBlockTag:
+		resolveFn = func(ctx context.Context, client *rpc.Client) (uint64, error) {
+			return ethutil.SafeBlock(ctx, client)
+		}
+	case flag.FinalizedBlockTag:
+		resolveFn = func(ctx context.Context, client *rpc.Client) (uint64, error) {
+			return ethutil.FinalizedBlock(ctx, client)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported block tag: [%v]", BlockFlagValue.Tag)
+	}
+
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to host chain node: [%v]", err)
+	}
+	defer client.Close()
+
+	blockNumber, err := resolveFn(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetUint64(blockNumber), nil
 }
 
 // InitNonConstFlags initializes flags useful for non-constant contract interactions,
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -145,3 +149,136 @@ func TestComposableArgCheckerShortCircuitsOnFailure(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveBlockFlag(t *testing.T) {
+	tests := map[string]struct {
+		value               string
+		expectedBlockNumber *big.Int
+	}{
+		"not set": {
+			value:               "",
+			expectedBlockNumber: nil,
+		},
+		"block number": {
+			value:               "100",
+			expectedBlockNumber: big.NewInt(100),
+		},
+		"latest tag": {
+			value:               "latest",
+			expectedBlockNumber: nil,
+		},
+		"pending tag": {
+			value:               "pending",
+			expectedBlockNumber: big.NewInt(-1),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			c := &cobra.Command{}
+			InitConstFlags(c)
+
+			if test.value != "" {
+				if err := c.Flags().Set(blockFlag, test.value); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The URL is not used for the tested values.
+			blockNumber, err := ResolveBlockFlag("")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(test.expectedBlockNumber, blockNumber) {
+				t.Errorf(
+					"unexpected block number\nexpected: [%v]\nactual:   [%v]",
+					test.expectedBlockNumber,
+					blockNumber,
+				)
+			}
+		})
+	}
+}
+
+func TestCommandContext(t *testing.T) {
+	tests := map[string]struct {
+		timeout          string
+		expectedDeadline bool
+	}{
+		"timeout not set": {
+			timeout:          "",
+			expectedDeadline: false,
+		},
+		"timeout set": {
+			timeout:          "30s",
+			expectedDeadline: true,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			c := &cobra.Command{}
+			InitConstFlags(c)
+
+			if test.timeout != "" {
+				if err := c.Flags().Set(timeoutFlag, test.timeout); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := CommandContext(c)
+			defer cancel()
+
+			_, hasDeadline := ctx.Deadline()
+			if hasDeadline != test.expectedDeadline {
+				t.Errorf(
+					"unexpected deadline\nexpected: [%v]\nactual:   [%v]",
+					test.expectedDeadline,
+					hasDeadline,
+				)
+			}
+		})
+	}
+}
+
+func TestRunWithContext(t *testing.T) {
+	errCompleted := fmt.Errorf("completed")
+
+	tests := map[string]struct {
+		fnDuration    time.Duration
+		expectedError error
+	}{
+		"function completed": {
+			fnDuration:    0,
+			expectedError: errCompleted,
+		},
+		"context done": {
+			fnDuration:    time.Second,
+			expectedError: context.DeadlineExceeded,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(
+				context.Background(),
+				100*time.Millisecond,
+			)
+			defer cancel()
+
+			err := RunWithContext(ctx, func() error {
+				time.Sleep(test.fnDuration)
+				return errCompleted
+			})
+
+			if !errors.Is(err, test.expectedError) {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package flag
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/spf13/pflag"
+)
+
+// Symbolic block tags accepted by the block flag in place of a block number.
+const (
+	LatestBlockTag    = "latest"
+	PendingBlockTag   = "pending"
+	SafeBlockTag      = "safe"
+	FinalizedBlockTag = "finalized"
+)
+
+// BlockVarPFlag is a custom flag to handle a block number or one of the
+// symbolic block tags: `latest`, `pending`, `safe`, `finalized`.
+func BlockVarPFlag(f *pflag.FlagSet, p *BlockFlagValue, name string, short string, usage string) {
+	if p == nil {
+		p = &BlockFlagValue{}
+	}
+	*p = BlockFlagValue{}
+
+	f.VarP(p, name, short, usage)
+}
+
+// BlockFlagValue is a flag value holding either a block number or a symbolic
+// block tag. If the value is a block number, it is held by the embedded
+// big.Int and Tag is empty. If the value is a block tag, the embedded big.Int
+// is `nil` and Tag holds the tag. Both are unset by default.
+type BlockFlagValue struct {
+	*big.Int
+	Tag string
+}
+
+// Set sets the flag value from a string.
+func (b *BlockFlagValue) Set(s string) error {
+	switch s {
+	case LatestBlockTag, PendingBlockTag, SafeBlockTag, FinalizedBlockTag:
+		*b = BlockFlagValue{Tag: s}
+		return nil
+	}
+
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok || v.Sign() < 0 {
+		return fmt.Errorf(
+			"failed to parse as block number or block tag: %s",
+			s,
+		)
+	}
+	*b = BlockFlagValue{Int: v}
+
+	return nil
+}
+
+// Type returns the type name handled by the flag.
+func (b *BlockFlagValue) Type() string {
+	return "block"
+}
+
+// String outputs the flag value as a string. If the value is not set it
+// returns an empty string.
+func (b *BlockFlagValue) String() string {
+	if b.Tag != "" {
+		return b.Tag
+	}
+	if b.Int == nil {
+		return ""
+	}
+	return b.Int.String()
+}
@@ -0,0 +1,105 @@
+package flag
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	pflag "github.com/spf13/pflag"
+)
+
+const blockFlagName = "block"
+
+func TestBlockVarPFlag_Set(t *testing.T) {
+	tests := map[string]struct {
+		value         string
+		expectedError error
+		expectedValue BlockFlagValue
+	}{
+		"block number": {
+			value:         "8569412",
+			expectedValue: BlockFlagValue{Int: big.NewInt(8569412)},
+		},
+		"hex block number": {
+			value:         "0x10",
+			expectedValue: BlockFlagValue{Int: big.NewInt(16)},
+		},
+		"latest tag": {
+			value:         "latest",
+			expectedValue: BlockFlagValue{Tag: LatestBlockTag},
+		},
+		"pending tag": {
+			value:         "pending",
+			expectedValue: BlockFlagValue{Tag: PendingBlockTag},
+		},
+		"safe tag": {
+			value:         "safe",
+			expectedValue: BlockFlagValue{Tag: SafeBlockTag},
+		},
+		"finalized tag": {
+			value:         "finalized",
+			expectedValue: BlockFlagValue{Tag: FinalizedBlockTag},
+		},
+		"negative block number": {
+			value: "-1",
+			expectedError: fmt.Errorf(
+				"invalid argument \"-1\" for \"-b, --%s\" flag: "+
+					"failed to parse as block number or block tag: -1",
+				blockFlagName,
+			),
+		},
+		"invalid value": {
+			value: "earliest",
+			expectedError: fmt.Errorf(
+				"invalid argument \"earliest\" for \"-b, --%s\" flag: "+
+					"failed to parse as block number or block tag: earliest",
+				blockFlagName,
+			),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			flags := pflag.NewFlagSet("flag-set-"+testName, pflag.PanicOnError)
+
+			var valueDest BlockFlagValue
+
+			BlockVarPFlag(flags, &valueDest, blockFlagName, "b", "")
+
+			err := flags.Set(blockFlagName, test.value)
+
+			if !reflect.DeepEqual(test.expectedError, err) {
+				t.Errorf(
+					"unexpected error\nexpected: %v\nactual:   %v\n",
+					test.expectedError,
+					err,
+				)
+			}
+
+			if !reflect.DeepEqual(test.expectedValue, valueDest) {
+				t.Errorf(
+					"\nexpected: %+v\nactual:   %+v",
+					test.expectedValue,
+					valueDest,
+				)
+			}
+		})
+	}
+}
+
+func TestBlockVarPFlag_DefaultValue(t *testing.T) {
+	flags := pflag.NewFlagSet("flag-set", pflag.PanicOnError)
+
+	valueDest := BlockFlagValue{Tag: SafeBlockTag}
+
+	BlockVarPFlag(flags, &valueDest, blockFlagName, "b", "")
+
+	if valueDest.Int != nil || valueDest.Tag != "" {
+		t.Errorf(
+			"invalid valueDest\nexpected: %+v\nactual:   %+v",
+			BlockFlagValue{},
+			valueDest,
+		)
+	}
+}
@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	logging "github.com/ipfs/go-log"
+)
+
+// Names of the logging subsystems of keep-common packages.
+const (
+	CacheSubsystem       = "keep-cache"
+	ClientInfoSubsystem  = "keep-clientinfo"
+	EthereumSubsystem    = "keep-ethereum"
+	EthutilSubsystem     = "keep-ethutil"
+	PersistenceSubsystem = "keep-persistence"
+)
+
+// ContractSubsystemPrefix is the prefix of the logging subsystems of
+// the generated contract bindings. The prefix is followed by the name of
+// the contract.
+const ContractSubsystemPrefix = "keep-contract-"
+
+var subsystems = []string{
+	CacheSubsystem,
+	ClientInfoSubsystem,
+	EthereumSubsystem,
+	EthutilSubsystem,
+	PersistenceSubsystem,
+}
+
+// Subsystems returns the sorted names of the keep-common logging subsystems,
+// including the subsystems of the generated contract bindings currently in
+// use.
+func Subsystems() []string {
+	result := append([]string{}, subsystems...)
+
+	for _, subsystem := range logging.GetSubsystems() {
+		if strings.HasPrefix(subsystem, ContractSubsystemPrefix) {
+			result = append(result, subsystem)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// SetLogLevel sets the log level of the given keep-common subsystem at
+// runtime, e.g. to enable debug logs of the mining waiter without restarting
+// the client. An error is returned if the subsystem is not a keep-common
+// subsystem, if the subsystem is not in use or if the level is not supported.
+func SetLogLevel(subsystem string, level string) error {
+	if !isKnownSubsystem(subsystem) {
+		return fmt.Errorf("unknown subsystem [%s]", subsystem)
+	}
+
+	if err := logging.SetLogLevel(subsystem, level); err != nil {
+		return fmt.Errorf(
+			"could not set log level of subsystem [%s]: [%v]",
+			subsystem,
+			err,
+		)
+	}
+
+	return nil
+}
+
+// SetAllLogLevels sets the given log level of all the keep-common subsystems
+// in use. An error is returned if the level is not supported.
+func SetAllLogLevels(level string) error {
+	inUse := make(map[string]bool)
+	for _, subsystem := range logging.GetSubsystems() {
+		inUse[subsystem] = true
+	}
+
+	for _, subsystem := range Subsystems() {
+		if !inUse[subsystem] {
+			continue
+		}
+
+		if err := SetLogLevel(subsystem, level); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isKnownSubsystem(subsystem string) bool {
+	if strings.HasPrefix(subsystem, ContractSubsystemPrefix) {
+		return len(subsystem) > len(ContractSubsystemPrefix)
+	}
+
+	for _, knownSubsystem := range subsystems {
+		if subsystem == knownSubsystem {
+			return true
+		}
+	}
+
+	return false
+}
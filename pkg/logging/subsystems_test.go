@@ -0,0 +1,100 @@
+package logging_test
+
+import (
+	"strings"
+	"testing"
+
+	log "github.com/ipfs/go-log"
+
+	// Packages imported for their loggers to be registered.
+	_ "github.com/keep-network/keep-common/pkg/cache"
+	_ "github.com/keep-network/keep-common/pkg/chain/ethereum"
+	_ "github.com/keep-network/keep-common/pkg/chain/ethereum/ethutil"
+	_ "github.com/keep-network/keep-common/pkg/clientinfo"
+	_ "github.com/keep-network/keep-common/pkg/persistence"
+
+	"github.com/keep-network/keep-common/pkg/logging"
+)
+
+func TestSubsystems(t *testing.T) {
+	known := make(map[string]bool)
+	for _, subsystem := range logging.Subsystems() {
+		known[subsystem] = true
+	}
+
+	for _, subsystem := range log.GetSubsystems() {
+		if strings.HasPrefix(subsystem, "keep-") && !known[subsystem] {
+			t.Errorf("subsystem [%s] is not registered", subsystem)
+		}
+	}
+}
+
+func TestSetLogLevel(t *testing.T) {
+	var tests = map[string]struct {
+		subsystem     string
+		level         string
+		expectedError string
+	}{
+		"known subsystem": {
+			subsystem: logging.EthutilSubsystem,
+			level:     "debug",
+		},
+		"unknown subsystem": {
+			subsystem:     "keep-unknown",
+			level:         "debug",
+			expectedError: "unknown subsystem [keep-unknown]",
+		},
+		"subsystem of other module": {
+			subsystem:     "*",
+			level:         "debug",
+			expectedError: "unknown subsystem [*]",
+		},
+		"contract subsystem not in use": {
+			subsystem: logging.ContractSubsystemPrefix + "Unknown",
+			level:     "debug",
+			expectedError: "could not set log level of subsystem " +
+				"[keep-contract-Unknown]: [Error: No such logger]",
+		},
+		"unsupported level": {
+			subsystem: logging.EthutilSubsystem,
+			level:     "verbose",
+			expectedError: "could not set log level of subsystem " +
+				"[keep-ethutil]: [logger: invalid log level]",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			err := logging.SetLogLevel(test.subsystem, test.level)
+
+			if test.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: [%v]", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
+
+func TestSetAllLogLevels(t *testing.T) {
+	if err := logging.SetAllLogLevels("debug"); err != nil {
+		t.Errorf("unexpected error: [%v]", err)
+	}
+
+	if err := logging.SetAllLogLevels("verbose"); err == nil {
+		t.Errorf("expected an error for unsupported level")
+	}
+
+	if err := logging.SetAllLogLevels("info"); err != nil {
+		t.Errorf("unexpected error: [%v]", err)
+	}
+}
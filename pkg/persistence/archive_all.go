@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ArchiveFailure is a directory that could not be archived by ArchiveAll
+// along with the error the archiving failed with.
+type ArchiveFailure struct {
+	Directory string
+	Err       error
+}
+
+// ArchiveAllError is returned from ArchiveAll when some of the directories
+// could not be archived. It lists the directories archived successfully and
+// the directories that failed to be archived, in the order they were given.
+type ArchiveAllError struct {
+	Archived []string
+	Failed   []ArchiveFailure
+}
+
+func (aae *ArchiveAllError) Error() string {
+	failures := make([]string, 0, len(aae.Failed))
+	for _, failure := range aae.Failed {
+		failures = append(
+			failures,
+			fmt.Sprintf("[%v]: [%v]", failure.Directory, failure.Err),
+		)
+	}
+
+	return fmt.Sprintf(
+		"could not archive [%v] of [%v] directories; "+
+			"archived: %v; failed: %v",
+		len(aae.Failed),
+		len(aae.Failed)+len(aae.Archived),
+		aae.Archived,
+		strings.Join(failures, ", "),
+	)
+}
+
+// ArchiveAll archives all the given directories of the handle one by one
+// with Archive. A failure to archive one of the directories does not stop
+// archiving the other ones. If any of the directories could not be archived,
+// an *ArchiveAllError listing the archived and the failed directories is
+// returned.
+func ArchiveAll(handle ProtectedHandle, directories []string) error {
+	result := &ArchiveAllError{}
+
+	for _, directory := range directories {
+		if err := handle.Archive(directory); err != nil {
+			logger.Errorf(
+				"could not archive directory [%v]: [%v]",
+				directory,
+				err,
+			)
+			result.Failed = append(result.Failed, ArchiveFailure{
+				Directory: directory,
+				Err:       err,
+			})
+			continue
+		}
+
+		result.Archived = append(result.Archived, directory)
+	}
+
+	if len(result.Failed) > 0 {
+		return result
+	}
+
+	return nil
+}
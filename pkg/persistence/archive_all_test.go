@@ -0,0 +1,84 @@
+package persistence
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArchiveAll(t *testing.T) {
+	handle, dataDir := initProtectedDiskPersistence(t)
+
+	if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.Save(fileContent, dirName2, fileName21); err != nil {
+		t.Fatal(err)
+	}
+
+	missingDir := "0x999999"
+
+	err := ArchiveAll(handle, []string{dirName1, missingDir, dirName2})
+
+	var archiveAllErr *ArchiveAllError
+	if !errors.As(err, &archiveAllErr) {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+
+	expectedArchived := []string{dirName1, dirName2}
+	if !reflect.DeepEqual(expectedArchived, archiveAllErr.Archived) {
+		t.Errorf(
+			"unexpected archived directories\nexpected: [%v]\nactual:   [%v]",
+			expectedArchived,
+			archiveAllErr.Archived,
+		)
+	}
+
+	if len(archiveAllErr.Failed) != 1 ||
+		archiveAllErr.Failed[0].Directory != missingDir {
+		t.Fatalf(
+			"unexpected failed directories\nexpected: [%v]\nactual:   [%v]",
+			missingDir,
+			archiveAllErr.Failed,
+		)
+	}
+
+	if !strings.Contains(err.Error(), missingDir) {
+		t.Errorf("error does not name the failed directory: [%v]", err)
+	}
+
+	assertExist(
+		t,
+		dataDir,
+		filepath.Join(dirArchive, dirName1, fileName11),
+		"archived file",
+	)
+	assertExist(
+		t,
+		dataDir,
+		filepath.Join(dirArchive, dirName2, fileName21),
+		"archived file",
+	)
+	assertNotExist(t, dataDir, filepath.Join(dirCurrent, dirName1), "current dir")
+	assertNotExist(t, dataDir, filepath.Join(dirCurrent, dirName2), "current dir")
+}
+
+func TestArchiveAll_NoFailures(t *testing.T) {
+	handle, dataDir := initProtectedDiskPersistence(t)
+
+	if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.Save(fileContent, dirName2, fileName21); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ArchiveAll(handle, []string{dirName1, dirName2}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNotExist(t, dataDir, filepath.Join(dirCurrent, dirName1), "current dir")
+	assertNotExist(t, dataDir, filepath.Join(dirCurrent, dirName2), "current dir")
+}
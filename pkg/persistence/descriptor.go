@@ -1,11 +1,68 @@
 package persistence
 
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// StreamingDescriptor is implemented by the data descriptors able to stream
+// the content so that large data does not have to be buffered in memory as
+// a whole. Reader returns the reader streaming the content. The caller is
+// responsible for closing the returned reader.
+type StreamingDescriptor interface {
+	Reader() (io.ReadCloser, error)
+}
+
+// DeletableDescriptor is implemented by the data descriptors able to remove
+// the data from the persistence layer it has been read from, so that
+// corrupted or obsolete data can be cleaned up while iterating over the data
+// returned from ReadAll. It is safe to call Delete before all the data has
+// been read. ErrDeleteNotSupported is returned if the data has been read from
+// a handle that does not allow to remove the data.
+type DeletableDescriptor interface {
+	Delete() error
+}
+
+// OpenContent returns a reader streaming the content of the given data
+// descriptor. If the descriptor does not implement StreamingDescriptor,
+// the content is read as a whole and streamed from memory. The caller is
+// responsible for closing the returned reader.
+func OpenContent(descriptor DataDescriptor) (io.ReadCloser, error) {
+	if streaming, ok := descriptor.(StreamingDescriptor); ok {
+		return streaming.Reader()
+	}
+
+	content, err := descriptor.Content()
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// DeleteData removes the data represented by the given data descriptor from
+// the persistence layer it has been read from. ErrDeleteNotSupported is
+// returned if the descriptor does not implement DeletableDescriptor.
+func DeleteData(descriptor DataDescriptor) error {
+	if deletable, ok := descriptor.(DeletableDescriptor); ok {
+		return deletable.Delete()
+	}
+
+	return ErrDeleteNotSupported
+}
+
 // dataDescriptor is the simplest possible implementation of DataDescriptor
-// interface that can be used by a storage when reading data.
+// interface that can be used by a storage when reading data. If openFunc is
+// not set, Reader streams the content returned by readFunc from memory.
+// If deleteFunc is not set, the data can not be deleted.
 type dataDescriptor struct {
-	name      string
-	directory string
-	readFunc  func() ([]byte, error)
+	name       string
+	directory  string
+	readFunc   func() ([]byte, error)
+	openFunc   func() (io.ReadCloser, error)
+	deleteFunc func() error
 }
 
 func (dd *dataDescriptor) Name() string {
@@ -19,3 +76,110 @@ func (dd *dataDescriptor) Directory() string {
 func (dd *dataDescriptor) Content() ([]byte, error) {
 	return dd.readFunc()
 }
+
+func (dd *dataDescriptor) Reader() (io.ReadCloser, error) {
+	if dd.openFunc != nil {
+		return dd.openFunc()
+	}
+
+	content, err := dd.readFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (dd *dataDescriptor) Delete() error {
+	if dd.deleteFunc == nil {
+		return ErrDeleteNotSupported
+	}
+
+	return dd.deleteFunc()
+}
+
+// wrapAll pipes the data descriptors and errors from the input channels to
+// the returned channels. Each descriptor is replaced with the one returned
+// from wrapFn; descriptors for which wrapFn returns nil are skipped. Errors
+// are passed thru without any change. Once the context is done, the piping
+// stops, the returned channels are closed, and the rest of the input is
+// drained so that the producer is never blocked. If onDone is set, it is
+// called once both input channels are closed with the number of descriptors
+// and errors read from them.
+func wrapAll(
+	ctx context.Context,
+	inputData <-chan DataDescriptor,
+	inputErrors <-chan error,
+	wrapFn func(descriptor DataDescriptor) DataDescriptor,
+	onDone func(descriptors int, errors int),
+) (<-chan DataDescriptor, <-chan error) {
+	outputData := make(chan DataDescriptor)
+	outputErrors := make(chan error)
+
+	var descriptors, errs int
+
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		output := outputErrors
+		for err := range inputErrors {
+			errs++
+
+			if output == nil {
+				continue
+			}
+
+			select {
+			case output <- err:
+			case <-ctx.Done():
+				close(output)
+				output = nil
+			}
+		}
+
+		if output != nil {
+			close(output)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		output := outputData
+		for descriptor := range inputData {
+			descriptors++
+
+			if output == nil {
+				continue
+			}
+
+			wrapped := wrapFn(descriptor)
+			if wrapped == nil {
+				continue
+			}
+
+			select {
+			case output <- wrapped:
+			case <-ctx.Done():
+				close(output)
+				output = nil
+			}
+		}
+
+		if output != nil {
+			close(output)
+		}
+	}()
+
+	if onDone != nil {
+		go func() {
+			wg.Wait()
+			onDone(descriptors, errs)
+		}()
+	}
+
+	return outputData, outputErrors
+}
@@ -0,0 +1,44 @@
+package persistence
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestOpenContent_Fallback(t *testing.T) {
+	descriptor := &memoryDataDescriptor{dataPath{dirName1, fileName11}, fileContent}
+
+	reader, err := OpenContent(descriptor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(fileContent, content) {
+		t.Errorf(
+			"unexpected content\nexpected: [%s]\nactual:   [%s]",
+			fileContent,
+			content,
+		)
+	}
+}
+
+func TestDeleteData_Fallback(t *testing.T) {
+	descriptor := &memoryDataDescriptor{dataPath{dirName1, fileName11}, fileContent}
+
+	err := DeleteData(descriptor)
+	if !errors.Is(err, ErrDeleteNotSupported) {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			ErrDeleteNotSupported,
+			err,
+		)
+	}
+}
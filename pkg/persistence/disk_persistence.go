@@ -1,11 +1,17 @@
 package persistence
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"sync"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -14,57 +20,323 @@ const (
 	archiveDir  = "archive"
 	snapshotDir = "snapshot"
 
+	compressedArchiveExtension = ".tar.gz"
+
 	maxFileNameLength = 128
 )
 
+// DirectoryLayout determines the names of the subdirectories of the protected
+// disk persistence data directory.
+type DirectoryLayout struct {
+	// Current is the name of the directory holding the current data.
+	Current string
+	// Archive is the name of the directory holding the archived data.
+	Archive string
+	// Snapshot is the name of the directory holding the data snapshots.
+	Snapshot string
+}
+
+// DefaultDirectoryLayout is the directory layout used by the protected disk
+// persistence unless configured otherwise.
+var DefaultDirectoryLayout = DirectoryLayout{
+	Current:  currentDir,
+	Archive:  archiveDir,
+	Snapshot: snapshotDir,
+}
+
+// withDefaults returns a copy of the layout with the names that are not set
+// replaced by their default values.
+func (dl DirectoryLayout) withDefaults() DirectoryLayout {
+	if dl.Current == "" {
+		dl.Current = DefaultDirectoryLayout.Current
+	}
+	if dl.Archive == "" {
+		dl.Archive = DefaultDirectoryLayout.Archive
+	}
+	if dl.Snapshot == "" {
+		dl.Snapshot = DefaultDirectoryLayout.Snapshot
+	}
+
+	return dl
+}
+
+func (dl DirectoryLayout) validate() error {
+	if dl.Current == dl.Archive ||
+		dl.Current == dl.Snapshot ||
+		dl.Archive == dl.Snapshot {
+		return fmt.Errorf(
+			"directory layout names must be distinct; "+
+				"current: [%v], archive: [%v], snapshot: [%v]",
+			dl.Current,
+			dl.Archive,
+			dl.Snapshot,
+		)
+	}
+
+	for _, name := range []string{dl.Current, dl.Archive, dl.Snapshot} {
+		if len(name) > maxFileNameLength {
+			return fmt.Errorf(
+				"the maximum directory name length of [%v] exceeded for [%v]",
+				maxFileNameLength,
+				name,
+			)
+		}
+	}
+
+	return nil
+}
+
 type basicDiskPersistence struct {
-	dataDir string
+	dataDir         string
+	readRetryPolicy ReadRetryPolicy
+	writeBuffer     *writeBuffer
+	fileOperations  *fileOperations
 }
 
 type protectedDiskPersistence struct {
-	dataDir string
-
-	snapshotMutex           sync.Mutex
+	dataDir         string
+	layout          DirectoryLayout
+	readRetryPolicy ReadRetryPolicy
+	writeBuffer     *writeBuffer
+	fileOperations  *fileOperations
+
+	snapshotMutex           keyedMutex
+	snapshotSemaphore       chan struct{}
 	snapshotSuffixGenerator func() string
 }
 
-// NewBasicDiskHandle creates on-disk data persistence handle
-func NewBasicDiskHandle(path string) (BasicHandle, error) {
+// BasicDiskHandleOption is an option customizing the basic disk persistence
+// handle.
+type BasicDiskHandleOption func(*basicDiskPersistence)
+
+// WithBasicReadRetryPolicy sets the policy of retrying the failed directory
+// and file reads of the basic disk persistence handle. By default, failed
+// reads are not retried.
+func WithBasicReadRetryPolicy(policy ReadRetryPolicy) BasicDiskHandleOption {
+	return func(ds *basicDiskPersistence) {
+		ds.readRetryPolicy = policy
+	}
+}
+
+// WithBasicBufferedWrites makes the basic disk persistence handle buffer
+// the saved data in memory and write it to the disk according to the given
+// flush policy, trading durability for throughput. The buffered data is lost
+// on a crash. The buffered data is flushed for the last time once the given
+// context is done and the data saved afterwards is written synchronously.
+// The buffered data is returned from ReadAll just like the data already
+// written to the disk. The handle implements Flusher so that the buffered data
+// can be flushed explicitly. By default, the data is written to the disk
+// synchronously on each save.
+func WithBasicBufferedWrites(
+	ctx context.Context,
+	policy FlushPolicy,
+) BasicDiskHandleOption {
+	return func(ds *basicDiskPersistence) {
+		ds.writeBuffer = newWriteBuffer(
+			ctx,
+			ds.currentDirPath(),
+			policy,
+			ds.fileOperations,
+		)
+	}
+}
+
+// WithBasicFileOperationInterceptor makes the basic disk persistence handle
+// execute its file writes and syncs through the given interceptor. It is
+// meant for crash-consistency tests injecting failures into the write path.
+// By default, the operations are not intercepted.
+func WithBasicFileOperationInterceptor(
+	interceptor FileOperationInterceptor,
+) BasicDiskHandleOption {
+	return func(ds *basicDiskPersistence) {
+		ds.fileOperations.interceptor = interceptor
+	}
+}
+
+// NewBasicDiskHandle creates on-disk data persistence handle. The handle
+// implements Verifier so that the integrity of the data can be checked and
+// Partitioner so that the data can be read by multiple processes in parallel.
+func NewBasicDiskHandle(
+	path string,
+	options ...BasicDiskHandleOption,
+) (BasicHandle, error) {
 	if err := CheckStoragePermission(path); err != nil {
 		return nil, err
 	}
 
-	return &basicDiskPersistence{path}, nil
+	handle := &basicDiskPersistence{
+		dataDir:        path,
+		fileOperations: &fileOperations{},
+	}
+
+	for _, option := range options {
+		option(handle)
+	}
+
+	return handle, nil
+}
+
+// ProtectedDiskHandleOption is an option customizing the protected disk
+// persistence handle.
+type ProtectedDiskHandleOption func(*protectedDiskPersistence)
+
+// WithSnapshotSuffixGenerator sets the function generating the suffixes
+// appended to the names of snapshot files, e.g. to get human-sortable snapshot
+// names. The suffix must consist of letters, digits, and the `.`, `_`, `-`,
+// `+` characters only, so that it is safe to use in a file name. The name of
+// the snapshot file along with the suffix can not exceed the maximum file name
+// length. By default, the suffix is the dot-prefixed Unix timestamp in
+// milliseconds.
+func WithSnapshotSuffixGenerator(
+	generator func() string,
+) ProtectedDiskHandleOption {
+	return func(ds *protectedDiskPersistence) {
+		ds.snapshotSuffixGenerator = generator
+	}
+}
+
+// WithReadRetryPolicy sets the policy of retrying the failed directory and
+// file reads of the protected disk persistence handle. By default, failed
+// reads are not retried.
+func WithReadRetryPolicy(policy ReadRetryPolicy) ProtectedDiskHandleOption {
+	return func(ds *protectedDiskPersistence) {
+		ds.readRetryPolicy = policy
+	}
+}
+
+// WithMaxConcurrentSnapshots sets the maximum number of snapshots the
+// protected disk persistence handle takes at the same time. Snapshots in
+// different directories are taken concurrently and, by default, their number
+// is not limited. Snapshots in the same directory are always taken one by one.
+func WithMaxConcurrentSnapshots(limit int) ProtectedDiskHandleOption {
+	return func(ds *protectedDiskPersistence) {
+		if limit > 0 {
+			ds.snapshotSemaphore = make(chan struct{}, limit)
+		}
+	}
+}
+
+// WithBufferedWrites makes the protected disk persistence handle buffer
+// the saved data in memory and write it to the disk according to the given
+// flush policy, trading durability for throughput. The buffered data is lost
+// on a crash. The buffered data is flushed for the last time once the given
+// context is done and the data saved afterwards is written synchronously.
+// The buffered data is returned from ReadAll just like the data already
+// written to the disk and it is flushed before its directory is archived.
+// The handle implements Flusher so that the buffered data can be flushed
+// explicitly. Snapshots are never buffered. By default, the data is written to
+// the disk synchronously on each save.
+func WithBufferedWrites(
+	ctx context.Context,
+	policy FlushPolicy,
+) ProtectedDiskHandleOption {
+	return func(ds *protectedDiskPersistence) {
+		ds.writeBuffer = newWriteBuffer(
+			ctx,
+			ds.currentDirPath(),
+			policy,
+			ds.fileOperations,
+		)
+	}
+}
+
+// WithFileOperationInterceptor makes the protected disk persistence handle
+// execute its file writes, syncs, and renames through the given interceptor,
+// including the ones of snapshots and archives. It is meant for
+// crash-consistency tests injecting failures into the write path.
+// By default, the operations are not intercepted.
+func WithFileOperationInterceptor(
+	interceptor FileOperationInterceptor,
+) ProtectedDiskHandleOption {
+	return func(ds *protectedDiskPersistence) {
+		ds.fileOperations.interceptor = interceptor
+	}
 }
 
-// NewProtectedDiskHandle creates on-disk data persistence handle
-func NewProtectedDiskHandle(path string) (ProtectedHandle, error) {
+// TimestampSnapshotSuffix is the default snapshot suffix generator returning
+// the dot-prefixed Unix timestamp in milliseconds.
+func TimestampSnapshotSuffix() string {
+	timestamp := time.Now().UnixMilli()
+	return fmt.Sprintf(".%d", timestamp)
+}
+
+// snapshotSuffixRegexp matches the snapshot suffixes that are safe to use
+// in a file name.
+var snapshotSuffixRegexp = regexp.MustCompile(`^[A-Za-z0-9._+-]+$`)
+
+func validateSnapshotSuffix(suffix string) error {
+	if !snapshotSuffixRegexp.MatchString(suffix) ||
+		strings.Trim(suffix, ".") == "" {
+		return fmt.Errorf("invalid snapshot suffix [%v]", suffix)
+	}
+
+	if len(suffix) >= maxFileNameLength {
+		return fmt.Errorf(
+			"the snapshot suffix [%v] exceeds the maximum file name length of [%v]",
+			suffix,
+			maxFileNameLength,
+		)
+	}
+
+	return nil
+}
+
+// NewProtectedDiskHandle creates on-disk data persistence handle. The handle
+// implements Verifier so that the integrity of the data can be checked and
+// Partitioner so that the data can be read by multiple processes in parallel.
+func NewProtectedDiskHandle(
+	path string,
+	options ...ProtectedDiskHandleOption,
+) (ProtectedHandle, error) {
+	return NewProtectedDiskHandleWithLayout(
+		path,
+		DefaultDirectoryLayout,
+		options...,
+	)
+}
+
+// NewProtectedDiskHandleWithLayout creates on-disk data persistence handle
+// using the given names of the current, archive, and snapshot directories.
+// Names that are not set in the layout default to the ones from
+// DefaultDirectoryLayout.
+func NewProtectedDiskHandleWithLayout(
+	path string,
+	layout DirectoryLayout,
+	options ...ProtectedDiskHandleOption,
+) (ProtectedHandle, error) {
+	layout = layout.withDefaults()
+	if err := layout.validate(); err != nil {
+		return nil, err
+	}
+
 	if err := CheckStoragePermission(path); err != nil {
 		return nil, err
 	}
 
-	if err := EnsureDirectoryExists(path, currentDir); err != nil {
+	if err := EnsureDirectoryExists(path, layout.Current); err != nil {
 		return nil, err
 	}
 
-	if err := EnsureDirectoryExists(path, archiveDir); err != nil {
+	if err := EnsureDirectoryExists(path, layout.Archive); err != nil {
 		return nil, err
 	}
 
-	if err := EnsureDirectoryExists(path, snapshotDir); err != nil {
+	if err := EnsureDirectoryExists(path, layout.Snapshot); err != nil {
 		return nil, err
 	}
 
-	snapshotSuffixGenerator := func() string {
-		timestamp := time.Now().UnixMilli()
-		return fmt.Sprintf(".%d", timestamp)
+	handle := &protectedDiskPersistence{
+		dataDir:                 path,
+		layout:                  layout,
+		fileOperations:          &fileOperations{},
+		snapshotSuffixGenerator: TimestampSnapshotSuffix,
 	}
 
-	return &protectedDiskPersistence{
-		path,
-		sync.Mutex{},
-		snapshotSuffixGenerator,
-	}, nil
+	for _, option := range options {
+		option(handle)
+	}
+
+	return handle, nil
 }
 
 func (ds *basicDiskPersistence) currentDirPath() string {
@@ -72,18 +344,92 @@ func (ds *basicDiskPersistence) currentDirPath() string {
 }
 
 func (ds *protectedDiskPersistence) currentDirPath() string {
-	return filepath.Join(ds.dataDir, currentDir)
+	return filepath.Join(ds.dataDir, ds.layout.Current)
 }
 
 func (ds *basicDiskPersistence) Save(data []byte, dirName, fileName string) error {
-	return save(ds.currentDirPath(), data, dirName, fileName)
+	return save(
+		ds.currentDirPath(),
+		ds.writeBuffer,
+		ds.fileOperations,
+		data,
+		dirName,
+		fileName,
+	)
 }
 
 func (ds *protectedDiskPersistence) Save(data []byte, dirName, fileName string) error {
-	return save(ds.currentDirPath(), data, dirName, fileName)
+	return save(
+		ds.currentDirPath(),
+		ds.writeBuffer,
+		ds.fileOperations,
+		data,
+		dirName,
+		fileName,
+	)
+}
+
+// Flush writes all the buffered data to the disk. It does nothing if
+// the handle does not buffer writes.
+func (ds *basicDiskPersistence) Flush() error {
+	return flush(ds.writeBuffer)
+}
+
+// Flush writes all the buffered data to the disk. It does nothing if
+// the handle does not buffer writes.
+func (ds *protectedDiskPersistence) Flush() error {
+	return flush(ds.writeBuffer)
 }
 
-func save(directoryPath string, data []byte, dirName, fileName string) error {
+func save(
+	directoryPath string,
+	buffer *writeBuffer,
+	operations *fileOperations,
+	data []byte,
+	dirName string,
+	fileName string,
+) error {
+	if err := validateNameLengths(dirName, fileName); err != nil {
+		return err
+	}
+
+	if buffer != nil {
+		return buffer.save(data, dirName, fileName)
+	}
+
+	err := EnsureDirectoryExists(directoryPath, dirName)
+	if err != nil {
+		return err
+	}
+
+	return write(
+		filepath.Join(directoryPath, dirName, fileName),
+		data,
+		operations,
+	)
+}
+
+func flush(buffer *writeBuffer) error {
+	if buffer == nil {
+		return nil
+	}
+
+	return buffer.flush()
+}
+
+// bufferedFiles returns the files buffered in the given buffer or nil
+// if there is no buffer.
+func bufferedFiles(buffer *writeBuffer) map[string]map[string][]byte {
+	if buffer == nil {
+		return nil
+	}
+
+	return buffer.buffered()
+}
+
+// validateNameLengths returns an error if the directory or file name exceeds
+// the maximum file name length.
+func validateNameLengths(dirName, fileName string) error {
 	if len(dirName) > maxFileNameLength {
 		return fmt.Errorf(
 			"the maximum directory name length of [%v] exceeded for [%v]",
@@ -100,26 +446,95 @@ func save(directoryPath string, data []byte, dirName, fileName string) error {
 		)
 	}
 
-	err := EnsureDirectoryExists(directoryPath, dirName)
-	if err != nil {
-		return err
-	}
-
-	return Write(filepath.Join(directoryPath, dirName, fileName), data)
+	return nil
 }
 
 func (ds *basicDiskPersistence) ReadAll() (<-chan DataDescriptor, <-chan error) {
-	return readAll(ds.currentDirPath())
+	return ds.ReadAllWithContext(context.Background())
+}
+
+func (ds *basicDiskPersistence) ReadAllWithContext(
+	ctx context.Context,
+) (<-chan DataDescriptor, <-chan error) {
+	return readAll(
+		ctx,
+		ds.currentDirPath(),
+		nil,
+		ds.readRetryPolicy,
+		ds.Delete,
+		bufferedFiles(ds.writeBuffer),
+	)
+}
+
+func (ds *basicDiskPersistence) ReadAllFiltered(
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return ds.ReadAllFilteredWithContext(context.Background(), predicate)
+}
+
+func (ds *basicDiskPersistence) ReadAllFilteredWithContext(
+	ctx context.Context,
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return readAll(
+		ctx,
+		ds.currentDirPath(),
+		predicate,
+		ds.readRetryPolicy,
+		ds.Delete,
+		bufferedFiles(ds.writeBuffer),
+	)
 }
 
 func (ds *protectedDiskPersistence) ReadAll() (<-chan DataDescriptor, <-chan error) {
-	return readAll(ds.currentDirPath())
+	return ds.ReadAllWithContext(context.Background())
+}
+
+func (ds *protectedDiskPersistence) ReadAllWithContext(
+	ctx context.Context,
+) (<-chan DataDescriptor, <-chan error) {
+	return readAll(
+		ctx,
+		ds.currentDirPath(),
+		nil,
+		ds.readRetryPolicy,
+		nil,
+		bufferedFiles(ds.writeBuffer),
+	)
+}
+
+func (ds *protectedDiskPersistence) ReadAllFiltered(
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return ds.ReadAllFilteredWithContext(context.Background(), predicate)
+}
+
+func (ds *protectedDiskPersistence) ReadAllFilteredWithContext(
+	ctx context.Context,
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return readAll(
+		ctx,
+		ds.currentDirPath(),
+		predicate,
+		ds.readRetryPolicy,
+		nil,
+		bufferedFiles(ds.writeBuffer),
+	)
 }
 
 func (ds *basicDiskPersistence) Delete(dirName string, fileName string) error {
 	dirPath := ds.currentDirPath()
 	filePath := filepath.Join(dirPath, dirName, fileName)
 
+	if ds.writeBuffer != nil && ds.writeBuffer.remove(dirName, fileName) {
+		// The file may have never been written to the disk.
+		if err := remove(filePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
 	return remove(filePath)
 }
 
@@ -133,6 +548,9 @@ func (ds *protectedDiskPersistence) Snapshot(data []byte, dirName, fileName stri
 	}
 
 	snapshotSuffix := ds.snapshotSuffixGenerator()
+	if err := validateSnapshotSuffix(snapshotSuffix); err != nil {
+		return err
+	}
 
 	maxSnapshotFileNameLength := maxFileNameLength - len(snapshotSuffix)
 	if len(fileName) > maxSnapshotFileNameLength {
@@ -143,10 +561,17 @@ func (ds *protectedDiskPersistence) Snapshot(data []byte, dirName, fileName stri
 		)
 	}
 
-	ds.snapshotMutex.Lock()
-	defer ds.snapshotMutex.Unlock()
+	if ds.snapshotSemaphore != nil {
+		ds.snapshotSemaphore <- struct{}{}
+		defer func() { <-ds.snapshotSemaphore }()
+	}
+
+	// Snapshots of different directories can not collide so only snapshots
+	// of the same directory need to be serialized.
+	unlock := ds.snapshotMutex.lock(dirName)
+	defer unlock()
 
-	dirPath := filepath.Join(ds.dataDir, snapshotDir)
+	dirPath := filepath.Join(ds.dataDir, ds.layout.Snapshot)
 	err := EnsureDirectoryExists(dirPath, dirName)
 	if err != nil {
 		return err
@@ -162,7 +587,7 @@ func (ds *protectedDiskPersistence) Snapshot(data []byte, dirName, fileName stri
 		)
 	}
 
-	return Write(filePath, data)
+	return write(filePath, data, ds.fileOperations)
 }
 
 func isNonExistingFile(filePath string) bool {
@@ -179,10 +604,47 @@ func (ds *protectedDiskPersistence) Archive(directory string) error {
 		)
 	}
 
-	from := filepath.Join(ds.dataDir, currentDir, directory)
-	to := filepath.Join(ds.dataDir, archiveDir, directory)
+	if ds.writeBuffer != nil {
+		if err := ds.writeBuffer.flushDirectory(directory); err != nil {
+			return err
+		}
+	}
+
+	from := filepath.Join(ds.currentDirPath(), directory)
+	to := filepath.Join(ds.dataDir, ds.layout.Archive, directory)
+
+	return moveAll(from, to, ds.fileOperations)
+}
+
+// ArchiveCompressed packs all the files of the given current directory into
+// the archive/<directory>.tar.gz file and removes the current directory.
+// If the directory has already been archived in the compressed form, files
+// are appended to the existing archive file. Files with the same names are
+// replaced. The directory is not archived if it contains anything but regular
+// files, e.g. subdirectories or symbolic links.
+func (ds *protectedDiskPersistence) ArchiveCompressed(directory string) error {
+	if len(directory) > maxFileNameLength {
+		return fmt.Errorf(
+			"the maximum directory name length of [%v] exceeded for [%v]",
+			maxFileNameLength,
+			directory,
+		)
+	}
+
+	if ds.writeBuffer != nil {
+		if err := ds.writeBuffer.flushDirectory(directory); err != nil {
+			return err
+		}
+	}
+
+	from := filepath.Join(ds.currentDirPath(), directory)
+	to := filepath.Join(
+		ds.dataDir,
+		ds.layout.Archive,
+		directory+compressedArchiveExtension,
+	)
 
-	return moveAll(from, to)
+	return compressAll(from, to, ds.fileOperations)
 }
 
 // CheckStoragePermission returns an error if we don't have both read and write access to a directory.
@@ -223,6 +685,12 @@ func EnsureDirectoryExists(dirBasePath, newDirName string) error {
 
 // Write creates and writes data to a file
 func Write(filePath string, data []byte) error {
+	return write(filePath, data, nil)
+}
+
+// write creates and writes data to a file executing the write and sync
+// through the given file operations.
+func write(filePath string, data []byte, operations *fileOperations) error {
 	writeFile, err := os.Create(filepath.Clean(filePath))
 	if err != nil {
 		return err
@@ -230,12 +698,15 @@ func Write(filePath string, data []byte) error {
 
 	defer closeFile(writeFile)
 
-	_, err = writeFile.Write(data)
+	err = operations.run(FileOperationWrite, filePath, func() error {
+		_, err := writeFile.Write(data)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	err = writeFile.Sync()
+	err = operations.run(FileOperationSync, filePath, writeFile.Sync)
 	if err != nil {
 		return err
 	}
@@ -263,6 +734,15 @@ func Read(filePath string) ([]byte, error) {
 	return data, nil
 }
 
+// open a file from a file system for reading; the caller is responsible for
+// closing the returned file
+func open(filePath string) (io.ReadCloser, error) {
+	// #nosec G304 (file path provided as taint input)
+	// This line opens a file from the predefined storage.
+	// There is no user input.
+	return os.Open(filePath)
+}
+
 // remove a file from a file system
 func remove(filePath string) error {
 	return os.Remove(filePath)
@@ -280,8 +760,23 @@ func closeFile(file *os.File) {
 // occurred during file system reading are sent to the second output channel
 // returned from this function. The output can be later processed using
 // pipeline pattern. This function is non-blocking and returned channels are
-// not buffered. Channels are closed when there is no more to be read.
-func readAll(directoryPath string) (<-chan DataDescriptor, <-chan error) {
+// not buffered. Channels are closed when there is no more to be read or when
+// the provided context is done. If the predicate is set, only files for which
+// it returns true are read. Failed directory and file reads are retried
+// according to the given retry policy. If the delete function is set,
+// the returned data descriptors delete the files using it. Directories are
+// listed as a whole before their files are sent to the output channel so
+// deleting the files while the reading is still in progress is safe.
+// The buffered files, mapped by directory and file names, are read instead
+// of the files on the disk and in addition to them; the map is modified.
+func readAll(
+	ctx context.Context,
+	directoryPath string,
+	predicate func(dirName, fileName string) bool,
+	retryPolicy ReadRetryPolicy,
+	deleteFn func(dirName, fileName string) error,
+	buffered map[string]map[string][]byte,
+) (<-chan DataDescriptor, <-chan error) {
 	dataChannel := make(chan DataDescriptor)
 	errorChannel := make(chan error)
 
@@ -289,25 +784,67 @@ func readAll(directoryPath string) (<-chan DataDescriptor, <-chan error) {
 		defer close(dataChannel)
 		defer close(errorChannel)
 
-		files, err := ioutil.ReadDir(directoryPath)
+		// sendError sends the error to the output channel unless the context
+		// is done. It returns false if the context is done.
+		sendError := func(err error) bool {
+			select {
+			case errorChannel <- err:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// send sends the descriptor to the output channel unless the context
+		// is done. It returns false if the context is done.
+		send := func(descriptor *dataDescriptor) bool {
+			if deleteFn != nil {
+				dirName, fileName := descriptor.directory, descriptor.name
+				descriptor.deleteFunc = func() error {
+					return deleteFn(dirName, fileName)
+				}
+			}
+
+			select {
+			case dataChannel <- descriptor:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		files, err := retryRead(retryPolicy, func() ([]os.FileInfo, error) {
+			return ioutil.ReadDir(directoryPath)
+		})
 		if err != nil {
-			errorChannel <- fmt.Errorf(
+			if !sendError(fmt.Errorf(
 				"could not read the directory [%v]: [%v]",
 				directoryPath,
 				err,
-			)
+			)) {
+				return
+			}
 		}
 
 		for _, file := range files {
+			if ctx.Err() != nil {
+				return
+			}
+
 			if file.IsDir() {
-				dir, err := ioutil.ReadDir(filepath.Join(directoryPath, file.Name()))
+				dirPath := filepath.Join(directoryPath, file.Name())
+				dir, err := retryRead(retryPolicy, func() ([]os.FileInfo, error) {
+					return ioutil.ReadDir(dirPath)
+				})
 				if err != nil {
-					errorChannel <- fmt.Errorf(
+					if !sendError(fmt.Errorf(
 						"could not read the directory [%s/%s]: [%v]",
 						directoryPath,
 						file.Name(),
 						err,
-					)
+					)) {
+						return
+					}
 				}
 
 				for _, dirFile := range dir {
@@ -315,14 +852,50 @@ func readAll(directoryPath string) (<-chan DataDescriptor, <-chan error) {
 					dirName := file.Name()
 					fileName := dirFile.Name()
 
-					readFunc := func() ([]byte, error) {
-						return Read(filepath.Join(
-							directoryPath,
-							dirName,
-							fileName,
-						))
+					if predicate != nil && !predicate(dirName, fileName) {
+						continue
+					}
+
+					filePath := filepath.Join(directoryPath, dirName, fileName)
+
+					descriptor := &dataDescriptor{
+						name:      fileName,
+						directory: dirName,
+						readFunc: func() ([]byte, error) {
+							return retryRead(retryPolicy, func() ([]byte, error) {
+								return Read(filePath)
+							})
+						},
+						openFunc: func() (io.ReadCloser, error) {
+							return retryRead(retryPolicy, func() (io.ReadCloser, error) {
+								return open(filePath)
+							})
+						},
+					}
+
+					// The buffered content is newer than the one on the disk.
+					if data, ok := buffered[dirName][fileName]; ok {
+						descriptor = bufferedDescriptor(data, dirName, fileName)
+						delete(buffered[dirName], fileName)
+					}
+
+					if !send(descriptor) {
+						return
 					}
-					dataChannel <- &dataDescriptor{fileName, dirName, readFunc}
+				}
+			}
+		}
+
+		// Files buffered but not yet written to the disk at all.
+		for _, dirName := range sortedKeys(buffered) {
+			for _, fileName := range sortedKeys(buffered[dirName]) {
+				if predicate != nil && !predicate(dirName, fileName) {
+					continue
+				}
+
+				data := buffered[dirName][fileName]
+				if !send(bufferedDescriptor(data, dirName, fileName)) {
+					return
 				}
 			}
 		}
@@ -331,12 +904,277 @@ func readAll(directoryPath string) (<-chan DataDescriptor, <-chan error) {
 	return dataChannel, errorChannel
 }
 
-func moveAll(directoryFromPath, directoryToPath string) error {
+// bufferedDescriptor returns the descriptor of the file buffered in memory.
+func bufferedDescriptor(data []byte, dirName, fileName string) *dataDescriptor {
+	return &dataDescriptor{
+		name:      fileName,
+		directory: dirName,
+		readFunc: func() ([]byte, error) {
+			return append([]byte{}, data...), nil
+		},
+	}
+}
+
+// sortedKeys returns the sorted keys of the given map.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// ListArchivedDirectories returns the sorted names of all the directories
+// archived with Archive or ArchiveCompressed.
+func (ds *protectedDiskPersistence) ListArchivedDirectories() ([]string, error) {
+	archivePath := filepath.Join(ds.dataDir, ds.layout.Archive)
+
+	files, err := ioutil.ReadDir(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"could not read the directory [%v]: [%v]",
+			archivePath,
+			err,
+		)
+	}
+
+	// The same directory may be archived in both forms.
+	unique := make(map[string]bool)
+	for _, file := range files {
+		switch {
+		case file.IsDir():
+			unique[file.Name()] = true
+		case strings.HasSuffix(file.Name(), compressedArchiveExtension):
+			unique[strings.TrimSuffix(file.Name(), compressedArchiveExtension)] = true
+		}
+	}
+
+	directories := make([]string, 0, len(unique))
+	for directory := range unique {
+		directories = append(directories, directory)
+	}
+	sort.Strings(directories)
+
+	return directories, nil
+}
+
+func compressAll(
+	directoryFromPath string,
+	archiveFilePath string,
+	operations *fileOperations,
+) error {
+	files, err := ioutil.ReadDir(directoryFromPath)
+	if err != nil {
+		return fmt.Errorf(
+			"could not read directory [%v]: [%v]",
+			directoryFromPath,
+			err,
+		)
+	}
+
+	// Only regular files are packed into the archive. Refuse to archive
+	// anything else as the directory is removed once the archive is written.
+	for _, fileInfo := range files {
+		if !fileInfo.Mode().IsRegular() {
+			return fmt.Errorf(
+				"could not archive [%v] in directory [%v]: not a regular file",
+				fileInfo.Name(),
+				directoryFromPath,
+			)
+		}
+	}
+
+	// The archive is written to a temporary file first so that the existing
+	// archive is not corrupted if something goes wrong in the middle.
+	tempFilePath := archiveFilePath + ".tmp"
+
+	// A temporary file left behind by a crash in the middle of a previous
+	// archiving is stale. Remove it so that it does not block archiving
+	// the directory forever.
+	if err := os.Remove(tempFilePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf(
+			"could not remove stale archive file [%v]: [%v]",
+			tempFilePath,
+			err,
+		)
+	}
+
+	err = writeCompressedArchive(
+		tempFilePath,
+		archiveFilePath,
+		directoryFromPath,
+		files,
+		operations,
+	)
+	if err != nil {
+		os.Remove(tempFilePath)
+		return err
+	}
+
+	err = operations.run(FileOperationRename, tempFilePath, func() error {
+		return os.Rename(tempFilePath, archiveFilePath)
+	})
+	if err != nil {
+		return fmt.Errorf("error occurred while moving archive file: [%v]", err)
+	}
+
+	err = os.RemoveAll(directoryFromPath)
+	if err != nil {
+		return fmt.Errorf("error occurred while removing archived dir: [%v]", err)
+	}
+
+	return nil
+}
+
+func writeCompressedArchive(
+	filePath string,
+	existingArchiveFilePath string,
+	directoryPath string,
+	files []os.FileInfo,
+	operations *fileOperations,
+) error {
+	file, err := os.OpenFile(
+		filepath.Clean(filePath),
+		os.O_CREATE|os.O_EXCL|os.O_WRONLY,
+		0600,
+	)
+	if err != nil {
+		return fmt.Errorf("could not create archive file: [%v]", err)
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	newFiles := make(map[string]bool)
+	for _, fileInfo := range files {
+		newFiles[fileInfo.Name()] = true
+	}
+
+	err = copyCompressedArchive(existingArchiveFilePath, tarWriter, newFiles)
+	if err != nil {
+		return err
+	}
+
+	for _, fileInfo := range files {
+		header, err := tar.FileInfoHeader(fileInfo, "")
+		if err != nil {
+			return fmt.Errorf(
+				"could not create archive header for [%v]: [%v]",
+				fileInfo.Name(),
+				err,
+			)
+		}
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("could not write archive header: [%v]", err)
+		}
+
+		err = copyFile(filepath.Join(directoryPath, fileInfo.Name()), tarWriter)
+		if err != nil {
+			return fmt.Errorf(
+				"could not archive file [%v]: [%v]",
+				fileInfo.Name(),
+				err,
+			)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("could not close archive: [%v]", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("could not close archive compression: [%v]", err)
+	}
+
+	return operations.run(FileOperationSync, filePath, file.Sync)
+}
+
+// copyCompressedArchive copies entries of the existing compressed archive to
+// the given writer, skipping entries with the names from the skip set. It does
+// nothing if the archive does not exist.
+func copyCompressedArchive(
+	archiveFilePath string,
+	tarWriter *tar.Writer,
+	skip map[string]bool,
+) error {
+	file, err := os.Open(filepath.Clean(archiveFilePath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not open existing archive: [%v]", err)
+	}
+	defer file.Close()
+
+	return copyArchiveEntries(file, tarWriter, skip)
+}
+
+// copyArchiveEntries copies entries of the compressed archive read from the
+// given reader to the given writer, skipping entries with the names from
+// the skip set.
+func copyArchiveEntries(
+	reader io.Reader,
+	tarWriter *tar.Writer,
+	skip map[string]bool,
+) error {
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return fmt.Errorf("could not decompress existing archive: [%v]", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read existing archive: [%v]", err)
+		}
+
+		if skip[header.Name] {
+			continue
+		}
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("could not write archive header: [%v]", err)
+		}
+
+		// #nosec G110 (decompression bomb)
+		// The archive has been created by this persistence layer.
+		if _, err := io.Copy(tarWriter, tarReader); err != nil {
+			return fmt.Errorf("could not copy existing archive entry: [%v]", err)
+		}
+	}
+}
+
+func copyFile(filePath string, writer io.Writer) error {
+	file, err := open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(writer, file)
+	return err
+}
+
+func moveAll(
+	directoryFromPath string,
+	directoryToPath string,
+	operations *fileOperations,
+) error {
 	_, err := os.Stat(directoryToPath)
 
 	// target directory does not exist, we can move everything
 	if os.IsNotExist(err) {
-		err := os.Rename(directoryFromPath, directoryToPath)
+		err := operations.run(FileOperationRename, directoryFromPath, func() error {
+			return os.Rename(directoryFromPath, directoryToPath)
+		})
 		if err != nil {
 			return fmt.Errorf("error occurred while moving a dir: [%v]", err)
 		}
@@ -358,7 +1196,9 @@ func moveAll(directoryFromPath, directoryToPath string) error {
 	for _, file := range files {
 		from := filepath.Join(directoryFromPath, file.Name())
 		to := filepath.Join(directoryToPath, file.Name())
-		err := os.Rename(from, to)
+		err := operations.run(FileOperationRename, from, func() error {
+			return os.Rename(from, to)
+		})
 		if err != nil {
 			return err
 		}
@@ -1,8 +1,13 @@
 package persistence
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -10,12 +15,13 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 var (
-	dirCurrent  = "current"
-	dirArchive  = "archive"
-	dirSnapshot = "snapshot"
+	dirCurrent  = DefaultDirectoryLayout.Current
+	dirArchive  = DefaultDirectoryLayout.Archive
+	dirSnapshot = DefaultDirectoryLayout.Snapshot
 
 	dirName1   = "0x424242"
 	fileName11 = "file11"
@@ -266,6 +272,170 @@ func TestProtectedDiskPersistence_RefuseSnapshot_NameCollision(t *testing.T) {
 	}
 }
 
+func TestProtectedDiskPersistence_ConcurrentSnapshots(t *testing.T) {
+	var tests = map[string]struct {
+		options            []ProtectedDiskHandleOption
+		sameDirectory      bool
+		expectedSuccesses  int
+		expectedCollisions int
+	}{
+		"same directory": {
+			sameDirectory:      true,
+			expectedSuccesses:  1,
+			expectedCollisions: 9,
+		},
+		"different directories": {
+			sameDirectory:     false,
+			expectedSuccesses: 10,
+		},
+		"different directories with max concurrent snapshots": {
+			options:           []ProtectedDiskHandleOption{WithMaxConcurrentSnapshots(2)},
+			sameDirectory:     false,
+			expectedSuccesses: 10,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			handle, err := NewProtectedDiskHandle(t.TempDir(), test.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diskHandle := handle.(*protectedDiskPersistence)
+			// the same suffix causes collisions of snapshots taken in the
+			// same directory
+			diskHandle.snapshotSuffixGenerator = func() string {
+				return ".suffix"
+			}
+
+			var wg sync.WaitGroup
+			errs := make(chan error, 10)
+
+			for i := 0; i < 10; i++ {
+				dirName := dirName1
+				if !test.sameDirectory {
+					dirName = fmt.Sprintf("%s%d", dirName1, i)
+				}
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- diskHandle.Snapshot(fileContent, dirName, fileName11)
+				}()
+			}
+
+			wg.Wait()
+			close(errs)
+
+			successes, collisions := 0, 0
+			for err := range errs {
+				switch {
+				case err == nil:
+					successes++
+				case strings.Contains(err.Error(), "collision"):
+					collisions++
+				default:
+					t.Errorf("unexpected error: [%v]", err)
+				}
+			}
+
+			if successes != test.expectedSuccesses {
+				t.Errorf(
+					"unexpected number of snapshots\nexpected: [%v]\nactual:   [%v]",
+					test.expectedSuccesses,
+					successes,
+				)
+			}
+			if collisions != test.expectedCollisions {
+				t.Errorf(
+					"unexpected number of collisions\nexpected: [%v]\nactual:   [%v]",
+					test.expectedCollisions,
+					collisions,
+				)
+			}
+		})
+	}
+}
+
+func TestProtectedDiskPersistence_SnapshotSuffixGenerator(t *testing.T) {
+	dataDir := t.TempDir()
+
+	snapshotSuffix := ".20221005T103015.123Z"
+	handle, err := NewProtectedDiskHandle(
+		dataDir,
+		WithSnapshotSuffixGenerator(func() string {
+			return snapshotSuffix
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to initialize disk handle: %v", err)
+	}
+
+	err = handle.Snapshot(fileContent, dirName1, fileName11)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pathToFile := filepath.Join(dirSnapshot, dirName1, fileName11+snapshotSuffix)
+
+	assertExist(t, dataDir, pathToFile, "check file after snapshot")
+}
+
+func TestProtectedDiskPersistence_RefuseSnapshot_InvalidSuffix(t *testing.T) {
+	var tests = map[string]struct {
+		snapshotSuffix string
+		expectedError  string
+	}{
+		"empty suffix": {
+			snapshotSuffix: "",
+			expectedError:  "invalid snapshot suffix []",
+		},
+		"path separator": {
+			snapshotSuffix: "/../../suffix",
+			expectedError:  "invalid snapshot suffix [/../../suffix]",
+		},
+		"dots only": {
+			snapshotSuffix: "..",
+			expectedError:  "invalid snapshot suffix [..]",
+		},
+		"unsafe characters": {
+			snapshotSuffix: ".2022-10-05T10:30:15Z",
+			expectedError:  "invalid snapshot suffix [.2022-10-05T10:30:15Z]",
+		},
+		"too long suffix": {
+			snapshotSuffix: "." + maxAllowedName,
+			expectedError: fmt.Sprintf(
+				"the snapshot suffix [.%v] exceeds the maximum file name "+
+					"length of [128]",
+				maxAllowedName,
+			),
+		},
+	}
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			handle, err := NewProtectedDiskHandle(
+				t.TempDir(),
+				WithSnapshotSuffixGenerator(func() string {
+					return test.snapshotSuffix
+				}),
+			)
+			if err != nil {
+				t.Fatalf("failed to initialize disk handle: %v", err)
+			}
+
+			err = handle.Snapshot(fileContent, dirName1, fileName11)
+			if err == nil || err.Error() != test.expectedError {
+				t.Fatalf(
+					"unexpected error returned\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
+
 func TestDiskPersistence_StoragePermission(t *testing.T) {
 	var tests = map[string]struct {
 		newDiskPersistenceFn func(dataDir string) (RWHandle, error)
@@ -380,6 +550,206 @@ func TestDiskPersistence_ReadAll(t *testing.T) {
 	}
 }
 
+func TestDiskPersistence_ReadAllReader(t *testing.T) {
+	var tests = map[string]struct {
+		initDiskPersistenceFn func(t *testing.T) (RWHandle, string)
+	}{
+		"basic disk persistence": {
+			initDiskPersistenceFn: func(t *testing.T) (RWHandle, string) { return initBasicDiskPersistence(t) },
+		},
+		"protected disk persistence": {
+			initDiskPersistenceFn: func(t *testing.T) (RWHandle, string) { return initProtectedDiskPersistence(t) },
+		},
+	}
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			diskHandle, _ := test.initDiskPersistenceFn(t)
+
+			if err := diskHandle.Save(fileContent, dirName1, fileName11); err != nil {
+				t.Fatal(err)
+			}
+
+			dataChannel, errChannel := diskHandle.ReadAll()
+
+			go func() {
+				for err := range errChannel {
+					t.Error(err)
+				}
+			}()
+
+			var descriptors []DataDescriptor
+			for d := range dataChannel {
+				descriptors = append(descriptors, d)
+			}
+
+			if len(descriptors) != 1 {
+				t.Fatalf(
+					"unexpected number of descriptors\nexpected: [%v]\nactual:   [%v]",
+					1,
+					len(descriptors),
+				)
+			}
+
+			reader, err := OpenContent(descriptors[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+
+			content, err := ioutil.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(fileContent, content) {
+				t.Errorf(
+					"unexpected file content\nexpected: [%v]\nactual:   [%v]",
+					fileContent,
+					content,
+				)
+			}
+		})
+	}
+}
+
+func TestDiskPersistence_ReadAllFiltered(t *testing.T) {
+	var tests = map[string]struct {
+		initDiskPersistenceFn func(t *testing.T) (RWHandle, string)
+		currentDir            string
+	}{
+		"basic disk persistence": {
+			initDiskPersistenceFn: func(t *testing.T) (RWHandle, string) { return initBasicDiskPersistence(t) },
+			currentDir:            "",
+		},
+		"protected disk persistence": {
+			initDiskPersistenceFn: func(t *testing.T) (RWHandle, string) { return initProtectedDiskPersistence(t) },
+			currentDir:            dirCurrent,
+		},
+	}
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			diskHandle, dataDir := test.initDiskPersistenceFn(t)
+
+			if err := diskHandle.Save(fileContent, dirName1, fileName11); err != nil {
+				t.Fatal(err)
+			}
+			if err := diskHandle.Save(fileContent, dirName2, fileName21); err != nil {
+				t.Fatal(err)
+			}
+
+			// An entry whose content can not be read. If the filtered out
+			// entry was read, an error would be returned.
+			err := os.Mkdir(
+				filepath.Join(dataDir, test.currentDir, dirName1, fileName12),
+				0700,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dataChannel, errChannel := ReadAllFiltered(
+				diskHandle,
+				func(dirName, fileName string) bool {
+					return fileName != fileName12
+				},
+			)
+
+			go func() {
+				for err := range errChannel {
+					t.Error(err)
+				}
+			}()
+
+			var paths []string
+			for d := range dataChannel {
+				if _, err := d.Content(); err != nil {
+					t.Errorf("unexpected error: [%v]", err)
+				}
+				paths = append(paths, d.Directory()+"/"+d.Name())
+			}
+
+			expectedPaths := []string{
+				dirName1 + "/" + fileName11,
+				dirName2 + "/" + fileName21,
+			}
+			if !reflect.DeepEqual(expectedPaths, paths) {
+				t.Errorf(
+					"unexpected read data\nexpected: [%v]\nactual:   [%v]",
+					expectedPaths,
+					paths,
+				)
+			}
+		})
+	}
+}
+
+func TestDiskPersistence_ReadAllWithContext_Cancelled(t *testing.T) {
+	var tests = map[string]struct {
+		initDiskPersistenceFn func(t *testing.T) (RWHandle, string)
+	}{
+		"basic disk persistence": {
+			initDiskPersistenceFn: func(t *testing.T) (RWHandle, string) { return initBasicDiskPersistence(t) },
+		},
+		"protected disk persistence": {
+			initDiskPersistenceFn: func(t *testing.T) (RWHandle, string) { return initProtectedDiskPersistence(t) },
+		},
+	}
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			diskHandle, _ := test.initDiskPersistenceFn(t)
+
+			for i := 0; i < 10; i++ {
+				err := diskHandle.Save(fileContent, dirName1, fmt.Sprintf("file%v", i))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			dataChannel, errChannel := ReadAllWithContext(ctx, diskHandle)
+
+			// read just the first descriptor and stop consuming
+			<-dataChannel
+			cancel()
+
+			select {
+			case <-errChannel:
+			case <-time.After(time.Second):
+				t.Fatal("error channel has not been closed")
+			}
+
+			// The reader may have a descriptor ready to be sent at the time of
+			// cancellation, so drain the channel but make sure it closes
+			// before all the descriptors are read.
+			count := 1
+			timeout := time.After(time.Second)
+			for done := false; !done; {
+				select {
+				case _, ok := <-dataChannel:
+					if !ok {
+						done = true
+						continue
+					}
+					count++
+				case <-timeout:
+					t.Fatal("data channel has not been closed")
+				}
+			}
+
+			if count >= 10 {
+				t.Errorf(
+					"unexpected number of descriptors\n"+
+						"expected: [less than %v]\nactual:   [%v]",
+					10,
+					count,
+				)
+			}
+		})
+	}
+}
+
 func TestProtectedDiskPersistence_Archive(t *testing.T) {
 	diskHandle, dataDir := initProtectedDiskPersistence(t)
 
@@ -461,6 +831,313 @@ func TestProtectedDiskPersistence_AppendToArchive(t *testing.T) {
 	}
 }
 
+func TestProtectedDiskPersistence_ArchiveCompressed(t *testing.T) {
+	diskHandle, dataDir := initProtectedDiskPersistence(t)
+
+	pathMoveFrom := filepath.Join(dirCurrent, dirName1)
+	pathMoveTo := filepath.Join(dirArchive, dirName1+".tar.gz")
+
+	content11 := []byte{1, 1}
+	content12 := []byte{1, 2}
+	content13 := []byte{1, 3}
+
+	if err := diskHandle.Save(content11, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+	if err := diskHandle.Save(fileContent, dirName1, fileName12); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := diskHandle.ArchiveCompressed(dirName1); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNotExist(t, dataDir, pathMoveFrom, "check path from after archive")
+	assertExist(t, dataDir, pathMoveTo, "check path to after archive")
+
+	// Archive the directory once again; new files should be appended and
+	// files with the same names replaced.
+	if err := diskHandle.Save(content12, dirName1, fileName12); err != nil {
+		t.Fatal(err)
+	}
+	if err := diskHandle.Save(content13, dirName1, "file13"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := diskHandle.ArchiveCompressed(dirName1); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNotExist(t, dataDir, pathMoveFrom, "check path from after archive")
+	assertNotExist(t, dataDir, pathMoveTo+".tmp", "check temporary file")
+
+	expectedFiles := map[string][]byte{
+		fileName11: content11,
+		fileName12: content12,
+		"file13":   content13,
+	}
+
+	files := readCompressedArchive(t, filepath.Join(dataDir, pathMoveTo))
+	if !reflect.DeepEqual(expectedFiles, files) {
+		t.Errorf(
+			"unexpected archived files\nexpected: [%v]\nactual:   [%v]",
+			expectedFiles,
+			files,
+		)
+	}
+
+	dataChannel, errChannel := diskHandle.ReadAll()
+	go func() {
+		for err := range errChannel {
+			t.Error(err)
+		}
+	}()
+	for d := range dataChannel {
+		t.Errorf("unexpected descriptor [%v/%v]", d.Directory(), d.Name())
+	}
+}
+
+func TestProtectedDiskPersistence_ArchiveCompressed_StaleTemporaryFile(t *testing.T) {
+	diskHandle, dataDir := initProtectedDiskPersistence(t)
+
+	pathMoveFrom := filepath.Join(dirCurrent, dirName1)
+	pathMoveTo := filepath.Join(dirArchive, dirName1+".tar.gz")
+
+	if err := diskHandle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash in the middle of a previous archiving.
+	staleFilePath := filepath.Join(dataDir, pathMoveTo+".tmp")
+	if err := os.MkdirAll(filepath.Dir(staleFilePath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(staleFilePath, []byte("stale"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := diskHandle.ArchiveCompressed(dirName1); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNotExist(t, dataDir, pathMoveFrom, "check path from after archive")
+	assertNotExist(t, dataDir, pathMoveTo+".tmp", "check temporary file")
+
+	expectedFiles := map[string][]byte{fileName11: fileContent}
+	files := readCompressedArchive(t, filepath.Join(dataDir, pathMoveTo))
+	if !reflect.DeepEqual(expectedFiles, files) {
+		t.Errorf(
+			"unexpected archived files\nexpected: [%v]\nactual:   [%v]",
+			expectedFiles,
+			files,
+		)
+	}
+}
+
+func TestProtectedDiskPersistence_ArchiveCompressed_NonRegularEntry(t *testing.T) {
+	diskHandle, dataDir := initProtectedDiskPersistence(t)
+
+	pathMoveFrom := filepath.Join(dirCurrent, dirName1)
+	pathMoveTo := filepath.Join(dirArchive, dirName1+".tar.gz")
+
+	if err := diskHandle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	subdirectoryPath := filepath.Join(dataDir, pathMoveFrom, "subdirectory")
+	if err := os.Mkdir(subdirectoryPath, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	err := diskHandle.ArchiveCompressed(dirName1)
+	if err == nil || !strings.Contains(err.Error(), "not a regular file") {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+
+	assertExist(t, dataDir, pathMoveFrom, "check path from after archive")
+	assertExist(
+		t,
+		dataDir,
+		filepath.Join(pathMoveFrom, "subdirectory"),
+		"check subdirectory after archive",
+	)
+	assertNotExist(t, dataDir, pathMoveTo, "check path to after archive")
+	assertNotExist(t, dataDir, pathMoveTo+".tmp", "check temporary file")
+}
+
+func TestProtectedDiskPersistence_RefuseArchiveCompressed(t *testing.T) {
+	diskHandle, _ := initProtectedDiskPersistence(t)
+
+	err := diskHandle.ArchiveCompressed(notAllowedName)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if errDirectoryNameLength.Error() != err.Error() {
+		t.Fatalf(
+			"unexpected error returned\nexpected: [%v]\nactual:   [%v]",
+			errDirectoryNameLength.Error(),
+			err.Error(),
+		)
+	}
+}
+
+func TestProtectedDiskPersistence_ListArchivedDirectories(t *testing.T) {
+	diskHandle, _ := initProtectedDiskPersistence(t)
+
+	directories, err := diskHandle.ListArchivedDirectories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(directories) != 0 {
+		t.Fatalf("unexpected archived directories: [%v]", directories)
+	}
+
+	for _, dirName := range []string{dirName1, dirName2, "0x999999"} {
+		if err := diskHandle.Save(fileContent, dirName, fileName11); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := diskHandle.Archive(dirName2); err != nil {
+		t.Fatal(err)
+	}
+	if err := diskHandle.ArchiveCompressed(dirName1); err != nil {
+		t.Fatal(err)
+	}
+
+	// archive the same directory in both forms
+	if err := diskHandle.Save(fileContent, dirName1, fileName12); err != nil {
+		t.Fatal(err)
+	}
+	if err := diskHandle.Archive(dirName1); err != nil {
+		t.Fatal(err)
+	}
+
+	directories, err = diskHandle.ListArchivedDirectories()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDirectories := []string{dirName1, dirName2}
+	if !reflect.DeepEqual(expectedDirectories, directories) {
+		t.Errorf(
+			"unexpected archived directories\nexpected: [%v]\nactual:   [%v]",
+			expectedDirectories,
+			directories,
+		)
+	}
+}
+
+func TestProtectedDiskPersistence_DirectoryLayout(t *testing.T) {
+	var tests = map[string]struct {
+		layout         DirectoryLayout
+		expectedLayout DirectoryLayout
+	}{
+		"default layout": {
+			layout:         DefaultDirectoryLayout,
+			expectedLayout: DefaultDirectoryLayout,
+		},
+		"custom layout": {
+			layout: DirectoryLayout{
+				Current:  "keep-current",
+				Archive:  "keep-archive",
+				Snapshot: "keep-snapshot",
+			},
+			expectedLayout: DirectoryLayout{
+				Current:  "keep-current",
+				Archive:  "keep-archive",
+				Snapshot: "keep-snapshot",
+			},
+		},
+		"partially custom layout": {
+			layout: DirectoryLayout{
+				Current: "keep-current",
+			},
+			expectedLayout: DirectoryLayout{
+				Current:  "keep-current",
+				Archive:  dirArchive,
+				Snapshot: dirSnapshot,
+			},
+		},
+	}
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			dataDir := t.TempDir()
+			handle, err := NewProtectedDiskHandleWithLayout(dataDir, test.layout)
+			if err != nil {
+				t.Fatalf("failed to initialize disk handle: %v", err)
+			}
+			diskHandle := handle.(*protectedDiskPersistence)
+			diskHandle.snapshotSuffixGenerator = func() string {
+				return ".1"
+			}
+
+			layout := test.expectedLayout
+
+			assertExist(t, dataDir, layout.Current, "check current directory")
+			assertExist(t, dataDir, layout.Archive, "check archive directory")
+			assertExist(t, dataDir, layout.Snapshot, "check snapshot directory")
+
+			if err := diskHandle.Save(fileContent, dirName1, fileName11); err != nil {
+				t.Fatal(err)
+			}
+			assertExist(
+				t,
+				dataDir,
+				filepath.Join(layout.Current, dirName1, fileName11),
+				"check file after save",
+			)
+
+			if err := diskHandle.Snapshot(fileContent, dirName1, fileName11); err != nil {
+				t.Fatal(err)
+			}
+			assertExist(
+				t,
+				dataDir,
+				filepath.Join(layout.Snapshot, dirName1, fileName11+".1"),
+				"check file after snapshot",
+			)
+
+			if err := diskHandle.Archive(dirName1); err != nil {
+				t.Fatal(err)
+			}
+			assertNotExist(
+				t,
+				dataDir,
+				filepath.Join(layout.Current, dirName1),
+				"check path from after archive",
+			)
+			assertExist(
+				t,
+				dataDir,
+				filepath.Join(layout.Archive, dirName1, fileName11),
+				"check path to after archive",
+			)
+		})
+	}
+}
+
+func TestProtectedDiskPersistence_RefuseDirectoryLayout(t *testing.T) {
+	_, err := NewProtectedDiskHandleWithLayout(
+		t.TempDir(),
+		DirectoryLayout{
+			Current:  "data",
+			Archive:  "data",
+			Snapshot: "snapshot",
+		},
+	)
+
+	expectedError := "directory layout names must be distinct; " +
+		"current: [data], archive: [data], snapshot: [snapshot]"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf(
+			"unexpected error returned\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
 func TestBasicDiskPersistence_Delete(t *testing.T) {
 	diskHandle, dataDir := initBasicDiskPersistence(t)
 
@@ -502,6 +1179,109 @@ func TestBasicDiskPersistence_RefuseDelete(t *testing.T) {
 	}
 }
 
+func TestBasicDiskPersistence_DescriptorDelete(t *testing.T) {
+	diskHandle, dataDir := initBasicDiskPersistence(t)
+
+	diskHandle.Save(fileContent, dirName1, fileName11)
+	diskHandle.Save(fileContent, dirName1, fileName12)
+	diskHandle.Save(fileContent, dirName2, fileName21)
+
+	dataChannel, errorChannel := diskHandle.ReadAll()
+	go func() {
+		for err := range errorChannel {
+			t.Error(err)
+		}
+	}()
+
+	// Delete the files inline, while the rest is still being read.
+	for descriptor := range dataChannel {
+		if descriptor.Name() == fileName11 || descriptor.Name() == fileName21 {
+			if err := DeleteData(descriptor); err != nil {
+				t.Fatalf("unexpected error for Delete call: %v", err)
+			}
+		}
+	}
+
+	assertNotExist(
+		t,
+		dataDir,
+		filepath.Join(dirName1, fileName11),
+		"check deleted file",
+	)
+	assertNotExist(
+		t,
+		dataDir,
+		filepath.Join(dirName2, fileName21),
+		"check deleted file",
+	)
+	assertExist(
+		t,
+		dataDir,
+		filepath.Join(dirName1, fileName12),
+		"check not deleted file",
+	)
+}
+
+func TestProtectedDiskPersistence_RefuseDescriptorDelete(t *testing.T) {
+	diskHandle, dataDir := initProtectedDiskPersistence(t)
+
+	diskHandle.Save(fileContent, dirName1, fileName11)
+
+	descriptors, err := readAllDescriptors(diskHandle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = DeleteData(descriptors[0])
+	if !errors.Is(err, ErrDeleteNotSupported) {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			ErrDeleteNotSupported,
+			err,
+		)
+	}
+
+	assertExist(
+		t,
+		dataDir,
+		filepath.Join(currentDir, dirName1, fileName11),
+		"check not deleted file",
+	)
+}
+
+func readCompressedArchive(t *testing.T, archivePath string) map[string][]byte {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string][]byte)
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files[header.Name] = content
+	}
+}
+
 func initBasicDiskPersistence(t *testing.T) (*basicDiskPersistence, string) {
 	dataDir := t.TempDir()
 	handle, err := NewBasicDiskHandle(dataDir)
@@ -1,6 +1,7 @@
 package persistence
 
 import (
+	"context"
 	"crypto/sha256"
 
 	"github.com/keep-network/keep-common/pkg/encryption"
@@ -54,43 +55,64 @@ func (ep *encryptedPersistance[H]) Save(data []byte, directory string, name stri
 }
 
 func (ep *encryptedPersistance[H]) ReadAll() (<-chan DataDescriptor, <-chan error) {
-	outputData := make(chan DataDescriptor)
-	outputErrors := make(chan error)
-
-	inputData, inputErrors := ep.delegate.ReadAll()
-
-	// pass thru all errors from the input to the output channel without
-	// changing anything
-	go func() {
-		defer close(outputErrors)
-		for err := range inputErrors {
-			outputErrors <- err
-		}
-	}()
-
-	// pipe input data descriptor channel to the output data descriptor channel
-	// decorading the descriptor passed so that the content is decrypted on read
-	go func() {
-		defer close(outputData)
-		for descriptor := range inputData {
-			// capture shared loop variable's value for the closure
-			d := descriptor
-
-			outputData <- &dataDescriptor{
-				name:      d.Name(),
-				directory: d.Directory(),
+	return ep.ReadAllWithContext(context.Background())
+}
+
+func (ep *encryptedPersistance[H]) ReadAllWithContext(
+	ctx context.Context,
+) (<-chan DataDescriptor, <-chan error) {
+	inputData, inputErrors := ReadAllWithContext(ctx, ep.delegate)
+	return ep.decryptAll(ctx, inputData, inputErrors)
+}
+
+func (ep *encryptedPersistance[H]) ReadAllFiltered(
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return ep.ReadAllFilteredWithContext(context.Background(), predicate)
+}
+
+func (ep *encryptedPersistance[H]) ReadAllFilteredWithContext(
+	ctx context.Context,
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	inputData, inputErrors := ReadAllFilteredWithContext(
+		ctx,
+		ep.delegate,
+		predicate,
+	)
+	return ep.decryptAll(ctx, inputData, inputErrors)
+}
+
+// decryptAll pipes the data descriptors read by the delegate to the returned
+// channel decorating them so that the content is decrypted on read. Errors are
+// passed thru without any change.
+func (ep *encryptedPersistance[H]) decryptAll(
+	ctx context.Context,
+	inputData <-chan DataDescriptor,
+	inputErrors <-chan error,
+) (<-chan DataDescriptor, <-chan error) {
+	return wrapAll(
+		ctx,
+		inputData,
+		inputErrors,
+		func(descriptor DataDescriptor) DataDescriptor {
+			return &dataDescriptor{
+				name:      descriptor.Name(),
+				directory: descriptor.Directory(),
 				readFunc: func() ([]byte, error) {
-					content, err := d.Content()
+					content, err := descriptor.Content()
 					if err != nil {
 						return nil, err
 					}
 					return ep.box.Decrypt(content)
 				},
+				deleteFunc: func() error {
+					return DeleteData(descriptor)
+				},
 			}
-		}
-	}()
-
-	return outputData, outputErrors
+		},
+		nil,
+	)
 }
 
 func (ep *encryptedBasicPersistence) Delete(directory string, name string) error {
@@ -101,6 +123,14 @@ func (ep *encryptedProtectedPersistence) Archive(directory string) error {
 	return ep.delegate.Archive(directory)
 }
 
+func (ep *encryptedProtectedPersistence) ArchiveCompressed(directory string) error {
+	return ep.delegate.ArchiveCompressed(directory)
+}
+
+func (ep *encryptedProtectedPersistence) ListArchivedDirectories() ([]string, error) {
+	return ep.delegate.ListArchivedDirectories()
+}
+
 func (ep *encryptedProtectedPersistence) Snapshot(data []byte, directory string, name string) error {
 	encrypted, err := ep.box.Encrypt(data)
 	if err != nil {
@@ -134,6 +134,16 @@ func (dpm *delegatePersistenceMock) Archive(directory string) error {
 	return nil
 }
 
+func (dpm *delegatePersistenceMock) ArchiveCompressed(directory string) error {
+	// noop
+	return nil
+}
+
+func (dpm *delegatePersistenceMock) ListArchivedDirectories() ([]string, error) {
+	// noop
+	return nil, nil
+}
+
 func (dpm *delegatePersistenceMock) Delete(directory string, name string) error {
 	// noop
 	return nil
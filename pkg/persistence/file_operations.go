@@ -0,0 +1,48 @@
+package persistence
+
+// FileOperation identifies a file system operation of the disk persistence
+// handles that can be intercepted with a FileOperationInterceptor.
+type FileOperation string
+
+const (
+	// FileOperationWrite is writing the content of a file.
+	FileOperationWrite FileOperation = "write"
+	// FileOperationSync is committing the written content of a file to
+	// the stable storage.
+	FileOperationSync FileOperation = "sync"
+	// FileOperationRename is moving a file or a directory to another path.
+	FileOperationRename FileOperation = "rename"
+)
+
+// FileOperationInterceptor wraps a file system operation of the disk
+// persistence handles executed on the given path. The interceptor decides
+// whether the operation is executed by calling operationFn and what error is
+// returned to the handle. It lets the tests inject failures into the write
+// path, e.g. fail the sync of a written file or fail the rename after it has
+// succeeded, to verify the recovery from torn writes.
+type FileOperationInterceptor func(
+	operation FileOperation,
+	path string,
+	operationFn func() error,
+) error
+
+// fileOperations executes the intercepted file system operations of a disk
+// persistence handle. A nil fileOperations or one without an interceptor
+// executes the operations directly.
+type fileOperations struct {
+	interceptor FileOperationInterceptor
+}
+
+// run executes the given operation on the given path through
+// the interceptor, if one is set.
+func (fo *fileOperations) run(
+	operation FileOperation,
+	path string,
+	operationFn func() error,
+) error {
+	if fo == nil || fo.interceptor == nil {
+		return operationFn()
+	}
+
+	return fo.interceptor(operation, path, operationFn)
+}
@@ -0,0 +1,252 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var errInjected = errors.New("injected failure")
+
+func TestProtectedDiskPersistence_FileOperationInterceptor(t *testing.T) {
+	var tests = map[string]struct {
+		failedOperation    FileOperation
+		failAfterExecution bool
+		operationFn        func(handle ProtectedHandle) error
+		expectedOperations []FileOperation
+		expectedExist      []string
+		expectedNotExist   []string
+	}{
+		"save with failed write": {
+			failedOperation: FileOperationWrite,
+			operationFn: func(handle ProtectedHandle) error {
+				return handle.Save(fileContent, dirName1, fileName11)
+			},
+			expectedOperations: []FileOperation{FileOperationWrite},
+		},
+		"save with failed sync": {
+			failedOperation: FileOperationSync,
+			operationFn: func(handle ProtectedHandle) error {
+				return handle.Save(fileContent, dirName1, fileName11)
+			},
+			expectedOperations: []FileOperation{
+				FileOperationWrite,
+				FileOperationSync,
+			},
+		},
+		"snapshot with failed sync": {
+			failedOperation: FileOperationSync,
+			operationFn: func(handle ProtectedHandle) error {
+				return handle.Snapshot(fileContent, dirName1, fileName11)
+			},
+			expectedOperations: []FileOperation{
+				FileOperationWrite,
+				FileOperationSync,
+			},
+		},
+		"archive with failed rename": {
+			failedOperation: FileOperationRename,
+			operationFn: func(handle ProtectedHandle) error {
+				if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+					return err
+				}
+				return handle.Archive(dirName1)
+			},
+			expectedOperations: []FileOperation{
+				FileOperationWrite,
+				FileOperationSync,
+				FileOperationRename,
+			},
+			expectedExist: []string{
+				filepath.Join(dirCurrent, dirName1, fileName11),
+			},
+			expectedNotExist: []string{
+				filepath.Join(dirArchive, dirName1),
+			},
+		},
+		"archive compressed with rename failed after execution": {
+			failedOperation:    FileOperationRename,
+			failAfterExecution: true,
+			operationFn: func(handle ProtectedHandle) error {
+				if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+					return err
+				}
+				return handle.ArchiveCompressed(dirName1)
+			},
+			expectedOperations: []FileOperation{
+				FileOperationWrite,
+				FileOperationSync,
+				FileOperationSync,
+				FileOperationRename,
+			},
+			// The archive has been moved in place but the archived directory
+			// has not been removed.
+			expectedExist: []string{
+				filepath.Join(dirCurrent, dirName1, fileName11),
+				filepath.Join(dirArchive, dirName1+compressedArchiveExtension),
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			dataDir := t.TempDir()
+
+			var operations []FileOperation
+			interceptor := func(
+				operation FileOperation,
+				path string,
+				operationFn func() error,
+			) error {
+				operations = append(operations, operation)
+
+				if operation != test.failedOperation {
+					return operationFn()
+				}
+
+				if test.failAfterExecution {
+					if err := operationFn(); err != nil {
+						return err
+					}
+				}
+
+				return errInjected
+			}
+
+			handle, err := NewProtectedDiskHandle(
+				dataDir,
+				WithFileOperationInterceptor(interceptor),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = test.operationFn(handle)
+			// Some of the errors are wrapped so only their messages can be
+			// compared.
+			if err == nil || !strings.Contains(err.Error(), errInjected.Error()) {
+				t.Fatalf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					errInjected,
+					err,
+				)
+			}
+
+			if !reflect.DeepEqual(test.expectedOperations, operations) {
+				t.Errorf(
+					"unexpected operations\nexpected: [%v]\nactual:   [%v]",
+					test.expectedOperations,
+					operations,
+				)
+			}
+
+			for _, path := range test.expectedExist {
+				assertExist(t, dataDir, path, "file left by the failed operation")
+			}
+			for _, path := range test.expectedNotExist {
+				assertNotExist(t, dataDir, path, "file of the failed operation")
+			}
+		})
+	}
+}
+
+func TestBasicDiskPersistence_FileOperationInterceptor_BufferedWrites(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	failing := true
+	interceptor := func(
+		operation FileOperation,
+		path string,
+		operationFn func() error,
+	) error {
+		if failing && operation == FileOperationSync {
+			return errInjected
+		}
+		return operationFn()
+	}
+
+	dataDir := t.TempDir()
+	handle, err := NewBasicDiskHandle(
+		dataDir,
+		WithBasicBufferedWrites(ctx, FlushPolicy{}),
+		WithBasicFileOperationInterceptor(interceptor),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	flusher := handle.(Flusher)
+
+	if err := flusher.Flush(); !errors.Is(err, errInjected) {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			errInjected,
+			err,
+		)
+	}
+
+	// The file failed to be flushed is kept in the buffer so it is written
+	// once the failure is gone.
+	failing = false
+
+	if err := flusher.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertExist(
+		t,
+		dataDir,
+		filepath.Join(dirName1, fileName11),
+		"flushed file",
+	)
+}
+
+func TestBasicDiskPersistence_FileOperationInterceptor_BufferedWritesThreshold(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interceptor := func(
+		operation FileOperation,
+		path string,
+		operationFn func() error,
+	) error {
+		if operation == FileOperationSync {
+			return errInjected
+		}
+		return operationFn()
+	}
+
+	handle, err := NewBasicDiskHandle(
+		t.TempDir(),
+		WithBasicBufferedWrites(ctx, FlushPolicy{MaxBufferedBytes: 1}),
+		WithBasicFileOperationInterceptor(interceptor),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = handle.Save(fileContent, dirName1, fileName11)
+	if !errors.Is(err, errInjected) {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			errInjected,
+			err,
+		)
+	}
+
+	// The file failed to be flushed by the save is not kept in the buffer
+	// so that the failed save has no effect and a retry does not write
+	// the file twice.
+	buffered := handle.(*basicDiskPersistence).writeBuffer.buffered()
+	if len(buffered) != 0 {
+		t.Errorf("unexpected buffered files: [%s]", buffered)
+	}
+}
@@ -0,0 +1,48 @@
+package persistence
+
+import "sync"
+
+// keyedMutex is a mutual exclusion lock keyed by strings. Holding the lock
+// for one key does not block locking any other key so operations on
+// unrelated resources can proceed concurrently. The zero value is an unlocked
+// mutex for all the keys.
+type keyedMutex struct {
+	mutex sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock is the lock of a single key along with the number of goroutines
+// holding or waiting for it, so that it can be dropped once unused.
+type keyLock struct {
+	sync.Mutex
+	references int
+}
+
+// lock locks the given key and returns the function unlocking it. If the key
+// is already locked, it blocks until the key is available.
+func (km *keyedMutex) lock(key string) func() {
+	km.mutex.Lock()
+	if km.locks == nil {
+		km.locks = make(map[string]*keyLock)
+	}
+	lock, ok := km.locks[key]
+	if !ok {
+		lock = &keyLock{}
+		km.locks[key] = lock
+	}
+	lock.references++
+	km.mutex.Unlock()
+
+	lock.Lock()
+
+	return func() {
+		lock.Unlock()
+
+		km.mutex.Lock()
+		lock.references--
+		if lock.references == 0 {
+			delete(km.locks, key)
+		}
+		km.mutex.Unlock()
+	}
+}
@@ -0,0 +1,50 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	var mutex keyedMutex
+
+	unlockFirst := mutex.lock("first")
+
+	// Locking an unrelated key does not wait for the first key.
+	otherLocked := make(chan func())
+	go func() {
+		otherLocked <- mutex.lock("second")
+	}()
+
+	select {
+	case unlockSecond := <-otherLocked:
+		unlockSecond()
+	case <-time.After(time.Second):
+		t.Fatal("locking unrelated key has been blocked")
+	}
+
+	// Locking the same key waits until the key is unlocked.
+	sameLocked := make(chan func())
+	go func() {
+		sameLocked <- mutex.lock("first")
+	}()
+
+	select {
+	case <-sameLocked:
+		t.Fatal("locking already locked key has not been blocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlockFirst()
+
+	select {
+	case unlockFirstAgain := <-sameLocked:
+		unlockFirstAgain()
+	case <-time.After(time.Second):
+		t.Fatal("locking unlocked key has been blocked")
+	}
+
+	if len(mutex.locks) != 0 {
+		t.Errorf("unexpected number of locks left: [%v]", len(mutex.locks))
+	}
+}
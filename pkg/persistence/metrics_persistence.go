@@ -0,0 +1,275 @@
+package persistence
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Names of the storage operations reported to the StorageMetricsReporter.
+const (
+	StorageOperationSave                    = "save"
+	StorageOperationReadAll                 = "read_all"
+	StorageOperationReadAllFiltered         = "read_all_filtered"
+	StorageOperationDelete                  = "delete"
+	StorageOperationArchive                 = "archive"
+	StorageOperationArchiveCompressed       = "archive_compressed"
+	StorageOperationListArchivedDirectories = "list_archived_directories"
+	StorageOperationSnapshot                = "snapshot"
+)
+
+// StorageMetricsReporter receives the access metrics of a handle created
+// with NewBasicPersistenceWithMetrics or NewProtectedPersistenceWithMetrics.
+// The reporter is called from the goroutines executing the operations and
+// streaming the read data so it must be safe for concurrent use.
+type StorageMetricsReporter interface {
+	// ReportOperation reports the duration of a completed operation and
+	// the error the operation failed with, if any.
+	ReportOperation(operation string, duration time.Duration, err error)
+
+	// ReportReadAll reports the duration of a completed read of all the data
+	// along with the number of data descriptors and errors streamed.
+	ReportReadAll(operation string, duration time.Duration, files int, errors int)
+
+	// ReportBytesRead reports the number of bytes of the content read from
+	// a data descriptor streamed by the given read operation.
+	ReportBytesRead(operation string, bytes int)
+}
+
+// NoopStorageMetricsReporter is a StorageMetricsReporter discarding all
+// the reported metrics.
+type NoopStorageMetricsReporter struct{}
+
+// ReportOperation does nothing.
+func (NoopStorageMetricsReporter) ReportOperation(string, time.Duration, error) {}
+
+// ReportReadAll does nothing.
+func (NoopStorageMetricsReporter) ReportReadAll(string, time.Duration, int, int) {}
+
+// ReportBytesRead does nothing.
+func (NoopStorageMetricsReporter) ReportBytesRead(string, int) {}
+
+type metricsPersistence[H RWHandle] struct {
+	delegate H
+	reporter StorageMetricsReporter
+}
+
+type metricsBasicPersistence struct {
+	metricsPersistence[BasicHandle]
+}
+
+type metricsProtectedPersistence struct {
+	metricsPersistence[ProtectedHandle]
+}
+
+// NewBasicPersistenceWithMetrics creates a handle measuring the duration of
+// each operation of the given handle and reporting it to the given reporter.
+// For the read operations, the number of streamed data descriptors and errors
+// as well as the number of bytes of the read content are reported too.
+// The handle can wrap any other handle, e.g. the mirrored or encrypted one.
+func NewBasicPersistenceWithMetrics(
+	handle BasicHandle,
+	reporter StorageMetricsReporter,
+) BasicHandle {
+	return &metricsBasicPersistence{
+		metricsPersistence: metricsPersistence[BasicHandle]{
+			delegate: handle,
+			reporter: reporter,
+		},
+	}
+}
+
+// NewProtectedPersistenceWithMetrics creates a handle measuring the duration
+// of each operation of the given handle and reporting it to the given
+// reporter. For the read operations, the number of streamed data descriptors
+// and errors as well as the number of bytes of the read content are reported
+// too. The handle can wrap any other handle, e.g. the mirrored or encrypted
+// one.
+func NewProtectedPersistenceWithMetrics(
+	handle ProtectedHandle,
+	reporter StorageMetricsReporter,
+) ProtectedHandle {
+	return &metricsProtectedPersistence{
+		metricsPersistence[ProtectedHandle]{
+			delegate: handle,
+			reporter: reporter,
+		},
+	}
+}
+
+// measure executes the given operation and reports its duration and error.
+func (mp *metricsPersistence[H]) measure(
+	operation string,
+	operationFn func() error,
+) error {
+	startTime := time.Now()
+	err := operationFn()
+	mp.reporter.ReportOperation(operation, time.Since(startTime), err)
+	return err
+}
+
+func (mp *metricsPersistence[H]) Save(data []byte, directory string, name string) error {
+	return mp.measure(StorageOperationSave, func() error {
+		return mp.delegate.Save(data, directory, name)
+	})
+}
+
+func (mp *metricsPersistence[H]) ReadAll() (<-chan DataDescriptor, <-chan error) {
+	return mp.ReadAllWithContext(context.Background())
+}
+
+func (mp *metricsPersistence[H]) ReadAllWithContext(
+	ctx context.Context,
+) (<-chan DataDescriptor, <-chan error) {
+	startTime := time.Now()
+	inputData, inputErrors := ReadAllWithContext(ctx, mp.delegate)
+	return mp.measureAll(
+		ctx,
+		StorageOperationReadAll,
+		startTime,
+		inputData,
+		inputErrors,
+	)
+}
+
+func (mp *metricsPersistence[H]) ReadAllFiltered(
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return mp.ReadAllFilteredWithContext(context.Background(), predicate)
+}
+
+func (mp *metricsPersistence[H]) ReadAllFilteredWithContext(
+	ctx context.Context,
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	startTime := time.Now()
+	inputData, inputErrors := ReadAllFilteredWithContext(
+		ctx,
+		mp.delegate,
+		predicate,
+	)
+	return mp.measureAll(
+		ctx,
+		StorageOperationReadAllFiltered,
+		startTime,
+		inputData,
+		inputErrors,
+	)
+}
+
+// measureAll pipes the data descriptors and errors read by the delegate to
+// the returned channels counting them. The descriptors are decorated so that
+// the number of bytes of the content is reported on read and deleting the data
+// is measured. Once both input channels are closed, the duration of the read
+// and the counts are reported.
+func (mp *metricsPersistence[H]) measureAll(
+	ctx context.Context,
+	operation string,
+	startTime time.Time,
+	inputData <-chan DataDescriptor,
+	inputErrors <-chan error,
+) (<-chan DataDescriptor, <-chan error) {
+	return wrapAll(
+		ctx,
+		inputData,
+		inputErrors,
+		func(descriptor DataDescriptor) DataDescriptor {
+			return &dataDescriptor{
+				name:      descriptor.Name(),
+				directory: descriptor.Directory(),
+				readFunc: func() ([]byte, error) {
+					content, err := descriptor.Content()
+					if err != nil {
+						return nil, err
+					}
+					mp.reporter.ReportBytesRead(operation, len(content))
+					return content, nil
+				},
+				openFunc: func() (io.ReadCloser, error) {
+					reader, err := OpenContent(descriptor)
+					if err != nil {
+						return nil, err
+					}
+					return &countingReadCloser{
+						ReadCloser: reader,
+						onClose: func(bytes int) {
+							mp.reporter.ReportBytesRead(operation, bytes)
+						},
+					}, nil
+				},
+				deleteFunc: func() error {
+					return mp.measure(StorageOperationDelete, func() error {
+						return DeleteData(descriptor)
+					})
+				},
+			}
+		},
+		func(descriptors int, errors int) {
+			mp.reporter.ReportReadAll(
+				operation,
+				time.Since(startTime),
+				descriptors,
+				errors,
+			)
+		},
+	)
+}
+
+// countingReadCloser counts the bytes read from the underlying reader and
+// passes the count to onClose once the reader is closed.
+type countingReadCloser struct {
+	io.ReadCloser
+
+	bytes   int
+	onClose func(bytes int)
+	closed  bool
+}
+
+func (crc *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := crc.ReadCloser.Read(p)
+	crc.bytes += n
+	return n, err
+}
+
+func (crc *countingReadCloser) Close() error {
+	if !crc.closed {
+		crc.closed = true
+		crc.onClose(crc.bytes)
+	}
+
+	return crc.ReadCloser.Close()
+}
+
+func (mp *metricsBasicPersistence) Delete(directory string, name string) error {
+	return mp.measure(StorageOperationDelete, func() error {
+		return mp.delegate.Delete(directory, name)
+	})
+}
+
+func (mp *metricsProtectedPersistence) Archive(directory string) error {
+	return mp.measure(StorageOperationArchive, func() error {
+		return mp.delegate.Archive(directory)
+	})
+}
+
+func (mp *metricsProtectedPersistence) ArchiveCompressed(directory string) error {
+	return mp.measure(StorageOperationArchiveCompressed, func() error {
+		return mp.delegate.ArchiveCompressed(directory)
+	})
+}
+
+func (mp *metricsProtectedPersistence) ListArchivedDirectories() ([]string, error) {
+	var directories []string
+	err := mp.measure(StorageOperationListArchivedDirectories, func() error {
+		var err error
+		directories, err = mp.delegate.ListArchivedDirectories()
+		return err
+	})
+	return directories, err
+}
+
+func (mp *metricsProtectedPersistence) Snapshot(data []byte, directory string, name string) error {
+	return mp.measure(StorageOperationSnapshot, func() error {
+		return mp.delegate.Snapshot(data, directory, name)
+	})
+}
@@ -0,0 +1,295 @@
+package persistence
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPersistenceWithMetrics_Operations(t *testing.T) {
+	var tests = map[string]struct {
+		operationFn       func(handle ProtectedHandle) error
+		expectedOperation string
+	}{
+		"save": {
+			operationFn: func(handle ProtectedHandle) error {
+				return handle.Save(fileContent, dirName1, fileName11)
+			},
+			expectedOperation: StorageOperationSave,
+		},
+		"snapshot": {
+			operationFn: func(handle ProtectedHandle) error {
+				return handle.Snapshot(fileContent, dirName1, fileName11)
+			},
+			expectedOperation: StorageOperationSnapshot,
+		},
+		"archive": {
+			operationFn: func(handle ProtectedHandle) error {
+				return handle.Archive(dirName2)
+			},
+			expectedOperation: StorageOperationArchive,
+		},
+		"archive compressed": {
+			operationFn: func(handle ProtectedHandle) error {
+				return handle.ArchiveCompressed(dirName2)
+			},
+			expectedOperation: StorageOperationArchiveCompressed,
+		},
+		"list archived directories": {
+			operationFn: func(handle ProtectedHandle) error {
+				_, err := handle.ListArchivedDirectories()
+				return err
+			},
+			expectedOperation: StorageOperationListArchivedDirectories,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			delegate, _ := initProtectedDiskPersistence(t)
+			if err := delegate.Save(fileContent, dirName2, fileName21); err != nil {
+				t.Fatal(err)
+			}
+
+			reporter := &mockStorageMetricsReporter{}
+			handle := NewProtectedPersistenceWithMetrics(delegate, reporter)
+
+			if err := test.operationFn(handle); err != nil {
+				t.Fatal(err)
+			}
+
+			expectedOperations := []string{test.expectedOperation}
+			if !reflect.DeepEqual(expectedOperations, reporter.operations) {
+				t.Errorf(
+					"unexpected reported operations\n"+
+						"expected: [%v]\nactual:   [%v]",
+					expectedOperations,
+					reporter.operations,
+				)
+			}
+		})
+	}
+}
+
+func TestPersistenceWithMetrics_Delete(t *testing.T) {
+	delegate, _ := initBasicDiskPersistence(t)
+	if err := delegate.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	reporter := &mockStorageMetricsReporter{}
+	handle := NewBasicPersistenceWithMetrics(delegate, reporter)
+
+	if err := handle.Delete(dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedOperations := []string{StorageOperationDelete}
+	if !reflect.DeepEqual(expectedOperations, reporter.operations) {
+		t.Errorf(
+			"unexpected reported operations\nexpected: [%v]\nactual:   [%v]",
+			expectedOperations,
+			reporter.operations,
+		)
+	}
+}
+
+func TestPersistenceWithMetrics_DescriptorDelete(t *testing.T) {
+	delegate, _ := initBasicDiskPersistence(t)
+	if err := delegate.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	reporter := &mockStorageMetricsReporter{
+		readAllDone: make(chan struct{}),
+	}
+	handle := NewBasicPersistenceWithMetrics(delegate, reporter)
+
+	descriptors, err := collectDescriptors(handle.ReadAll())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(descriptors) != 1 {
+		t.Fatalf("unexpected number of descriptors: [%v]", len(descriptors))
+	}
+
+	if err := DeleteData(descriptors[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	reporter.mutex.Lock()
+	defer reporter.mutex.Unlock()
+
+	expectedOperations := []string{StorageOperationDelete}
+	if !reflect.DeepEqual(expectedOperations, reporter.operations) {
+		t.Errorf(
+			"unexpected reported operations\nexpected: [%v]\nactual:   [%v]",
+			expectedOperations,
+			reporter.operations,
+		)
+	}
+}
+
+func TestPersistenceWithMetrics_ReadAll(t *testing.T) {
+	var tests = map[string]struct {
+		readFn            func(handle ProtectedHandle) (<-chan DataDescriptor, <-chan error)
+		expectedOperation string
+		expectedFiles     int
+	}{
+		"read all": {
+			readFn: func(handle ProtectedHandle) (<-chan DataDescriptor, <-chan error) {
+				return handle.ReadAll()
+			},
+			expectedOperation: StorageOperationReadAll,
+			expectedFiles:     2,
+		},
+		"read all filtered": {
+			readFn: func(handle ProtectedHandle) (<-chan DataDescriptor, <-chan error) {
+				return ReadAllFiltered(handle, func(dirName, fileName string) bool {
+					return dirName == dirName1
+				})
+			},
+			expectedOperation: StorageOperationReadAllFiltered,
+			expectedFiles:     1,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			delegate, _ := initProtectedDiskPersistence(t)
+			if err := delegate.Save(fileContent, dirName1, fileName11); err != nil {
+				t.Fatal(err)
+			}
+			if err := delegate.Save(fileContent, dirName2, fileName21); err != nil {
+				t.Fatal(err)
+			}
+
+			reporter := &mockStorageMetricsReporter{
+				readAllDone: make(chan struct{}),
+			}
+			handle := NewProtectedPersistenceWithMetrics(delegate, reporter)
+
+			dataChan, errorChan := test.readFn(handle)
+
+			// The content of the first descriptor is read in one piece and
+			// the content of the others is streamed.
+			readDescriptors := 0
+			for descriptor := range dataChan {
+				if readDescriptors == 0 {
+					if _, err := descriptor.Content(); err != nil {
+						t.Fatal(err)
+					}
+				} else {
+					reader, err := OpenContent(descriptor)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if _, err := io.ReadAll(reader); err != nil {
+						t.Fatal(err)
+					}
+					if err := reader.Close(); err != nil {
+						t.Fatal(err)
+					}
+				}
+				readDescriptors++
+			}
+			for err := range errorChan {
+				t.Fatal(err)
+			}
+
+			select {
+			case <-reporter.readAllDone:
+			case <-time.After(time.Second):
+				t.Fatal("read all has not been reported")
+			}
+
+			reporter.mutex.Lock()
+			defer reporter.mutex.Unlock()
+
+			if reporter.readAllOperation != test.expectedOperation {
+				t.Errorf(
+					"unexpected reported operation\n"+
+						"expected: [%v]\nactual:   [%v]",
+					test.expectedOperation,
+					reporter.readAllOperation,
+				)
+			}
+
+			if reporter.files != test.expectedFiles {
+				t.Errorf(
+					"unexpected reported files\nexpected: [%v]\nactual:   [%v]",
+					test.expectedFiles,
+					reporter.files,
+				)
+			}
+
+			if reporter.errors != 0 {
+				t.Errorf(
+					"unexpected reported errors\nexpected: [%v]\nactual:   [%v]",
+					0,
+					reporter.errors,
+				)
+			}
+
+			expectedBytes := test.expectedFiles * len(fileContent)
+			if reporter.bytes != expectedBytes {
+				t.Errorf(
+					"unexpected reported bytes\nexpected: [%v]\nactual:   [%v]",
+					expectedBytes,
+					reporter.bytes,
+				)
+			}
+		})
+	}
+}
+
+type mockStorageMetricsReporter struct {
+	mutex sync.Mutex
+
+	operations []string
+
+	readAllOperation string
+	files            int
+	errors           int
+	readAllDone      chan struct{}
+
+	bytes int
+}
+
+func (msmr *mockStorageMetricsReporter) ReportOperation(
+	operation string,
+	duration time.Duration,
+	err error,
+) {
+	msmr.mutex.Lock()
+	defer msmr.mutex.Unlock()
+
+	msmr.operations = append(msmr.operations, operation)
+}
+
+func (msmr *mockStorageMetricsReporter) ReportReadAll(
+	operation string,
+	duration time.Duration,
+	files int,
+	errors int,
+) {
+	msmr.mutex.Lock()
+	defer msmr.mutex.Unlock()
+
+	msmr.readAllOperation = operation
+	msmr.files = files
+	msmr.errors = errors
+	close(msmr.readAllDone)
+}
+
+func (msmr *mockStorageMetricsReporter) ReportBytesRead(
+	operation string,
+	bytes int,
+) {
+	msmr.mutex.Lock()
+	defer msmr.mutex.Unlock()
+
+	msmr.bytes += bytes
+}
@@ -0,0 +1,145 @@
+package persistence
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Migrate copies all data returned by ReadAll of the source handle to the
+// destination handle, preserving the directory and file names. It can be used
+// to switch between storage backends, e.g. from plain to encrypted disk
+// persistence. Migrate returns the first error that occurred when reading
+// the source data or saving it to the destination handle. The source data is
+// left untouched.
+func Migrate(src RWHandle, dst RWHandle) error {
+	_, err := migrate(src, dst)
+	return err
+}
+
+// MigrateAndVerify migrates the data just like Migrate and then reads all data
+// from the destination handle back to verify every migrated file is present
+// there with unchanged content.
+func MigrateAndVerify(src RWHandle, dst RWHandle) error {
+	migrated, err := migrate(src, dst)
+	if err != nil {
+		return err
+	}
+
+	read, err := readAllContent(dst)
+	if err != nil {
+		return fmt.Errorf("could not read migrated data: [%w]", err)
+	}
+
+	for path, content := range migrated {
+		readContent, ok := read[path]
+		if !ok {
+			return fmt.Errorf(
+				"migrated file [%s/%s] is missing in the destination",
+				path.directory,
+				path.name,
+			)
+		}
+
+		if !bytes.Equal(content, readContent) {
+			return fmt.Errorf(
+				"migrated file [%s/%s] content does not match the source",
+				path.directory,
+				path.name,
+			)
+		}
+	}
+
+	return nil
+}
+
+// dataPath identifies a file in the persistence layer.
+type dataPath struct {
+	directory string
+	name      string
+}
+
+// migrate saves all data read from the source handle to the destination
+// handle. It returns the migrated content.
+func migrate(src RWHandle, dst RWHandle) (map[dataPath][]byte, error) {
+	descriptors, readErr := readAllDescriptors(src)
+
+	migrated := make(map[dataPath][]byte)
+
+	for _, descriptor := range descriptors {
+		content, err := descriptor.Content()
+		if err != nil {
+			return nil, fmt.Errorf(
+				"could not read [%s/%s]: [%w]",
+				descriptor.Directory(),
+				descriptor.Name(),
+				err,
+			)
+		}
+
+		err = dst.Save(content, descriptor.Directory(), descriptor.Name())
+		if err != nil {
+			return nil, fmt.Errorf(
+				"could not save [%s/%s]: [%w]",
+				descriptor.Directory(),
+				descriptor.Name(),
+				err,
+			)
+		}
+
+		path := dataPath{descriptor.Directory(), descriptor.Name()}
+		migrated[path] = content
+	}
+
+	// Data read successfully before the read error occurred is migrated
+	// anyway; the error is surfaced once that is done.
+	if readErr != nil {
+		return nil, fmt.Errorf("could not read source data: [%w]", readErr)
+	}
+
+	return migrated, nil
+}
+
+// readAllContent reads the content of all data returned by ReadAll of the
+// given handle.
+func readAllContent(handle RWHandle) (map[dataPath][]byte, error) {
+	descriptors, err := readAllDescriptors(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make(map[dataPath][]byte, len(descriptors))
+	for _, descriptor := range descriptors {
+		content, err := descriptor.Content()
+		if err != nil {
+			return nil, err
+		}
+
+		contents[dataPath{descriptor.Directory(), descriptor.Name()}] = content
+	}
+
+	return contents, nil
+}
+
+// readAllDescriptors collects all descriptors returned by ReadAll of the
+// given handle along with the first error that occurred during reading.
+func readAllDescriptors(handle RWHandle) ([]DataDescriptor, error) {
+	dataChannel, errorChannel := handle.ReadAll()
+
+	errorsDone := make(chan error)
+	go func() {
+		var firstErr error
+		for err := range errorChannel {
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		errorsDone <- firstErr
+	}()
+
+	var descriptors []DataDescriptor
+	for descriptor := range dataChannel {
+		descriptors = append(descriptors, descriptor)
+	}
+
+	return descriptors, <-errorsDone
+}
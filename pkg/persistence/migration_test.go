@@ -0,0 +1,210 @@
+package persistence
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	var tests = map[string]struct {
+		migrateFn func(src RWHandle, dst RWHandle) error
+	}{
+		"without verification": {
+			migrateFn: Migrate,
+		},
+		"with verification": {
+			migrateFn: MigrateAndVerify,
+		},
+	}
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			src, _ := initBasicDiskPersistence(t)
+			dst := newMemoryPersistence()
+
+			if err := src.Save(fileContent, dirName1, fileName11); err != nil {
+				t.Fatal(err)
+			}
+			if err := src.Save(fileContent, dirName1, fileName12); err != nil {
+				t.Fatal(err)
+			}
+			if err := src.Save([]byte{1, 2, 3}, dirName2, fileName21); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := test.migrateFn(src, dst); err != nil {
+				t.Fatal(err)
+			}
+
+			expectedData := map[dataPath][]byte{
+				{dirName1, fileName11}: fileContent,
+				{dirName1, fileName12}: fileContent,
+				{dirName2, fileName21}: {1, 2, 3},
+			}
+			if !reflect.DeepEqual(expectedData, dst.data) {
+				t.Errorf(
+					"unexpected migrated data\nexpected: [%v]\nactual:   [%v]",
+					expectedData,
+					dst.data,
+				)
+			}
+		})
+	}
+}
+
+func TestMigrate_ToDisk(t *testing.T) {
+	src := newMemoryPersistence()
+	dst, dataDir := initProtectedDiskPersistence(t)
+
+	if err := src.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MigrateAndVerify(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	assertExist(
+		t,
+		dataDir,
+		filepath.Join(dirCurrent, dirName1, fileName11),
+		"check file after migration",
+	)
+}
+
+func TestMigrate_SaveError(t *testing.T) {
+	src := newMemoryPersistence()
+	dst, _ := initBasicDiskPersistence(t)
+
+	if err := src.Save(fileContent, notAllowedName, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Migrate(src, dst)
+
+	expectedError := fmt.Sprintf(
+		"could not save [%s/%s]: [%v]",
+		notAllowedName,
+		fileName11,
+		errDirectoryNameLength,
+	)
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf(
+			"unexpected error returned\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
+func TestMigrate_ReadError(t *testing.T) {
+	src := newMemoryPersistence()
+	src.readErr = fmt.Errorf("disk failure")
+	dst := newMemoryPersistence()
+
+	if err := src.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Migrate(src, dst)
+
+	expectedError := "could not read source data: [disk failure]"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf(
+			"unexpected error returned\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
+func TestMigrateAndVerify_ContentMismatch(t *testing.T) {
+	src := newMemoryPersistence()
+	dst := newMemoryPersistence()
+	dst.corruptOnSave = true
+
+	if err := src.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	err := MigrateAndVerify(src, dst)
+
+	expectedError := fmt.Sprintf(
+		"migrated file [%s/%s] content does not match the source",
+		dirName1,
+		fileName11,
+	)
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf(
+			"unexpected error returned\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
+// memoryPersistence is an in-memory RWHandle used for testing.
+type memoryPersistence struct {
+	mutex sync.Mutex
+	data  map[dataPath][]byte
+
+	readErr       error
+	corruptOnSave bool
+}
+
+func newMemoryPersistence() *memoryPersistence {
+	return &memoryPersistence{data: make(map[dataPath][]byte)}
+}
+
+func (mp *memoryPersistence) Save(data []byte, directory string, name string) error {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	saved := make([]byte, len(data))
+	copy(saved, data)
+	if mp.corruptOnSave && len(saved) > 0 {
+		saved[0]++
+	}
+
+	mp.data[dataPath{directory, name}] = saved
+	return nil
+}
+
+func (mp *memoryPersistence) ReadAll() (<-chan DataDescriptor, <-chan error) {
+	mp.mutex.Lock()
+	defer mp.mutex.Unlock()
+
+	dataChannel := make(chan DataDescriptor, len(mp.data))
+	errorChannel := make(chan error, 1)
+
+	for path, content := range mp.data {
+		dataChannel <- &memoryDataDescriptor{path, content}
+	}
+	if mp.readErr != nil {
+		errorChannel <- mp.readErr
+	}
+
+	close(dataChannel)
+	close(errorChannel)
+
+	return dataChannel, errorChannel
+}
+
+type memoryDataDescriptor struct {
+	path    dataPath
+	content []byte
+}
+
+func (mdd *memoryDataDescriptor) Name() string {
+	return mdd.path.name
+}
+
+func (mdd *memoryDataDescriptor) Directory() string {
+	return mdd.path.directory
+}
+
+func (mdd *memoryDataDescriptor) Content() ([]byte, error) {
+	return mdd.content, nil
+}
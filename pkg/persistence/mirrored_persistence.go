@@ -0,0 +1,205 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+type mirroredPersistence[H RWHandle] struct {
+	primary   H
+	secondary H
+
+	failOnSecondaryError bool
+}
+
+type mirroredBasicPersistence struct {
+	mirroredPersistence[BasicHandle]
+}
+
+type mirroredProtectedPersistence struct {
+	mirroredPersistence[ProtectedHandle]
+}
+
+// NewMirroredBasicPersistence creates a handle writing the data through to
+// both the primary and the secondary handle, for example a disk and a remote
+// store, and reading the data only from the primary handle. A write fails if
+// it fails for the primary handle. If failOnSecondaryError is false, a write
+// failing only for the secondary handle is logged and does not fail.
+func NewMirroredBasicPersistence(
+	primary BasicHandle,
+	secondary BasicHandle,
+	failOnSecondaryError bool,
+) BasicHandle {
+	return &mirroredBasicPersistence{
+		mirroredPersistence: mirroredPersistence[BasicHandle]{
+			primary:              primary,
+			secondary:            secondary,
+			failOnSecondaryError: failOnSecondaryError,
+		},
+	}
+}
+
+// NewMirroredProtectedPersistence creates a handle writing the data through
+// to both the primary and the secondary handle, for example a disk and
+// a remote store, and reading the data only from the primary handle. A write
+// fails if it fails for the primary handle. If failOnSecondaryError is false,
+// a write failing only for the secondary handle is logged and does not fail.
+func NewMirroredProtectedPersistence(
+	primary ProtectedHandle,
+	secondary ProtectedHandle,
+	failOnSecondaryError bool,
+) ProtectedHandle {
+	return &mirroredProtectedPersistence{
+		mirroredPersistence[ProtectedHandle]{
+			primary:              primary,
+			secondary:            secondary,
+			failOnSecondaryError: failOnSecondaryError,
+		},
+	}
+}
+
+// mirror executes the write operation against the primary handle and, if
+// it succeeded, against the secondary handle.
+func (mp *mirroredPersistence[H]) mirror(
+	operation string,
+	writeFn func(handle H) error,
+) error {
+	if err := writeFn(mp.primary); err != nil {
+		return err
+	}
+
+	if err := writeFn(mp.secondary); err != nil {
+		if mp.failOnSecondaryError {
+			return fmt.Errorf(
+				"%v failed for the secondary handle: [%v]",
+				operation,
+				err,
+			)
+		}
+
+		logger.Errorf(
+			"%v failed for the secondary handle: [%v]",
+			operation,
+			err,
+		)
+	}
+
+	return nil
+}
+
+func (mp *mirroredPersistence[H]) Save(data []byte, directory string, name string) error {
+	return mp.mirror("save", func(handle H) error {
+		return handle.Save(data, directory, name)
+	})
+}
+
+func (mp *mirroredPersistence[H]) ReadAll() (<-chan DataDescriptor, <-chan error) {
+	return mp.primary.ReadAll()
+}
+
+func (mp *mirroredPersistence[H]) ReadAllWithContext(
+	ctx context.Context,
+) (<-chan DataDescriptor, <-chan error) {
+	return ReadAllWithContext(ctx, mp.primary)
+}
+
+func (mp *mirroredPersistence[H]) ReadAllFiltered(
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return ReadAllFiltered(mp.primary, predicate)
+}
+
+func (mp *mirroredPersistence[H]) ReadAllFilteredWithContext(
+	ctx context.Context,
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return ReadAllFilteredWithContext(ctx, mp.primary, predicate)
+}
+
+func (mp *mirroredBasicPersistence) ReadAll() (<-chan DataDescriptor, <-chan error) {
+	return mp.ReadAllWithContext(context.Background())
+}
+
+func (mp *mirroredBasicPersistence) ReadAllWithContext(
+	ctx context.Context,
+) (<-chan DataDescriptor, <-chan error) {
+	inputData, inputErrors := ReadAllWithContext(ctx, mp.primary)
+	return mp.mirrorDeletes(ctx, inputData, inputErrors)
+}
+
+func (mp *mirroredBasicPersistence) ReadAllFiltered(
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return mp.ReadAllFilteredWithContext(context.Background(), predicate)
+}
+
+func (mp *mirroredBasicPersistence) ReadAllFilteredWithContext(
+	ctx context.Context,
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	inputData, inputErrors := ReadAllFilteredWithContext(
+		ctx,
+		mp.primary,
+		predicate,
+	)
+	return mp.mirrorDeletes(ctx, inputData, inputErrors)
+}
+
+// mirrorDeletes pipes the data descriptors read from the primary handle to
+// the returned channel decorating them so that deleting the data deletes it
+// from both the primary and the secondary handle. Errors are passed thru
+// without any change.
+func (mp *mirroredBasicPersistence) mirrorDeletes(
+	ctx context.Context,
+	inputData <-chan DataDescriptor,
+	inputErrors <-chan error,
+) (<-chan DataDescriptor, <-chan error) {
+	return wrapAll(
+		ctx,
+		inputData,
+		inputErrors,
+		func(descriptor DataDescriptor) DataDescriptor {
+			return &dataDescriptor{
+				name:      descriptor.Name(),
+				directory: descriptor.Directory(),
+				readFunc:  descriptor.Content,
+				openFunc: func() (io.ReadCloser, error) {
+					return OpenContent(descriptor)
+				},
+				deleteFunc: func() error {
+					return mp.Delete(descriptor.Directory(), descriptor.Name())
+				},
+			}
+		},
+		nil,
+	)
+}
+
+func (mp *mirroredBasicPersistence) Delete(directory string, name string) error {
+	return mp.mirror("delete", func(handle BasicHandle) error {
+		return handle.Delete(directory, name)
+	})
+}
+
+func (mp *mirroredProtectedPersistence) Archive(directory string) error {
+	return mp.mirror("archive", func(handle ProtectedHandle) error {
+		return handle.Archive(directory)
+	})
+}
+
+func (mp *mirroredProtectedPersistence) ArchiveCompressed(directory string) error {
+	return mp.mirror("compressed archive", func(handle ProtectedHandle) error {
+		return handle.ArchiveCompressed(directory)
+	})
+}
+
+func (mp *mirroredProtectedPersistence) ListArchivedDirectories() ([]string, error) {
+	return mp.primary.ListArchivedDirectories()
+}
+
+func (mp *mirroredProtectedPersistence) Snapshot(data []byte, directory string, name string) error {
+	return mp.mirror("snapshot", func(handle ProtectedHandle) error {
+		return handle.Snapshot(data, directory, name)
+	})
+}
@@ -0,0 +1,218 @@
+package persistence
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestMirroredProtectedPersistence_WritesToBoth(t *testing.T) {
+	primary, primaryDir := initProtectedDiskPersistence(t)
+	secondary, secondaryDir := initProtectedDiskPersistence(t)
+
+	handle := NewMirroredProtectedPersistence(primary, secondary, true)
+
+	if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.Snapshot(fileContent, dirName2, fileName21); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.Archive(dirName1); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dataDir := range []string{primaryDir, secondaryDir} {
+		assertExist(
+			t,
+			dataDir,
+			filepath.Join(dirArchive, dirName1, fileName11),
+			"saved and archived file",
+		)
+		assertExist(
+			t,
+			dataDir,
+			filepath.Join(dirSnapshot, dirName2),
+			"snapshot directory",
+		)
+	}
+}
+
+func TestMirroredBasicPersistence_DeletesFromBoth(t *testing.T) {
+	primary, primaryDir := initBasicDiskPersistence(t)
+	secondary, secondaryDir := initBasicDiskPersistence(t)
+
+	handle := NewMirroredBasicPersistence(primary, secondary, true)
+
+	if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dataDir := range []string{primaryDir, secondaryDir} {
+		assertExist(
+			t,
+			dataDir,
+			filepath.Join(dirName1, fileName11),
+			"saved file",
+		)
+	}
+
+	if err := handle.Delete(dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dataDir := range []string{primaryDir, secondaryDir} {
+		assertNotExist(
+			t,
+			dataDir,
+			filepath.Join(dirName1, fileName11),
+			"deleted file",
+		)
+	}
+}
+
+func TestMirroredBasicPersistence_DescriptorDeletesFromBoth(t *testing.T) {
+	primary, primaryDir := initBasicDiskPersistence(t)
+	secondary, secondaryDir := initBasicDiskPersistence(t)
+
+	handle := NewMirroredBasicPersistence(primary, secondary, true)
+
+	if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	descriptors, err := readAllDescriptors(handle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeleteData(descriptors[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dataDir := range []string{primaryDir, secondaryDir} {
+		assertNotExist(
+			t,
+			dataDir,
+			filepath.Join(dirName1, fileName11),
+			"deleted file",
+		)
+	}
+}
+
+func TestMirroredPersistence_ReadsFromPrimary(t *testing.T) {
+	primary, _ := initProtectedDiskPersistence(t)
+	secondary, _ := initProtectedDiskPersistence(t)
+
+	if err := primary.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+	if err := secondary.Save(fileContent, dirName2, fileName21); err != nil {
+		t.Fatal(err)
+	}
+
+	handle := NewMirroredProtectedPersistence(primary, secondary, true)
+
+	dataChannel, errChannel := handle.ReadAll()
+	go func() {
+		for err := range errChannel {
+			t.Error(err)
+		}
+	}()
+
+	var descriptors []DataDescriptor
+	for d := range dataChannel {
+		descriptors = append(descriptors, d)
+	}
+
+	if len(descriptors) != 1 {
+		t.Fatalf(
+			"unexpected number of descriptors\nexpected: [%v]\nactual:   [%v]",
+			1,
+			len(descriptors),
+		)
+	}
+
+	if descriptors[0].Directory() != dirName1 || descriptors[0].Name() != fileName11 {
+		t.Errorf(
+			"unexpected descriptor\nexpected: [%v/%v]\nactual:   [%v/%v]",
+			dirName1,
+			fileName11,
+			descriptors[0].Directory(),
+			descriptors[0].Name(),
+		)
+	}
+}
+
+func TestMirroredPersistence_WriteErrors(t *testing.T) {
+	var tests = map[string]struct {
+		primaryErr           error
+		secondaryErr         error
+		failOnSecondaryError bool
+		expectedError        error
+	}{
+		"primary fails": {
+			primaryErr:    fmt.Errorf("disk full"),
+			expectedError: fmt.Errorf("disk full"),
+		},
+		"secondary fails and its errors are tolerated": {
+			secondaryErr:         fmt.Errorf("remote unavailable"),
+			failOnSecondaryError: false,
+			expectedError:        nil,
+		},
+		"secondary fails and its errors are not tolerated": {
+			secondaryErr:         fmt.Errorf("remote unavailable"),
+			failOnSecondaryError: true,
+			expectedError: fmt.Errorf(
+				"save failed for the secondary handle: [remote unavailable]",
+			),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			primary := &failingPersistenceMock{err: test.primaryErr}
+			secondary := &failingPersistenceMock{err: test.secondaryErr}
+
+			handle := NewMirroredProtectedPersistence(
+				primary,
+				secondary,
+				test.failOnSecondaryError,
+			)
+
+			err := handle.Save(fileContent, dirName1, fileName11)
+			if fmt.Sprint(test.expectedError) != fmt.Sprint(err) {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+
+			expectedSecondaryWrites := 1
+			if test.primaryErr != nil {
+				expectedSecondaryWrites = 0
+			}
+			if secondary.writes != expectedSecondaryWrites {
+				t.Errorf(
+					"unexpected number of secondary writes\n"+
+						"expected: [%v]\nactual:   [%v]",
+					expectedSecondaryWrites,
+					secondary.writes,
+				)
+			}
+		})
+	}
+}
+
+type failingPersistenceMock struct {
+	delegatePersistenceMock
+
+	err    error
+	writes int
+}
+
+func (fpm *failingPersistenceMock) Save(data []byte, directory string, name string) error {
+	fpm.writes++
+	return fpm.err
+}
@@ -0,0 +1,567 @@
+package persistence
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ObjectStore is an interface of an S3-compatible object storage the object
+// store persistence is backed by. Object keys are slash-separated paths.
+// Implementations are expected to adapt the client of the given storage
+// provider to this interface.
+type ObjectStore interface {
+	// PutObject stores the data under the given key, replacing the object
+	// stored under that key, if any.
+	PutObject(ctx context.Context, key string, data []byte) error
+
+	// GetObject returns a reader streaming the content of the object stored
+	// under the given key. The caller is responsible for closing the returned
+	// reader.
+	GetObject(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// ObjectExists checks if an object is stored under the given key.
+	ObjectExists(ctx context.Context, key string) (bool, error)
+
+	// ListObjects returns keys of all the objects with keys starting with
+	// the given prefix.
+	ListObjects(ctx context.Context, prefix string) ([]string, error)
+
+	// CopyObject copies the object stored under the source key to
+	// the destination key, replacing the object stored under the destination
+	// key, if any.
+	CopyObject(ctx context.Context, sourceKey, destinationKey string) error
+
+	// DeleteObject removes the object stored under the given key.
+	DeleteObject(ctx context.Context, key string) error
+}
+
+// objectStorePersistence maps directories and files of the persistence layer
+// to object keys in the form of <prefix>/<root>/<directory>/<file>.
+type objectStorePersistence struct {
+	store  ObjectStore
+	prefix string
+}
+
+type basicObjectStorePersistence struct {
+	objectStorePersistence
+}
+
+type protectedObjectStorePersistence struct {
+	objectStorePersistence
+
+	layout DirectoryLayout
+
+	snapshotMutex           keyedMutex
+	snapshotSuffixGenerator func() string
+}
+
+// NewBasicObjectStoreHandle creates data persistence handle backed by
+// the given object store. All the objects are stored under the given key
+// prefix which may be empty.
+func NewBasicObjectStoreHandle(store ObjectStore, prefix string) BasicHandle {
+	return &basicObjectStorePersistence{
+		objectStorePersistence{
+			store:  store,
+			prefix: strings.Trim(prefix, "/"),
+		},
+	}
+}
+
+// NewProtectedObjectStoreHandle creates data persistence handle backed by
+// the given object store. All the objects are stored under the given key
+// prefix which may be empty, and then under the keys of the current, archive,
+// and snapshot directories from DefaultDirectoryLayout. Archiving and taking
+// snapshots follow the same naming and collision rules as the protected disk
+// persistence.
+func NewProtectedObjectStoreHandle(
+	store ObjectStore,
+	prefix string,
+) ProtectedHandle {
+	return &protectedObjectStorePersistence{
+		objectStorePersistence: objectStorePersistence{
+			store:  store,
+			prefix: strings.Trim(prefix, "/"),
+		},
+		layout:                  DefaultDirectoryLayout,
+		snapshotSuffixGenerator: TimestampSnapshotSuffix,
+	}
+}
+
+// key returns the object key for the given path elements.
+func (osp *objectStorePersistence) key(elements ...string) string {
+	return path.Join(append([]string{osp.prefix}, elements...)...)
+}
+
+// keyPrefix returns the prefix of the keys of all the objects stored under
+// the given path elements.
+func (osp *objectStorePersistence) keyPrefix(elements ...string) string {
+	key := osp.key(elements...)
+	if key == "" {
+		return ""
+	}
+
+	return key + "/"
+}
+
+func (osp *objectStorePersistence) save(
+	root string,
+	data []byte,
+	dirName string,
+	fileName string,
+) error {
+	if err := validateNameLengths(dirName, fileName); err != nil {
+		return err
+	}
+
+	return osp.store.PutObject(
+		context.Background(),
+		osp.key(root, dirName, fileName),
+		data,
+	)
+}
+
+// listDirectory returns the sorted keys of all the objects stored in the
+// given directory and the names of the files they represent.
+func (osp *objectStorePersistence) listDirectory(
+	ctx context.Context,
+	root string,
+	dirName string,
+) ([]string, []string, error) {
+	keyPrefix := osp.keyPrefix(root, dirName)
+
+	keys, err := osp.store.ListObjects(ctx, keyPrefix)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"could not list objects with prefix [%v]: [%v]",
+			keyPrefix,
+			err,
+		)
+	}
+	sort.Strings(keys)
+
+	var fileKeys, fileNames []string
+	for _, key := range keys {
+		fileName := strings.TrimPrefix(key, keyPrefix)
+		if fileName == "" || strings.Contains(fileName, "/") {
+			continue
+		}
+
+		fileKeys = append(fileKeys, key)
+		fileNames = append(fileNames, fileName)
+	}
+
+	return fileKeys, fileNames, nil
+}
+
+// readAll works just like the disk persistence readAll but reads all
+// the objects stored under the given root key instead of files.
+func (osp *objectStorePersistence) readAll(
+	ctx context.Context,
+	root string,
+	predicate func(dirName, fileName string) bool,
+	deleteFn func(dirName, fileName string) error,
+) (<-chan DataDescriptor, <-chan error) {
+	dataChannel := make(chan DataDescriptor)
+	errorChannel := make(chan error)
+
+	go func() {
+		defer close(dataChannel)
+		defer close(errorChannel)
+
+		keyPrefix := osp.keyPrefix(root)
+
+		keys, err := osp.store.ListObjects(ctx, keyPrefix)
+		if err != nil {
+			select {
+			case errorChannel <- fmt.Errorf(
+				"could not list objects with prefix [%v]: [%v]",
+				keyPrefix,
+				err,
+			):
+			case <-ctx.Done():
+			}
+			return
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if ctx.Err() != nil {
+				return
+			}
+
+			// Only objects representing files in directories are read, just
+			// like the disk persistence reads only files in directories.
+			elements := strings.Split(strings.TrimPrefix(key, keyPrefix), "/")
+			if len(elements) != 2 || elements[0] == "" || elements[1] == "" {
+				continue
+			}
+
+			// capture shared loop variables for the closure
+			dirName := elements[0]
+			fileName := elements[1]
+			objectKey := key
+
+			if predicate != nil && !predicate(dirName, fileName) {
+				continue
+			}
+
+			openFunc := func() (io.ReadCloser, error) {
+				return osp.store.GetObject(context.Background(), objectKey)
+			}
+			readFunc := func() ([]byte, error) {
+				reader, err := openFunc()
+				if err != nil {
+					return nil, err
+				}
+				defer reader.Close()
+
+				return ioutil.ReadAll(reader)
+			}
+			descriptor := &dataDescriptor{
+				name:      fileName,
+				directory: dirName,
+				readFunc:  readFunc,
+				openFunc:  openFunc,
+			}
+			if deleteFn != nil {
+				descriptor.deleteFunc = func() error {
+					return deleteFn(dirName, fileName)
+				}
+			}
+
+			select {
+			case dataChannel <- descriptor:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return dataChannel, errorChannel
+}
+
+func (osp *basicObjectStorePersistence) Save(data []byte, dirName, fileName string) error {
+	return osp.save("", data, dirName, fileName)
+}
+
+func (osp *basicObjectStorePersistence) ReadAll() (<-chan DataDescriptor, <-chan error) {
+	return osp.ReadAllWithContext(context.Background())
+}
+
+func (osp *basicObjectStorePersistence) ReadAllWithContext(
+	ctx context.Context,
+) (<-chan DataDescriptor, <-chan error) {
+	return osp.readAll(ctx, "", nil, osp.Delete)
+}
+
+func (osp *basicObjectStorePersistence) ReadAllFiltered(
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return osp.ReadAllFilteredWithContext(context.Background(), predicate)
+}
+
+func (osp *basicObjectStorePersistence) ReadAllFilteredWithContext(
+	ctx context.Context,
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return osp.readAll(ctx, "", predicate, osp.Delete)
+}
+
+func (osp *basicObjectStorePersistence) Delete(dirName string, fileName string) error {
+	return osp.store.DeleteObject(
+		context.Background(),
+		osp.key(dirName, fileName),
+	)
+}
+
+func (osp *protectedObjectStorePersistence) Save(data []byte, dirName, fileName string) error {
+	return osp.save(osp.layout.Current, data, dirName, fileName)
+}
+
+func (osp *protectedObjectStorePersistence) ReadAll() (<-chan DataDescriptor, <-chan error) {
+	return osp.ReadAllWithContext(context.Background())
+}
+
+func (osp *protectedObjectStorePersistence) ReadAllWithContext(
+	ctx context.Context,
+) (<-chan DataDescriptor, <-chan error) {
+	return osp.readAll(ctx, osp.layout.Current, nil, nil)
+}
+
+func (osp *protectedObjectStorePersistence) ReadAllFiltered(
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return osp.ReadAllFilteredWithContext(context.Background(), predicate)
+}
+
+func (osp *protectedObjectStorePersistence) ReadAllFilteredWithContext(
+	ctx context.Context,
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return osp.readAll(
+		ctx,
+		osp.layout.Current,
+		predicate,
+		nil,
+	)
+}
+
+func (osp *protectedObjectStorePersistence) Snapshot(data []byte, dirName, fileName string) error {
+	if err := validateNameLengths(dirName, ""); err != nil {
+		return err
+	}
+
+	snapshotSuffix := osp.snapshotSuffixGenerator()
+	if err := validateSnapshotSuffix(snapshotSuffix); err != nil {
+		return err
+	}
+
+	maxSnapshotFileNameLength := maxFileNameLength - len(snapshotSuffix)
+	if len(fileName) > maxSnapshotFileNameLength {
+		return fmt.Errorf(
+			"the maximum file name length of [%v] exceeded for [%v]",
+			maxSnapshotFileNameLength,
+			fileName,
+		)
+	}
+
+	// Snapshots of different directories can not collide so only snapshots
+	// of the same directory need to be serialized.
+	unlock := osp.snapshotMutex.lock(dirName)
+	defer unlock()
+
+	ctx := context.Background()
+	key := osp.key(osp.layout.Snapshot, dirName, fileName+snapshotSuffix)
+
+	exists, err := osp.store.ObjectExists(ctx, key)
+	if err != nil {
+		return fmt.Errorf("could not check if snapshot exists: [%v]", err)
+	}
+
+	// very unlikely but better fail than overwrite an existing object
+	if exists {
+		return fmt.Errorf(
+			"could not create unique snapshot; " +
+				"snapshot name collision has been detected",
+		)
+	}
+
+	return osp.store.PutObject(ctx, key, data)
+}
+
+// Archive moves all the objects of the given current directory to
+// the archive directory by copying and removing them one by one.
+func (osp *protectedObjectStorePersistence) Archive(directory string) error {
+	if err := validateNameLengths(directory, ""); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	keys, fileNames, err := osp.listDirectory(ctx, osp.layout.Current, directory)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("could not find directory [%v] to archive", directory)
+	}
+
+	for i, key := range keys {
+		archiveKey := osp.key(osp.layout.Archive, directory, fileNames[i])
+
+		if err := osp.store.CopyObject(ctx, key, archiveKey); err != nil {
+			return fmt.Errorf(
+				"error occurred while archiving object [%v]: [%v]",
+				key,
+				err,
+			)
+		}
+
+		if err := osp.store.DeleteObject(ctx, key); err != nil {
+			return fmt.Errorf(
+				"error occurred while removing archived object [%v]: [%v]",
+				key,
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// ArchiveCompressed packs all the objects of the given current directory into
+// the archive/<directory>.tar.gz object and removes them. If the directory has
+// already been archived in the compressed form, files are appended to the
+// existing archive. Files with the same names are replaced.
+func (osp *protectedObjectStorePersistence) ArchiveCompressed(directory string) error {
+	if err := validateNameLengths(directory, ""); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	keys, fileNames, err := osp.listDirectory(ctx, osp.layout.Current, directory)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("could not find directory [%v] to archive", directory)
+	}
+
+	archiveKey := osp.key(
+		osp.layout.Archive,
+		directory+compressedArchiveExtension,
+	)
+
+	archive, err := osp.compress(ctx, archiveKey, keys, fileNames)
+	if err != nil {
+		return err
+	}
+
+	if err := osp.store.PutObject(ctx, archiveKey, archive); err != nil {
+		return fmt.Errorf("could not store archive: [%v]", err)
+	}
+
+	for _, key := range keys {
+		if err := osp.store.DeleteObject(ctx, key); err != nil {
+			return fmt.Errorf(
+				"error occurred while removing archived object [%v]: [%v]",
+				key,
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// compress returns the compressed archive containing the entries of
+// the existing archive, if any, and the given objects.
+func (osp *protectedObjectStorePersistence) compress(
+	ctx context.Context,
+	archiveKey string,
+	keys []string,
+	fileNames []string,
+) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	newFiles := make(map[string]bool)
+	for _, fileName := range fileNames {
+		newFiles[fileName] = true
+	}
+
+	exists, err := osp.store.ObjectExists(ctx, archiveKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not check if archive exists: [%v]", err)
+	}
+	if exists {
+		existingArchive, err := osp.store.GetObject(ctx, archiveKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not open existing archive: [%v]", err)
+		}
+		defer existingArchive.Close()
+
+		err = copyArchiveEntries(existingArchive, tarWriter, newFiles)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, key := range keys {
+		content, err := osp.readObject(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"could not archive file [%v]: [%v]",
+				fileNames[i],
+				err,
+			)
+		}
+
+		header := &tar.Header{
+			Name:    fileNames[i],
+			Mode:    0600,
+			Size:    int64(len(content)),
+			ModTime: time.Now(),
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("could not write archive header: [%v]", err)
+		}
+		if _, err := tarWriter.Write(content); err != nil {
+			return nil, fmt.Errorf(
+				"could not archive file [%v]: [%v]",
+				fileNames[i],
+				err,
+			)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return nil, fmt.Errorf("could not close archive: [%v]", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("could not close archive compression: [%v]", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (osp *protectedObjectStorePersistence) readObject(
+	ctx context.Context,
+	key string,
+) ([]byte, error) {
+	reader, err := osp.store.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ioutil.ReadAll(reader)
+}
+
+// ListArchivedDirectories returns the sorted names of all the directories
+// archived with Archive or ArchiveCompressed.
+func (osp *protectedObjectStorePersistence) ListArchivedDirectories() ([]string, error) {
+	keyPrefix := osp.keyPrefix(osp.layout.Archive)
+
+	keys, err := osp.store.ListObjects(context.Background(), keyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"could not list objects with prefix [%v]: [%v]",
+			keyPrefix,
+			err,
+		)
+	}
+
+	// The same directory may be archived in both forms.
+	unique := make(map[string]bool)
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, keyPrefix)
+
+		switch {
+		case strings.Contains(name, "/"):
+			unique[strings.SplitN(name, "/", 2)[0]] = true
+		case strings.HasSuffix(name, compressedArchiveExtension):
+			unique[strings.TrimSuffix(name, compressedArchiveExtension)] = true
+		}
+	}
+
+	directories := make([]string, 0, len(unique))
+	for directory := range unique {
+		directories = append(directories, directory)
+	}
+	sort.Strings(directories)
+
+	return directories, nil
+}
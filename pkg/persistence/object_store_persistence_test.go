@@ -0,0 +1,379 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestObjectStorePersistence_SaveAndReadAll(t *testing.T) {
+	var tests = map[string]struct {
+		initHandleFn func(store ObjectStore) RWHandle
+		expectedKey  string
+	}{
+		"basic object store persistence": {
+			initHandleFn: func(store ObjectStore) RWHandle {
+				return NewBasicObjectStoreHandle(store, "keep")
+			},
+			expectedKey: "keep/0x424242/file11",
+		},
+		"protected object store persistence": {
+			initHandleFn: func(store ObjectStore) RWHandle {
+				return NewProtectedObjectStoreHandle(store, "keep")
+			},
+			expectedKey: "keep/current/0x424242/file11",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			store := newFakeObjectStore()
+			handle := test.initHandleFn(store)
+
+			if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, ok := store.objects[test.expectedKey]; !ok {
+				t.Fatalf("object [%v] has not been stored", test.expectedKey)
+			}
+
+			// objects not representing files in directories are not read
+			store.objects["keep/unrelated"] = fileContent
+
+			descriptors, err := readAllDescriptors(handle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(descriptors) != 1 {
+				t.Fatalf(
+					"unexpected number of descriptors\nexpected: [%v]\nactual:   [%v]",
+					1,
+					len(descriptors),
+				)
+			}
+
+			descriptor := descriptors[0]
+			if descriptor.Directory() != dirName1 || descriptor.Name() != fileName11 {
+				t.Errorf(
+					"unexpected descriptor\nexpected: [%v/%v]\nactual:   [%v/%v]",
+					dirName1,
+					fileName11,
+					descriptor.Directory(),
+					descriptor.Name(),
+				)
+			}
+
+			content, err := descriptor.Content()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(fileContent, content) {
+				t.Errorf(
+					"unexpected content\nexpected: [%v]\nactual:   [%v]",
+					fileContent,
+					content,
+				)
+			}
+		})
+	}
+}
+
+func TestObjectStorePersistence_NameLength(t *testing.T) {
+	handle := NewProtectedObjectStoreHandle(newFakeObjectStore(), "")
+
+	err := handle.Save(fileContent, notAllowedName, fileName11)
+	if !reflect.DeepEqual(errDirectoryNameLength, err) {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			errDirectoryNameLength,
+			err,
+		)
+	}
+
+	err = handle.Save(fileContent, dirName1, notAllowedName)
+	if !reflect.DeepEqual(errFileNameLength, err) {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			errFileNameLength,
+			err,
+		)
+	}
+
+	if err := handle.Save(fileContent, maxAllowedName, maxAllowedName); err != nil {
+		t.Errorf("unexpected error: [%v]", err)
+	}
+}
+
+func TestObjectStorePersistence_Delete(t *testing.T) {
+	store := newFakeObjectStore()
+	handle := NewBasicObjectStoreHandle(store, "")
+
+	if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.Delete(dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store.objects) != 0 {
+		t.Errorf("unexpected objects left: [%v]", store.objects)
+	}
+}
+
+func TestObjectStorePersistence_Snapshot(t *testing.T) {
+	store := newFakeObjectStore()
+	handle := NewProtectedObjectStoreHandle(store, "").(*protectedObjectStorePersistence)
+	handle.snapshotSuffixGenerator = func() string { return ".1" }
+
+	if err := handle.Snapshot(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedKey := "snapshot/0x424242/file11.1"
+	if _, ok := store.objects[expectedKey]; !ok {
+		t.Fatalf("object [%v] has not been stored", expectedKey)
+	}
+
+	err := handle.Snapshot(fileContent, dirName1, fileName11)
+	expectedError := fmt.Errorf(
+		"could not create unique snapshot; " +
+			"snapshot name collision has been detected",
+	)
+	if !reflect.DeepEqual(expectedError, err) {
+		t.Errorf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
+func TestObjectStorePersistence_Archive(t *testing.T) {
+	store := newFakeObjectStore()
+	handle := NewProtectedObjectStoreHandle(store, "keep")
+
+	if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.Save(fileContent, dirName1, fileName12); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.Save(fileContent, dirName2, fileName21); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handle.Archive(dirName1); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedKeys := []string{
+		"keep/archive/0x424242/file11",
+		"keep/archive/0x424242/file12",
+		"keep/current/0x777777/file21",
+	}
+	if !reflect.DeepEqual(expectedKeys, store.keys()) {
+		t.Errorf(
+			"unexpected objects\nexpected: [%v]\nactual:   [%v]",
+			expectedKeys,
+			store.keys(),
+		)
+	}
+
+	descriptors, err := readAllDescriptors(handle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(descriptors) != 1 || descriptors[0].Directory() != dirName2 {
+		t.Errorf("archived directory has been read")
+	}
+
+	if err := handle.Archive(dirName1); err == nil {
+		t.Errorf("expected error when archiving non-existing directory")
+	}
+}
+
+func TestObjectStorePersistence_ArchiveCompressed(t *testing.T) {
+	store := newFakeObjectStore()
+	handle := NewProtectedObjectStoreHandle(store, "")
+
+	if err := handle.Save([]byte("old"), dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.ArchiveCompressed(dirName1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handle.Save([]byte("new"), dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.Save(fileContent, dirName1, fileName12); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.ArchiveCompressed(dirName1); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedKeys := []string{"archive/0x424242.tar.gz"}
+	if !reflect.DeepEqual(expectedKeys, store.keys()) {
+		t.Fatalf(
+			"unexpected objects\nexpected: [%v]\nactual:   [%v]",
+			expectedKeys,
+			store.keys(),
+		)
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "archive.tar.gz")
+	err := ioutil.WriteFile(archivePath, store.objects[expectedKeys[0]], 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedEntries := map[string][]byte{
+		fileName11: []byte("new"),
+		fileName12: fileContent,
+	}
+	entries := readCompressedArchive(t, archivePath)
+	if !reflect.DeepEqual(expectedEntries, entries) {
+		t.Errorf(
+			"unexpected archive entries\nexpected: [%s]\nactual:   [%s]",
+			expectedEntries,
+			entries,
+		)
+	}
+}
+
+func TestObjectStorePersistence_ListArchivedDirectories(t *testing.T) {
+	store := newFakeObjectStore()
+	handle := NewProtectedObjectStoreHandle(store, "")
+
+	for _, dirName := range []string{dirName1, dirName2} {
+		if err := handle.Save(fileContent, dirName, fileName11); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := handle.Archive(dirName2); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.ArchiveCompressed(dirName1); err != nil {
+		t.Fatal(err)
+	}
+
+	directories, err := handle.ListArchivedDirectories()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDirectories := []string{dirName1, dirName2}
+	if !reflect.DeepEqual(expectedDirectories, directories) {
+		t.Errorf(
+			"unexpected directories\nexpected: [%v]\nactual:   [%v]",
+			expectedDirectories,
+			directories,
+		)
+	}
+}
+
+type fakeObjectStore struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+func newFakeObjectStore() *fakeObjectStore {
+	return &fakeObjectStore{objects: make(map[string][]byte)}
+}
+
+func (fos *fakeObjectStore) keys() []string {
+	keys, _ := fos.ListObjects(context.Background(), "")
+	return keys
+}
+
+func (fos *fakeObjectStore) PutObject(
+	ctx context.Context,
+	key string,
+	data []byte,
+) error {
+	fos.mutex.Lock()
+	defer fos.mutex.Unlock()
+
+	fos.objects[key] = append([]byte{}, data...)
+	return nil
+}
+
+func (fos *fakeObjectStore) GetObject(
+	ctx context.Context,
+	key string,
+) (io.ReadCloser, error) {
+	fos.mutex.Lock()
+	defer fos.mutex.Unlock()
+
+	data, ok := fos.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("no such key [%v]", key)
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (fos *fakeObjectStore) ObjectExists(
+	ctx context.Context,
+	key string,
+) (bool, error) {
+	fos.mutex.Lock()
+	defer fos.mutex.Unlock()
+
+	_, ok := fos.objects[key]
+	return ok, nil
+}
+
+func (fos *fakeObjectStore) ListObjects(
+	ctx context.Context,
+	prefix string,
+) ([]string, error) {
+	fos.mutex.Lock()
+	defer fos.mutex.Unlock()
+
+	var keys []string
+	for key := range fos.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func (fos *fakeObjectStore) CopyObject(
+	ctx context.Context,
+	sourceKey string,
+	destinationKey string,
+) error {
+	fos.mutex.Lock()
+	defer fos.mutex.Unlock()
+
+	data, ok := fos.objects[sourceKey]
+	if !ok {
+		return fmt.Errorf("no such key [%v]", sourceKey)
+	}
+
+	fos.objects[destinationKey] = data
+	return nil
+}
+
+func (fos *fakeObjectStore) DeleteObject(ctx context.Context, key string) error {
+	fos.mutex.Lock()
+	defer fos.mutex.Unlock()
+
+	delete(fos.objects, key)
+	return nil
+}
@@ -0,0 +1,80 @@
+package persistence
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// Partitioner is implemented by the persistence handles able to split
+// the persisted data into disjoint shards so that multiple processes can read
+// the data in parallel without coordination.
+type Partitioner interface {
+	// ReadAllPartition works just like ReadAll but returns only the data
+	// stored in the directories assigned to the given shard. Each directory
+	// is deterministically assigned to exactly one of the total shards based
+	// on its name, so the shards from 0 to totalShards-1 cover all the data.
+	// The error is returned if the shard is negative or not lower than
+	// the total shards or the total shards is not positive.
+	ReadAllPartition(
+		shard, totalShards int,
+	) (<-chan DataDescriptor, <-chan error, error)
+}
+
+func (ds *basicDiskPersistence) ReadAllPartition(
+	shard, totalShards int,
+) (<-chan DataDescriptor, <-chan error, error) {
+	predicate, err := partitionPredicate(shard, totalShards)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dataChannel, errorChannel := ds.ReadAllFiltered(predicate)
+	return dataChannel, errorChannel, nil
+}
+
+func (ds *protectedDiskPersistence) ReadAllPartition(
+	shard, totalShards int,
+) (<-chan DataDescriptor, <-chan error, error) {
+	predicate, err := partitionPredicate(shard, totalShards)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dataChannel, errorChannel := ds.ReadAllFiltered(predicate)
+	return dataChannel, errorChannel, nil
+}
+
+// partitionPredicate returns the ReadAllFiltered predicate accepting only
+// the files stored in the directories assigned to the given shard.
+func partitionPredicate(
+	shard, totalShards int,
+) (func(dirName, fileName string) bool, error) {
+	if totalShards <= 0 {
+		return nil, fmt.Errorf(
+			"total shards must be positive; has: [%v]",
+			totalShards,
+		)
+	}
+	if shard < 0 || shard >= totalShards {
+		return nil, fmt.Errorf(
+			"shard must be in the range [0, %v); has: [%v]",
+			totalShards,
+			shard,
+		)
+	}
+
+	return func(dirName, fileName string) bool {
+		return directoryShard(dirName, totalShards) == shard
+	}, nil
+}
+
+// directoryShard returns the shard the directory with the given name is
+// assigned to. The assignment depends only on the directory name so it is
+// stable across processes and restarts.
+func directoryShard(dirName string, totalShards int) int {
+	hash := fnv.New32a()
+	// Writing to the hash never returns an error.
+	_, _ = hash.Write([]byte(dirName))
+
+	return int(hash.Sum32() % uint32(totalShards))
+}
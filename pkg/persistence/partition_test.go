@@ -0,0 +1,163 @@
+package persistence
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type partitionedHandle interface {
+	RWHandle
+	Partitioner
+}
+
+func TestDiskPersistence_ReadAllPartition(t *testing.T) {
+	var tests = map[string]struct {
+		initDiskPersistenceFn func(t *testing.T) partitionedHandle
+	}{
+		"basic disk persistence": {
+			initDiskPersistenceFn: func(t *testing.T) partitionedHandle {
+				handle, _ := initBasicDiskPersistence(t)
+				return handle
+			},
+		},
+		"protected disk persistence": {
+			initDiskPersistenceFn: func(t *testing.T) partitionedHandle {
+				handle, _ := initProtectedDiskPersistence(t)
+				return handle
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			handle := test.initDiskPersistenceFn(t)
+
+			expectedFiles := make(map[string]int)
+			for i := 0; i < 20; i++ {
+				dirName := fmt.Sprintf("0x%06x", i)
+				for _, fileName := range []string{fileName11, fileName12} {
+					if err := handle.Save(fileContent, dirName, fileName); err != nil {
+						t.Fatal(err)
+					}
+					expectedFiles[dirName+"/"+fileName] = 1
+				}
+			}
+
+			for _, totalShards := range []int{1, 3, 7} {
+				files := make(map[string]int)
+				shardDirectories := make(map[string]int)
+
+				for shard := 0; shard < totalShards; shard++ {
+					dataChannel, errorChannel, err := handle.ReadAllPartition(
+						shard,
+						totalShards,
+					)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					descriptors, err := collectDescriptors(
+						dataChannel,
+						errorChannel,
+					)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					for _, descriptor := range descriptors {
+						files[descriptor.Directory()+"/"+descriptor.Name()]++
+
+						if previous, ok := shardDirectories[descriptor.Directory()]; ok &&
+							previous != shard {
+							t.Errorf(
+								"directory [%v] split between shards [%v] and [%v]",
+								descriptor.Directory(),
+								previous,
+								shard,
+							)
+						}
+						shardDirectories[descriptor.Directory()] = shard
+					}
+				}
+
+				if !reflect.DeepEqual(expectedFiles, files) {
+					t.Errorf(
+						"unexpected files read from [%v] shards\n"+
+							"expected: [%v]\nactual:   [%v]",
+						totalShards,
+						expectedFiles,
+						files,
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestDiskPersistence_RefuseReadAllPartition(t *testing.T) {
+	var tests = map[string]struct {
+		shard         int
+		totalShards   int
+		expectedError string
+	}{
+		"zero total shards": {
+			shard:         0,
+			totalShards:   0,
+			expectedError: "total shards must be positive; has: [0]",
+		},
+		"negative total shards": {
+			shard:         0,
+			totalShards:   -1,
+			expectedError: "total shards must be positive; has: [-1]",
+		},
+		"negative shard": {
+			shard:         -1,
+			totalShards:   3,
+			expectedError: "shard must be in the range [0, 3); has: [-1]",
+		},
+		"shard equal to total shards": {
+			shard:         3,
+			totalShards:   3,
+			expectedError: "shard must be in the range [0, 3); has: [3]",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			handle, _ := initBasicDiskPersistence(t)
+
+			_, _, err := handle.ReadAllPartition(test.shard, test.totalShards)
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
+
+func collectDescriptors(
+	dataChannel <-chan DataDescriptor,
+	errorChannel <-chan error,
+) ([]DataDescriptor, error) {
+	errorsDone := make(chan error)
+	go func() {
+		var firstErr error
+		for err := range errorChannel {
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		errorsDone <- firstErr
+	}()
+
+	var descriptors []DataDescriptor
+	for descriptor := range dataChannel {
+		descriptors = append(descriptors, descriptor)
+	}
+
+	return descriptors, <-errorsDone
+}
@@ -3,7 +3,11 @@
 // retrieving it.
 package persistence
 
-import "github.com/ipfs/go-log"
+import (
+	"errors"
+
+	"github.com/ipfs/go-log"
+)
 
 var logger = log.Logger("keep-persistence")
 
@@ -44,6 +48,15 @@ type ProtectedHandle interface {
 	// so that the data in that directory is not returned from ReadAll.
 	Archive(directory string) error
 
+	// ArchiveCompressed works just like Archive but packs the archived
+	// directory into a single compressed file, appropriate for the given
+	// persistent storage implementation, to ease the long-term retention.
+	ArchiveCompressed(directory string) error
+
+	// ListArchivedDirectories returns names of all the archived directories,
+	// regardless of whether they were archived in the compressed form or not.
+	ListArchivedDirectories() ([]string, error)
+
 	// Snapshot takes the provided data and persists it as an unique snapshot
 	// file in the provided directory appropriate for the given persistent
 	// storage implementation.
@@ -57,3 +70,7 @@ type DataDescriptor interface {
 	Directory() string
 	Content() ([]byte, error)
 }
+
+// ErrDeleteNotSupported is returned when deleting data read from a handle
+// that does not allow to remove the data.
+var ErrDeleteNotSupported = errors.New("deleting data is not supported")
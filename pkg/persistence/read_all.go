@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"context"
+)
+
+// ContextReader is implemented by the persistence handles able to stop
+// reading the data once the consumer is no longer interested in the rest of
+// it. ReadAllWithContext works just like ReadAll but stops reading and closes
+// the returned channels once the provided context is done.
+type ContextReader interface {
+	ReadAllWithContext(ctx context.Context) (<-chan DataDescriptor, <-chan error)
+}
+
+// FilteredReader is implemented by the persistence handles able to skip
+// the data without reading it. ReadAllFiltered works just like ReadAll but
+// returns only the data for which the predicate called with the directory
+// and name of the data returns true. ReadAllFilteredWithContext works just
+// like ReadAllFiltered but stops reading and closes the returned channels
+// once the provided context is done.
+type FilteredReader interface {
+	ReadAllFiltered(
+		predicate func(dirName, fileName string) bool,
+	) (<-chan DataDescriptor, <-chan error)
+
+	ReadAllFilteredWithContext(
+		ctx context.Context,
+		predicate func(dirName, fileName string) bool,
+	) (<-chan DataDescriptor, <-chan error)
+}
+
+// ReadAllWithContext reads all non-archived data of the given handle just
+// like ReadAll but stops and closes the returned channels once the provided
+// context is done. If the handle does not implement ContextReader, the data
+// is read with ReadAll and the data read after the context is done is
+// discarded.
+func ReadAllWithContext(
+	ctx context.Context,
+	handle RWHandle,
+) (<-chan DataDescriptor, <-chan error) {
+	if reader, ok := handle.(ContextReader); ok {
+		return reader.ReadAllWithContext(ctx)
+	}
+
+	inputData, inputErrors := handle.ReadAll()
+	return wrapAll(
+		ctx,
+		inputData,
+		inputErrors,
+		func(descriptor DataDescriptor) DataDescriptor {
+			return descriptor
+		},
+		nil,
+	)
+}
+
+// ReadAllFiltered reads the non-archived data of the given handle for which
+// the predicate called with the directory and name of the data returns true.
+// If the handle does not implement FilteredReader, all the data is read with
+// ReadAll and the data not satisfying the predicate is discarded.
+func ReadAllFiltered(
+	handle RWHandle,
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return ReadAllFilteredWithContext(context.Background(), handle, predicate)
+}
+
+// ReadAllFilteredWithContext works just like ReadAllFiltered but stops reading
+// and closes the returned channels once the provided context is done.
+// If the handle does not implement FilteredReader, the data is read with
+// ReadAllWithContext and the data not satisfying the predicate is discarded.
+func ReadAllFilteredWithContext(
+	ctx context.Context,
+	handle RWHandle,
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	if reader, ok := handle.(FilteredReader); ok {
+		return reader.ReadAllFilteredWithContext(ctx, predicate)
+	}
+
+	inputData, inputErrors := ReadAllWithContext(ctx, handle)
+	return wrapAll(
+		ctx,
+		inputData,
+		inputErrors,
+		func(descriptor DataDescriptor) DataDescriptor {
+			if predicate != nil &&
+				!predicate(descriptor.Directory(), descriptor.Name()) {
+				return nil
+			}
+			return descriptor
+		},
+		nil,
+	)
+}
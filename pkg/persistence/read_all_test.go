@@ -0,0 +1,99 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestReadAllFiltered_Fallback(t *testing.T) {
+	handle := newMemoryPersistence()
+	saveTestFiles(t, handle)
+
+	descriptors, err := collectDescriptors(
+		ReadAllFiltered(
+			handle,
+			func(dirName, fileName string) bool {
+				return dirName == dirName1
+			},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, descriptor := range descriptors {
+		paths = append(paths, descriptor.Directory()+"/"+descriptor.Name())
+	}
+	sort.Strings(paths)
+
+	expectedPaths := []string{
+		dirName1 + "/" + fileName11,
+		dirName1 + "/" + fileName12,
+	}
+	if !reflect.DeepEqual(expectedPaths, paths) {
+		t.Errorf(
+			"unexpected read data\nexpected: [%v]\nactual:   [%v]",
+			expectedPaths,
+			paths,
+		)
+	}
+}
+
+func TestReadAllWithContext_Fallback_Cancelled(t *testing.T) {
+	diskHandle, _ := initBasicDiskPersistence(t)
+
+	for i := 0; i < 10; i++ {
+		err := diskHandle.Save(fileContent, dirName1, fmt.Sprintf("file%v", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The handle implements only RWHandle so the context is handled by
+	// the fallback.
+	handle := &readAllOnlyPersistence{diskHandle}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dataChannel, errChannel := ReadAllWithContext(ctx, handle)
+
+	// read just the first descriptor and stop consuming
+	<-dataChannel
+	cancel()
+
+	select {
+	case <-errChannel:
+	case <-time.After(time.Second):
+		t.Fatal("error channel has not been closed")
+	}
+
+	// The fallback may have a descriptor ready to be sent at the time of
+	// cancellation, so drain the channel but make sure it closes before all
+	// the descriptors are read.
+	read := 1
+	for range dataChannel {
+		read++
+	}
+	if read == 10 {
+		t.Errorf("all descriptors have been read despite the cancellation")
+	}
+}
+
+// readAllOnlyPersistence exposes only the RWHandle methods of the delegate.
+type readAllOnlyPersistence struct {
+	delegate RWHandle
+}
+
+func (raop *readAllOnlyPersistence) Save(data []byte, directory string, name string) error {
+	return raop.delegate.Save(data, directory, name)
+}
+
+func (raop *readAllOnlyPersistence) ReadAll() (<-chan DataDescriptor, <-chan error) {
+	return raop.delegate.ReadAll()
+}
@@ -0,0 +1,130 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrReadOnly is returned when modifying data through a read-only handle.
+var ErrReadOnly = errors.New("persistence handle is read-only")
+
+type readOnlyPersistence[H RWHandle] struct {
+	delegate H
+}
+
+type readOnlyBasicPersistence struct {
+	readOnlyPersistence[BasicHandle]
+}
+
+type readOnlyProtectedPersistence struct {
+	readOnlyPersistence[ProtectedHandle]
+}
+
+// NewReadOnlyBasicPersistence creates an adapter for the persistence refusing
+// all the modifications of the data with ErrReadOnly, including deleting
+// the data through the read data descriptors. Reading the data is delegated
+// to the given handle. It is meant for tooling that must never modify
+// the stored data, e.g. inspection utilities.
+func NewReadOnlyBasicPersistence(handle BasicHandle) BasicHandle {
+	return &readOnlyBasicPersistence{
+		readOnlyPersistence: readOnlyPersistence[BasicHandle]{
+			delegate: handle,
+		},
+	}
+}
+
+// NewReadOnlyProtectedPersistence creates an adapter for the persistence
+// refusing all the modifications of the data with ErrReadOnly, including
+// archiving and taking snapshots. Reading the data and listing the archived
+// directories is delegated to the given handle. It is meant for tooling that
+// must never modify the stored data, e.g. inspection utilities.
+func NewReadOnlyProtectedPersistence(handle ProtectedHandle) ProtectedHandle {
+	return &readOnlyProtectedPersistence{
+		readOnlyPersistence: readOnlyPersistence[ProtectedHandle]{
+			delegate: handle,
+		},
+	}
+}
+
+func (rop *readOnlyPersistence[H]) Save(data []byte, directory string, name string) error {
+	return ErrReadOnly
+}
+
+func (rop *readOnlyPersistence[H]) ReadAll() (<-chan DataDescriptor, <-chan error) {
+	return rop.ReadAllWithContext(context.Background())
+}
+
+func (rop *readOnlyPersistence[H]) ReadAllWithContext(
+	ctx context.Context,
+) (<-chan DataDescriptor, <-chan error) {
+	inputData, inputErrors := ReadAllWithContext(ctx, rop.delegate)
+	return readOnlyAll(ctx, inputData, inputErrors)
+}
+
+func (rop *readOnlyPersistence[H]) ReadAllFiltered(
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	return rop.ReadAllFilteredWithContext(context.Background(), predicate)
+}
+
+func (rop *readOnlyPersistence[H]) ReadAllFilteredWithContext(
+	ctx context.Context,
+	predicate func(dirName, fileName string) bool,
+) (<-chan DataDescriptor, <-chan error) {
+	inputData, inputErrors := ReadAllFilteredWithContext(
+		ctx,
+		rop.delegate,
+		predicate,
+	)
+	return readOnlyAll(ctx, inputData, inputErrors)
+}
+
+// readOnlyAll pipes the data descriptors read by the delegate to the returned
+// channel decorating them so that the data can not be deleted. Errors are
+// passed thru without any change.
+func readOnlyAll(
+	ctx context.Context,
+	inputData <-chan DataDescriptor,
+	inputErrors <-chan error,
+) (<-chan DataDescriptor, <-chan error) {
+	return wrapAll(
+		ctx,
+		inputData,
+		inputErrors,
+		func(descriptor DataDescriptor) DataDescriptor {
+			return &dataDescriptor{
+				name:      descriptor.Name(),
+				directory: descriptor.Directory(),
+				readFunc:  descriptor.Content,
+				openFunc: func() (io.ReadCloser, error) {
+					return OpenContent(descriptor)
+				},
+				deleteFunc: func() error {
+					return ErrReadOnly
+				},
+			}
+		},
+		nil,
+	)
+}
+
+func (rop *readOnlyBasicPersistence) Delete(directory string, name string) error {
+	return ErrReadOnly
+}
+
+func (rop *readOnlyProtectedPersistence) Archive(directory string) error {
+	return ErrReadOnly
+}
+
+func (rop *readOnlyProtectedPersistence) ArchiveCompressed(directory string) error {
+	return ErrReadOnly
+}
+
+func (rop *readOnlyProtectedPersistence) ListArchivedDirectories() ([]string, error) {
+	return rop.delegate.ListArchivedDirectories()
+}
+
+func (rop *readOnlyProtectedPersistence) Snapshot(data []byte, directory string, name string) error {
+	return ErrReadOnly
+}
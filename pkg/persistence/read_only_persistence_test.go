@@ -0,0 +1,291 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestReadOnlyPersistence_RefuseModifications(t *testing.T) {
+	basicHandle, basicDataDir := initBasicDiskPersistence(t)
+	protectedHandle, protectedDataDir := initProtectedDiskPersistence(t)
+
+	for _, handle := range []RWHandle{basicHandle, protectedHandle} {
+		if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	readOnlyBasic := NewReadOnlyBasicPersistence(basicHandle)
+	readOnlyProtected := NewReadOnlyProtectedPersistence(protectedHandle)
+
+	var tests = map[string]struct {
+		modifyFn func() error
+	}{
+		"basic save": {
+			modifyFn: func() error {
+				return readOnlyBasic.Save(fileContent, dirName2, fileName21)
+			},
+		},
+		"basic delete": {
+			modifyFn: func() error {
+				return readOnlyBasic.Delete(dirName1, fileName11)
+			},
+		},
+		"basic descriptor delete": {
+			modifyFn: func() error {
+				return deleteFirstDescriptor(t, readOnlyBasic)
+			},
+		},
+		"protected save": {
+			modifyFn: func() error {
+				return readOnlyProtected.Save(fileContent, dirName2, fileName21)
+			},
+		},
+		"protected snapshot": {
+			modifyFn: func() error {
+				return readOnlyProtected.Snapshot(fileContent, dirName1, fileName11)
+			},
+		},
+		"protected archive": {
+			modifyFn: func() error {
+				return readOnlyProtected.Archive(dirName1)
+			},
+		},
+		"protected compressed archive": {
+			modifyFn: func() error {
+				return readOnlyProtected.ArchiveCompressed(dirName1)
+			},
+		},
+		"protected descriptor delete": {
+			modifyFn: func() error {
+				return deleteFirstDescriptor(t, readOnlyProtected)
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			err := test.modifyFn()
+			if !errors.Is(err, ErrReadOnly) {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					ErrReadOnly,
+					err,
+				)
+			}
+		})
+	}
+
+	assertExist(t, basicDataDir, filepath.Join(dirName1, fileName11), "check file after modifications")
+	assertNotExist(t, basicDataDir, dirName2, "check directory after modifications")
+	assertExist(t, protectedDataDir, filepath.Join(dirCurrent, dirName1, fileName11), "check file after modifications")
+	assertNotExist(t, protectedDataDir, filepath.Join(dirCurrent, dirName2), "check directory after modifications")
+	assertNotExist(t, protectedDataDir, filepath.Join(dirSnapshot, dirName1), "check snapshot after modifications")
+	assertNotExist(t, protectedDataDir, filepath.Join(dirArchive, dirName1), "check archive after modifications")
+}
+
+func TestReadOnlyPersistence_Read(t *testing.T) {
+	var tests = map[string]struct {
+		initReadOnlyPersistenceFn func(t *testing.T) RWHandle
+	}{
+		"basic read-only persistence": {
+			initReadOnlyPersistenceFn: func(t *testing.T) RWHandle {
+				handle, _ := initBasicDiskPersistence(t)
+				saveTestFiles(t, handle)
+				return NewReadOnlyBasicPersistence(handle)
+			},
+		},
+		"protected read-only persistence": {
+			initReadOnlyPersistenceFn: func(t *testing.T) RWHandle {
+				handle, _ := initProtectedDiskPersistence(t)
+				saveTestFiles(t, handle)
+				return NewReadOnlyProtectedPersistence(handle)
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			handle := test.initReadOnlyPersistenceFn(t)
+
+			var readFns = map[string]func() (<-chan DataDescriptor, <-chan error){
+				"ReadAll": handle.ReadAll,
+				"ReadAllWithContext": func() (<-chan DataDescriptor, <-chan error) {
+					return ReadAllWithContext(context.Background(), handle)
+				},
+				"ReadAllFiltered": func() (<-chan DataDescriptor, <-chan error) {
+					return ReadAllFiltered(
+						handle,
+						func(dirName, fileName string) bool { return true },
+					)
+				},
+				"ReadAllFilteredWithContext": func() (<-chan DataDescriptor, <-chan error) {
+					return ReadAllFilteredWithContext(
+						context.Background(),
+						handle,
+						func(dirName, fileName string) bool { return true },
+					)
+				},
+			}
+
+			for readFnName, readFn := range readFns {
+				descriptors, err := collectDescriptors(readFn())
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var files []string
+				for _, descriptor := range descriptors {
+					content, err := descriptor.Content()
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !reflect.DeepEqual(fileContent, content) {
+						t.Errorf(
+							"unexpected content read with [%v]\n"+
+								"expected: [%v]\nactual:   [%v]",
+							readFnName,
+							fileContent,
+							content,
+						)
+					}
+
+					files = append(
+						files,
+						filepath.Join(descriptor.Directory(), descriptor.Name()),
+					)
+				}
+				sort.Strings(files)
+
+				expectedFiles := []string{
+					filepath.Join(dirName1, fileName11),
+					filepath.Join(dirName1, fileName12),
+					filepath.Join(dirName2, fileName21),
+				}
+				if !reflect.DeepEqual(expectedFiles, files) {
+					t.Errorf(
+						"unexpected files read with [%v]\n"+
+							"expected: [%v]\nactual:   [%v]",
+						readFnName,
+						expectedFiles,
+						files,
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestReadOnlyPersistence_ReadAllFilteredWithContext_Cancelled(t *testing.T) {
+	diskHandle, _ := initBasicDiskPersistence(t)
+
+	for i := 0; i < 10; i++ {
+		err := diskHandle.Save(fileContent, dirName1, fmt.Sprintf("file%v", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handle := NewReadOnlyBasicPersistence(diskHandle)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dataChannel, errChannel := ReadAllFilteredWithContext(
+		ctx,
+		handle,
+		func(dirName, fileName string) bool { return true },
+	)
+
+	// read just the first descriptor and stop consuming
+	<-dataChannel
+	cancel()
+
+	select {
+	case <-errChannel:
+	case <-time.After(time.Second):
+		t.Fatal("error channel has not been closed")
+	}
+
+	// The reader may have a descriptor ready to be sent at the time of
+	// cancellation, so drain the channel but make sure it closes before all
+	// the descriptors are read.
+	count := 1
+	timeout := time.After(time.Second)
+	for done := false; !done; {
+		select {
+		case _, ok := <-dataChannel:
+			if !ok {
+				done = true
+				continue
+			}
+			count++
+		case <-timeout:
+			t.Fatal("data channel has not been closed")
+		}
+	}
+
+	if count >= 10 {
+		t.Errorf(
+			"unexpected number of descriptors\n"+
+				"expected: [less than %v]\nactual:   [%v]",
+			10,
+			count,
+		)
+	}
+}
+
+func TestReadOnlyPersistence_ListArchivedDirectories(t *testing.T) {
+	handle, _ := initProtectedDiskPersistence(t)
+	saveTestFiles(t, handle)
+
+	if err := handle.Archive(dirName1); err != nil {
+		t.Fatal(err)
+	}
+
+	directories, err := NewReadOnlyProtectedPersistence(
+		handle,
+	).ListArchivedDirectories()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDirectories := []string{dirName1}
+	if !reflect.DeepEqual(expectedDirectories, directories) {
+		t.Errorf(
+			"unexpected archived directories\nexpected: [%v]\nactual:   [%v]",
+			expectedDirectories,
+			directories,
+		)
+	}
+}
+
+func saveTestFiles(t *testing.T, handle RWHandle) {
+	for _, file := range []struct{ dirName, fileName string }{
+		{dirName1, fileName11},
+		{dirName1, fileName12},
+		{dirName2, fileName21},
+	} {
+		if err := handle.Save(fileContent, file.dirName, file.fileName); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func deleteFirstDescriptor(t *testing.T, handle RWHandle) error {
+	descriptors, err := collectDescriptors(handle.ReadAll())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(descriptors) == 0 {
+		t.Fatal("no data read")
+	}
+
+	return DeleteData(descriptors[0])
+}
@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"time"
+)
+
+// ReadRetryPolicy determines how failed directory and file reads are retried
+// by the disk persistence, e.g. to survive transient errors of network file
+// systems. The zero value means failed reads are not retried.
+type ReadRetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a single read,
+	// including the first one. Values lower than 2 disable the retries.
+	MaxAttempts int
+
+	// Backoff is the time to wait before the first retry. The time is
+	// doubled before each subsequent retry.
+	Backoff time.Duration
+}
+
+// retryRead executes the given read function and retries it according to
+// the given policy as long as it fails. The error of the last attempt is
+// returned if all the attempts fail.
+func retryRead[T any](policy ReadRetryPolicy, readFn func() (T, error)) (T, error) {
+	backoff := policy.Backoff
+
+	for attempt := 1; ; attempt++ {
+		result, err := readFn()
+		if err == nil || attempt >= policy.MaxAttempts {
+			return result, err
+		}
+
+		logger.Warningf(
+			"read attempt [%v/%v] failed: [%v]; retrying in [%v]",
+			attempt,
+			policy.MaxAttempts,
+			err,
+			backoff,
+		)
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,102 @@
+package persistence
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRetryRead(t *testing.T) {
+	var tests = map[string]struct {
+		policy           ReadRetryPolicy
+		failures         int
+		expectedAttempts int
+		expectedError    error
+	}{
+		"no retries by default": {
+			policy:           ReadRetryPolicy{},
+			failures:         1,
+			expectedAttempts: 1,
+			expectedError:    fmt.Errorf("resource temporarily unavailable"),
+		},
+		"transient failure": {
+			policy:           ReadRetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+			failures:         2,
+			expectedAttempts: 3,
+			expectedError:    nil,
+		},
+		"retries exhausted": {
+			policy:           ReadRetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+			failures:         5,
+			expectedAttempts: 3,
+			expectedError:    fmt.Errorf("resource temporarily unavailable"),
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			attempts := 0
+			_, err := retryRead(test.policy, func() ([]byte, error) {
+				attempts++
+				if attempts <= test.failures {
+					return nil, fmt.Errorf("resource temporarily unavailable")
+				}
+				return fileContent, nil
+			})
+
+			if !reflect.DeepEqual(test.expectedError, err) {
+				t.Errorf(
+					"unexpected error\nexpected: [%v]\nactual:   [%v]",
+					test.expectedError,
+					err,
+				)
+			}
+
+			if attempts != test.expectedAttempts {
+				t.Errorf(
+					"unexpected number of attempts\nexpected: [%v]\nactual:   [%v]",
+					test.expectedAttempts,
+					attempts,
+				)
+			}
+		})
+	}
+}
+
+func TestDiskPersistence_ReadRetryPolicy_MissingFile(t *testing.T) {
+	dataDir := t.TempDir()
+
+	handle, err := NewBasicDiskHandle(
+		dataDir,
+		WithBasicReadRetryPolicy(
+			ReadRetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	descriptors, err := readAllDescriptors(handle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file goes missing after it has been listed.
+	if err := os.Remove(filepath.Join(dataDir, dirName1, fileName11)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := descriptors[0].Content(); !os.IsNotExist(err) {
+		t.Errorf(
+			"unexpected error\nexpected: [file does not exist]\nactual:   [%v]",
+			err,
+		)
+	}
+}
@@ -0,0 +1,196 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Codec converts values to and from the bytes stored by the persistence
+// handles.
+type Codec interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte, value interface{}) error
+}
+
+// JSONCodec is a Codec encoding values as JSON.
+type JSONCodec struct{}
+
+// Marshal encodes the value as JSON.
+func (JSONCodec) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal decodes the JSON data into the value.
+func (JSONCodec) Unmarshal(data []byte, value interface{}) error {
+	return json.Unmarshal(data, value)
+}
+
+// ProtobufMessage is a value which can be encoded with the ProtobufCodec.
+// It is implemented by the messages generated by the gogo protobuf compiler.
+type ProtobufMessage interface {
+	Marshal() ([]byte, error)
+	Unmarshal(data []byte) error
+}
+
+// ProtobufCodec is a Codec encoding protobuf messages. The encoded values
+// must implement ProtobufMessage.
+type ProtobufCodec struct{}
+
+// Marshal encodes the protobuf message.
+func (ProtobufCodec) Marshal(value interface{}) ([]byte, error) {
+	message, ok := value.(ProtobufMessage)
+	if !ok {
+		return nil, fmt.Errorf("value of type [%T] is not a protobuf message", value)
+	}
+
+	return message.Marshal()
+}
+
+// Unmarshal decodes the data into the protobuf message.
+func (ProtobufCodec) Unmarshal(data []byte, value interface{}) error {
+	message, ok := value.(ProtobufMessage)
+	if !ok {
+		return fmt.Errorf("value of type [%T] is not a protobuf message", value)
+	}
+
+	return message.Unmarshal(data)
+}
+
+// StoredValue is a value decoded from the data read by ReadAllValues along
+// with the directory and the name the data is stored under.
+type StoredValue struct {
+	Directory string
+	Name      string
+	Value     interface{}
+}
+
+// ValueStore is a typed layer over a persistence handle. It encodes the saved
+// values with a codec before passing them to the handle and decodes the data
+// read from the handle, so that the users of the handle do not have to encode
+// and decode the data on their own.
+type ValueStore struct {
+	handle RWHandle
+	codec  Codec
+}
+
+// NewValueStore creates a ValueStore storing the values encoded with
+// the given codec in the given handle.
+func NewValueStore(handle RWHandle, codec Codec) *ValueStore {
+	return &ValueStore{
+		handle: handle,
+		codec:  codec,
+	}
+}
+
+// NewJSONStore creates a ValueStore storing the values encoded as JSON in
+// the given handle.
+func NewJSONStore(handle RWHandle) *ValueStore {
+	return NewValueStore(handle, JSONCodec{})
+}
+
+// NewProtobufStore creates a ValueStore storing protobuf messages in the given
+// handle.
+func NewProtobufStore(handle RWHandle) *ValueStore {
+	return NewValueStore(handle, ProtobufCodec{})
+}
+
+// SaveValue encodes the given value and saves it under the given name in
+// the given directory.
+func (vs *ValueStore) SaveValue(
+	value interface{},
+	directory string,
+	name string,
+) error {
+	data, err := vs.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf(
+			"could not encode value [%s/%s]: [%v]",
+			directory,
+			name,
+			err,
+		)
+	}
+
+	return vs.handle.Save(data, directory, name)
+}
+
+// ReadAllValues reads all the data returned by ReadAll of the handle and
+// decodes each piece of the data into a value returned by newValue, which
+// must return a new pointer on each call, e.g. a pointer to a new struct.
+// It returns two channels, just like ReadAll. The first channel streams
+// the decoded values and the second one streams the errors occurred during
+// reading and decoding. Both channels should be read concurrently and they
+// are closed when there is no more to be read.
+func (vs *ValueStore) ReadAllValues(
+	newValue func() interface{},
+) (<-chan StoredValue, <-chan error) {
+	inputData, inputErrors := vs.handle.ReadAll()
+
+	outputValues := make(chan StoredValue)
+	outputErrors := make(chan error)
+
+	go func() {
+		defer close(outputValues)
+		defer close(outputErrors)
+
+		for inputData != nil || inputErrors != nil {
+			select {
+			case descriptor, ok := <-inputData:
+				if !ok {
+					inputData = nil
+					continue
+				}
+
+				value, err := vs.decode(descriptor, newValue)
+				if err != nil {
+					outputErrors <- err
+					continue
+				}
+
+				outputValues <- StoredValue{
+					Directory: descriptor.Directory(),
+					Name:      descriptor.Name(),
+					Value:     value,
+				}
+			case err, ok := <-inputErrors:
+				if !ok {
+					inputErrors = nil
+					continue
+				}
+
+				outputErrors <- err
+			}
+		}
+	}()
+
+	return outputValues, outputErrors
+}
+
+// decode reads the content of the given descriptor and decodes it into
+// a value returned by newValue.
+func (vs *ValueStore) decode(
+	descriptor DataDescriptor,
+	newValue func() interface{},
+) (interface{}, error) {
+	content, err := descriptor.Content()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"could not read value [%s/%s]: [%v]",
+			descriptor.Directory(),
+			descriptor.Name(),
+			err,
+		)
+	}
+
+	value := newValue()
+	if err := vs.codec.Unmarshal(content, value); err != nil {
+		return nil, fmt.Errorf(
+			"could not decode value [%s/%s]: [%v]",
+			descriptor.Directory(),
+			descriptor.Name(),
+			err,
+		)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,192 @@
+package persistence
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type storedDeposit struct {
+	Depositor string   `json:"depositor"`
+	Amount    uint64   `json:"amount"`
+	Signers   []string `json:"signers"`
+}
+
+func TestJSONStore_RoundTrip(t *testing.T) {
+	handle := newMemoryPersistence()
+	store := NewJSONStore(handle)
+
+	deposits := map[string]*storedDeposit{
+		fileName11: {
+			Depositor: "0x2b5ad5c4795c026514f8317c7a215e218dccd6cf",
+			Amount:    1000,
+			Signers:   []string{"alice", "bob"},
+		},
+		fileName12: {
+			Depositor: "0x6813eb9362372eef6200f3b1dbc3f819671cba69",
+			Amount:    42,
+		},
+	}
+
+	for name, deposit := range deposits {
+		if err := store.SaveValue(deposit, dirName1, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	valueChan, errorChan := store.ReadAllValues(func() interface{} {
+		return &storedDeposit{}
+	})
+
+	errorsDone := make(chan []error)
+	go func() {
+		var errs []error
+		for err := range errorChan {
+			errs = append(errs, err)
+		}
+		errorsDone <- errs
+	}()
+
+	readDeposits := make(map[string]*storedDeposit)
+	for value := range valueChan {
+		if value.Directory != dirName1 {
+			t.Errorf(
+				"unexpected directory\nexpected: [%v]\nactual:   [%v]",
+				dirName1,
+				value.Directory,
+			)
+		}
+
+		readDeposits[value.Name] = value.Value.(*storedDeposit)
+	}
+
+	if errs := <-errorsDone; len(errs) != 0 {
+		t.Fatalf("unexpected errors: [%v]", errs)
+	}
+
+	if !reflect.DeepEqual(deposits, readDeposits) {
+		t.Errorf(
+			"unexpected values\nexpected: [%v]\nactual:   [%v]",
+			deposits,
+			readDeposits,
+		)
+	}
+}
+
+func TestJSONStore_ReadAllValuesErrors(t *testing.T) {
+	handle := newMemoryPersistence()
+	handle.readErr = fmt.Errorf("could not read the directory")
+
+	store := NewJSONStore(handle)
+
+	if err := store.SaveValue(
+		&storedDeposit{Amount: 1},
+		dirName1,
+		fileName11,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.Save([]byte("not json"), dirName1, fileName12); err != nil {
+		t.Fatal(err)
+	}
+
+	valueChan, errorChan := store.ReadAllValues(func() interface{} {
+		return &storedDeposit{}
+	})
+
+	errorsDone := make(chan []string)
+	go func() {
+		var errs []string
+		for err := range errorChan {
+			errs = append(errs, err.Error())
+		}
+		errorsDone <- errs
+	}()
+
+	var names []string
+	for value := range valueChan {
+		names = append(names, value.Name)
+	}
+
+	errs := <-errorsDone
+	sort.Strings(errs)
+
+	expectedNames := []string{fileName11}
+	if !reflect.DeepEqual(expectedNames, names) {
+		t.Errorf(
+			"unexpected decoded values\nexpected: [%v]\nactual:   [%v]",
+			expectedNames,
+			names,
+		)
+	}
+
+	if len(errs) != 2 ||
+		!strings.HasPrefix(errs[0], "could not decode value") ||
+		errs[1] != handle.readErr.Error() {
+		t.Errorf("unexpected errors: [%v]", errs)
+	}
+}
+
+func TestProtobufStore_NotAMessage(t *testing.T) {
+	store := NewProtobufStore(newMemoryPersistence())
+
+	err := store.SaveValue(&storedDeposit{}, dirName1, fileName11)
+
+	expectedError := "could not encode value [0x424242/file11]: " +
+		"[value of type [*persistence.storedDeposit] is not a protobuf message]"
+	if err == nil || err.Error() != expectedError {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			expectedError,
+			err,
+		)
+	}
+}
+
+func TestProtobufStore_RoundTrip(t *testing.T) {
+	store := NewProtobufStore(newMemoryPersistence())
+
+	message := &mockProtobufMessage{payload: "payload"}
+	if err := store.SaveValue(message, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	valueChan, errorChan := store.ReadAllValues(func() interface{} {
+		return &mockProtobufMessage{}
+	})
+
+	go func() {
+		for err := range errorChan {
+			t.Error(err)
+		}
+	}()
+
+	var values []interface{}
+	for value := range valueChan {
+		values = append(values, value.Value)
+	}
+
+	expectedValues := []interface{}{message}
+	if !reflect.DeepEqual(expectedValues, values) {
+		t.Errorf(
+			"unexpected values\nexpected: [%v]\nactual:   [%v]",
+			expectedValues,
+			values,
+		)
+	}
+}
+
+type mockProtobufMessage struct {
+	payload string
+}
+
+func (mpm *mockProtobufMessage) Marshal() ([]byte, error) {
+	return []byte(mpm.payload), nil
+}
+
+func (mpm *mockProtobufMessage) Unmarshal(data []byte) error {
+	mpm.payload = string(data)
+	return nil
+}
@@ -0,0 +1,99 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Verifier is implemented by the persistence handles able to check
+// the integrity of the persisted data without modifying it.
+type Verifier interface {
+	// Verify reads all non-archived data and returns the problems found.
+	// The error is returned only if the verification could not be performed
+	// at all, e.g. because the data directory does not exist.
+	Verify() ([]VerificationError, error)
+}
+
+// VerificationError describes a problem with the persisted data found by
+// Verify. Directory and Name are empty if the problem does not concern
+// a single file, e.g. if a directory could not be listed; the error
+// describes the affected path in such a case.
+type VerificationError struct {
+	Directory string
+	Name      string
+	Err       error
+}
+
+func (ve VerificationError) Error() string {
+	if ve.Name == "" {
+		return ve.Err.Error()
+	}
+
+	return fmt.Sprintf(
+		"file [%v] in directory [%v] failed verification: [%v]",
+		ve.Name,
+		ve.Directory,
+		ve.Err,
+	)
+}
+
+func (ve VerificationError) Unwrap() error {
+	return ve.Err
+}
+
+func (ds *basicDiskPersistence) Verify() ([]VerificationError, error) {
+	return verify(ds.currentDirPath(), ds.readRetryPolicy)
+}
+
+func (ds *protectedDiskPersistence) Verify() ([]VerificationError, error) {
+	return verify(ds.currentDirPath(), ds.readRetryPolicy)
+}
+
+// verify reads all the files from the given directory path and returns
+// the problems found. The files are read according to the given retry
+// policy. The writes buffered in memory are not verified as they have not
+// reached the disk yet. Nothing is modified.
+func verify(
+	directoryPath string,
+	retryPolicy ReadRetryPolicy,
+) ([]VerificationError, error) {
+	if _, err := os.Stat(directoryPath); err != nil {
+		return nil, fmt.Errorf(
+			"could not access the directory [%v]: [%v]",
+			directoryPath,
+			err,
+		)
+	}
+
+	dataChannel, errorChannel := readAll(
+		context.Background(),
+		directoryPath,
+		nil,
+		retryPolicy,
+		nil,
+		nil,
+	)
+
+	errorsDone := make(chan []VerificationError)
+	go func() {
+		var problems []VerificationError
+		for err := range errorChannel {
+			problems = append(problems, VerificationError{Err: err})
+		}
+		errorsDone <- problems
+	}()
+
+	var problems []VerificationError
+	for descriptor := range dataChannel {
+		if _, err := descriptor.Content(); err != nil {
+			problems = append(problems, VerificationError{
+				Directory: descriptor.Directory(),
+				Name:      descriptor.Name(),
+				Err:       err,
+			})
+		}
+	}
+
+	return append(<-errorsDone, problems...), nil
+}
@@ -0,0 +1,147 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type verifiableHandle interface {
+	RWHandle
+	Verifier
+}
+
+func TestDiskPersistence_Verify(t *testing.T) {
+	var tests = map[string]struct {
+		initDiskPersistenceFn func(t *testing.T) (verifiableHandle, string)
+		corruptFn             func(t *testing.T, filePath string)
+		expectedProblems      []VerificationError
+	}{
+		"basic disk persistence, no problems": {
+			initDiskPersistenceFn: initVerifiableBasicDiskPersistence,
+		},
+		"protected disk persistence, no problems": {
+			initDiskPersistenceFn: initVerifiableProtectedDiskPersistence,
+		},
+		"basic disk persistence, file replaced with a dangling link": {
+			initDiskPersistenceFn: initVerifiableBasicDiskPersistence,
+			corruptFn:             replaceWithDanglingLink,
+			expectedProblems: []VerificationError{
+				{Directory: dirName1, Name: fileName12},
+			},
+		},
+		"protected disk persistence, file replaced with a dangling link": {
+			initDiskPersistenceFn: initVerifiableProtectedDiskPersistence,
+			corruptFn:             replaceWithDanglingLink,
+			expectedProblems: []VerificationError{
+				{Directory: dirName1, Name: fileName12},
+			},
+		},
+		"protected disk persistence, file replaced with a directory": {
+			initDiskPersistenceFn: initVerifiableProtectedDiskPersistence,
+			corruptFn:             replaceWithDirectory,
+			expectedProblems: []VerificationError{
+				{Directory: dirName1, Name: fileName12},
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			handle, currentDir := test.initDiskPersistenceFn(t)
+
+			for _, file := range []struct{ dirName, fileName string }{
+				{dirName1, fileName11},
+				{dirName1, fileName12},
+				{dirName2, fileName21},
+			} {
+				if err := handle.Save(
+					fileContent,
+					file.dirName,
+					file.fileName,
+				); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if test.corruptFn != nil {
+				test.corruptFn(t, filepath.Join(currentDir, dirName1, fileName12))
+			}
+
+			problems, err := handle.Verify()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var actualProblems []VerificationError
+			for _, problem := range problems {
+				if problem.Err == nil {
+					t.Errorf("missing error of problem [%+v]", problem)
+				}
+				actualProblems = append(
+					actualProblems,
+					VerificationError{
+						Directory: problem.Directory,
+						Name:      problem.Name,
+					},
+				)
+			}
+
+			if !reflect.DeepEqual(test.expectedProblems, actualProblems) {
+				t.Errorf(
+					"unexpected problems\nexpected: [%v]\nactual:   [%v]",
+					test.expectedProblems,
+					problems,
+				)
+			}
+
+			// Verification must not modify anything.
+			if test.corruptFn == nil {
+				assertExist(t, currentDir, filepath.Join(dirName1, fileName12), "check file after verification")
+			}
+			assertExist(t, currentDir, filepath.Join(dirName1, fileName11), "check file after verification")
+			assertExist(t, currentDir, filepath.Join(dirName2, fileName21), "check file after verification")
+		})
+	}
+}
+
+func TestDiskPersistence_VerifyMissingDirectory(t *testing.T) {
+	handle, dataDir := initBasicDiskPersistence(t)
+
+	if err := os.RemoveAll(dataDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := handle.Verify(); err == nil {
+		t.Fatal("expected verification error")
+	}
+}
+
+func initVerifiableBasicDiskPersistence(t *testing.T) (verifiableHandle, string) {
+	handle, dataDir := initBasicDiskPersistence(t)
+	return handle, dataDir
+}
+
+func initVerifiableProtectedDiskPersistence(t *testing.T) (verifiableHandle, string) {
+	handle, dataDir := initProtectedDiskPersistence(t)
+	return handle, filepath.Join(dataDir, dirCurrent)
+}
+
+func replaceWithDanglingLink(t *testing.T, filePath string) {
+	if err := os.Remove(filePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filePath+".missing", filePath); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func replaceWithDirectory(t *testing.T, filePath string) {
+	if err := os.Remove(filePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filePath, 0700); err != nil {
+		t.Fatal(err)
+	}
+}
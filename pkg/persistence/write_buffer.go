@@ -0,0 +1,252 @@
+package persistence
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FlushPolicy determines when the writes buffered in memory by the disk
+// persistence are flushed to the disk. Buffering the writes trades durability
+// for throughput: the writes not yet flushed are lost on a crash.
+type FlushPolicy struct {
+	// Interval is the interval in which the buffered writes are flushed.
+	// The writes are not flushed periodically if the value is not set.
+	Interval time.Duration
+
+	// MaxBufferedBytes is the size of the buffered data which, once reached,
+	// triggers the flush. The size is not limited if the value is not set.
+	MaxBufferedBytes int
+}
+
+// Flusher is implemented by the persistence handles buffering the writes in
+// memory. Flush persists all the writes buffered so far.
+type Flusher interface {
+	Flush() error
+}
+
+// writeBuffer buffers the files saved in the given directory in memory and
+// writes them to the disk when flushed.
+type writeBuffer struct {
+	mutex sync.Mutex
+
+	directoryPath string
+	policy        FlushPolicy
+	operations    *fileOperations
+
+	// files maps directory names to names of the files in the directory
+	// and their content.
+	files map[string]map[string][]byte
+	size  int
+
+	// stopped is set once the context of the buffer is done and nothing
+	// flushes the buffer periodically anymore.
+	stopped bool
+}
+
+// newWriteBuffer creates a buffer of the files saved in the given directory.
+// If the policy sets the flush interval, the buffer is flushed periodically
+// until the context is done, when it is flushed for the last time. Files saved
+// after the context is done are written synchronously. The files are written
+// through the given file operations.
+func newWriteBuffer(
+	ctx context.Context,
+	directoryPath string,
+	policy FlushPolicy,
+	operations *fileOperations,
+) *writeBuffer {
+	wb := &writeBuffer{
+		directoryPath: directoryPath,
+		policy:        policy,
+		operations:    operations,
+		files:         make(map[string]map[string][]byte),
+	}
+
+	go func() {
+		var tick <-chan time.Time
+		if policy.Interval > 0 {
+			ticker := time.NewTicker(policy.Interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-tick:
+				if err := wb.flush(); err != nil {
+					logger.Errorf("could not flush buffered writes: [%v]", err)
+				}
+			case <-ctx.Done():
+				if err := wb.stop(); err != nil {
+					logger.Errorf("could not flush buffered writes: [%v]", err)
+				}
+				return
+			}
+		}
+	}()
+
+	return wb
+}
+
+// save buffers the file. The file is flushed along with all the other
+// buffered files if the buffered data size reaches the threshold or if
+// the buffer has been stopped. If the file could not be flushed, it is dropped
+// from the buffer and the error is returned so that the failed save has no
+// effect and can be retried. Failures to flush the other files are logged and
+// the files are kept in the buffer.
+func (wb *writeBuffer) save(data []byte, dirName, fileName string) error {
+	wb.mutex.Lock()
+	defer wb.mutex.Unlock()
+
+	directory, ok := wb.files[dirName]
+	if !ok {
+		directory = make(map[string][]byte)
+		wb.files[dirName] = directory
+	}
+
+	previousData, previouslyBuffered := directory[fileName]
+	wb.size -= len(previousData)
+	directory[fileName] = append([]byte{}, data...)
+	wb.size += len(data)
+
+	thresholdReached := wb.policy.MaxBufferedBytes > 0 &&
+		wb.size >= wb.policy.MaxBufferedBytes
+	if !thresholdReached && !wb.stopped {
+		return nil
+	}
+
+	err := wb.flushDirectories(wb.directoryNames()...)
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := wb.files[dirName][fileName]; !ok {
+		// The saved file has been written so the save succeeded.
+		logger.Errorf("could not flush buffered writes: [%v]", err)
+		return nil
+	}
+
+	// Restore the buffer state from before the save.
+	wb.size -= len(data)
+	if previouslyBuffered {
+		wb.files[dirName][fileName] = previousData
+		wb.size += len(previousData)
+	} else {
+		delete(wb.files[dirName], fileName)
+		if len(wb.files[dirName]) == 0 {
+			delete(wb.files, dirName)
+		}
+	}
+
+	return err
+}
+
+// remove drops the file from the buffer. It returns false if the file has not
+// been buffered.
+func (wb *writeBuffer) remove(dirName, fileName string) bool {
+	wb.mutex.Lock()
+	defer wb.mutex.Unlock()
+
+	data, ok := wb.files[dirName][fileName]
+	if !ok {
+		return false
+	}
+
+	wb.size -= len(data)
+	delete(wb.files[dirName], fileName)
+	if len(wb.files[dirName]) == 0 {
+		delete(wb.files, dirName)
+	}
+
+	return true
+}
+
+// buffered returns a copy of the buffer content which can be read while
+// the buffer is being modified.
+func (wb *writeBuffer) buffered() map[string]map[string][]byte {
+	wb.mutex.Lock()
+	defer wb.mutex.Unlock()
+
+	files := make(map[string]map[string][]byte, len(wb.files))
+	for dirName, directory := range wb.files {
+		files[dirName] = make(map[string][]byte, len(directory))
+		for fileName, data := range directory {
+			// The buffered data is never modified so it can be shared.
+			files[dirName][fileName] = data
+		}
+	}
+
+	return files
+}
+
+// flush writes all the buffered files to the disk.
+func (wb *writeBuffer) flush() error {
+	wb.mutex.Lock()
+	defer wb.mutex.Unlock()
+
+	return wb.flushDirectories(wb.directoryNames()...)
+}
+
+// stop flushes all the buffered files for the last time and makes the buffer
+// write all the files saved from now on synchronously.
+func (wb *writeBuffer) stop() error {
+	wb.mutex.Lock()
+	defer wb.mutex.Unlock()
+
+	wb.stopped = true
+
+	return wb.flushDirectories(wb.directoryNames()...)
+}
+
+// flushDirectory writes the buffered files of the given directory to
+// the disk.
+func (wb *writeBuffer) flushDirectory(dirName string) error {
+	wb.mutex.Lock()
+	defer wb.mutex.Unlock()
+
+	return wb.flushDirectories(dirName)
+}
+
+// flushDirectories writes the buffered files of the given directories to
+// the disk. Files which could not be written are kept in the buffer. It must
+// be called with the buffer mutex held.
+func (wb *writeBuffer) flushDirectories(dirNames ...string) error {
+	for _, dirName := range dirNames {
+		directory, ok := wb.files[dirName]
+		if !ok {
+			continue
+		}
+
+		if err := EnsureDirectoryExists(wb.directoryPath, dirName); err != nil {
+			return err
+		}
+
+		for fileName, data := range directory {
+			filePath := filepath.Join(wb.directoryPath, dirName, fileName)
+			if err := write(filePath, data, wb.operations); err != nil {
+				return err
+			}
+
+			wb.size -= len(data)
+			delete(directory, fileName)
+		}
+
+		delete(wb.files, dirName)
+	}
+
+	return nil
+}
+
+// directoryNames returns the sorted names of the directories with buffered
+// files. It must be called with the buffer mutex held.
+func (wb *writeBuffer) directoryNames() []string {
+	dirNames := make([]string, 0, len(wb.files))
+	for dirName := range wb.files {
+		dirNames = append(dirNames, dirName)
+	}
+	sort.Strings(dirNames)
+
+	return dirNames
+}
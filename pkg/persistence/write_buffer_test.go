@@ -0,0 +1,247 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiskPersistence_BufferedWrites(t *testing.T) {
+	var tests = map[string]struct {
+		initHandleFn func(ctx context.Context, dataDir string) (RWHandle, error)
+		currentDir   string
+	}{
+		"basic disk persistence": {
+			initHandleFn: func(ctx context.Context, dataDir string) (RWHandle, error) {
+				return NewBasicDiskHandle(
+					dataDir,
+					WithBasicBufferedWrites(ctx, FlushPolicy{}),
+				)
+			},
+			currentDir: "",
+		},
+		"protected disk persistence": {
+			initHandleFn: func(ctx context.Context, dataDir string) (RWHandle, error) {
+				return NewProtectedDiskHandle(
+					dataDir,
+					WithBufferedWrites(ctx, FlushPolicy{}),
+				)
+			},
+			currentDir: dirCurrent,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ctx, cancelCtx := context.WithCancel(context.Background())
+			defer cancelCtx()
+
+			dataDir := t.TempDir()
+			handle, err := test.initHandleFn(ctx, dataDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := handle.Save([]byte("old"), dirName1, fileName11); err != nil {
+				t.Fatal(err)
+			}
+			if err := handle.(Flusher).Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			filePath11 := filepath.Join(test.currentDir, dirName1, fileName11)
+			filePath21 := filepath.Join(test.currentDir, dirName2, fileName21)
+
+			assertExist(t, dataDir, filePath11, "check flushed file")
+
+			if err := handle.Save([]byte("new"), dirName1, fileName11); err != nil {
+				t.Fatal(err)
+			}
+			if err := handle.Save(fileContent, dirName2, fileName21); err != nil {
+				t.Fatal(err)
+			}
+
+			assertNotExist(t, dataDir, filePath21, "check buffered file")
+
+			// ReadAll sees the buffered writes.
+			expectedContent := map[dataPath][]byte{
+				{dirName1, fileName11}: []byte("new"),
+				{dirName2, fileName21}: fileContent,
+			}
+			content, err := readAllContent(handle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(expectedContent, content) {
+				t.Errorf(
+					"unexpected content\nexpected: [%s]\nactual:   [%s]",
+					expectedContent,
+					content,
+				)
+			}
+
+			// The buffered writes are flushed once the context is done.
+			cancelCtx()
+			time.Sleep(100 * time.Millisecond)
+
+			assertExist(t, dataDir, filePath21, "check flushed file")
+
+			content11, err := Read(filepath.Join(dataDir, filePath11))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal([]byte("new"), content11) {
+				t.Errorf(
+					"unexpected flushed content\nexpected: [%s]\nactual:   [%s]",
+					"new",
+					content11,
+				)
+			}
+		})
+	}
+}
+
+func TestDiskPersistence_BufferedWrites_FlushPolicy(t *testing.T) {
+	var tests = map[string]struct {
+		policy FlushPolicy
+		wait   time.Duration
+	}{
+		"buffered data size threshold reached": {
+			policy: FlushPolicy{MaxBufferedBytes: len(fileContent)},
+		},
+		"flush interval passed": {
+			policy: FlushPolicy{Interval: 10 * time.Millisecond},
+			wait:   100 * time.Millisecond,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ctx, cancelCtx := context.WithCancel(context.Background())
+			defer cancelCtx()
+
+			dataDir := t.TempDir()
+			handle, err := NewBasicDiskHandle(
+				dataDir,
+				WithBasicBufferedWrites(ctx, test.policy),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+				t.Fatal(err)
+			}
+
+			time.Sleep(test.wait)
+
+			assertExist(
+				t,
+				dataDir,
+				filepath.Join(dirName1, fileName11),
+				"check flushed file",
+			)
+		})
+	}
+}
+
+func TestBasicDiskPersistence_BufferedWrites_SaveAfterContextDone(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+
+	dataDir := t.TempDir()
+	handle, err := NewBasicDiskHandle(
+		dataDir,
+		WithBasicBufferedWrites(ctx, FlushPolicy{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancelCtx()
+	time.Sleep(100 * time.Millisecond)
+
+	// Nothing flushes the buffer once the context is done so the file is
+	// written synchronously.
+	if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+
+	assertExist(
+		t,
+		dataDir,
+		filepath.Join(dirName1, fileName11),
+		"check written file",
+	)
+}
+
+func TestBasicDiskPersistence_BufferedWrites_Delete(t *testing.T) {
+	dataDir := t.TempDir()
+	handle, err := NewBasicDiskHandle(
+		dataDir,
+		WithBasicBufferedWrites(context.Background(), FlushPolicy{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.Delete(dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.(Flusher).Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNotExist(
+		t,
+		dataDir,
+		filepath.Join(dirName1, fileName11),
+		"check deleted file",
+	)
+
+	content, err := readAllContent(handle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(content) != 0 {
+		t.Errorf("unexpected content: [%s]", content)
+	}
+}
+
+func TestProtectedDiskPersistence_BufferedWrites_Archive(t *testing.T) {
+	dataDir := t.TempDir()
+	handle, err := NewProtectedDiskHandle(
+		dataDir,
+		WithBufferedWrites(context.Background(), FlushPolicy{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := handle.Save(fileContent, dirName1, fileName11); err != nil {
+		t.Fatal(err)
+	}
+	if err := handle.Archive(dirName1); err != nil {
+		t.Fatal(err)
+	}
+
+	assertExist(
+		t,
+		dataDir,
+		filepath.Join(dirArchive, dirName1, fileName11),
+		"check archived file",
+	)
+
+	content, err := readAllContent(handle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(content) != 0 {
+		t.Errorf("unexpected content: [%s]", content)
+	}
+}
@@ -2,9 +2,11 @@ package rate
 
 import (
 	"context"
+	"errors"
+	"math"
+	"sync"
 	"time"
 
-	"golang.org/x/sync/semaphore"
 	"golang.org/x/time/rate"
 )
 
@@ -18,12 +20,30 @@ var (
 	DefaultConcurrencyLimit = 30
 )
 
+// ErrLimiterClosed is returned when a permit is requested from a limiter
+// that has been drained.
+var ErrLimiterClosed = errors.New("rate limiter is closed")
+
 // Limiter is a helper tool which allows controlling the number and
-// concurrency of requests made against a generic target.
+// concurrency of requests made against a generic target. Both limits can be
+// changed at runtime with SetRequestsPerSecond and SetConcurrencyLimit.
 type Limiter struct {
 	limiter              *rate.Limiter
-	semaphore            *semaphore.Weighted
 	acquirePermitTimeout time.Duration
+	callTimeout          time.Duration
+
+	stateMutex       sync.Mutex
+	closed           bool
+	heldPermits      int
+	lastTokenTime    time.Time
+	concurrencyLimit int
+	drained          chan struct{}
+
+	// permitsChanged is closed and replaced each time a permit is released,
+	// the concurrency limit changes, or the limiter is closed, so that
+	// the requests waiting for a permit can recheck whether they can
+	// acquire it.
+	permitsChanged chan struct{}
 }
 
 // LimiterConfig represents the configuration of the rate limiter.
@@ -40,25 +60,29 @@ type LimiterConfig struct {
 	// AcquirePermitTimeout determines how long a request can wait trying
 	// to acquire a permit from the rate limiter.
 	AcquirePermitTimeout time.Duration
+
+	// CallTimeout determines how long a request executed with an acquired
+	// permit can take. It bounds the request itself, not the time spent on
+	// acquiring the permit, so that a request which hangs does not hold
+	// the permit indefinitely. The timeout is disabled if the value is not set.
+	CallTimeout time.Duration
 }
 
 // NewLimiter creates a new rate limiter instance basing on given config.
 func NewLimiter(
 	config *LimiterConfig,
 ) *Limiter {
-	l := &Limiter{}
-
-	if config.RequestsPerSecondLimit > 0 {
-		l.limiter = rate.NewLimiter(
-			rate.Limit(config.RequestsPerSecondLimit),
+	l := &Limiter{
+		limiter: rate.NewLimiter(
+			requestsPerSecondLimit(float64(config.RequestsPerSecondLimit)),
 			1,
-		)
+		),
+		drained:        make(chan struct{}),
+		permitsChanged: make(chan struct{}),
 	}
 
 	if config.ConcurrencyLimit > 0 {
-		l.semaphore = semaphore.NewWeighted(
-			int64(config.ConcurrencyLimit),
-		)
+		l.concurrencyLimit = config.ConcurrencyLimit
 	}
 
 	if config.AcquirePermitTimeout > 0 {
@@ -67,37 +91,208 @@ func NewLimiter(
 		l.acquirePermitTimeout = 5 * time.Minute
 	}
 
+	if config.CallTimeout > 0 {
+		l.callTimeout = config.CallTimeout
+	}
+
 	return l
 }
 
-// AcquirePermit acquires the permit.
+// CallContext returns a context the request executed with an acquired permit
+// should use. If the call timeout is configured, the returned context is done
+// once the timeout passes. Otherwise, the parent context is returned.
+// The returned cancel function must be called once the request completes.
+func (l *Limiter) CallContext(
+	parent context.Context,
+) (context.Context, context.CancelFunc) {
+	if l.callTimeout > 0 {
+		return context.WithTimeout(parent, l.callTimeout)
+	}
+
+	return parent, func() {}
+}
+
+// AcquirePermit acquires the permit. It returns ErrLimiterClosed if the
+// limiter has been drained.
 func (l *Limiter) AcquirePermit() error {
+	if l.isClosed() {
+		return ErrLimiterClosed
+	}
+
 	ctx, cancel := context.WithTimeout(
 		context.Background(),
 		l.acquirePermitTimeout,
 	)
 	defer cancel()
 
-	if l.limiter != nil {
-		err := l.limiter.Wait(ctx)
-		if err != nil {
-			return err
-		}
+	err := l.limiter.Wait(ctx)
+	if err != nil {
+		return err
 	}
 
-	if l.semaphore != nil {
-		err := l.semaphore.Acquire(ctx, 1)
-		if err != nil {
-			return err
+	l.stateMutex.Lock()
+	l.lastTokenTime = time.Now()
+	l.stateMutex.Unlock()
+
+	for {
+		l.stateMutex.Lock()
+
+		// The limiter could be drained while we were waiting for the permit.
+		if l.closed {
+			l.stateMutex.Unlock()
+			return ErrLimiterClosed
 		}
-	}
 
-	return nil
+		if l.concurrencyLimit == 0 || l.heldPermits < l.concurrencyLimit {
+			l.heldPermits++
+			l.stateMutex.Unlock()
+			return nil
+		}
+
+		permitsChanged := l.permitsChanged
+		l.stateMutex.Unlock()
+
+		select {
+		case <-permitsChanged:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 }
 
 // ReleasePermit releases the permit.
 func (l *Limiter) ReleasePermit() {
-	if l.semaphore != nil {
-		l.semaphore.Release(1)
+	l.stateMutex.Lock()
+	defer l.stateMutex.Unlock()
+
+	if l.heldPermits == 0 {
+		return
+	}
+
+	l.heldPermits--
+	l.notifyPermitsChanged()
+
+	if l.closed && l.heldPermits == 0 {
+		close(l.drained)
+	}
+}
+
+// SetConcurrencyLimit changes the maximum number of concurrent requests
+// which can be executed against the target at the same time. A non-positive
+// limit disables the concurrency limit. The change is safe while requests
+// are in flight. If the limit is lowered below the number of permits held
+// at the moment, the requests holding them are not affected but no new
+// permits are granted until enough of them are released.
+func (l *Limiter) SetConcurrencyLimit(limit int) {
+	if limit < 0 {
+		limit = 0
+	}
+
+	l.stateMutex.Lock()
+	defer l.stateMutex.Unlock()
+
+	l.concurrencyLimit = limit
+	l.notifyPermitsChanged()
+}
+
+// SetRequestsPerSecond changes the maximum average number of requests per
+// second. A non-positive limit disables the requests per second limit.
+// The change is safe while requests are in flight. Requests already
+// scheduled by the previous limit keep their schedule.
+func (l *Limiter) SetRequestsPerSecond(limit float64) {
+	l.limiter.SetLimit(requestsPerSecondLimit(limit))
+}
+
+// notifyPermitsChanged wakes up the requests waiting for a permit. It must be
+// called with the state mutex held.
+func (l *Limiter) notifyPermitsChanged() {
+	close(l.permitsChanged)
+	l.permitsChanged = make(chan struct{})
+}
+
+// requestsPerSecondLimit converts the requests per second limit to
+// the limit of the underlying limiter. Non-positive values mean the requests
+// are not limited.
+func requestsPerSecondLimit(limit float64) rate.Limit {
+	if limit <= 0 {
+		return rate.Inf
+	}
+
+	return rate.Limit(limit)
+}
+
+// Drain closes the limiter so that all subsequent AcquirePermit calls fail
+// immediately with ErrLimiterClosed. It blocks until all permits held at the
+// moment are released or until the provided context is done. Releasing
+// permits is still possible once the limiter is closed.
+func (l *Limiter) Drain(ctx context.Context) error {
+	l.stateMutex.Lock()
+	if !l.closed {
+		l.closed = true
+		l.notifyPermitsChanged()
+		if l.heldPermits == 0 {
+			close(l.drained)
+		}
+	}
+	l.stateMutex.Unlock()
+
+	select {
+	case <-l.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// AvailablePermits returns the number of requests which can be executed at
+// the moment without waiting for other requests to release their permits.
+// It returns -1 if the concurrency is not limited. The function does not
+// affect the limiter state.
+func (l *Limiter) AvailablePermits() int {
+	l.stateMutex.Lock()
+	defer l.stateMutex.Unlock()
+
+	if l.concurrencyLimit == 0 {
+		return -1
+	}
+
+	available := l.concurrencyLimit - l.heldPermits
+	if available < 0 {
+		return 0
+	}
+
+	return available
+}
+
+// TokensAvailable returns the approximate number of requests which can be
+// started at the moment without exceeding the requests per second limit.
+// The value is evaluated based on the time the last request has been let
+// through and it is capped by the burst of the limiter. It returns +Inf if
+// the requests per second are not limited. The function does not affect
+// the limiter state.
+func (l *Limiter) TokensAvailable() float64 {
+	if l.limiter.Limit() == rate.Inf {
+		return math.Inf(1)
+	}
+
+	burst := float64(l.limiter.Burst())
+
+	l.stateMutex.Lock()
+	lastTokenTime := l.lastTokenTime
+	l.stateMutex.Unlock()
+
+	if lastTokenTime.IsZero() {
+		return burst
 	}
+
+	tokens := time.Since(lastTokenTime).Seconds() * float64(l.limiter.Limit())
+
+	return math.Min(tokens, burst)
+}
+
+func (l *Limiter) isClosed() bool {
+	l.stateMutex.Lock()
+	defer l.stateMutex.Unlock()
+
+	return l.closed
 }
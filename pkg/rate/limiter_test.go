@@ -0,0 +1,397 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Drain(t *testing.T) {
+	limiter := NewLimiter(&LimiterConfig{
+		ConcurrencyLimit: 2,
+	})
+
+	err := limiter.AcquirePermit()
+	if err != nil {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+
+	drainErr := make(chan error)
+	go func() {
+		drainErr <- limiter.Drain(context.Background())
+	}()
+
+	// Wait until the limiter gets closed by the drain.
+	for !limiter.isClosed() {
+		time.Sleep(time.Millisecond)
+	}
+
+	err = limiter.AcquirePermit()
+	if !errors.Is(err, ErrLimiterClosed) {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			ErrLimiterClosed,
+			err,
+		)
+	}
+
+	select {
+	case err := <-drainErr:
+		t.Fatalf("drain completed with a permit still held: [%v]", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	limiter.ReleasePermit()
+
+	select {
+	case err := <-drainErr:
+		if err != nil {
+			t.Fatalf("unexpected drain error: [%v]", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("drain did not complete after releasing the permit")
+	}
+}
+
+func TestLimiter_DrainTimeout(t *testing.T) {
+	limiter := NewLimiter(&LimiterConfig{})
+
+	err := limiter.AcquirePermit()
+	if err != nil {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		10*time.Millisecond,
+	)
+	defer cancel()
+
+	err = limiter.Drain(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf(
+			"unexpected error\nexpected: [%v]\nactual:   [%v]",
+			context.DeadlineExceeded,
+			err,
+		)
+	}
+}
+
+func TestLimiter_CallContext(t *testing.T) {
+	limiter := NewLimiter(&LimiterConfig{
+		CallTimeout: 50 * time.Millisecond,
+	})
+
+	ctx, cancel := limiter.CallContext(context.Background())
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); !ok {
+		t.Fatal("call context should have a deadline")
+	}
+}
+
+func TestLimiter_CallContext_Disabled(t *testing.T) {
+	limiter := NewLimiter(&LimiterConfig{})
+
+	ctx, cancel := limiter.CallContext(context.Background())
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("call context should not have a deadline")
+	}
+}
+
+func TestLimiter_AvailablePermits(t *testing.T) {
+	limiter := NewLimiter(&LimiterConfig{
+		ConcurrencyLimit: 3,
+	})
+
+	assertAvailablePermits := func(expected int) {
+		if actual := limiter.AvailablePermits(); actual != expected {
+			t.Fatalf(
+				"unexpected available permits\nexpected: [%v]\nactual:   [%v]",
+				expected,
+				actual,
+			)
+		}
+	}
+
+	assertAvailablePermits(3)
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.AcquirePermit(); err != nil {
+			t.Fatalf("unexpected error: [%v]", err)
+		}
+	}
+
+	assertAvailablePermits(1)
+	// reading the utilization must not change it
+	assertAvailablePermits(1)
+
+	limiter.ReleasePermit()
+
+	assertAvailablePermits(2)
+}
+
+func TestLimiter_AvailablePermits_Unlimited(t *testing.T) {
+	limiter := NewLimiter(&LimiterConfig{})
+
+	if available := limiter.AvailablePermits(); available != -1 {
+		t.Fatalf(
+			"unexpected available permits\nexpected: [%v]\nactual:   [%v]",
+			-1,
+			available,
+		)
+	}
+}
+
+func TestLimiter_TokensAvailable(t *testing.T) {
+	limiter := NewLimiter(&LimiterConfig{
+		RequestsPerSecondLimit: 10,
+	})
+
+	if tokens := limiter.TokensAvailable(); tokens != 1 {
+		t.Fatalf(
+			"unexpected available tokens\nexpected: [%v]\nactual:   [%v]",
+			1,
+			tokens,
+		)
+	}
+
+	if err := limiter.AcquirePermit(); err != nil {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+	limiter.ReleasePermit()
+
+	if tokens := limiter.TokensAvailable(); tokens >= 1 {
+		t.Fatalf(
+			"unexpected available tokens\nexpected: [less than 1]\nactual:   [%v]",
+			tokens,
+		)
+	}
+
+	// The token is replenished within 100ms with 10 requests per second.
+	time.Sleep(150 * time.Millisecond)
+
+	if tokens := limiter.TokensAvailable(); tokens != 1 {
+		t.Fatalf(
+			"unexpected available tokens\nexpected: [%v]\nactual:   [%v]",
+			1,
+			tokens,
+		)
+	}
+}
+
+func TestLimiter_TokensAvailable_Unlimited(t *testing.T) {
+	limiter := NewLimiter(&LimiterConfig{})
+
+	if tokens := limiter.TokensAvailable(); !math.IsInf(tokens, 1) {
+		t.Fatalf(
+			"unexpected available tokens\nexpected: [%v]\nactual:   [%v]",
+			math.Inf(1),
+			tokens,
+		)
+	}
+}
+
+func TestLimiter_SetConcurrencyLimit(t *testing.T) {
+	limiter := NewLimiter(&LimiterConfig{
+		ConcurrencyLimit:     4,
+		AcquirePermitTimeout: 5 * time.Second,
+	})
+
+	for i := 0; i < 4; i++ {
+		if err := limiter.AcquirePermit(); err != nil {
+			t.Fatalf("unexpected error: [%v]", err)
+		}
+	}
+
+	// Lower the limit below the number of permits in flight. The permits
+	// already held remain valid.
+	limiter.SetConcurrencyLimit(2)
+
+	if available := limiter.AvailablePermits(); available != 0 {
+		t.Fatalf(
+			"unexpected available permits\nexpected: [%v]\nactual:   [%v]",
+			0,
+			available,
+		)
+	}
+
+	acquired := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			acquired <- limiter.AcquirePermit()
+		}()
+	}
+
+	// Releasing two permits brings the number of held permits down to
+	// the new limit so no new permit is granted yet.
+	limiter.ReleasePermit()
+	limiter.ReleasePermit()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("permit granted above the concurrency limit: [%v]", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	limiter.ReleasePermit()
+
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("unexpected error: [%v]", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("permit not granted after releasing a permit")
+	}
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("permit granted above the concurrency limit: [%v]", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Raising the limit admits the waiting request without any permit
+	// being released.
+	limiter.SetConcurrencyLimit(3)
+
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("unexpected error: [%v]", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("permit not granted after raising the concurrency limit")
+	}
+
+	if available := limiter.AvailablePermits(); available != 0 {
+		t.Fatalf(
+			"unexpected available permits\nexpected: [%v]\nactual:   [%v]",
+			0,
+			available,
+		)
+	}
+}
+
+func TestLimiter_SetConcurrencyLimit_Concurrent(t *testing.T) {
+	limiter := NewLimiter(&LimiterConfig{
+		ConcurrencyLimit:     8,
+		AcquirePermitTimeout: 5 * time.Second,
+	})
+
+	var inFlightMutex sync.Mutex
+	inFlight, maxInFlight := 0, 0
+
+	workersDone := make(chan error)
+	for i := 0; i < 16; i++ {
+		go func() {
+			for j := 0; j < 50; j++ {
+				if err := limiter.AcquirePermit(); err != nil {
+					workersDone <- err
+					return
+				}
+
+				inFlightMutex.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				inFlightMutex.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				inFlightMutex.Lock()
+				inFlight--
+				inFlightMutex.Unlock()
+
+				limiter.ReleasePermit()
+			}
+			workersDone <- nil
+		}()
+	}
+
+	// Keep reconfiguring the limiter while the requests are in flight.
+	stopReconfiguring := make(chan struct{})
+	reconfiguringDone := make(chan struct{})
+	go func() {
+		defer close(reconfiguringDone)
+		for i := 0; ; i++ {
+			select {
+			case <-stopReconfiguring:
+				return
+			default:
+			}
+
+			limiter.SetConcurrencyLimit(1 + i%8)
+			limiter.SetRequestsPerSecond(float64(1000 + i%1000))
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	for i := 0; i < 16; i++ {
+		select {
+		case err := <-workersDone:
+			if err != nil {
+				t.Fatalf("unexpected error: [%v]", err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("requests did not complete; possible deadlock")
+		}
+	}
+
+	close(stopReconfiguring)
+	<-reconfiguringDone
+
+	if maxInFlight > 8 {
+		t.Fatalf(
+			"unexpected max number of requests in flight\n"+
+				"expected: [at most %v]\nactual:   [%v]",
+			8,
+			maxInFlight,
+		)
+	}
+
+	if available := limiter.AvailablePermits(); available < 0 {
+		t.Fatalf("unexpected available permits: [%v]", available)
+	}
+}
+
+func TestLimiter_SetRequestsPerSecond(t *testing.T) {
+	limiter := NewLimiter(&LimiterConfig{
+		RequestsPerSecondLimit: 1,
+	})
+
+	if err := limiter.AcquirePermit(); err != nil {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+	limiter.ReleasePermit()
+
+	// With 1 request per second, the next permit would be granted in
+	// a second. Raising the limit lets it be granted right away.
+	limiter.SetRequestsPerSecond(1000)
+
+	start := time.Now()
+	if err := limiter.AcquirePermit(); err != nil {
+		t.Fatalf("unexpected error: [%v]", err)
+	}
+	limiter.ReleasePermit()
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("permit not granted with the raised limit; took [%v]", elapsed)
+	}
+
+	limiter.SetRequestsPerSecond(0)
+
+	if tokens := limiter.TokensAvailable(); !math.IsInf(tokens, 1) {
+		t.Fatalf(
+			"unexpected available tokens\nexpected: [%v]\nactual:   [%v]",
+			math.Inf(1),
+			tokens,
+		)
+	}
+}
@@ -7,14 +7,13 @@ var commandTemplateContent = `// Code generated - DO NOT EDIT.
 package cmd
 
 import (
-	"sync"
-
 	"{{.HostChainModule}}/common"
 	"{{.HostChainModule}}/common/hexutil"
 	"{{.HostChainModule}}/core/types"
 	"{{.HostChainModule}}/ethclient"
 
 	chainutil "{{.ChainUtilPackage}}"
+	"github.com/keep-network/keep-common/pkg/chain/ethereum"
 	"github.com/keep-network/keep-common/pkg/cmd"
 
 	"github.com/spf13/cobra"
@@ -33,6 +32,9 @@ var {{.FullVar}}Description = ` + "`" + `The {{.DashedName}} command allows call
 	All subcommands can be called against a specific block by passing the
 	-b/--block flag.
 
+	All subcommands fail if they do not complete within the duration passed
+	with the --timeout flag, if set.
+
 	Subcommands for mutating methods may be submitted as a mutating transaction
 	by passing the -s/--submit flag. In this mode, this command will terminate
 	successfully once the transaction has been submitted, but will not wait for
@@ -87,7 +89,10 @@ func {{$contract.ShortVar}}{{$method.CapsName}}Command() *cobra.Command {
 }
 
 func {{$contract.ShortVar}}{{$method.CapsName}}(c *cobra.Command, args []string) error {
-	contract, err := initialize{{$contract.Class}}(c)
+	ctx, cancelCtx := cmd.CommandContext(c)
+	defer cancelCtx()
+
+	contract, err := initialize{{$contract.Class}}(ctx, c)
 	if err != nil {
 		return err
 	}
@@ -109,20 +114,30 @@ func {{$contract.ShortVar}}{{$method.CapsName}}(c *cobra.Command, args []string)
 	{{- end }}
 	{{- end }}
 
-	result, err := contract.{{$method.CapsName}}AtBlock(
-		{{- range $i, $param := .CmdArgInfos }}
-		{{ $param.Name }},
-		{{- end }}
-		cmd.BlockFlagValue.Int,
+	blockNumber, err := cmd.ResolveBlockFlagWithContext(
+		ctx,
+		ModuleCommand.GetConfig().URL,
 	)
-
 	if err != nil {
 		return err
 	}
 
-	cmd.PrintOutput(result)
+	return cmd.RunWithContext(ctx, func() error {
+		result, err := contract.{{$method.CapsName}}AtBlock(
+			{{- range $i, $param := .CmdArgInfos }}
+			{{ $param.Name }},
+			{{- end }}
+			blockNumber,
+		)
 
-	return nil
+		if err != nil {
+			return err
+		}
+
+		cmd.PrintOutput(result)
+
+		return nil
+	})
 }
 
 {{- end -}}
@@ -155,7 +170,10 @@ func {{$contract.ShortVar}}{{$method.CapsName}}Command() *cobra.Command {
 }
 
 func {{$contract.ShortVar}}{{$method.CapsName}}(c *cobra.Command, args []string) error {
-	contract, err := initialize{{$contract.Class}}(c)
+	ctx, cancelCtx := cmd.CommandContext(c)
+	defer cancelCtx()
+
+	contract, err := initialize{{$contract.Class}}(ctx, c)
 	if err != nil {
 		return err
 	}
@@ -186,14 +204,18 @@ func {{$contract.ShortVar}}{{$method.CapsName}}(c *cobra.Command, args []string)
 
 	if shouldSubmit, _ := c.Flags().GetBool(cmd.SubmitFlag); shouldSubmit {
 		// Do a regular submission. Take payable into account.
-		transaction, err = contract.{{$method.CapsName}}(
-			{{- range $i, $param := .CmdArgInfos }}
-			{{ $param.Name }},
-			{{- end }}
-			{{- if $method.Payable }}
-			cmd.ValueFlagValue.Int(),
-			{{- end }}
-		)
+		err = cmd.RunWithContext(ctx, func() error {
+			var err error
+			transaction, err = contract.{{$method.CapsName}}(
+				{{- range $i, $param := .CmdArgInfos }}
+				{{ $param.Name }},
+				{{- end }}
+				{{- if $method.Payable }}
+				cmd.ValueFlagValue.Int,
+				{{- end }}
+			)
+			return err
+		})
 		if err != nil {
 			return err
 		}
@@ -201,19 +223,31 @@ func {{$contract.ShortVar}}{{$method.CapsName}}(c *cobra.Command, args []string)
 		cmd.PrintOutput(transaction.Hash())
 	} else {
 		// Do a call.
-		{{ if gt (len $method.Return.Type) 0 -}} result, {{ end -}} err = contract.Call{{$method.CapsName}}(
-			{{- range $i, $param := .CmdArgInfos }}
-			{{ $param.Name }},
-			{{- end }}
-			{{- if $method.Payable }}
-			cmd.ValueFlagValue.Int(),
-			{{- end }}
-			cmd.BlockFlagValue.Int,
+		blockNumber, err := cmd.ResolveBlockFlagWithContext(
+			ctx,
+			ModuleCommand.GetConfig().URL,
 		)
 		if err != nil {
 			return err
 		}
 
+		err = cmd.RunWithContext(ctx, func() error {
+			var err error
+			{{ if gt (len $method.Return.Type) 0 -}} result, {{ end -}} err = contract.Call{{$method.CapsName}}(
+				{{- range $i, $param := .CmdArgInfos }}
+				{{ $param.Name }},
+				{{- end }}
+				{{- if $method.Payable }}
+				cmd.ValueFlagValue.Int,
+				{{- end }}
+				blockNumber,
+			)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
 		{{ if gt (len $method.Return.Type) 0 -}}
 		cmd.PrintOutput(result)
 		{{- else -}}
@@ -234,15 +268,18 @@ func {{$contract.ShortVar}}{{$method.CapsName}}(c *cobra.Command, args []string)
 
 /// ------------------- Initialization -------------------
 
-func initialize{{.Class}}(c *cobra.Command) (*contract.{{.Class}}, error) {
+func initialize{{.Class}}(
+	ctx context.Context,
+	c *cobra.Command,
+) (*contract.{{.Class}}, error) {
 	cfg := *ModuleCommand.GetConfig()
 
-	client, err := ethclient.Dial(cfg.URL)
+	client, err := ethclient.DialContext(ctx, cfg.URL)
 	if err != nil {
 		return nil, fmt.Errorf("error connecting to host chain node: [%v]", err)
 	}
 
-	chainID, err := client.ChainID(context.Background())
+	chainID, err := client.ChainID(ctx)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"failed to resolve host chain id: [%v]",
@@ -262,16 +299,6 @@ func initialize{{.Class}}(c *cobra.Command) (*contract.{{.Class}}, error) {
 		)
 	}
 
-	miningWaiter := chainutil.NewMiningWaiter(client, cfg)
-
-	blockCounter, err := chainutil.NewBlockCounter(client)
-	if err != nil {
-		return nil, fmt.Errorf(
-			"failed to create block counter: [%v]",
-			err,
-		)
-	}
-
 	address, err := cfg.ContractAddress("{{.Class}}")
 	if err != nil {
 		return nil, fmt.Errorf(
@@ -281,15 +308,33 @@ func initialize{{.Class}}(c *cobra.Command) (*contract.{{.Class}}, error) {
 		)
 	}
 
-	return contract.New{{.Class}}(
+	instance, err := contract.New{{.Class}}WithClient(
 		address,
 		chainID,
 		key,
 		client,
-		chainutil.NewNonceManager(client, key.Address),
-		miningWaiter,
-		blockCounter,
-		&sync.Mutex{},
+		cfg,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := instance.EnsureDeployed(ctx); err != nil {
+		return nil, err
+	}
+
+	deploymentBlock, err := cfg.DeploymentBlock("{{.Class}}")
+	if err != nil && !errors.Is(err, ethereum.ErrDeploymentBlockNotConfigured) {
+		return nil, fmt.Errorf(
+			"failed to get %s deployment block: [%w]",
+			"{{.Class}}",
+			err,
+		)
+	}
+	if err == nil {
+		instance.SetDeploymentBlock(deploymentBlock)
+	}
+
+	return instance, nil
 }
 `
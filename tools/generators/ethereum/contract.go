@@ -41,6 +41,26 @@ import (
 //
 // Note that currently the packages for contract and command are hardcoded to
 // contract and cmd, respectively.
+//
+// Payable contract methods, marked with the payable state mutability or, in
+// ABIs generated by compiler before v0.6.0, with the payable flag, take the
+// value transferred with the call as an additional value *big.Int argument
+// following the method parameters. This applies to the transaction
+// submission, the call, and the gas estimate of the method alike. The
+// generated command passes the value of the --value flag there.
+//
+// If the optional -immutable-methods flag points to a JSON file with an array
+// of method names, e.g. ["decimals", "name"], the contract binding caches
+// the result of the first successful call of each of these methods and serves
+// all the subsequent calls from the cache. Only methods whose results never
+// change after the contract deployment, such as constants and immutable
+// variables, should be listed there. Listing a method whose result may change
+// makes the contract binding serve a stale value forever.
+//
+// If the optional -package-abis flag lists ABIs of other contracts generated
+// into the same package, a warning is printed for each struct declared by more
+// than one of the contracts and for each structure declared under different
+// names.
 func main() {
 	hostChainModule := flag.String(
 		"host-chain-module",
@@ -54,6 +74,21 @@ func main() {
 		"Host chain utils package imported from the generated code",
 	)
 
+	immutableMethodsPath := flag.String(
+		"immutable-methods",
+		"",
+		"Path to a JSON file with an array of names of the contract const "+
+			"methods whose results never change after the deployment; "+
+			"results of these methods are cached by the contract handle",
+	)
+
+	packageABIs := flag.String(
+		"package-abis",
+		"",
+		"Comma-separated paths to ABIs of other contracts generated into "+
+			"the same package; used to detect colliding struct declarations",
+	)
+
 	flag.Parse()
 
 	// Two leading arguments (`input.abi` and `contract_output.go`) are required.
@@ -107,16 +142,51 @@ func main() {
 		))
 	}
 
+	var immutableMethods []string
+	if len(*immutableMethodsPath) > 0 {
+		// #nosec G304 (file path provided as taint input)
+		// This line is placed in the auxiliary generator code,
+		// not in the core application. User input has to be passed to
+		// provide a path to the immutable methods file.
+		immutableMethodsFile, err := ioutil.ReadFile(*immutableMethodsPath)
+		if err != nil {
+			panic(fmt.Sprintf(
+				"Failed to read immutable methods file at [%v]: [%v].",
+				*immutableMethodsPath,
+				err,
+			))
+		}
+
+		err = json.Unmarshal(immutableMethodsFile, &immutableMethods)
+		if err != nil {
+			panic(fmt.Sprintf(
+				"Failed to parse immutable methods file at [%v]: [%v].",
+				*immutableMethodsPath,
+				err,
+			))
+		}
+	}
+
 	// The name of the ABI binding Go class is the same as the filename of the
 	// ABI file, minus the extension.
 	abiClassName := path.Base(abiPath)
 	abiClassName = abiClassName[0 : len(abiClassName)-4] // strip .abi
+
+	if len(*packageABIs) > 0 {
+		warnStructCollisions(
+			abiClassName,
+			&abi,
+			strings.Split(*packageABIs, ","),
+		)
+	}
+
 	contractInfo := buildContractInfo(
 		*hostChainModule,
 		*chainUtilPackage,
 		abiClassName,
 		&abi,
 		payableInfo,
+		immutableMethods,
 	)
 
 	contractBuf, err := generateCode(
@@ -171,6 +241,48 @@ func main() {
 	}
 }
 
+// Prints a warning for each struct declaration collision between the contract
+// ABI and ABIs of other contracts generated into the same package.
+func warnStructCollisions(
+	abiClassName string,
+	contractABI *abi.ABI,
+	packageABIPaths []string,
+) {
+	contractABIs := map[string]*abi.ABI{abiClassName: contractABI}
+
+	for _, packageABIPath := range packageABIPaths {
+		// #nosec G304 (file path provided as taint input)
+		// This line is placed in the auxiliary generator code,
+		// not in the core application. User input has to be passed to
+		// provide paths to the contract ABIs.
+		packageABIFile, err := ioutil.ReadFile(packageABIPath)
+		if err != nil {
+			panic(fmt.Sprintf(
+				"Failed to read ABI file at [%v]: [%v].",
+				packageABIPath,
+				err,
+			))
+		}
+
+		packageABI, err := abi.JSON(strings.NewReader(string(packageABIFile)))
+		if err != nil {
+			panic(fmt.Sprintf(
+				"Failed to parse ABI at [%v]: [%v].",
+				packageABIPath,
+				err,
+			))
+		}
+
+		packageABIClassName := path.Base(packageABIPath)
+		packageABIClassName = strings.TrimSuffix(packageABIClassName, ".abi")
+		contractABIs[packageABIClassName] = &packageABI
+	}
+
+	for _, collision := range findStructCollisions(contractABIs) {
+		fmt.Printf("WARNING: %s\n", collision)
+	}
+}
+
 func parseTemplates() (*template.Template, error) {
 	templates := map[string]string{
 		"contract_const_methods.go.tmpl":     contractConstMethodsTemplateContent,
@@ -10,16 +10,32 @@ type {{$method.Return.Type}} struct {
 }
 {{- end }}
 
+{{- if $method.Immutable }}
+// {{$method.CapsName}} is declared immutable. The result of the first
+// successful call is cached and returned by all the subsequent calls
+// without calling the chain.
+func ({{$contract.ShortVar}} *{{$contract.Class}}) {{$method.CapsName}}() ({{$method.Return.Type}}, error) {
+	return {{$contract.ShortVar}}.{{$method.LowerName}}Cache.Get(
+		{{$contract.ShortVar}}.fetch{{$method.CapsName}},
+	)
+}
+
+func ({{$contract.ShortVar}} *{{$contract.Class}}) fetch{{$method.CapsName}}() ({{$method.Return.Type}}, error) {
+{{- else }}
 func ({{$contract.ShortVar}} *{{$contract.Class}}) {{$method.CapsName}}(
 	{{$method.ParamDeclarations -}}
 	{{if $method.Payable -}} value *big.Int, {{- end -}}
 ) ({{$method.Return.Type}}, error) {
+{{- end }}
 	{{- if and $method.Return.Multi (not $method.Return.Structured) }}
 	{{$method.Return.Vars}}
 	{{- else }}
 	result,
 	{{- end }} err := {{$contract.ShortVar}}.contract.{{$method.CapsName}}(
-		{{$contract.ShortVar}}.callerOptions,
+		chainutil.WithCallLabelOpts(
+			{{$contract.ShortVar}}.callerOptions,
+			"{{$contract.Class}}.{{$method.LowerName}}",
+		),
 		{{$method.Params}}
 	)
 
@@ -50,7 +66,11 @@ func ({{$contract.ShortVar}} *{{$contract.Class}}) {{$method.CapsName}}AtBlock(
 ) ({{$method.Return.Type}}, error) {
 	var result {{$method.Return.Type}}
 
-	err := chainutil.CallAtBlock(
+	err := chainutil.CallAtBlockWithContext(
+		chainutil.WithCallLabelOpts(
+			{{$contract.ShortVar}}.callerOptions,
+			"{{$contract.Class}}.{{$method.LowerName}}",
+		).Context,
 		{{$contract.ShortVar}}.callerOptions.From,
 		blockNumber,
 		nil,
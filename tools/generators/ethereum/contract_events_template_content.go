@@ -18,6 +18,9 @@ func ({{$contract.ShortVar}} *{{$contract.Class}}) {{$event.CapsName}}Event(
 	if opts.PastBlocks == 0 {
 		opts.PastBlocks = chainutil.DefaultSubscribeOptsPastBlocks
 	}
+	if opts.OrderingDelay == 0 {
+		opts.OrderingDelay = chainutil.DefaultSubscribeOptsOrderingDelay
+	}
 
 	return &{{$event.SubscriptionCapsName}}{
 		{{$contract.ShortVar}},
@@ -32,6 +35,13 @@ type {{$event.SubscriptionCapsName}} struct {
 	{{$event.IndexedFilterFields -}}
 }
 
+{{if $event.HashedIndexedParams -}}
+// {{$contract.FullVar}}{{$event.CapsName}}Func handles {{$event.CapsName}} events.
+// Only the Keccak-256 hashes of the indexed string and bytes parameters are
+// stored in the event topics so the handler receives the hashes instead of
+// the values. Use the {{$event.CapsName}}<Parameter>Hash functions of the
+// contract to compute the hashes of the expected values.
+{{end -}}
 type {{$contract.FullVar}}{{$event.CapsName}}Func func(
 	{{$event.ParamDeclarations -}}
 )
@@ -62,6 +72,65 @@ func ({{$event.SubscriptionShortVar}} *{{$event.SubscriptionCapsName}}) OnEvent(
 	})
 }
 
+// {{$contract.FullVar}}{{$event.CapsName}}OrderedFunc handles {{$event.CapsName}}
+// events in their chain order. Besides the block number, the handler receives
+// the index of the transaction emitting the event in the block and the index
+// of the event log in the block.
+type {{$contract.FullVar}}{{$event.CapsName}}OrderedFunc func(
+	{{$event.OrderedParamDeclarations -}}
+)
+
+// OnOrderedEvent works like OnEvent but the events are passed to the handler
+// in strict (blockNumber, txIndex, logIndex) order. Each event is held for
+// the ordering delay of the subscription options so that the events received
+// from the regular subscription and the ones pulled by the subscription
+// monitoring are sorted. Duplicated events are handled once. An event received
+// after a later event has already been handled is dropped.
+func ({{$event.SubscriptionShortVar}} *{{$event.SubscriptionCapsName}}) OnOrderedEvent(
+	handler {{$contract.FullVar}}{{$event.CapsName}}OrderedFunc,
+) subscription.EventSubscription {
+	eventChan := make(chan *abi.{{$contract.AbiClass}}{{$event.CapsName}})
+	ctx, cancelCtx := context.WithCancel(context.Background())
+
+	go chainutil.OrderEvents(
+		ctx,
+		eventChan,
+		func(event *abi.{{$contract.AbiClass}}{{$event.CapsName}}) chainutil.EventPosition {
+			return chainutil.EventPosition{
+				BlockNumber: event.Raw.BlockNumber,
+				TxIndex:     event.Raw.TxIndex,
+				LogIndex:    event.Raw.Index,
+			}
+		},
+		{{$event.SubscriptionShortVar}}.opts.OrderingDelay,
+		func(event *abi.{{$contract.AbiClass}}{{$event.CapsName}}) {
+			handler(
+				{{$event.OrderedParamExtractors}}
+			)
+		},
+	)
+
+	sub := {{$event.SubscriptionShortVar}}.Pipe(eventChan)
+	return subscription.NewEventSubscription(func() {
+		sub.Unsubscribe()
+		cancelCtx()
+	})
+}
+
+// {{$event.CapsName}}EventFields returns the parameters of the given
+// {{$event.CapsName}} event keyed by their names in the contract ABI, along
+// with the number of the block the event has been emitted in, so that
+// the event can be passed to generic sinks, e.g. serialized to JSON, without
+// knowing its shape. Indexed string and bytes parameters are keyed by their
+// names with the Hash suffix as only their hashes are stored in the event.
+func ({{$contract.ShortVar}} *{{$contract.Class}}) {{$event.CapsName}}EventFields(
+	event *abi.{{$contract.AbiClass}}{{$event.CapsName}},
+) map[string]interface{} {
+	return map[string]interface{}{
+		{{$event.FieldEntries}}
+	}
+}
+
 func ({{$event.SubscriptionShortVar}} *{{$event.SubscriptionCapsName}}) Pipe(
 	sink chan *abi.{{$contract.AbiClass}}{{$event.CapsName}},
 ) subscription.EventSubscription {
@@ -191,5 +260,228 @@ func ({{$contract.ShortVar}} *{{$contract.Class}}) Past{{$event.CapsName}}Events
 	return events, nil
 }
 
-{{- end -}}
+// Past{{$event.CapsName}}EventsSinceDeployment returns all {{$event.CapsName}}
+// events emitted by the contract since its deployment up to the current
+// block. Events are fetched in chunks of chainutil.PastEventsChunkSize blocks
+// starting from the deployment block set with SetDeploymentBlock.
+func ({{$contract.ShortVar}} *{{$contract.Class}}) Past{{$event.CapsName}}EventsSinceDeployment(
+	ctx context.Context,
+	{{$event.IndexedFilterDeclarations -}}
+) ([]*abi.{{$contract.AbiClass}}{{$event.CapsName}}, error) {
+	if {{$contract.ShortVar}}.deploymentBlock == nil {
+		return nil, fmt.Errorf(
+			"deployment block of {{$contract.Class}} is not known; " +
+				"please configure it in the DeploymentBlocks config " +
+				"section or set it with SetDeploymentBlock",
+		)
+	}
+
+	currentBlock, err := {{$contract.ShortVar}}.blockCounter.CurrentBlock()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error getting current block: [%v]",
+			err,
+		)
+	}
+
+	events := make([]*abi.{{$contract.AbiClass}}{{$event.CapsName}}, 0)
+
+	for startBlock := *{{$contract.ShortVar}}.deploymentBlock; startBlock <= currentBlock; startBlock += chainutil.PastEventsChunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		endBlock := startBlock + chainutil.PastEventsChunkSize - 1
+		if endBlock > currentBlock {
+			endBlock = currentBlock
+		}
+
+		chunkEvents, err := {{$contract.ShortVar}}.Past{{$event.CapsName}}Events(
+			startBlock,
+			&endBlock,
+			{{$event.IndexedFilters}}
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, chunkEvents...)
+	}
+
+	return events, nil
+}
+
+// {{$event.CapsName}}TopicFilter returns the topic filter matching
+// {{$event.CapsName}} events with the given indexed parameter values and
+// a ready-to-use query for these events emitted by the contract. The block
+// range of the returned query is not set.
+func ({{$contract.ShortVar}} *{{$contract.Class}}) {{$event.CapsName}}TopicFilter(
+	{{$event.IndexedFilterDeclarations -}}
+) ([][]interface{}, hostchain.FilterQuery, error) {
+	{{$event.IndexedTopicRules -}}
+	filter := [][]interface{}{
+		{ {{- $contract.ShortVar}}.contractABI.Events["{{$event.Name}}"].ID},
+		{{$event.IndexedTopicRuleList}}
+	}
+
+	topics, err := hostchainabi.MakeTopics(filter...)
+	if err != nil {
+		return nil, hostchain.FilterQuery{}, fmt.Errorf(
+			"error encoding {{$event.CapsName}} topic filter: [%v]",
+			err,
+		)
+	}
+
+	return filter, hostchain.FilterQuery{
+		Addresses: []common.Address{ {{- $contract.ShortVar}}.contractAddress},
+		Topics:    topics,
+	}, nil
+}
+
+{{- range $param := $event.HashedIndexedParams }}
+
+// {{$event.CapsName}}{{$param.CapsName}}Hash returns the Keccak-256 hash of
+// the given {{$param.CapsName}} value as stored in the topics of
+// {{$event.CapsName}} events, so that the hash received by the event handler
+// can be matched against the expected value.
+func ({{$contract.ShortVar}} *{{$contract.Class}}) {{$event.CapsName}}{{$param.CapsName}}Hash(
+	value {{$param.GoType}},
+) common.Hash {
+	{{- if eq $param.GoType "string" }}
+	return crypto.Keccak256Hash([]byte(value))
+	{{- else }}
+	return crypto.Keccak256Hash(value)
+	{{- end }}
+}
+{{- end }}
+
+{{- end }}
+
+{{- if .Events }}
+
+// {{$contract.Class}}Event is an event of any type emitted by {{$contract.Class}}
+// and delivered by WatchAllEvents. Name is the name of the event in
+// the contract ABI and Event is the decoded event, e.g.
+// *abi.{{$contract.AbiClass}}{{(index .Events 0).CapsName}} for the {{(index .Events 0).Name}} event.
+type {{$contract.Class}}Event struct {
+	Name  string
+	Event interface{}
+	Raw   types.Log
+}
+
+// WatchAllEvents subscribes to all the events emitted by {{$contract.Class}}
+// with a single subscription and passes them to the sink in the order of their
+// logs. The subscription is re-established in case of a failure. Logs which
+// can not be decoded are logged and skipped. The subscription is closed when
+// the context is done or the returned subscription is unsubscribed.
+func ({{$contract.ShortVar}} *{{$contract.Class}}) WatchAllEvents(
+	ctx context.Context,
+	sink chan<- *{{$contract.Class}}Event,
+) subscription.EventSubscription {
+	ctx, cancelCtx := context.WithCancel(ctx)
+
+	query := hostchain.FilterQuery{
+		Addresses: []common.Address{ {{- $contract.ShortVar}}.contractAddress},
+		Topics: [][]common.Hash{
+			{
+			{{- range $event := .Events }}
+				{{$contract.ShortVar}}.contractABI.Events["{{$event.Name}}"].ID,
+			{{- end }}
+			},
+		},
+	}
+
+	logs := make(chan types.Log)
+
+	subscribeFn := func(ctx context.Context) (event.Subscription, error) {
+		return {{$contract.ShortVar}}.filterer.SubscribeFilterLogs(ctx, query, logs)
+	}
+
+	thresholdViolatedFn := func(elapsed time.Duration) {
+		{{$logger}}.Warnf(
+			"subscription to all events had to be "+
+				"retried [%s] since the last attempt; please inspect "+
+				"host chain connectivity",
+				elapsed,
+		)
+	}
+
+	subscriptionFailedFn := func(err error) {
+		{{$logger}}.Errorf(
+			"subscription to all events failed "+
+				"with error: [%v]; resubscription attempt will be "+
+				"performed",
+				err,
+		)
+	}
+
+	sub := chainutil.WithResubscription(
+		chainutil.SubscriptionBackoffMax,
+		subscribeFn,
+		chainutil.SubscriptionAlertThreshold,
+		thresholdViolatedFn,
+		subscriptionFailedFn,
+	)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				sub.Unsubscribe()
+				return
+			case eventLog := <-logs:
+				decoded, err := {{$contract.ShortVar}}.decodeEvent(eventLog)
+				if err != nil {
+					{{$logger}}.Errorf(
+						"could not decode event log [%v] of transaction [%v]: [%v]",
+						eventLog.Index,
+						eventLog.TxHash.Hex(),
+						err,
+					)
+					continue
+				}
+
+				select {
+				case sink <- decoded:
+				case <-ctx.Done():
+					sub.Unsubscribe()
+					return
+				}
+			}
+		}
+	}()
+
+	return subscription.NewEventSubscription(func() {
+		cancelCtx()
+	})
+}
+
+// decodeEvent decodes the given log into the {{$contract.Class}} event
+// identified by the first topic of the log.
+func ({{$contract.ShortVar}} *{{$contract.Class}}) decodeEvent(
+	log types.Log,
+) (*{{$contract.Class}}Event, error) {
+	if len(log.Topics) == 0 {
+		return nil, fmt.Errorf("log has no topics")
+	}
+
+	switch log.Topics[0] {
+	{{- range $event := .Events }}
+	case {{$contract.ShortVar}}.contractABI.Events["{{$event.Name}}"].ID:
+		decoded, err := {{$contract.ShortVar}}.contract.Parse{{$event.CapsName}}(log)
+		if err != nil {
+			return nil, err
+		}
+		return &{{$contract.Class}}Event{
+			Name:  "{{$event.Name}}",
+			Event: decoded,
+			Raw:   log,
+		}, nil
+	{{- end }}
+	default:
+		return nil, fmt.Errorf("unknown event topic [%v]", log.Topics[0].Hex())
+	}
+}
+{{- end }}
+
 `
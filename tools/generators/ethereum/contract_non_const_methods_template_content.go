@@ -123,6 +123,64 @@ func ({{$contract.ShortVar}} *{{$contract.Class}}) {{$method.CapsName}}(
 	return transaction, err
 }
 
+// Assembles the {{$method.LowerName}} transaction with the nonce, gas limit
+// and fees resolved just like for the transaction submission, but neither
+// signs nor submits it, so that the transaction can be signed offline.
+// The local nonce is not incremented.
+func ({{$contract.ShortVar}} *{{$contract.Class}}) Build{{$method.CapsName}}Tx(
+	{{$method.ParamDeclarations -}}
+	{{- if $method.Payable -}}
+	value *big.Int,
+	{{ end }}
+	transactionOptions ...chainutil.TransactionOptions,
+) (*types.Transaction, error) {
+	{{$contract.ShortVar}}.transactionMutex.Lock()
+	defer {{$contract.ShortVar}}.transactionMutex.Unlock()
+
+	transactorOptions := chainutil.UnsignedTransactorOptions(
+		{{$contract.ShortVar}}.transactorOptions,
+	)
+
+	{{if $method.Payable -}}
+	transactorOptions.Value = value
+	{{- end }}
+
+	if len(transactionOptions) > 1 {
+		return nil, fmt.Errorf(
+			"could not process multiple transaction options sets",
+		)
+	} else if len(transactionOptions) > 0 {
+		transactionOptions[0].Apply(transactorOptions)
+	}
+
+	nonce, err := {{$contract.ShortVar}}.nonceManager.CurrentNonce()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve account nonce: %v", err)
+	}
+
+	transactorOptions.Nonce = new(big.Int).SetUint64(nonce)
+
+	transaction, err := {{$contract.ShortVar}}.contract.{{$method.CapsName}}(
+		transactorOptions,
+		{{$method.Params}}
+	)
+	if err != nil {
+		return nil, {{$contract.ShortVar}}.errorResolver.ResolveError(
+			err,
+			{{$contract.ShortVar}}.transactorOptions.From,
+			{{if $method.Payable -}}
+			value
+			{{- else -}}
+			nil
+			{{- end -}},
+			"{{$method.LowerName}}",
+			{{$method.Params}}
+		)
+	}
+
+	return transaction, nil
+}
+
 {{- $returnVar := print "result, " -}}
 {{ if eq $method.Return.Type "" -}}
 {{- $returnVar = "" -}}
@@ -162,14 +220,25 @@ func ({{$contract.ShortVar}} *{{$contract.Class}}) Call{{$method.CapsName}}(
 	return {{$returnVar}}err
 }
 
+// Gas estimate of the {{$method.LowerName}} transaction submission, based
+// on the current pending state of the chain. The estimate can be used to set
+// the gas limit of the submitted transaction.
 func ({{$contract.ShortVar}} *{{$contract.Class}}) {{$method.CapsName}}GasEstimate(
 	{{$method.ParamDeclarations -}}
+	{{- if $method.Payable -}}
+	value *big.Int,
+	{{ end -}}
 ) (uint64, error) {
 	var result uint64
 
-	result, err := chainutil.EstimateGas(
+	result, err := chainutil.EstimateGasWithValue(
 		{{$contract.ShortVar}}.callerOptions.From,
 		{{$contract.ShortVar}}.contractAddress,
+		{{- if $method.Payable }}
+		value,
+		{{- else }}
+		nil,
+		{{- end }}
 		"{{$method.LowerName}}",
 		{{$contract.ShortVar}}.contractABI,
 		{{$contract.ShortVar}}.transactor,
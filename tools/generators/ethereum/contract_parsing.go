@@ -15,8 +15,16 @@ import (
 
 // The extracted name + payability of methods from ABI JSON.
 type methodPayableInfo struct {
-	Name    string
-	Payable bool
+	Name            string
+	Payable         bool
+	StateMutability string
+}
+
+// isPayable returns true if the method accepts value. ABIs generated by
+// compiler before v0.6.0 mark such methods with the payable flag, newer ones
+// with the payable state mutability.
+func (mpi methodPayableInfo) isPayable() bool {
+	return mpi.Payable || mpi.StateMutability == "payable"
 }
 
 var (
@@ -86,6 +94,7 @@ type methodInfo struct {
 	DashedName        string
 	Modifiers         string
 	Payable           bool
+	Immutable         bool
 	CommandCallable   bool
 	Params            string
 	ParamDeclarations string
@@ -104,6 +113,7 @@ type returnInfo struct {
 }
 
 type eventInfo struct {
+	Name                      string
 	CapsName                  string
 	LowerName                 string
 	SubscriptionCapsName      string
@@ -112,9 +122,23 @@ type eventInfo struct {
 	IndexedFilters            string
 	ParamExtractors           string
 	ParamDeclarations         string
+	OrderedParamExtractors    string
+	OrderedParamDeclarations  string
 	IndexedFilterExtractors   string
 	IndexedFilterDeclarations string
 	IndexedFilterFields       string
+	IndexedTopicRules         string
+	IndexedTopicRuleList      string
+	FieldEntries              string
+	HashedIndexedParams       []hashedIndexedParamInfo
+}
+
+// hashedIndexedParamInfo describes an indexed event parameter of a dynamic
+// type. Only the Keccak-256 hash of such a parameter value is stored in the
+// event topic so the original value can not be recovered from the event.
+type hashedIndexedParamInfo struct {
+	CapsName string
+	GoType   string
 }
 
 func buildContractInfo(
@@ -123,10 +147,11 @@ func buildContractInfo(
 	abiClassName string,
 	abi *abi.ABI,
 	payableInfo []methodPayableInfo,
+	immutableMethods []string,
 ) contractInfo {
 	payableMethods := make(map[string]struct{})
 	for _, methodPayableInfo := range payableInfo {
-		if methodPayableInfo.Payable {
+		if methodPayableInfo.isPayable() {
 			normalizedName := camelCase(methodPayableInfo.Name)
 			_, ok := payableMethods[normalizedName]
 			for idx := 0; ok; idx++ {
@@ -149,6 +174,13 @@ func buildContractInfo(
 
 	structs := make(map[string]struct{})
 	constMethods, nonConstMethods := buildMethodInfo(payableMethods, abi.Methods, structs)
+	for _, warning := range markImmutableMethods(
+		constMethods,
+		nonConstMethods,
+		immutableMethods,
+	) {
+		fmt.Printf("WARNING: %s\n", warning)
+	}
 	events := buildEventInfo(shortVar, abi.Events, structs)
 
 	return contractInfo{
@@ -189,8 +221,10 @@ func buildMethodInfo(
 			modifiers = append(modifiers, method.StateMutability)
 		}
 
-		// Legacy indicators generated by compiler before v0.6.0
-		if payable {
+		// Legacy indicators generated by compiler before v0.6.0, which has
+		// no state mutability. Newer ABIs carry the payable state mutability
+		// appended above.
+		if payable && method.StateMutability == "" {
 			modifiers = append(modifiers, "payable")
 		}
 		if method.Constant {
@@ -328,6 +362,7 @@ func buildMethodInfo(
 			dashedName,
 			modifierString,
 			payable,
+			false,
 			commandCallable,
 			params,
 			paramDeclarations,
@@ -348,6 +383,63 @@ func buildMethodInfo(
 	return constMethods, nonConstMethods
 }
 
+// markImmutableMethods marks the given const methods listed as immutable, so
+// that cached accessors are generated for them. Only const, non-payable
+// methods without parameters returning a value can be cached. It returns
+// warnings for the listed methods which are not found or can not be cached.
+func markImmutableMethods(
+	constMethods []methodInfo,
+	nonConstMethods []methodInfo,
+	immutableMethods []string,
+) []string {
+	var warnings []string
+
+	for _, immutableMethod := range immutableMethods {
+		lowerName := lowercaseFirst(camelCase(immutableMethod))
+
+		found := false
+		for i := range constMethods {
+			method := &constMethods[i]
+			if method.LowerName != lowerName {
+				continue
+			}
+
+			found = true
+
+			if method.Params != "" || method.Payable || method.Return.Type == "" {
+				warnings = append(warnings, fmt.Sprintf(
+					"immutable method %s has parameters, is payable, or "+
+						"does not return a value; its result won't be cached",
+					immutableMethod,
+				))
+				continue
+			}
+
+			method.Immutable = true
+		}
+
+		for _, method := range nonConstMethods {
+			if method.LowerName == lowerName {
+				found = true
+				warnings = append(warnings, fmt.Sprintf(
+					"immutable method %s is not a const method; "+
+						"its result won't be cached",
+					immutableMethod,
+				))
+			}
+		}
+
+		if !found {
+			warnings = append(warnings, fmt.Sprintf(
+				"immutable method %s not found in the contract ABI",
+				immutableMethod,
+			))
+		}
+	}
+
+	return warnings
+}
+
 func buildEventInfo(
 	contractShortVar string,
 	eventsByName map[string]abi.Event,
@@ -374,6 +466,10 @@ func buildEventInfo(
 		indexedFilterDeclarations := ""
 		indexedFilterFields := ""
 		indexedFilters := ""
+		indexedTopicRules := ""
+		indexedTopicRuleList := ""
+		fieldEntries := ""
+		hashedIndexedParams := make([]hashedIndexedParamInfo, 0)
 		for _, param := range event.Inputs {
 			upperParam := uppercaseFirst(param.Name)
 			goType := bindType(param.Type, structs)
@@ -383,21 +479,54 @@ func buildEventInfo(
 			if param.Indexed {
 				// For event's indexed parameter abigen uses dedicated type binding
 				// for topic.
-				paramDeclarations += fmt.Sprintf("%v %v,\n", upperParam, bindTopicType(param.Type, structs))
+				topicType := bindTopicType(param.Type, structs)
+
+				// Indexed strings and bytes are bound to the hash of the value
+				// as only the hash is stored in the topic. The parameter name
+				// makes it clear the value is not decoded.
+				if topicType != goType {
+					paramDeclarations += fmt.Sprintf("%vHash %v,\n", upperParam, topicType)
+					fieldEntries += fmt.Sprintf("\"%vHash\": event.%v,\n", param.Name, upperParam)
+					hashedIndexedParams = append(hashedIndexedParams, hashedIndexedParamInfo{
+						CapsName: upperParam,
+						GoType:   goType,
+					})
+				} else {
+					paramDeclarations += fmt.Sprintf("%v %v,\n", upperParam, topicType)
+					fieldEntries += fmt.Sprintf("\"%v\": event.%v,\n", param.Name, upperParam)
+				}
 
 				indexedFilterExtractors += fmt.Sprintf("%v.%vFilter,\n", subscriptionShortVar, param.Name)
 				indexedFilterDeclarations += fmt.Sprintf("%vFilter []%v,\n", param.Name, goType)
 				indexedFilterFields += fmt.Sprintf("%vFilter []%v\n", param.Name, goType)
 				indexedFilters += fmt.Sprintf("%vFilter,\n", param.Name)
+				indexedTopicRules += fmt.Sprintf(
+					"var %[1]vRule []interface{}\n"+
+						"for _, %[1]vItem := range %[1]vFilter {\n"+
+						"%[1]vRule = append(%[1]vRule, %[1]vItem)\n"+
+						"}\n",
+					param.Name,
+				)
+				indexedTopicRuleList += fmt.Sprintf("%vRule,\n", param.Name)
 			} else {
 				paramDeclarations += fmt.Sprintf("%v %v,\n", upperParam, goType)
+				fieldEntries += fmt.Sprintf("\"%v\": event.%v,\n", param.Name, upperParam)
 			}
 		}
 
 		paramDeclarations += "blockNumber uint64,\n"
 		paramExtractors += "event.Raw.BlockNumber,\n"
+		fieldEntries += "\"blockNumber\": event.Raw.BlockNumber,\n"
+
+		// Ordered event handlers receive the complete chain position of
+		// the event.
+		orderedParamDeclarations := paramDeclarations +
+			"txIndex uint,\nlogIndex uint,\n"
+		orderedParamExtractors := paramExtractors +
+			"event.Raw.TxIndex,\nevent.Raw.Index,\n"
 
 		eventInfos = append(eventInfos, eventInfo{
+			name,
 			capsName,
 			lowerName,
 			subscriptionCapsName,
@@ -406,9 +535,15 @@ func buildEventInfo(
 			indexedFilters,
 			paramExtractors,
 			paramDeclarations,
+			orderedParamExtractors,
+			orderedParamDeclarations,
 			indexedFilterExtractors,
 			indexedFilterDeclarations,
 			indexedFilterFields,
+			indexedTopicRules,
+			indexedTopicRuleList,
+			fieldEntries,
+			hashedIndexedParams,
 		})
 	}
 
@@ -417,6 +552,174 @@ func buildEventInfo(
 	return eventInfos
 }
 
+// structDeclaration describes a struct declared in the Go bindings of
+// a contract ABI.
+type structDeclaration struct {
+	Name     string
+	Contract string
+	// Signature describes the struct structure as the names and types of
+	// all its fields.
+	Signature string
+}
+
+// findStructCollisions looks for structs declared by multiple contract ABIs
+// generated into the same Go package. Bindings for structs are generated into
+// the package by the abigen command so a struct declared by more than one
+// contract will be declared more than once in the package. Also, the same
+// structure declared by multiple contracts under different names is reported
+// as a candidate for a shared type. Returned messages describe the collisions
+// found. The contract ABIs are keyed by the contract name.
+func findStructCollisions(contractABIs map[string]*abi.ABI) []string {
+	contractNames := make([]string, 0, len(contractABIs))
+	for contractName := range contractABIs {
+		contractNames = append(contractNames, contractName)
+	}
+	sort.Strings(contractNames)
+
+	declarationsByName := make(map[string][]structDeclaration)
+	namesBySignature := make(map[string]map[string]struct{})
+
+	for _, contractName := range contractNames {
+		for _, declaration := range contractStructs(
+			contractName,
+			contractABIs[contractName],
+		) {
+			declarationsByName[declaration.Name] = append(
+				declarationsByName[declaration.Name],
+				declaration,
+			)
+
+			if _, ok := namesBySignature[declaration.Signature]; !ok {
+				namesBySignature[declaration.Signature] = make(map[string]struct{})
+			}
+			namesBySignature[declaration.Signature][declaration.Name] = struct{}{}
+		}
+	}
+
+	collisions := make([]string, 0)
+
+	for name, declarations := range declarationsByName {
+		if len(declarations) < 2 {
+			continue
+		}
+
+		contracts := make([]string, 0, len(declarations))
+		identical := true
+		for _, declaration := range declarations {
+			contracts = append(contracts, declaration.Contract)
+			identical = identical &&
+				declaration.Signature == declarations[0].Signature
+		}
+
+		description := "identically"
+		if !identical {
+			description = "differently"
+		}
+
+		collisions = append(collisions, fmt.Sprintf(
+			"struct %s is declared %s by contracts: %s",
+			name,
+			description,
+			strings.Join(contracts, ", "),
+		))
+	}
+
+	for signature, names := range namesBySignature {
+		if len(names) < 2 {
+			continue
+		}
+
+		sortedNames := make([]string, 0, len(names))
+		for name := range names {
+			sortedNames = append(sortedNames, name)
+		}
+		sort.Strings(sortedNames)
+
+		collisions = append(collisions, fmt.Sprintf(
+			"structs %s are structurally identical: %s",
+			strings.Join(sortedNames, ", "),
+			signature,
+		))
+	}
+
+	sort.Strings(collisions)
+
+	return collisions
+}
+
+// contractStructs returns declarations of all structs, including the nested
+// ones, used by the methods and events of the given contract ABI. Each struct
+// is returned once.
+func contractStructs(contractName string, contractABI *abi.ABI) []structDeclaration {
+	declarations := make(map[string]structDeclaration)
+
+	var collect func(kind abi.Type)
+	collect = func(kind abi.Type) {
+		switch kind.T {
+		case abi.TupleTy:
+			for _, elem := range kind.TupleElems {
+				collect(*elem)
+			}
+
+			declarations[kind.TupleRawName] = structDeclaration{
+				Name:      kind.TupleRawName,
+				Contract:  contractName,
+				Signature: structSignature(kind),
+			}
+		case abi.SliceTy, abi.ArrayTy:
+			collect(*kind.Elem)
+		}
+	}
+
+	for _, method := range contractABI.Methods {
+		for _, argument := range method.Inputs {
+			collect(argument.Type)
+		}
+		for _, argument := range method.Outputs {
+			collect(argument.Type)
+		}
+	}
+
+	for _, event := range contractABI.Events {
+		for _, argument := range event.Inputs {
+			collect(argument.Type)
+		}
+	}
+
+	names := make([]string, 0, len(declarations))
+	for name := range declarations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]structDeclaration, 0, len(names))
+	for _, name := range names {
+		result = append(result, declarations[name])
+	}
+
+	return result
+}
+
+// structSignature describes the structure of the given type. For tuples it
+// includes names and types of all the fields, with nested tuples described
+// by their structure instead of their name.
+func structSignature(kind abi.Type) string {
+	switch kind.T {
+	case abi.TupleTy:
+		fields := make([]string, len(kind.TupleElems))
+		for i, elem := range kind.TupleElems {
+			fields[i] = kind.TupleRawNames[i] + " " + structSignature(*elem)
+		}
+		return "(" + strings.Join(fields, ", ") + ")"
+	case abi.SliceTy:
+		return structSignature(*kind.Elem) + "[]"
+	case abi.ArrayTy:
+		return fmt.Sprintf("%s[%d]", structSignature(*kind.Elem), kind.Size)
+	default:
+		return kind.String()
+	}
+}
+
 func uppercaseFirst(str string) string {
 	if len(str) == 0 {
 		return str
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -168,6 +170,146 @@ func TestMethodStability(t *testing.T) {
 	}
 }
 
+func TestMarkImmutableMethods(t *testing.T) {
+	constMethods := []methodInfo{
+		{LowerName: "decimals", Return: returnInfo{Type: "uint8"}},
+		{
+			LowerName: "balanceOf",
+			Params:    "arg_account,\n",
+			Return:    returnInfo{Type: "*big.Int"},
+		},
+		{LowerName: "name", Return: returnInfo{Type: "string"}},
+	}
+	nonConstMethods := []methodInfo{
+		{LowerName: "transfer"},
+	}
+
+	warnings := markImmutableMethods(
+		constMethods,
+		nonConstMethods,
+		[]string{"decimals", "balanceOf", "transfer", "symbol"},
+	)
+
+	immutable := map[string]bool{}
+	for _, method := range constMethods {
+		immutable[method.LowerName] = method.Immutable
+	}
+
+	expectedImmutable := map[string]bool{
+		"decimals":  true,
+		"balanceOf": false,
+		"name":      false,
+	}
+	if !reflect.DeepEqual(expectedImmutable, immutable) {
+		t.Errorf(
+			"unexpected immutable methods\nexpected: [%v]\nactual:   [%v]",
+			expectedImmutable,
+			immutable,
+		)
+	}
+
+	expectedWarnings := []string{
+		"immutable method balanceOf has parameters, is payable, or " +
+			"does not return a value; its result won't be cached",
+		"immutable method transfer is not a const method; " +
+			"its result won't be cached",
+		"immutable method symbol not found in the contract ABI",
+	}
+	if !reflect.DeepEqual(expectedWarnings, warnings) {
+		t.Errorf(
+			"unexpected warnings\nexpected: [%v]\nactual:   [%v]",
+			expectedWarnings,
+			warnings,
+		)
+	}
+}
+
+func TestMethodPayableInfo(t *testing.T) {
+	var tests = map[string]struct {
+		abiEntry        string
+		expectedPayable bool
+	}{
+		"payable flag": {
+			abiEntry:        `{"type":"function","name":"deposit","payable":true}`,
+			expectedPayable: true,
+		},
+		"payable state mutability": {
+			abiEntry:        `{"type":"function","name":"deposit","stateMutability":"payable"}`,
+			expectedPayable: true,
+		},
+		"nonpayable state mutability": {
+			abiEntry:        `{"type":"function","name":"ping","stateMutability":"nonpayable"}`,
+			expectedPayable: false,
+		},
+		"no payability": {
+			abiEntry:        `{"type":"function","name":"ping"}`,
+			expectedPayable: false,
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			var payableInfo methodPayableInfo
+			if err := json.Unmarshal([]byte(test.abiEntry), &payableInfo); err != nil {
+				t.Fatal(err)
+			}
+
+			if payableInfo.isPayable() != test.expectedPayable {
+				t.Errorf(
+					"unexpected payability\nexpected: [%v]\nactual:   [%v]",
+					test.expectedPayable,
+					payableInfo.isPayable(),
+				)
+			}
+		})
+	}
+}
+
+func TestMethodPayableModifiers(t *testing.T) {
+	var tests = map[string]struct {
+		method            abi.Method
+		expectedModifiers string
+	}{
+		"payable state mutability": {
+			method: abi.Method{
+				Name:            "deposit",
+				RawName:         "deposit",
+				StateMutability: "payable",
+			},
+			expectedModifiers: "payable ",
+		},
+		"legacy payable flag": {
+			method: abi.Method{
+				Name:    "deposit",
+				RawName: "deposit",
+				Payable: true,
+			},
+			expectedModifiers: "payable ",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			payableMethods := map[string]struct{}{"deposit": {}}
+			methods := map[string]abi.Method{"deposit": test.method}
+
+			_, nonConstMethods := buildMethodInfo(
+				payableMethods,
+				methods,
+				make(map[string]struct{}),
+			)
+
+			if nonConstMethods[0].Modifiers != test.expectedModifiers {
+				t.Errorf(
+					"unexpected modifiers\nexpected: [%v]\nactual:   [%v]",
+					test.expectedModifiers,
+					nonConstMethods[0].Modifiers,
+				)
+			}
+		})
+	}
+}
+
 // TODO: Implement tests for Inputs type bindings including structs.
 func TestEventStability(t *testing.T) {
 	allEvents := make(map[string]abi.Event)
@@ -198,3 +340,121 @@ func TestEventStability(t *testing.T) {
 		}
 	}
 }
+
+func TestEventHashedIndexedParams(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(
+		`[{"type":"event","name":"Labeled","anonymous":false,"inputs":[` +
+			`{"name":"label","type":"string","indexed":true},` +
+			`{"name":"payload","type":"bytes","indexed":true},` +
+			`{"name":"owner","type":"address","indexed":true},` +
+			`{"name":"note","type":"string","indexed":false}]}]`,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := buildEventInfo("l", parsed.Events, make(map[string]struct{}))
+
+	expectedHashedIndexedParams := []hashedIndexedParamInfo{
+		{CapsName: "Label", GoType: "string"},
+		{CapsName: "Payload", GoType: "[]byte"},
+	}
+	if !reflect.DeepEqual(
+		expectedHashedIndexedParams,
+		events[0].HashedIndexedParams,
+	) {
+		t.Errorf(
+			"unexpected hashed indexed params\nexpected: [%v]\nactual:   [%v]",
+			expectedHashedIndexedParams,
+			events[0].HashedIndexedParams,
+		)
+	}
+
+	expectedParamDeclarations := "LabelHash common.Hash,\n" +
+		"PayloadHash common.Hash,\n" +
+		"Owner common.Address,\n" +
+		"Note string,\n" +
+		"blockNumber uint64,\n"
+	if expectedParamDeclarations != events[0].ParamDeclarations {
+		t.Errorf(
+			"unexpected param declarations\nexpected: [%v]\nactual:   [%v]",
+			expectedParamDeclarations,
+			events[0].ParamDeclarations,
+		)
+	}
+
+	expectedFieldEntries := "\"labelHash\": event.Label,\n" +
+		"\"payloadHash\": event.Payload,\n" +
+		"\"owner\": event.Owner,\n" +
+		"\"note\": event.Note,\n" +
+		"\"blockNumber\": event.Raw.BlockNumber,\n"
+	if expectedFieldEntries != events[0].FieldEntries {
+		t.Errorf(
+			"unexpected field entries\nexpected: [%v]\nactual:   [%v]",
+			expectedFieldEntries,
+			events[0].FieldEntries,
+		)
+	}
+}
+
+func TestFindStructCollisions(t *testing.T) {
+	parseABI := func(json string) *abi.ABI {
+		parsed, err := abi.JSON(strings.NewReader(json))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &parsed
+	}
+
+	txInfoABI := func(method string, structName string) string {
+		return `[{"type":"function","name":"` + method + `","stateMutability":"nonpayable","outputs":[],"inputs":[` +
+			`{"name":"info","type":"tuple","internalType":"struct ` + structName + `","components":[` +
+			`{"name":"version","type":"bytes4"},{"name":"locktime","type":"bytes4"}]}]}]`
+	}
+
+	var tests = map[string]struct {
+		contractABIs       map[string]*abi.ABI
+		expectedCollisions []string
+	}{
+		"no collisions": {
+			contractABIs: map[string]*abi.ABI{
+				"Bridge": parseABI(txInfoABI("submit", "BitcoinTx.Info")),
+			},
+			expectedCollisions: []string{},
+		},
+		"same struct declared by multiple contracts": {
+			contractABIs: map[string]*abi.ABI{
+				"Bridge":            parseABI(txInfoABI("submit", "BitcoinTx.Info")),
+				"WalletCoordinator": parseABI(txInfoABI("propose", "BitcoinTx.Info")),
+			},
+			expectedCollisions: []string{
+				"struct BitcoinTxInfo is declared identically by contracts: " +
+					"Bridge, WalletCoordinator",
+			},
+		},
+		"same structure declared under different names": {
+			contractABIs: map[string]*abi.ABI{
+				"Bridge":            parseABI(txInfoABI("submit", "BitcoinTx.Info")),
+				"WalletCoordinator": parseABI(txInfoABI("propose", "Wallet.TxInfo")),
+			},
+			expectedCollisions: []string{
+				"structs BitcoinTxInfo, WalletTxInfo are structurally identical: " +
+					"(version bytes4, locktime bytes4)",
+			},
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			collisions := findStructCollisions(test.contractABIs)
+
+			if !reflect.DeepEqual(collisions, test.expectedCollisions) {
+				t.Errorf(
+					"unexpected collisions\nexpected: [%v]\nactual:   [%v]",
+					test.expectedCollisions,
+					collisions,
+				)
+			}
+		})
+	}
+}
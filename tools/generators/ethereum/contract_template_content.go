@@ -10,6 +10,7 @@ import (
 	"strings"
 	"sync"
 
+	hostchain "{{.HostChainModule}}"
 	hostchainabi "{{.HostChainModule}}/accounts/abi"
 	"{{.HostChainModule}}/accounts/abi/bind"
 	"{{.HostChainModule}}/accounts/keystore"
@@ -36,16 +37,67 @@ type {{.Class}} struct {
 	contractABI        *hostchainabi.ABI
 	caller             bind.ContractCaller
 	transactor         bind.ContractTransactor
+	filterer           bind.ContractFilterer
 	callerOptions      *bind.CallOpts
 	transactorOptions  *bind.TransactOpts
 	errorResolver      *chainutil.ErrorResolver
 	nonceManager       *ethereum.NonceManager
 	miningWaiter       *chainutil.MiningWaiter
-	blockCounter	   *ethereum.BlockCounter
+	blockCounter	   ethereum.BlockCounter
+	deploymentBlock    *uint64
+{{- range $i, $method := .ConstMethods }}
+{{- if $method.Immutable }}
+	{{$method.LowerName}}Cache chainutil.ImmutableValue[{{$method.Return.Type}}]
+{{- end }}
+{{- end }}
 
 	transactionMutex *sync.Mutex
 }
 
+// {{.Class}}Caller is the interface of the read-only methods of
+// {{.Class}}. Code depending only on the contract state can depend on
+// this interface instead of the whole contract so that it can be easily
+// mocked.
+type {{.Class}}Caller interface {
+{{- range $i, $method := .ConstMethods }}
+{{- if $i }}
+{{ end }}
+	{{$method.CapsName}}(
+		{{$method.ParamDeclarations -}}
+		{{if $method.Payable -}} value *big.Int, {{- end -}}
+	) ({{$method.Return.Type}}, error)
+
+	{{$method.CapsName}}AtBlock(
+		{{$method.ParamDeclarations -}}
+		{{if $method.Payable -}} value *big.Int, {{- end -}}
+		blockNumber *big.Int,
+	) ({{$method.Return.Type}}, error)
+{{- end }}
+}
+
+// {{.Class}}Transactor is the interface of the methods of {{.Class}}
+// submitting transactions. Code mutating the contract state can depend on
+// this interface instead of the whole contract so that it can be easily
+// mocked.
+type {{.Class}}Transactor interface {
+{{- range $i, $method := .NonConstMethods }}
+{{- if $i }}
+{{ end }}
+	{{$method.CapsName}}(
+		{{$method.ParamDeclarations -}}
+		{{- if $method.Payable -}}
+		value *big.Int,
+		{{ end }}
+		transactionOptions ...chainutil.TransactionOptions,
+	) (*types.Transaction, error)
+{{- end }}
+}
+
+var (
+	_ {{.Class}}Caller     = (*{{.Class}})(nil)
+	_ {{.Class}}Transactor = (*{{.Class}})(nil)
+)
+
 func New{{.Class}}(
 	contractAddress common.Address,
 	chainId *big.Int,
@@ -53,7 +105,7 @@ func New{{.Class}}(
 	backend bind.ContractBackend,
 	nonceManager *ethereum.NonceManager,
 	miningWaiter *chainutil.MiningWaiter,
-	blockCounter *ethereum.BlockCounter,
+	blockCounter ethereum.BlockCounter,
 	transactionMutex *sync.Mutex,
 ) (*{{.Class}}, error) {
 	callerOptions := &bind.CallOpts{
@@ -91,6 +143,7 @@ func New{{.Class}}(
 		contractABI: 	   &contractABI,
 		caller:     	   backend,
 		transactor:        backend,
+		filterer:          backend,
 		callerOptions:     callerOptions,
 		transactorOptions: transactorOptions,
 		errorResolver:     chainutil.NewErrorResolver(backend, &contractABI, &contractAddress),
@@ -101,6 +154,108 @@ func New{{.Class}}(
 	}, nil
 }
 
+// New{{.Class}}WithClient creates the contract handle for the given client,
+// setting up the nonce manager, mining waiter, and block counter on top of
+// it. The client can be wrapped with any middleware, e.g. rate limiting or
+// call logging, before it is passed, so that all the calls and transactions
+// of the contract handle go through that middleware.
+func New{{.Class}}WithClient(
+	contractAddress common.Address,
+	chainId *big.Int,
+	accountKey *keystore.Key,
+	client chainutil.EthereumClient,
+	config ethereum.Config,
+) (*{{.Class}}, error) {
+	blockCounter, err := chainutil.NewBlockCounter(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create block counter: [%v]", err)
+	}
+
+	return New{{.Class}}(
+		contractAddress,
+		chainId,
+		accountKey,
+		client,
+		chainutil.NewNonceManager(client, accountKey.Address),
+		chainutil.NewMiningWaiter(client, config),
+		blockCounter,
+		&sync.Mutex{},
+	)
+}
+
+// SetDeploymentBlock sets the number of the block the contract was deployed
+// at. The deployment block is the starting point when all past events of
+// the contract are fetched.
+func ({{.ShortVar}} *{{.Class}}) SetDeploymentBlock(blockNumber uint64) {
+	{{.ShortVar}}.deploymentBlock = &blockNumber
+}
+
+// EnsureDeployed verifies there is a contract deployed at the address of
+// the contract handle. It lets the client fail fast with a clear message
+// when the address is misconfigured instead of failing on the first call.
+func ({{.ShortVar}} *{{.Class}}) EnsureDeployed(ctx context.Context) error {
+	deployed, err := chainutil.IsContractDeployed(
+		ctx,
+		{{.ShortVar}}.caller,
+		{{.ShortVar}}.contractAddress,
+	)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to check whether {{.Class}} is deployed: [%v]",
+			err,
+		)
+	}
+
+	if !deployed {
+		return fmt.Errorf(
+			"no {{.Class}} contract code found at address [%v]; make sure "+
+				"the contract address and the network are correct",
+			{{.ShortVar}}.contractAddress.Hex(),
+		)
+	}
+
+	return nil
+}
+
+// Decode{{.Class}}Calldata decodes the input data of a transaction calling
+// {{.Class}} into the name of the called method and its arguments keyed by
+// the parameter names. It returns an error if the method selector does not
+// match any of the contract methods.
+func Decode{{.Class}}Calldata(
+	data []byte,
+) (string, map[string]interface{}, error) {
+	if len(data) < 4 {
+		return "", nil, fmt.Errorf(
+			"calldata of [%v] bytes does not contain a method selector",
+			len(data),
+		)
+	}
+
+	contractABI, err := hostchainabi.JSON(strings.NewReader(abi.{{.AbiClass}}ABI))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to instantiate ABI: [%v]", err)
+	}
+
+	method, err := contractABI.MethodById(data[:4])
+	if err != nil {
+		return "", nil, fmt.Errorf(
+			"unknown {{.Class}} method selector [%#x]",
+			data[:4],
+		)
+	}
+
+	args := make(map[string]interface{})
+	if err := method.Inputs.UnpackIntoMap(args, data[4:]); err != nil {
+		return "", nil, fmt.Errorf(
+			"failed to unpack [%v] arguments: [%v]",
+			method.Name,
+			err,
+		)
+	}
+
+	return method.Name, args, nil
+}
+
 // ----- Non-const Methods ------
 {{template "contract_non_const_methods.go.tmpl" .}}
 
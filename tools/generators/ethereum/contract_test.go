@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConstMethodsCallLabel(t *testing.T) {
+	templates, err := parseTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contract := contractInfo{
+		Class:    "StakingContract",
+		ShortVar: "sc",
+		ConstMethods: []methodInfo{
+			{
+				CapsName:          "BalanceOf",
+				LowerName:         "balanceOf",
+				Params:            "arg_account,",
+				ParamDeclarations: "arg_account common.Address,",
+				Return:            returnInfo{Type: "*big.Int"},
+			},
+		},
+	}
+
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(
+		&buffer,
+		"contract_const_methods.go.tmpl",
+		contract,
+	); err != nil {
+		t.Fatal(err)
+	}
+	code := buffer.String()
+
+	var tests = map[string]struct {
+		functionDeclaration string
+	}{
+		"plain call": {
+			functionDeclaration: "func (sc *StakingContract) BalanceOf(",
+		},
+		"call at block": {
+			functionDeclaration: "func (sc *StakingContract) BalanceOfAtBlock(",
+		},
+	}
+
+	for testName, test := range tests {
+		t.Run(testName, func(t *testing.T) {
+			start := strings.Index(code, test.functionDeclaration)
+			if start == -1 {
+				t.Fatalf(
+					"function [%v] has not been generated",
+					test.functionDeclaration,
+				)
+			}
+
+			// The function body ends with the first closing brace at
+			// the beginning of a line.
+			body := code[start:]
+			if end := strings.Index(body, "\n}"); end != -1 {
+				body = body[:end]
+			}
+
+			expectedLabel := `chainutil.WithCallLabelOpts(
+			sc.callerOptions,
+			"StakingContract.balanceOf",
+		)`
+			if !strings.Contains(body, expectedLabel) {
+				t.Errorf(
+					"call of [%v] is not labeled\nexpected label: [%v]\nbody: [%v]",
+					test.functionDeclaration,
+					expectedLabel,
+					body,
+				)
+			}
+		})
+	}
+}